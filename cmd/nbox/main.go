@@ -5,13 +5,21 @@ import (
 	"fmt"
 	"log"
 	"nbox/internal/adapters/amazonaws"
+	"nbox/internal/adapters/boltdb"
+	"nbox/internal/adapters/events"
+	"nbox/internal/adapters/local"
 	"nbox/internal/adapters/persistence"
+	"nbox/internal/adapters/secrets"
 	"nbox/internal/adapters/sse"
+	"nbox/internal/adapters/vault"
+	"nbox/internal/adapters/webhook"
 	"nbox/internal/application"
 	"nbox/internal/domain"
+	"nbox/internal/domain/models"
 	"nbox/internal/entrypoints/api/auth"
 	"nbox/internal/entrypoints/api/handlers"
 	"nbox/internal/entrypoints/httpapi"
+	"nbox/internal/entrypoints/scheduler"
 	"nbox/internal/usecases"
 	"nbox/pkg/logger"
 	"os"
@@ -20,6 +28,7 @@ import (
 
 	"go.uber.org/zap"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/norlis/httpgate/pkg/adapter/apidriven/presenters"
 	status "github.com/norlis/httpgate/pkg/application/health"
 	"go.uber.org/fx"
@@ -56,26 +65,17 @@ func main() {
 	flag.StringVar(&application.Address, "address", "", "--address=0.0.0.0")
 	flag.Parse()
 
+	config := application.NewConfigFromEnv()
+
 	app := fx.New(
 		fx.Provide(logger.NewLogger),
 		fx.WithLogger(func(log *zap.Logger) fxevent.Logger {
 			return &fxevent.ZapLogger{Logger: log}
 		}),
-		fx.Provide(amazonaws.NewAwsConfig),
-		fx.Provide(amazonaws.NewS3Client),
-		fx.Provide(amazonaws.NewDynamodbClient),
-		fx.Provide(amazonaws.NewSsmClient),
-
-		// Checkers (health | status | live | ready)
-		fx.Provide(amazonaws.NewS3Checker),
-		fx.Provide(amazonaws.NewDynamoDBChecker),
-		fx.Provide(amazonaws.NewSSMChecker),
-
-		// Adapters
-		fx.Provide(amazonaws.NewS3TemplateStore),
-		fx.Provide(amazonaws.NewDynamodbBackend),
-		fx.Provide(amazonaws.NewSecureParameterStore),
-		fx.Provide(amazonaws.NewTypeValidatorBackend),
+
+		// Adapters + checkers (health | status | live | ready)
+		storageBackend(config),
+		secretBackends(config),
 
 		// Handlers
 		fx.Provide(handlers.NewEntryHandler),
@@ -84,22 +84,49 @@ func main() {
 		fx.Provide(handlers.NewUIHandler),
 		fx.Provide(handlers.NewTypeValidatorHandler),
 		fx.Provide(handlers.NewExportHandler),
+		fx.Provide(handlers.NewImportHandler),
+		fx.Provide(handlers.NewWebhookHandler),
+		fx.Provide(handlers.NewAuditHandler),
+		fx.Provide(handlers.NewRotationHandler),
+		fx.Provide(handlers.NewReconcileHandler),
+		fx.Provide(handlers.NewResolveHandler),
+		fx.Provide(handlers.NewSearchHandler),
 
 		// Use case
 		fx.Provide(usecases.NewPathUseCase),
+		fx.Provide(usecases.NewKeyPolicy),
 		fx.Provide(usecases.NewEntryUseCase),
+		fx.Provide(usecases.NewEntryDeleteUseCase),
+		fx.Provide(usecases.NewTemplateUseCase),
 		fx.Provide(usecases.NewBox),
 		fx.Provide(usecases.NewExportUseCase),
+		fx.Provide(usecases.NewImportUseCase),
+		fx.Provide(usecases.NewRollbackUseCase),
+		fx.Provide(usecases.NewRetypeUseCase),
+		fx.Provide(usecases.NewSecretCheckUseCase),
+		fx.Provide(usecases.NewSecretRevealUseCase),
+		fx.Provide(usecases.NewRotationUseCase),
+		fx.Provide(usecases.NewReconcileUseCase),
+		fx.Provide(usecases.NewResolveUseCase),
+		fx.Provide(usecases.NewEntryListUseCase),
+		fx.Provide(usecases.NewSearchUseCase),
+		fx.Provide(usecases.NewWebhookUseCase),
+		fx.Provide(usecases.NewTypeValidatorUseCase),
 
 		fx.Decorate(usecases.NewEntryUseCaseWithEvents),
+		fx.Decorate(usecases.NewEntryDeleteUseCaseWithEvents),
+		fx.Decorate(usecases.NewTemplateUseCaseWithEvents),
 		fx.Provide(usecases.NewEventUseCase),
 
 		// sse
 		fx.Provide(sse.NewEventBroker),
-		fx.Provide(sse.NewInMemoryEventPublisher),
+		fx.Provide(httpapi.NewEventAuthorizer),
+
+		// events fan-out: sse, webhooks, audit file, stdout
+		eventSinks(config),
 
 		// config
-		fx.Provide(application.NewConfigFromEnv),
+		fx.Supply(config),
 		fx.Provide(func() *status.Status {
 			version := application.GitHash
 			if version == "" {
@@ -109,6 +136,7 @@ func main() {
 		}),
 		fx.Provide(presenters.NewPresenters),
 		fx.Provide(httpapi.NewHttpServerMux),
+		fx.Provide(httpapi.NewOpaClient),
 		fx.Provide(func() domain.UserRepository {
 			credentials := os.Getenv(application.EnvCredentials)
 			repo, err := persistence.NewInMemoryUserRepository([]byte(credentials))
@@ -121,6 +149,8 @@ func main() {
 			return auth.NewAuthn(application.EnvCredentials, config, render, logger, repo)
 		}),
 		fx.Invoke(httpapi.NewHttpApi),
+		fx.Invoke(scheduler.NewRotationScheduler),
+		fx.Invoke(scheduler.NewReconcileScheduler),
 	)
 
 	if err := app.Err(); err != nil {
@@ -130,3 +160,142 @@ func main() {
 	app.Run()
 
 }
+
+// storageBackend selects the adapters set for entries, secrets, templates, type validators, webhooks, the secret audit and rotation policies
+func storageBackend(config *application.Config) fx.Option {
+	switch config.StorageBackend {
+	case application.StorageBackendLocal:
+		return fx.Options(
+			fx.Provide(boltdb.NewBoltDB),
+			fx.Provide(boltdb.NewBoltChecker),
+			fx.Provide(func(checker *boltdb.BoltChecker) httpapi.ReadinessCheckers {
+				return httpapi.ReadinessCheckers{"local": checker}
+			}),
+
+			fx.Provide(boltdb.NewBoltTemplateStore),
+			fx.Provide(boltdb.NewBoltEntryBackend),
+			fx.Provide(boltdb.NewBoltSecretStore),
+			fx.Provide(boltdb.NewBoltTypeValidatorBackend),
+			fx.Provide(boltdb.NewBoltWebhookRepository),
+			fx.Provide(boltdb.NewBoltSecretAuditRepository),
+			fx.Provide(boltdb.NewBoltRotationRepository),
+		)
+	case application.StorageBackendAWS:
+		return fx.Options(
+			fx.Provide(amazonaws.NewAwsConfig),
+			fx.Provide(amazonaws.NewS3Client),
+			fx.Provide(amazonaws.NewDynamodbClient),
+			fx.Provide(amazonaws.NewSsmClient),
+
+			fx.Provide(amazonaws.NewS3Checker),
+			fx.Provide(amazonaws.NewDynamoDBChecker),
+			fx.Provide(amazonaws.NewSSMChecker),
+			fx.Provide(func(s3 *amazonaws.S3Checker, dynamodb *amazonaws.DynamoDBChecker, ssm *amazonaws.SSMChecker) httpapi.ReadinessCheckers {
+				return httpapi.ReadinessCheckers{"s3": s3, "ssm": ssm, "dynamodb": dynamodb}
+			}),
+
+			fx.Provide(amazonaws.NewS3TemplateStore),
+			fx.Provide(amazonaws.NewDynamodbBackend),
+			fx.Provide(amazonaws.NewSecureParameterStore),
+			fx.Provide(amazonaws.NewTypeValidatorBackend),
+			fx.Provide(amazonaws.NewWebhookRepository),
+			fx.Provide(amazonaws.NewSecretAuditRepository),
+			fx.Provide(amazonaws.NewRotationRepository),
+		)
+	default:
+		return fx.Error(fmt.Errorf("unsupported storage backend %q (NBOX_STORAGE_BACKEND)", config.StorageBackend))
+	}
+}
+
+// secretBackends backends named by NBOX_SECRET_BACKENDS, the secret store of the storage backend
+// (ssm) is decorated by a router that writes each prefix in its backend
+func secretBackends(config *application.Config) fx.Option {
+	if len(config.SecretRoutes) == 0 {
+		return fx.Options()
+	}
+
+	options := []fx.Option{fx.Decorate(secrets.NewSecretRouter)}
+	provided := map[string]bool{models.SecretBackendSSM: true}
+
+	for _, route := range config.SecretRoutes {
+		name := route.Backend
+		if provided[name] {
+			continue
+		}
+		provided[name] = true
+
+		var provider any
+		switch name {
+		case models.SecretBackendSecretsManager:
+			if config.StorageBackend != application.StorageBackendAWS {
+				options = append(options, fx.Provide(amazonaws.NewAwsConfig))
+			}
+			provider = func(cfg *aws.Config, logger *zap.Logger) secrets.Backend {
+				return secrets.Backend{Name: name, Adapter: amazonaws.NewSecretsManagerStore(cfg, config, logger)}
+			}
+		case models.SecretBackendVault:
+			provider = func(logger *zap.Logger) (secrets.Backend, error) {
+				adapter, err := vault.NewSecretStore(config, logger)
+				return secrets.Backend{Name: name, Adapter: adapter}, err
+			}
+		case models.SecretBackendLocal:
+			provider = func(logger *zap.Logger) (secrets.Backend, error) {
+				adapter, err := local.NewSecretStore(config, logger)
+				return secrets.Backend{Name: name, Adapter: adapter}, err
+			}
+		default:
+			return fx.Error(fmt.Errorf("unsupported secret backend %q for prefix %q (NBOX_SECRET_BACKENDS)", name, route.Prefix))
+		}
+
+		options = append(options, fx.Provide(fx.Annotate(provider, fx.ResultTags(`group:"secret_backends"`))))
+	}
+
+	return fx.Options(options...)
+}
+
+// eventSinks sinks enabled by NBOX_EVENT_SINKS, all of them behind the composite publisher
+func eventSinks(config *application.Config) fx.Option {
+	options := []fx.Option{fx.Provide(events.NewCompositePublisher)}
+
+	for _, sinkConfig := range config.EventSinks {
+		filter := make([]domain.EventType, 0, len(sinkConfig.Events))
+		for _, name := range sinkConfig.Events {
+			eventType := domain.EventType(name)
+			if !eventType.IsValid() {
+				return fx.Error(fmt.Errorf("unknown event type %q for sink %q (NBOX_EVENT_SINKS)", name, sinkConfig.Name))
+			}
+			filter = append(filter, eventType)
+		}
+
+		sink := func(publisher domain.EventPublisher) events.Sink {
+			return events.Sink{Name: sinkConfig.Name, Events: filter, Publisher: publisher}
+		}
+
+		var provider any
+		switch sinkConfig.Name {
+		case application.EventSinkSSE:
+			provider = func(broker *sse.EventBroker, logger *zap.Logger) events.Sink {
+				return sink(sse.NewInMemoryEventPublisher(broker, logger))
+			}
+		case application.EventSinkWebhook:
+			provider = func(repository domain.WebhookRepository, logger *zap.Logger) events.Sink {
+				return sink(webhook.NewPublisher(repository, config, logger))
+			}
+		case application.EventSinkAudit:
+			provider = func(lc fx.Lifecycle) (events.Sink, error) {
+				publisher, err := events.NewAuditFilePublisher(lc, config.EventAuditPath)
+				return sink(publisher), err
+			}
+		case application.EventSinkStdout:
+			provider = func() events.Sink {
+				return sink(events.NewStdoutPublisher())
+			}
+		default:
+			return fx.Error(fmt.Errorf("unsupported event sink %q (NBOX_EVENT_SINKS)", sinkConfig.Name))
+		}
+
+		options = append(options, fx.Provide(fx.Annotate(provider, fx.ResultTags(`group:"sinks"`))))
+	}
+
+	return fx.Options(options...)
+}
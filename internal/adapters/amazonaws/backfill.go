@@ -0,0 +1,205 @@
+package amazonaws
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// indexMarkerPath partition of the markers written once the backfill of a GSI completes; it starts
+// with the lock prefix so it is never listed nor accepted as a key
+const indexMarkerPath = DynamoDBLockPrefix + "indexes"
+
+// indexMarkerKey sort key of the marker of the GSI
+func indexMarkerKey(index string) string {
+	return DynamoDBLockPrefix + index
+}
+
+// backfillIndexes entries written before the GSIs existed don't carry their top-level attributes.
+// On start the table is scanned once in the background and those attributes are set; until the
+// marker of an index exists the adapter keeps scanning instead of querying it.
+func (d *dynamodbBackend) backfillIndexes(lc fx.Lifecycle) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+				if err := d.BackfillIndexes(ctx); err != nil && !errors.Is(err, context.Canceled) {
+					d.logger.Error("ErrBackfillIndexes", zap.Error(err))
+				}
+			}()
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+			case <-stopCtx.Done():
+			}
+			return nil
+		},
+	})
+}
+
+// BackfillIndexes sets the GSI attributes of the entries that miss them and marks the configured
+// indexes as complete. It is idempotent, several instances may run it at once.
+func (d *dynamodbBackend) BackfillIndexes(ctx context.Context) error {
+	validator := d.config.EntryValidatorIndex != "" && !d.indexReady(ctx, d.config.EntryValidatorIndex)
+	keys := d.config.EntryKeyIndex != "" && !d.indexReady(ctx, d.config.EntryKeyIndex)
+	if !validator && !keys {
+		return nil
+	}
+
+	d.logger.Info("Backfilling index attributes",
+		zap.Bool("validatorIndex", validator),
+		zap.Bool("keyIndex", keys),
+	)
+
+	updated := 0
+	paginator := dynamodb.NewScanPaginator(d.client, &dynamodb.ScanInput{
+		TableName:      aws.String(d.config.EntryTableName),
+		ConsistentRead: aws.Bool(true),
+	})
+	for paginator.HasMorePages() {
+		response, err := paginator.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+		var page []Record
+		if err = attributevalue.UnmarshalListOfMaps(response.Items, &page); err != nil {
+			return err
+		}
+
+		for _, record := range page {
+			update, condition, ok := backfillUpdate(record, validator, keys, d.pathUseCase.Concat(record.Path, record.Key))
+			if !ok {
+				continue
+			}
+			if err := d.backfillRecord(ctx, record, update, condition); err != nil {
+				return err
+			}
+			updated++
+		}
+	}
+
+	if validator {
+		if err := d.markIndexReady(ctx, d.config.EntryValidatorIndex); err != nil {
+			return err
+		}
+	}
+	if keys {
+		if err := d.markIndexReady(ctx, d.config.EntryKeyIndex); err != nil {
+			return err
+		}
+	}
+
+	d.logger.Info("Index attributes backfilled", zap.Int("updated", updated))
+	return nil
+}
+
+// backfillUpdate attributes missing in the record, false when there is nothing to set. The
+// condition skips entries rewritten or deleted since the scan, the new write carries them.
+func backfillUpdate(record Record, validator, keys bool, fullKey string) (expression.UpdateBuilder, expression.ConditionBuilder, bool) {
+	var update expression.UpdateBuilder
+	condition := expression.AttributeExists(expression.Name("Path"))
+	if record.RecordBase == nil || strings.HasPrefix(record.Path, DynamoDBLockPrefix) ||
+		strings.HasPrefix(record.Key, DynamoDBLockPrefix) || strings.HasSuffix(record.Key, "/") {
+		return update, condition, false
+	}
+
+	set := false
+	if name := record.Metadata.TypeValidatorName; validator && name != "" && record.TypeValidatorName != name {
+		update = update.Set(expression.Name("TypeValidatorName"), expression.Value(name))
+		condition = condition.And(expression.Name("Metadata.TypeValidatorName").Equal(expression.Value(name)))
+		set = true
+	}
+	if keys && (record.FullKey != fullKey || record.KeyRoot != keyRoot(fullKey)) {
+		update = update.Set(expression.Name("KeyRoot"), expression.Value(keyRoot(fullKey))).
+			Set(expression.Name("FullKey"), expression.Value(fullKey))
+		set = true
+	}
+
+	return update, condition, set
+}
+
+func (d *dynamodbBackend) backfillRecord(ctx context.Context, record Record, update expression.UpdateBuilder, condition expression.ConditionBuilder) error {
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(condition).Build()
+	if err != nil {
+		return err
+	}
+	p, _ := attributevalue.Marshal(record.Path)
+	k, _ := attributevalue.Marshal(record.Key)
+
+	_, err = d.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(d.config.EntryTableName),
+		Key:                       map[string]types.AttributeValue{"Path": p, "Key": k},
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		UpdateExpression:          expr.Update(),
+		ConditionExpression:       expr.Condition(),
+	})
+	var conditionErr *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		return nil
+	}
+	return err
+}
+
+// indexReady the backfill of the index completed. Errors count as not ready, the scan is always correct.
+func (d *dynamodbBackend) indexReady(ctx context.Context, index string) bool {
+	if _, ok := d.readyIndexes.Load(index); ok {
+		return true
+	}
+
+	p, _ := attributevalue.Marshal(indexMarkerPath)
+	k, _ := attributevalue.Marshal(indexMarkerKey(index))
+	resp, err := d.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(d.config.EntryTableName),
+		Key:            map[string]types.AttributeValue{"Path": p, "Key": k},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		d.logger.Warn("ErrIndexMarker, scanning the table", zap.String("index", index), zap.Error(err))
+		return false
+	}
+	if resp.Item == nil {
+		return false
+	}
+
+	d.readyIndexes.Store(index, struct{}{})
+	return true
+}
+
+func (d *dynamodbBackend) markIndexReady(ctx context.Context, index string) error {
+	item, err := attributevalue.MarshalMap(Record{
+		Path: indexMarkerPath,
+		RecordBase: &RecordBase{
+			Key: indexMarkerKey(index),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	item["BackfilledAt"], _ = attributevalue.Marshal(time.Now().UTC().Format(time.RFC3339))
+
+	if _, err = d.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(d.config.EntryTableName),
+		Item:      item,
+	}); err != nil {
+		return err
+	}
+
+	d.readyIndexes.Store(index, struct{}{})
+	return nil
+}
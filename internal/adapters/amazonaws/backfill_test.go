@@ -0,0 +1,65 @@
+package amazonaws
+
+import (
+	"nbox/internal/domain/models"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+)
+
+func TestBackfillUpdate_TypeValidator(t *testing.T) {
+	legacy := Record{
+		Path:       "development/app",
+		RecordBase: &RecordBase{Key: "port", Metadata: models.Metadata{TypeValidatorName: "port"}},
+	}
+	update, condition, ok := backfillUpdate(legacy, true, false, "development/app/port")
+	if !ok {
+		t.Fatal("legacy entries with a validator must be backfilled")
+	}
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(condition).Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if expr.Update() == nil || expr.Condition() == nil {
+		t.Error("the update must set the attribute under a condition")
+	}
+
+	skipped := map[string]Record{
+		"already indexed": {Path: "development/app", TypeValidatorName: "port", RecordBase: &RecordBase{Key: "port", Metadata: models.Metadata{TypeValidatorName: "port"}}},
+		"no validator":    {Path: "development/app", RecordBase: &RecordBase{Key: "host"}},
+		"folder marker":   {Path: "development", RecordBase: &RecordBase{Key: "app/"}},
+		"lock":            {Path: "development/app", RecordBase: &RecordBase{Key: "_lock", Metadata: models.Metadata{TypeValidatorName: "port"}}},
+		"index marker":    {Path: indexMarkerPath, RecordBase: &RecordBase{Key: indexMarkerKey("validator-index")}},
+	}
+	for name, record := range skipped {
+		if _, _, ok := backfillUpdate(record, true, false, record.Path+"/"+record.Key); ok {
+			t.Errorf("%s: nothing to backfill expected", name)
+		}
+	}
+
+	if _, _, ok := backfillUpdate(legacy, false, false, "development/app/port"); ok {
+		t.Error("without the validator index configured nothing is backfilled")
+	}
+}
+
+func TestBackfillUpdate_KeyIndex(t *testing.T) {
+	legacy := Record{Path: "production/myapp", RecordBase: &RecordBase{Key: "db_host"}}
+	if _, _, ok := backfillUpdate(legacy, false, true, "production/myapp/db_host"); !ok {
+		t.Fatal("legacy entries must get KeyRoot and FullKey")
+	}
+
+	indexed := Record{
+		Path:       "production/myapp",
+		KeyRoot:    "production",
+		FullKey:    "production/myapp/db_host",
+		RecordBase: &RecordBase{Key: "db_host"},
+	}
+	if _, _, ok := backfillUpdate(indexed, false, true, "production/myapp/db_host"); ok {
+		t.Error("indexed entries need no update")
+	}
+
+	folder := Record{Path: "production", RecordBase: &RecordBase{Key: "myapp/"}}
+	if _, _, ok := backfillUpdate(folder, false, true, "production/myapp/"); ok {
+		t.Error("folder markers stay out of the sparse index")
+	}
+}
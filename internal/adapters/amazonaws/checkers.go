@@ -52,7 +52,8 @@ func (c *DynamoDBChecker) Check() error {
 		c.config.TrackingEntryTableName,
 		c.config.BoxTableName,
 		c.config.TypeValidatorTableName,
-		// c.config.WebhookConfigTableName, // TODO Descomentar esto cuando se active
+		c.config.WebhookConfigTableName,
+		c.config.WebhookDeliveryTableName,
 	}
 
 	for _, tableName := range tableNames {
@@ -11,10 +11,13 @@ import (
 	"nbox/internal/domain/models"
 	"nbox/internal/domain/models/operations"
 	"nbox/internal/usecases"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"go.uber.org/fx"
 	"go.uber.org/zap"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -26,8 +29,11 @@ import (
 )
 
 const (
-	DynamoDBLockPrefix        = "_"
+	DynamoDBLockPrefix        = domain.ReservedKeyPrefix
 	DefaultParallelOperations = 128
+	MaxVersionRetries         = 3
+	// MaxTransactItems actions of a TransactWriteItems call
+	MaxTransactItems = 100
 )
 
 var ErrBackendTimeout = errors.New("dynamodb: timeout handling Unprocessed Items")
@@ -46,6 +52,13 @@ type RecordBase struct {
 
 type Record struct {
 	Path string `dynamodbav:"Path"`
+	// TypeValidatorName copy of Metadata.TypeValidatorName at the top level, partition key of the
+	// sparse GSI used to list the entries of a validator (a GSI can't use nested attributes)
+	TypeValidatorName string `dynamodbav:"TypeValidatorName,omitempty"`
+	// KeyRoot first component of the full key and FullKey the key itself, partition and sort key of
+	// the sparse GSI of the key search. Folder markers and locks don't carry them.
+	KeyRoot string `dynamodbav:"KeyRoot,omitempty"`
+	FullKey string `dynamodbav:"FullKey,omitempty"`
 	*RecordBase
 }
 
@@ -85,113 +98,260 @@ type dynamodbBackend struct {
 	permitPool  *PermitPool
 	pathUseCase *usecases.PathUseCase
 	logger      *zap.Logger
+	// readyIndexes GSIs whose backfill completed
+	readyIndexes sync.Map
 }
 
-func NewDynamodbBackend(dynamodb *dynamodb.Client, config *application.Config, pathUseCase *usecases.PathUseCase, logger *zap.Logger) domain.EntryAdapter {
-	return &dynamodbBackend{
+func NewDynamodbBackend(lc fx.Lifecycle, dynamodb *dynamodb.Client, config *application.Config, pathUseCase *usecases.PathUseCase, logger *zap.Logger) domain.EntryAdapter {
+	backend := &dynamodbBackend{
 		client:      dynamodb,
 		config:      config,
 		permitPool:  NewPermitPool(0),
 		pathUseCase: pathUseCase,
 		logger:      logger,
 	}
+	backend.backfillIndexes(lc)
+	return backend
 }
 
-func (d *dynamodbBackend) cleanedKey(key string) string {
-	for _, prefix := range d.config.AllowedPrefixes {
-		if strings.HasPrefix(key, prefix) {
-			return key
-		}
-	}
-	return fmt.Sprintf(
-		"%s/%s", strings.Trim(d.config.DefaultPrefix, "/"), key,
-	)
-}
-
+// sanitize same normalization as the key policy, so the stored key is the one returned
 func (d *dynamodbBackend) sanitize(key string) string {
-	key = strings.ToLower(key)
-	key = strings.TrimSpace(key)
-	key = strings.Trim(key, "/")
-
-	key = d.cleanedKey(key)
-
-	return key
+	return usecases.NormalizeKey(d.config, key)
 }
 
-// Upsert is used to insert or update an entry
+// Upsert is used to insert or update an entry.
+// Entries are written one by one with a conditional PutItem on their current version so
+// concurrent writers cannot clobber each other; folder markers and tracking go in batches.
 func (d *dynamodbBackend) Upsert(ctx context.Context, entries []models.Entry) operations.Results {
 	records := map[string]Record{}
 	tracking := map[string]RecordTracking{}
 	results := make(operations.Results, len(entries))
+	updatedBy, action := writer(ctx)
 
-	updatedBy := "ghost"
-	action := "upsert"
-
-	user, ok := application.UserFromContext(ctx)
-	if ok {
-		updatedBy = user.Name
-	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
 
 	for _, entry := range entries {
 		now := time.Now().UTC()
+		entryKey := d.sanitize(entry.Key)
+		record := d.entryRecord(entryKey, entry, now, updatedBy)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			d.permitPool.Acquire()
+			version, err := d.putVersioned(ctx, record, entry.ExpectedVersion)
+			d.permitPool.Release()
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				opType := operations.Error
+				if errors.Is(err, domain.ErrVersionConflict) {
+					opType = operations.Conflict
+				}
+				d.logger.Error("ErrPutEntry", zap.Error(err), zap.String("key", entryKey))
+				results[entryKey] = operations.Result{Key: entryKey, Type: opType, Error: err}
+				return
+			}
 
+			results[entryKey] = versionedResult(entryKey, version)
+
+			tracking[entryKey] = trackingRecord(entryKey, entry, now, updatedBy, action, version)
+		}()
+
+		d.addFolders(records, entryKey, now, updatedBy)
+	}
+
+	wg.Wait()
+
+	d.writeFoldersAndTracking(ctx, records, tracking)
+
+	return results
+}
+
+// MaxAtomicBatch a TransactWriteItems call holds at most MaxTransactItems actions
+func (d *dynamodbBackend) MaxAtomicBatch() int {
+	return MaxTransactItems
+}
+
+// UpsertAll writes the entries with a single TransactWriteItems, each put conditioned on the
+// version read before. Folder markers and tracking rows are written after the commit, like in
+// Upsert. A transaction holds at most MaxTransactItems entries.
+func (d *dynamodbBackend) UpsertAll(ctx context.Context, entries []models.Entry) (operations.Results, error) {
+	if len(entries) > MaxTransactItems {
+		return nil, fmt.Errorf("%w: an atomic batch holds at most %d entries", domain.ErrBatchSizeTooLarge, MaxTransactItems)
+	}
+
+	records := map[string]Record{}
+	tracking := map[string]RecordTracking{}
+	updatedBy, action := writer(ctx)
+	now := time.Now().UTC()
+
+	keys := make([]string, 0, len(entries))
+	versions := make([]int64, 0, len(entries))
+	items := make([]types.TransactWriteItem, 0, len(entries))
+	for _, entry := range entries {
 		entryKey := d.sanitize(entry.Key)
+		record := d.entryRecord(entryKey, entry, now, updatedBy)
 
-		results[entryKey] = operations.Result{
-			Key:   entryKey,
-			Type:  operations.Updated,
-			Error: nil,
+		current, err := d.currentVersion(ctx, record.Path, record.Key)
+		if err != nil {
+			return operations.Results{entryKey: {Key: entryKey, Type: operations.Error, Error: err}}, err
+		}
+		if entry.ExpectedVersion != nil && *entry.ExpectedVersion != current {
+			err = fmt.Errorf("%w: key '%s' expected version %d, current %d", domain.ErrVersionConflict, entryKey, *entry.ExpectedVersion, current)
+			return operations.Results{entryKey: {Key: entryKey, Type: operations.Conflict, Error: err}}, err
 		}
 
-		path := d.pathUseCase.PathWithoutKey(entryKey)
-		key := d.pathUseCase.BaseKey(entryKey)
+		condition := expression.AttributeNotExists(expression.Name("Metadata.Version"))
+		if current > 0 {
+			condition = expression.Name("Metadata.Version").Equal(expression.Value(current))
+		}
+		expr, err := expression.NewBuilder().WithCondition(condition).Build()
+		if err != nil {
+			return nil, err
+		}
 
-		metadata := models.Metadata{
-			UpdatedAt:         now,
-			UpdatedBy:         updatedBy,
-			Secure:            entry.Secure,
-			TypeValidatorName: entry.TypeValidatorName,
+		record.Metadata.Version = current + 1
+		item, err := attributevalue.MarshalMap(record)
+		if err != nil {
+			return operations.Results{entryKey: {Key: entryKey, Type: operations.Error, Error: err}}, err
 		}
 
-		records[fmt.Sprintf("%s/%s", path, key)] = Record{
-			Path: path,
-			RecordBase: &RecordBase{
-				Key:      key,
-				Value:    []byte(entry.Value),
-				Metadata: metadata,
-			},
+		items = append(items, types.TransactWriteItem{Put: &types.Put{
+			TableName:                 aws.String(d.config.EntryTableName),
+			Item:                      item,
+			ConditionExpression:       expr.Condition(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+		}})
+		keys = append(keys, entryKey)
+		versions = append(versions, record.Metadata.Version)
+
+		tracking[entryKey] = trackingRecord(entryKey, entry, now, updatedBy, action, record.Metadata.Version)
+		d.addFolders(records, entryKey, now, updatedBy)
+	}
+
+	_, err := d.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: items})
+	var canceled *types.TransactionCanceledException
+	if errors.As(err, &canceled) {
+		d.logger.Error("ErrTransactWriteItems", zap.Error(err))
+		failed := make(operations.Results)
+		for i, reason := range canceled.CancellationReasons {
+			if i >= len(keys) || aws.ToString(reason.Code) == "None" {
+				continue
+			}
+			result := operations.Result{Key: keys[i], Type: operations.Error, Error: fmt.Errorf("%s: %s", aws.ToString(reason.Code), aws.ToString(reason.Message))}
+			if aws.ToString(reason.Code) == "ConditionalCheckFailed" {
+				result.Type = operations.Conflict
+				result.Error = fmt.Errorf("%w: key '%s' changed while writing", domain.ErrVersionConflict, keys[i])
+			}
+			failed[keys[i]] = result
 		}
+		return failed, err
+	}
+	if err != nil {
+		d.logger.Error("ErrTransactWriteItems", zap.Error(err))
+		return nil, err
+	}
 
-		tracking[entryKey] = RecordTracking{
-			Timestamp: strconv.FormatInt(now.Unix(), 10),
+	results := make(operations.Results, len(keys))
+	for i, key := range keys {
+		results[key] = versionedResult(key, versions[i])
+	}
+
+	d.writeFoldersAndTracking(ctx, records, tracking)
+
+	return results, nil
+}
+
+// writer user and action recorded in the tracking rows
+func writer(ctx context.Context) (string, string) {
+	updatedBy := "ghost"
+	action := models.ActionUpsert
+
+	if user, ok := application.UserFromContext(ctx); ok {
+		updatedBy = user.Name
+	}
+	if a, ok := application.ActionFromContext(ctx); ok {
+		action = a
+	}
+	return updatedBy, action
+}
+
+func (d *dynamodbBackend) entryRecord(entryKey string, entry models.Entry, now time.Time, updatedBy string) Record {
+	return Record{
+		Path:              d.pathUseCase.PathWithoutKey(entryKey),
+		TypeValidatorName: entry.TypeValidatorName,
+		KeyRoot:           keyRoot(entryKey),
+		FullKey:           entryKey,
+		RecordBase: &RecordBase{
+			Key:   d.pathUseCase.BaseKey(entryKey),
+			Value: []byte(entry.Value),
+			Metadata: models.Metadata{
+				UpdatedAt:         now,
+				UpdatedBy:         updatedBy,
+				Secure:            entry.Secure,
+				TypeValidatorName: entry.TypeValidatorName,
+			},
+		},
+	}
+}
+
+// versionedResult the first version of a key is its creation
+func versionedResult(key string, version int64) operations.Result {
+	if version == 1 {
+		return operations.Result{Key: key, Type: operations.Created}
+	}
+	return operations.Result{Key: key, Type: operations.Updated}
+}
+
+// trackingTimestamp sort key of the tracking rows. Nanoseconds so writes of the same second don't
+// overwrite each other; it still sorts after the rows written with seconds.
+func trackingTimestamp(now time.Time) string {
+	return strconv.FormatInt(now.UnixNano(), 10)
+}
+
+func trackingRecord(entryKey string, entry models.Entry, now time.Time, updatedBy, action string, version int64) RecordTracking {
+	return RecordTracking{
+		Timestamp: trackingTimestamp(now),
+		RecordBase: &RecordBase{
+			Key:   entryKey,
+			Value: []byte(entry.Value),
+			Metadata: models.Metadata{
+				UpdatedAt:         now,
+				UpdatedBy:         updatedBy,
+				Secure:            entry.Secure,
+				Action:            action,
+				Version:           version,
+				TypeValidatorName: entry.TypeValidatorName,
+			},
+		},
+	}
+}
+
+// addFolders folder markers of every parent of the key
+func (d *dynamodbBackend) addFolders(records map[string]Record, entryKey string, now time.Time, updatedBy string) {
+	for _, prefix := range d.pathUseCase.Prefixes(entryKey) {
+		path := d.pathUseCase.PathWithoutKey(prefix)
+		key := fmt.Sprintf("%s/", d.pathUseCase.BaseKey(prefix))
+		records[fmt.Sprintf("%s%s", path, key)] = Record{
+			Path: path,
 			RecordBase: &RecordBase{
-				Key:   entryKey,
-				Value: []byte(entry.Value),
+				Key: key,
 				Metadata: models.Metadata{
 					UpdatedAt: now,
 					UpdatedBy: updatedBy,
-					Secure:    entry.Secure,
-					Action:    action,
 				},
 			},
 		}
-
-		for _, prefix := range d.pathUseCase.Prefixes(entryKey) {
-			path = d.pathUseCase.PathWithoutKey(prefix)
-			key = fmt.Sprintf("%s/", d.pathUseCase.BaseKey(prefix))
-			records[fmt.Sprintf("%s%s", path, key)] = Record{
-				Path: path,
-				RecordBase: &RecordBase{
-					Key: key,
-					Metadata: models.Metadata{
-						UpdatedAt: now,
-						UpdatedBy: updatedBy,
-					},
-				},
-			}
-		}
 	}
+}
 
+func (d *dynamodbBackend) writeFoldersAndTracking(ctx context.Context, records map[string]Record, tracking map[string]RecordTracking) {
 	ch := make(chan BatchResult)
 
 	go func(channel chan BatchResult) {
@@ -202,24 +362,92 @@ func (d *dynamodbBackend) Upsert(ctx context.Context, entries []models.Entry) op
 	result1 := <-ch
 	result2 := <-ch
 
+	if result1.Err != nil {
+		d.logger.Error("ErrSaveFolders", zap.Error(result1.Err))
+	}
+
 	if result2.Err != nil {
 		d.logger.Error("ErrSaveTracking", zap.Error(result2.Err))
 	}
+}
+
+// putVersioned writes the record only if its version did not change since it was read.
+// Without an expected version a concurrent write is retried on top of the new version,
+// with one the write is rejected with ErrVersionConflict.
+func (d *dynamodbBackend) putVersioned(ctx context.Context, record Record, expected *int64) (int64, error) {
+	entryKey := d.pathUseCase.Concat(record.Path, record.Key)
+
+	for attempt := 0; attempt < MaxVersionRetries; attempt++ {
+		current, err := d.currentVersion(ctx, record.Path, record.Key)
+		if err != nil {
+			return 0, err
+		}
+
+		if expected != nil && *expected != current {
+			return 0, fmt.Errorf("%w: key '%s' expected version %d, current %d", domain.ErrVersionConflict, entryKey, *expected, current)
+		}
+
+		// legacy items were written before versions existed and count as version 0
+		condition := expression.AttributeNotExists(expression.Name("Metadata.Version"))
+		if current > 0 {
+			condition = expression.Name("Metadata.Version").Equal(expression.Value(current))
+		}
 
-	for _, req := range result1.Out[d.config.EntryTableName] {
-		key := req.PutRequest.Item["Key"]
-		path := req.PutRequest.Item["Path"]
+		expr, err := expression.NewBuilder().WithCondition(condition).Build()
+		if err != nil {
+			return 0, err
+		}
 
-		k := fmt.Sprintf("%s/%s", path, key)
+		record.Metadata.Version = current + 1
+		item, err := attributevalue.MarshalMap(record)
+		if err != nil {
+			return 0, err
+		}
 
-		results[k] = operations.Result{
-			Key:   k,
-			Type:  operations.Updated,
-			Error: result1.Err,
+		_, err = d.client.PutItem(ctx, &dynamodb.PutItemInput{
+			TableName:                 aws.String(d.config.EntryTableName),
+			Item:                      item,
+			ConditionExpression:       expr.Condition(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+		})
+
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			if expected != nil {
+				return 0, fmt.Errorf("%w: key '%s' changed while writing", domain.ErrVersionConflict, entryKey)
+			}
+			continue
+		}
+		if err != nil {
+			return 0, err
 		}
+
+		return record.Metadata.Version, nil
 	}
 
-	return results
+	return 0, fmt.Errorf("%w: key '%s' too many concurrent writers", domain.ErrVersionConflict, entryKey)
+}
+
+func (d *dynamodbBackend) currentVersion(ctx context.Context, path string, key string) (int64, error) {
+	p, _ := attributevalue.Marshal(path)
+	k, _ := attributevalue.Marshal(key)
+
+	resp, err := d.client.GetItem(ctx, &dynamodb.GetItemInput{
+		Key:            map[string]types.AttributeValue{"Path": p, "Key": k},
+		TableName:      aws.String(d.config.EntryTableName),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil || resp.Item == nil {
+		return 0, err
+	}
+
+	record := &Record{}
+	if err = attributevalue.UnmarshalMap(resp.Item, record); err != nil {
+		return 0, err
+	}
+
+	return record.Metadata.Version, nil
 }
 
 func (d *dynamodbBackend) writeReqsBatch(ctx context.Context, tableName string, requests []types.WriteRequest) BatchResult {
@@ -297,6 +525,7 @@ func (d *dynamodbBackend) Retrieve(ctx context.Context, key string) (*models.Ent
 		Value:             string(record.Value),
 		Secure:            record.Metadata.Secure,
 		TypeValidatorName: record.Metadata.TypeValidatorName,
+		Version:           record.Metadata.Version,
 	}, nil
 }
 
@@ -345,6 +574,7 @@ func (d *dynamodbBackend) List(ctx context.Context, prefix string) ([]models.Ent
 					Path:              record.Path,
 					Secure:            record.Metadata.Secure,
 					TypeValidatorName: record.Metadata.TypeValidatorName,
+					Version:           record.Metadata.Version,
 				})
 			}
 		}
@@ -353,7 +583,70 @@ func (d *dynamodbBackend) List(ctx context.Context, prefix string) ([]models.Ent
 	return entries, nil
 }
 
-func (d *dynamodbBackend) Delete(ctx context.Context, key string) error {
+// ListPage one page of the entries of the path sorted by key, after the key `after`. A query
+// reads at most limit items, lock rows included, so a page may be short; next is empty on the last page.
+func (d *dynamodbBackend) ListPage(ctx context.Context, path string, after string, limit int) ([]models.Entry, string, error) {
+	path = d.pathUseCase.EscapeEmptyPath(strings.TrimSuffix(path, "/"))
+
+	keyEx := expression.Key("Path").Equal(expression.Value(path))
+	if after != "" {
+		keyEx = keyEx.And(expression.Key("Key").GreaterThan(expression.Value(after)))
+	}
+	expr, err := expression.NewBuilder().WithKeyCondition(keyEx).Build()
+	if err != nil {
+		d.logger.Error("ErrExpressionBuilder", zap.Error(err))
+		return nil, "", err
+	}
+
+	response, err := d.client.Query(ctx, &dynamodb.QueryInput{
+		TableName:                 aws.String(d.config.EntryTableName),
+		ConsistentRead:            aws.Bool(true),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		Limit:                     aws.Int32(int32(limit)),
+	})
+	if err != nil {
+		d.logger.Error("ErrQuery", zap.Error(err), zap.String("path", path))
+		return nil, "", err
+	}
+
+	var records []Record
+	if err = attributevalue.UnmarshalListOfMaps(response.Items, &records); err != nil {
+		d.logger.Error("ErrUnmarshalListOfMaps", zap.Error(err), zap.String("path", path))
+		return nil, "", err
+	}
+
+	entries := make([]models.Entry, 0, len(records))
+	for _, record := range records {
+		if strings.HasPrefix(record.Key, DynamoDBLockPrefix) {
+			continue
+		}
+		entries = append(entries, models.Entry{
+			Key:               record.Key,
+			Value:             string(record.Value),
+			Path:              record.Path,
+			Secure:            record.Metadata.Secure,
+			TypeValidatorName: record.Metadata.TypeValidatorName,
+			Version:           record.Metadata.Version,
+		})
+	}
+
+	next := ""
+	if len(response.LastEvaluatedKey) > 0 && len(records) > 0 {
+		next = records[len(records)-1].Key
+	}
+	return entries, next, nil
+}
+
+// Delete removes the key and its children. Every deleted entry leaves a tombstone row
+// (Action "delete") in the tracking table with its last value, so it can be audited or restored.
+func (d *dynamodbBackend) Delete(ctx context.Context, key string) ([]string, error) {
+	updatedBy := "ghost"
+	user, ok := application.UserFromContext(ctx)
+	if ok {
+		updatedBy = user.Name
+	}
 
 	p, _ := attributevalue.Marshal(d.pathUseCase.PathWithoutKey(key))
 	k, _ := attributevalue.Marshal(d.pathUseCase.BaseKey(key))
@@ -366,6 +659,15 @@ func (d *dynamodbBackend) Delete(ctx context.Context, key string) error {
 		},
 	}
 
+	deleted := make([]models.Entry, 0)
+	current, err := d.Retrieve(ctx, key)
+	if err != nil {
+		d.logger.Warn("ErrRetrieveDeleted", zap.Error(err), zap.String("key", key))
+	}
+	if current != nil {
+		deleted = append(deleted, *current)
+	}
+
 	entries, _ := d.List(ctx, key)
 
 	// children
@@ -380,10 +682,46 @@ func (d *dynamodbBackend) Delete(ctx context.Context, key string) error {
 				},
 			},
 		})
+
+		// folder markers are not entries
+		if !strings.HasSuffix(e.Key, "/") {
+			e.Key = d.pathUseCase.Concat(e.Path, e.Key)
+			deleted = append(deleted, e)
+		}
 	}
 
 	result := d.writeReqsBatch(ctx, d.config.EntryTableName, requests)
-	return result.Err
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	now := time.Now().UTC()
+	keys := make([]string, 0, len(deleted))
+	tracking := make(map[string]RecordTracking, len(deleted))
+	for _, e := range deleted {
+		keys = append(keys, e.Key)
+		tracking[e.Key] = RecordTracking{
+			Timestamp: trackingTimestamp(now),
+			RecordBase: &RecordBase{
+				Key:   e.Key,
+				Value: []byte(e.Value),
+				Metadata: models.Metadata{
+					UpdatedAt:         now,
+					UpdatedBy:         updatedBy,
+					Secure:            e.Secure,
+					Action:            models.ActionDelete,
+					Version:           e.Version,
+					TypeValidatorName: e.TypeValidatorName,
+				},
+			},
+		}
+	}
+
+	if result = d.writeReqsBatch(ctx, d.config.TrackingEntryTableName, prepareWriteRequest(tracking)); result.Err != nil {
+		d.logger.Error("ErrSaveTracking", zap.Error(result.Err), zap.String("key", key))
+	}
+
+	return keys, nil
 }
 
 func (d *dynamodbBackend) Tracking(ctx context.Context, key string) ([]models.Tracking, error) {
@@ -424,11 +762,14 @@ func (d *dynamodbBackend) Tracking(ctx context.Context, key string) ([]models.Tr
 		for _, record := range records {
 			if !strings.HasPrefix(record.Key, DynamoDBLockPrefix) {
 				entries = append(entries, models.Tracking{
-					Key:       record.Key,
-					Value:     string(record.Value),
-					Secure:    record.Metadata.Secure,
-					UpdatedAt: record.Metadata.UpdatedAt,
-					UpdatedBy: record.Metadata.UpdatedBy,
+					Key:               record.Key,
+					Value:             string(record.Value),
+					Secure:            record.Metadata.Secure,
+					UpdatedAt:         record.Metadata.UpdatedAt,
+					UpdatedBy:         record.Metadata.UpdatedBy,
+					Action:            record.Metadata.Action,
+					Version:           record.Metadata.Version,
+					TypeValidatorName: record.Metadata.TypeValidatorName,
 				})
 			}
 		}
@@ -437,6 +778,249 @@ func (d *dynamodbBackend) Tracking(ctx context.Context, key string) ([]models.Tr
 	return entries, nil
 }
 
+// ListByTypeValidator entries validated by the type validator. With NBOX_ENTRIES_VALIDATOR_INDEX
+// the GSI is queried once its backfill completed, otherwise the entry table is scanned.
+func (d *dynamodbBackend) ListByTypeValidator(ctx context.Context, name string) ([]models.Entry, error) {
+	var records []Record
+	var err error
+	if d.config.EntryValidatorIndex != "" && d.indexReady(ctx, d.config.EntryValidatorIndex) {
+		records, err = d.queryByTypeValidator(ctx, name)
+	} else {
+		records, err = d.scanByTypeValidator(ctx, name)
+	}
+	if err != nil {
+		d.logger.Error("ErrListByTypeValidator", zap.Error(err), zap.String("validator", name))
+		return nil, err
+	}
+
+	entries := make([]models.Entry, 0, len(records))
+	for _, record := range records {
+		if strings.HasPrefix(record.Key, DynamoDBLockPrefix) || strings.HasSuffix(record.Key, "/") {
+			continue
+		}
+		entries = append(entries, models.Entry{
+			Key:               d.pathUseCase.Concat(record.Path, record.Key),
+			Value:             string(record.Value),
+			Path:              record.Path,
+			Secure:            record.Metadata.Secure,
+			TypeValidatorName: record.Metadata.TypeValidatorName,
+			Version:           record.Metadata.Version,
+		})
+	}
+
+	return entries, nil
+}
+
+// queryByTypeValidator reads the GSI (partition key TypeValidatorName, projection ALL)
+func (d *dynamodbBackend) queryByTypeValidator(ctx context.Context, name string) ([]Record, error) {
+	keyEx := expression.Key("TypeValidatorName").Equal(expression.Value(name))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyEx).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]Record, 0)
+	paginator := dynamodb.NewQueryPaginator(d.client, &dynamodb.QueryInput{
+		TableName:                 aws.String(d.config.EntryTableName),
+		IndexName:                 aws.String(d.config.EntryValidatorIndex),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+	})
+	for paginator.HasMorePages() {
+		response, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		var page []Record
+		if err = attributevalue.UnmarshalListOfMaps(response.Items, &page); err != nil {
+			return nil, err
+		}
+		records = append(records, page...)
+	}
+
+	return records, nil
+}
+
+// scanByTypeValidator full table scan, for tables without the GSI
+func (d *dynamodbBackend) scanByTypeValidator(ctx context.Context, name string) ([]Record, error) {
+	filter := expression.Name("Metadata.TypeValidatorName").Equal(expression.Value(name))
+	expr, err := expression.NewBuilder().WithFilter(filter).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]Record, 0)
+	paginator := dynamodb.NewScanPaginator(d.client, &dynamodb.ScanInput{
+		TableName:                 aws.String(d.config.EntryTableName),
+		ConsistentRead:            aws.Bool(true),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		FilterExpression:          expr.Filter(),
+	})
+	for paginator.HasMorePages() {
+		response, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		var page []Record
+		if err = attributevalue.UnmarshalListOfMaps(response.Items, &page); err != nil {
+			return nil, err
+		}
+		records = append(records, page...)
+	}
+
+	return records, nil
+}
+
+// SearchKeys entries under the prefix without their value. With NBOX_ENTRIES_KEY_INDEX the GSI
+// (KeyRoot, FullKey) is queried, one partition per root folder, once its backfill completed;
+// otherwise the entry table is scanned.
+func (d *dynamodbBackend) SearchKeys(ctx context.Context, prefix string) ([]models.Entry, error) {
+	var records []Record
+	var err error
+	if d.config.EntryKeyIndex != "" && d.indexReady(ctx, d.config.EntryKeyIndex) {
+		records, err = d.queryByKeyPrefix(ctx, prefix)
+	} else {
+		records, err = d.scanByKeyPrefix(ctx, prefix)
+	}
+	if err != nil {
+		d.logger.Error("ErrSearchKeys", zap.Error(err), zap.String("prefix", prefix))
+		return nil, err
+	}
+
+	entries := make([]models.Entry, 0, len(records))
+	for _, record := range records {
+		if strings.HasPrefix(record.Key, DynamoDBLockPrefix) || strings.HasSuffix(record.Key, "/") {
+			continue
+		}
+		entryKey := d.pathUseCase.Concat(record.Path, record.Key)
+		if !strings.HasPrefix(entryKey, prefix) {
+			continue
+		}
+		entries = append(entries, models.Entry{
+			Key:               entryKey,
+			Path:              record.Path,
+			Secure:            record.Metadata.Secure,
+			TypeValidatorName: record.Metadata.TypeValidatorName,
+			Version:           record.Metadata.Version,
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+
+	return entries, nil
+}
+
+// queryByKeyPrefix reads the GSI (partition key KeyRoot, sort key FullKey, projection INCLUDE
+// Metadata or ALL). A prefix without a whole root folder queries every root listed in the table.
+func (d *dynamodbBackend) queryByKeyPrefix(ctx context.Context, prefix string) ([]Record, error) {
+	roots := []string{keyRoot(prefix)}
+	if !strings.Contains(prefix, "/") {
+		top, err := d.List(ctx, "")
+		if err != nil {
+			return nil, err
+		}
+		roots = searchRoots(prefix, top)
+	}
+
+	records := make([]Record, 0)
+	for _, root := range roots {
+		keyEx := expression.Key("KeyRoot").Equal(expression.Value(root))
+		if strings.Contains(prefix, "/") {
+			keyEx = keyEx.And(expression.Key("FullKey").BeginsWith(prefix))
+		}
+		expr, err := expression.NewBuilder().WithKeyCondition(keyEx).WithProjection(searchProjection()).Build()
+		if err != nil {
+			return nil, err
+		}
+
+		paginator := dynamodb.NewQueryPaginator(d.client, &dynamodb.QueryInput{
+			TableName:                 aws.String(d.config.EntryTableName),
+			IndexName:                 aws.String(d.config.EntryKeyIndex),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+			KeyConditionExpression:    expr.KeyCondition(),
+			ProjectionExpression:      expr.Projection(),
+		})
+		for paginator.HasMorePages() {
+			response, err := paginator.NextPage(ctx)
+			if err != nil {
+				return nil, err
+			}
+			var page []Record
+			if err = attributevalue.UnmarshalListOfMaps(response.Items, &page); err != nil {
+				return nil, err
+			}
+			records = append(records, page...)
+		}
+	}
+
+	return records, nil
+}
+
+// scanByKeyPrefix full table scan without the values, for tables without the GSI
+func (d *dynamodbBackend) scanByKeyPrefix(ctx context.Context, prefix string) ([]Record, error) {
+	expr, err := expression.NewBuilder().WithProjection(searchProjection()).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]Record, 0)
+	paginator := dynamodb.NewScanPaginator(d.client, &dynamodb.ScanInput{
+		TableName:                aws.String(d.config.EntryTableName),
+		ConsistentRead:           aws.Bool(true),
+		ExpressionAttributeNames: expr.Names(),
+		ProjectionExpression:     expr.Projection(),
+	})
+	for paginator.HasMorePages() {
+		response, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		var page []Record
+		if err = attributevalue.UnmarshalListOfMaps(response.Items, &page); err != nil {
+			return nil, err
+		}
+		for _, record := range page {
+			if strings.HasPrefix(d.pathUseCase.Concat(record.Path, record.Key), prefix) {
+				records = append(records, record)
+			}
+		}
+	}
+
+	return records, nil
+}
+
+// searchProjection attributes read by the key search, never the value
+func searchProjection() expression.ProjectionBuilder {
+	return expression.NamesList(expression.Name("Path"), expression.Name("Key"), expression.Name("Metadata"))
+}
+
+// keyRoot first component of the key
+// searchRoots partitions of the key index matching a prefix without "/": the folders and the
+// leaf keys of the top level, a root that is both is queried once
+func searchRoots(prefix string, top []models.Entry) []string {
+	roots := make([]string, 0, len(top))
+	seen := make(map[string]struct{}, len(top))
+	for _, entry := range top {
+		root := strings.TrimSuffix(entry.Key, "/")
+		if !strings.HasPrefix(root, prefix) {
+			continue
+		}
+		if _, ok := seen[root]; ok {
+			continue
+		}
+		seen[root] = struct{}{}
+		roots = append(roots, root)
+	}
+	return roots
+}
+
+func keyRoot(key string) string {
+	root, _, _ := strings.Cut(key, "/")
+	return root
+}
+
 func prepareWriteRequest[T any](items map[string]T) []types.WriteRequest {
 	var writeReqs []types.WriteRequest
 	var item map[string]types.AttributeValue
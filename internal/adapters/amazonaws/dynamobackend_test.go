@@ -0,0 +1,121 @@
+package amazonaws
+
+import (
+	"nbox/internal/domain/models"
+	"nbox/internal/domain/models/operations"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func TestRecord_TypeValidatorIndexAttribute(t *testing.T) {
+	entry, err := attributevalue.MarshalMap(Record{
+		Path:              "development/app",
+		TypeValidatorName: "port",
+		RecordBase: &RecordBase{
+			Key:      "port",
+			Value:    []byte("8080"),
+			Metadata: models.Metadata{TypeValidatorName: "port"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("MarshalMap() error = %v", err)
+	}
+	if _, ok := entry["TypeValidatorName"]; !ok {
+		t.Error("entries with a validator must carry the top-level GSI key")
+	}
+
+	// folder markers and entries without validator stay out of the sparse index
+	folder, err := attributevalue.MarshalMap(Record{
+		Path:       "development",
+		RecordBase: &RecordBase{Key: "app/"},
+	})
+	if err != nil {
+		t.Fatalf("MarshalMap() error = %v", err)
+	}
+	if _, ok := folder["TypeValidatorName"]; ok {
+		t.Error("records without validator must not carry the GSI key")
+	}
+}
+
+func TestRecord_KeyIndexAttributes(t *testing.T) {
+	entry, err := attributevalue.MarshalMap(Record{
+		Path:       "production/myapp",
+		KeyRoot:    keyRoot("production/myapp/db_host"),
+		FullKey:    "production/myapp/db_host",
+		RecordBase: &RecordBase{Key: "db_host", Value: []byte("db.internal")},
+	})
+	if err != nil {
+		t.Fatalf("MarshalMap() error = %v", err)
+	}
+	root, ok := entry["KeyRoot"].(*types.AttributeValueMemberS)
+	if !ok || root.Value != "production" {
+		t.Errorf("KeyRoot = %v, want production", entry["KeyRoot"])
+	}
+	if _, ok := entry["FullKey"]; !ok {
+		t.Error("entries must carry the sort key of the key index")
+	}
+
+	// folder markers stay out of the sparse index
+	folder, err := attributevalue.MarshalMap(Record{Path: "production", RecordBase: &RecordBase{Key: "myapp/"}})
+	if err != nil {
+		t.Fatalf("MarshalMap() error = %v", err)
+	}
+	if _, ok := folder["KeyRoot"]; ok {
+		t.Error("folder markers must not carry the GSI key")
+	}
+}
+
+func TestSearchRoots(t *testing.T) {
+	top := []models.Entry{
+		{Key: "production/"},
+		{Key: "prometheus"},
+		{Key: "development/"},
+		{Key: "development"},
+		{Key: "token"},
+	}
+
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{"pro", []string{"production", "prometheus"}},
+		{"token", []string{"token"}},
+		{"dev", []string{"development"}},
+		{"staging", []string{}},
+	}
+	for _, tt := range tests {
+		if got := searchRoots(tt.prefix, top); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("searchRoots(%q) = %v, want %v", tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestTrackingTimestamp(t *testing.T) {
+	now := time.Unix(1700000000, 100)
+	later := now.Add(time.Millisecond)
+
+	if trackingTimestamp(now) == trackingTimestamp(later) {
+		t.Error("writes of the same second must not share the tracking sort key")
+	}
+	if trackingTimestamp(later) <= trackingTimestamp(now) {
+		t.Error("tracking sort keys must follow the write order")
+	}
+	// rows written before with seconds
+	if seconds := strconv.FormatInt(now.Unix()-1, 10); trackingTimestamp(now) <= seconds {
+		t.Errorf("%s must sort after %s", trackingTimestamp(now), seconds)
+	}
+}
+
+func TestVersionedResult(t *testing.T) {
+	if got := versionedResult("production/myapp/db_host", 1).Type; got != operations.Created {
+		t.Errorf("version 1 = %s, want %s", got, operations.Created)
+	}
+	if got := versionedResult("production/myapp/db_host", 2).Type; got != operations.Updated {
+		t.Errorf("version 2 = %s, want %s", got, operations.Updated)
+	}
+}
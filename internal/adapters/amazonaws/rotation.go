@@ -0,0 +1,124 @@
+package amazonaws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"nbox/internal/application"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"go.uber.org/zap"
+)
+
+// rotationRepository rotation policies table (PK Key)
+type rotationRepository struct {
+	client *dynamodb.Client
+	config *application.Config
+	logger *zap.Logger
+}
+
+func NewRotationRepository(client *dynamodb.Client, config *application.Config, logger *zap.Logger) domain.RotationRepository {
+	return &rotationRepository{
+		client: client,
+		config: config,
+		logger: logger.Named("rotation_repository"),
+	}
+}
+
+func (r *rotationRepository) Save(ctx context.Context, policy models.RotationPolicy) error {
+	item, err := attributevalue.MarshalMap(policy)
+	if err != nil {
+		r.logger.Error("ErrMarshalMap", zap.Error(err))
+		return err
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.config.RotationTableName),
+		Item:      item,
+	})
+	if err != nil {
+		r.logger.Error("ErrPutItem", zap.Error(err), zap.String("key", policy.Key))
+		return err
+	}
+
+	return nil
+}
+
+func (r *rotationRepository) Retrieve(ctx context.Context, key string) (*models.RotationPolicy, error) {
+	keyAttr, _ := attributevalue.Marshal(key)
+
+	resp, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(r.config.RotationTableName),
+		Key:            map[string]types.AttributeValue{"Key": keyAttr},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		r.logger.Error("ErrGetItem", zap.Error(err), zap.String("key", key))
+		return nil, err
+	}
+
+	if resp.Item == nil {
+		return nil, nil
+	}
+
+	policy := &models.RotationPolicy{}
+	if err = attributevalue.UnmarshalMap(resp.Item, policy); err != nil {
+		r.logger.Error("ErrUnmarshalMap", zap.Error(err))
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// List the table holds one item per rotated secret, a scan is enough
+func (r *rotationRepository) List(ctx context.Context) ([]models.RotationPolicy, error) {
+	policies := make([]models.RotationPolicy, 0)
+
+	paginator := dynamodb.NewScanPaginator(r.client, &dynamodb.ScanInput{
+		TableName: aws.String(r.config.RotationTableName),
+	})
+	for paginator.HasMorePages() {
+		response, err := paginator.NextPage(ctx)
+		if err != nil {
+			r.logger.Error("ErrScanPaginator", zap.Error(err))
+			return nil, err
+		}
+
+		var records []models.RotationPolicy
+		if err = attributevalue.UnmarshalListOfMaps(response.Items, &records); err != nil {
+			r.logger.Error("ErrUnmarshalListOfMaps", zap.Error(err))
+			return nil, err
+		}
+		policies = append(policies, records...)
+	}
+
+	return policies, nil
+}
+
+func (r *rotationRepository) Delete(ctx context.Context, key string) error {
+	keyAttr, _ := attributevalue.Marshal(key)
+
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:           aws.String(r.config.RotationTableName),
+		Key:                 map[string]types.AttributeValue{"Key": keyAttr},
+		ConditionExpression: aws.String("attribute_exists(#k)"),
+		// Key is a DynamoDB reserved word
+		ExpressionAttributeNames: map[string]string{"#k": "Key"},
+	})
+
+	var conditionErr *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		return fmt.Errorf("%w: %s", domain.ErrRotationPolicyNotFound, key)
+	}
+	if err != nil {
+		r.logger.Error("ErrDeleteItem", zap.Error(err), zap.String("key", key))
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package amazonaws
+
+import (
+	"context"
+	"fmt"
+	"nbox/internal/application"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"go.uber.org/zap"
+)
+
+// secretRevealRecord item of the audit table (PK Key, SK SortKey = unix nano#id, newest last)
+type secretRevealRecord struct {
+	models.SecretReveal
+	SortKey string `dynamodbav:"SortKey"`
+}
+
+type secretAuditRepository struct {
+	client *dynamodb.Client
+	config *application.Config
+	logger *zap.Logger
+}
+
+func NewSecretAuditRepository(client *dynamodb.Client, config *application.Config, logger *zap.Logger) domain.SecretAuditRepository {
+	return &secretAuditRepository{
+		client: client,
+		config: config,
+		logger: logger.Named("secret_audit_repository"),
+	}
+}
+
+func (s *secretAuditRepository) Save(ctx context.Context, reveal models.SecretReveal) error {
+	item, err := attributevalue.MarshalMap(secretRevealRecord{
+		SecretReveal: reveal,
+		SortKey:      fmt.Sprintf("%s#%s", revealSortKey(reveal.RevealedAt), reveal.ID),
+	})
+	if err != nil {
+		s.logger.Error("ErrMarshalMap", zap.Error(err))
+		return err
+	}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.config.SecretAuditTableName),
+		Item:      item,
+	})
+	if err != nil {
+		s.logger.Error("ErrPutItem", zap.Error(err), zap.String("key", reveal.Key))
+		return err
+	}
+
+	return nil
+}
+
+// List queries the partition of the key (newest first); without a key the table is scanned
+func (s *secretAuditRepository) List(ctx context.Context, filter models.SecretRevealFilter) ([]models.SecretReveal, error) {
+	if filter.Key == "" {
+		return s.scan(ctx, filter)
+	}
+
+	keyEx := expression.Key("Key").Equal(expression.Value(filter.Key))
+	switch {
+	case !filter.Since.IsZero() && !filter.Until.IsZero():
+		keyEx = keyEx.And(expression.Key("SortKey").Between(
+			expression.Value(revealSortKey(filter.Since)),
+			expression.Value(revealSortKey(filter.Until.Add(time.Nanosecond))),
+		))
+	case !filter.Since.IsZero():
+		keyEx = keyEx.And(expression.Key("SortKey").GreaterThanEqual(expression.Value(revealSortKey(filter.Since))))
+	case !filter.Until.IsZero():
+		keyEx = keyEx.And(expression.Key("SortKey").LessThan(expression.Value(revealSortKey(filter.Until.Add(time.Nanosecond)))))
+	}
+
+	expr, err := expression.NewBuilder().WithKeyCondition(keyEx).Build()
+	if err != nil {
+		s.logger.Error("ErrExpressionBuilder", zap.Error(err))
+		return nil, err
+	}
+
+	reveals := make([]models.SecretReveal, 0)
+
+	paginator := dynamodb.NewQueryPaginator(s.client, &dynamodb.QueryInput{
+		TableName:                 aws.String(s.config.SecretAuditTableName),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ScanIndexForward:          aws.Bool(false),
+	})
+
+	for paginator.HasMorePages() {
+		response, err := paginator.NextPage(ctx)
+		if err != nil {
+			s.logger.Error("ErrQueryPaginator", zap.Error(err), zap.String("key", filter.Key))
+			return nil, err
+		}
+
+		var records []secretRevealRecord
+		if err = attributevalue.UnmarshalListOfMaps(response.Items, &records); err != nil {
+			s.logger.Error("ErrUnmarshalListOfMaps", zap.Error(err))
+			return nil, err
+		}
+
+		for _, record := range records {
+			if filter.Match(record.SecretReveal) {
+				reveals = append(reveals, record.SecretReveal)
+			}
+		}
+
+		// the partition is read newest first, no need to keep paging
+		if filter.Limit > 0 && len(reveals) >= filter.Limit {
+			return reveals[:filter.Limit], nil
+		}
+	}
+
+	return reveals, nil
+}
+
+func (s *secretAuditRepository) scan(ctx context.Context, filter models.SecretRevealFilter) ([]models.SecretReveal, error) {
+	reveals := make([]models.SecretReveal, 0)
+
+	paginator := dynamodb.NewScanPaginator(s.client, &dynamodb.ScanInput{
+		TableName: aws.String(s.config.SecretAuditTableName),
+	})
+	for paginator.HasMorePages() {
+		response, err := paginator.NextPage(ctx)
+		if err != nil {
+			s.logger.Error("ErrScanPaginator", zap.Error(err))
+			return nil, err
+		}
+
+		var records []secretRevealRecord
+		if err = attributevalue.UnmarshalListOfMaps(response.Items, &records); err != nil {
+			s.logger.Error("ErrUnmarshalListOfMaps", zap.Error(err))
+			return nil, err
+		}
+
+		for _, record := range records {
+			if filter.Match(record.SecretReveal) {
+				reveals = append(reveals, record.SecretReveal)
+			}
+		}
+	}
+
+	sort.SliceStable(reveals, func(i, j int) bool {
+		return reveals[i].RevealedAt.After(reveals[j].RevealedAt)
+	})
+	if filter.Limit > 0 && len(reveals) > filter.Limit {
+		reveals = reveals[:filter.Limit]
+	}
+
+	return reveals, nil
+}
+
+// revealSortKey fixed width so the sort key orders by time
+func revealSortKey(t time.Time) string {
+	return fmt.Sprintf("%020d", t.UnixNano())
+}
@@ -0,0 +1,377 @@
+package amazonaws
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"nbox/internal/application"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+	"nbox/internal/domain/models/operations"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"go.uber.org/zap"
+)
+
+const (
+	secretsManagerService = "secretsmanager"
+	// BatchGetSecretValue accepts up to 20 secret ids
+	batchGetSecretsBatch = 20
+)
+
+// SecretsManagerError error response of the Secrets Manager API
+type SecretsManagerError struct {
+	StatusCode int
+	Type       string `json:"__type"`
+	Message    string `json:"message"`
+}
+
+func (e *SecretsManagerError) Error() string {
+	return fmt.Sprintf("secretsmanager: %s (%d): %s", e.Type, e.StatusCode, e.Message)
+}
+
+func (e *SecretsManagerError) notFound() bool {
+	return strings.HasSuffix(e.Type, "ResourceNotFoundException")
+}
+
+type secretsManagerTag struct {
+	Key   string `json:"Key"`
+	Value string `json:"Value"`
+}
+
+type secretsManagerSecret struct {
+	ARN             string              `json:"ARN"`
+	Name            string              `json:"Name"`
+	Tags            []secretsManagerTag `json:"Tags"`
+	LastChangedDate float64             `json:"LastChangedDate"`
+}
+
+// secretsManagerStore secrets in AWS Secrets Manager, named as the key without the leading "/".
+// The JSON API is called directly, signed with the credentials of the AWS config, with the same
+// tags as Parameter Store. Secrets are created with NBOX_SECRETS_MANAGER_KEY_ID when set.
+type secretsManagerStore struct {
+	client      aws.HTTPClient
+	credentials aws.CredentialsProvider
+	signer      *v4.Signer
+	endpoint    string
+	region      string
+	config      *application.Config
+	logger      *zap.Logger
+}
+
+func NewSecretsManagerStore(cfg *aws.Config, config *application.Config, logger *zap.Logger) domain.SecretAdapter {
+	endpoint := config.SecretsManagerEndpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://secretsmanager.%s.amazonaws.com", cfg.Region)
+	}
+
+	var client aws.HTTPClient = http.DefaultClient
+	if cfg.HTTPClient != nil {
+		client = cfg.HTTPClient
+	}
+
+	return &secretsManagerStore{
+		client:      client,
+		credentials: cfg.Credentials,
+		signer:      v4.NewSigner(),
+		endpoint:    strings.TrimSuffix(endpoint, "/") + "/",
+		region:      cfg.Region,
+		config:      config,
+		logger:      logger.Named("secrets_manager_store"),
+	}
+}
+
+func (s *secretsManagerStore) Upsert(ctx context.Context, entries []models.Entry) operations.Results {
+	results := make(operations.Results, len(entries))
+
+	owner := "ghost"
+	if user, ok := application.UserFromContext(ctx); ok {
+		owner = user.Name
+	}
+
+	for _, entry := range entries {
+		id := secretId(entry.Key)
+		tags := []secretsManagerTag{
+			{Key: TagProject, Value: tagProjectValue},
+			{Key: TagKey, Value: tagValue(strings.TrimPrefix(entry.Key, "/"))},
+			{Key: TagOwner, Value: tagValue(owner)},
+		}
+		if entry.TypeValidatorName != "" {
+			tags = append(tags, secretsManagerTag{Key: TagTypeValidator, Value: tagValue(entry.TypeValidatorName)})
+		}
+
+		opType := operations.Updated
+		err := s.call(ctx, "PutSecretValue", map[string]any{"SecretId": id, "SecretString": entry.Value}, nil)
+
+		var smErr *SecretsManagerError
+		if errors.As(err, &smErr) && smErr.notFound() {
+			opType = operations.Created
+			input := map[string]any{"Name": id, "SecretString": entry.Value, "Tags": tags}
+			if s.config.SecretsManagerKeyId != "" {
+				input["KmsKeyId"] = s.config.SecretsManagerKeyId
+			}
+			err = s.call(ctx, "CreateSecret", input, nil)
+		} else if err == nil {
+			s.tag(ctx, id, tags, entry.TypeValidatorName == "")
+		}
+
+		if err != nil {
+			s.logger.Error("ErrSecureUpsert", zap.String("key", entry.Key), zap.Error(err))
+			results[entry.Key] = operations.Result{Key: entry.Key, Error: err}
+			continue
+		}
+		results[entry.Key] = operations.Result{Key: entry.Key, Type: opType}
+	}
+
+	return results
+}
+
+// tag refreshes the tags of an existing secret, the type validator tag is removed when the entry
+// no longer declares one
+func (s *secretsManagerStore) tag(ctx context.Context, id string, tags []secretsManagerTag, untagValidator bool) {
+	if err := s.call(ctx, "TagResource", map[string]any{"SecretId": id, "Tags": tags}, nil); err != nil {
+		s.logger.Warn("ErrSecureAddingTags", zap.Error(err), zap.String("key", id))
+	}
+	if !untagValidator {
+		return
+	}
+	if err := s.call(ctx, "UntagResource", map[string]any{"SecretId": id, "TagKeys": []string{TagTypeValidator}}, nil); err != nil {
+		s.logger.Warn("ErrSecureRemovingTags", zap.Error(err), zap.String("key", id))
+	}
+}
+
+func (s *secretsManagerStore) RetrieveSecretValue(ctx context.Context, key string) (*models.Entry, error) {
+	return s.value(ctx, key, "")
+}
+
+// RetrieveSecretValueAt walks the version ids, deprecated ones included, and returns the last
+// version created before the given time
+func (s *secretsManagerStore) RetrieveSecretValueAt(ctx context.Context, key string, at time.Time) (*models.Entry, error) {
+
+	var found string
+	var foundTime time.Time
+	input := map[string]any{"SecretId": secretId(key), "IncludeDeprecated": true, "MaxResults": 100}
+	for {
+		var output struct {
+			Versions []struct {
+				VersionId   string  `json:"VersionId"`
+				CreatedDate float64 `json:"CreatedDate"`
+			} `json:"Versions"`
+			NextToken string `json:"NextToken"`
+		}
+		if err := s.call(ctx, "ListSecretVersionIds", input, &output); err != nil {
+			return nil, err
+		}
+
+		for _, version := range output.Versions {
+			created := epoch(version.CreatedDate)
+			if created.After(at) || created.Before(foundTime) {
+				continue
+			}
+			found, foundTime = version.VersionId, created
+		}
+
+		if output.NextToken == "" {
+			break
+		}
+		input["NextToken"] = output.NextToken
+	}
+
+	if found == "" {
+		s.logger.Error("ErrParameterNotFound", zap.String("key", key), zap.Time("at", at))
+		return nil, ErrParameterNotFound
+	}
+
+	return s.value(ctx, key, found)
+}
+
+// ListSecrets secrets tagged project=nbox whose name starts with the prefix
+func (s *secretsManagerStore) ListSecrets(ctx context.Context, prefix string) ([]models.SecretParameter, error) {
+	filters := []map[string]any{
+		{"Key": "tag-key", "Values": []string{TagProject}},
+		{"Key": "tag-value", "Values": []string{tagProjectValue}},
+	}
+	if prefix = strings.Trim(prefix, "/"); prefix != "" {
+		filters = append(filters, map[string]any{"Key": "name", "Values": []string{prefix}})
+	}
+
+	listed := make([]secretsManagerSecret, 0)
+	input := map[string]any{"Filters": filters, "MaxResults": 100}
+	for {
+		var output struct {
+			SecretList []secretsManagerSecret `json:"SecretList"`
+			NextToken  string                 `json:"NextToken"`
+		}
+		if err := s.call(ctx, "ListSecrets", input, &output); err != nil {
+			return nil, err
+		}
+		for _, secret := range output.SecretList {
+			// the name filter also matches words inside the name
+			if strings.HasPrefix(secret.Name, prefix) {
+				listed = append(listed, secret)
+			}
+		}
+		if output.NextToken == "" {
+			break
+		}
+		input["NextToken"] = output.NextToken
+	}
+
+	values := make(map[string]string, len(listed))
+	for start := 0; start < len(listed); start += batchGetSecretsBatch {
+		ids := make([]string, 0, batchGetSecretsBatch)
+		for _, secret := range listed[start:min(start+batchGetSecretsBatch, len(listed))] {
+			ids = append(ids, secret.Name)
+		}
+
+		var output struct {
+			SecretValues []struct {
+				Name         string `json:"Name"`
+				SecretString string `json:"SecretString"`
+			} `json:"SecretValues"`
+		}
+		if err := s.call(ctx, "BatchGetSecretValue", map[string]any{"SecretIdList": ids}, &output); err != nil {
+			return nil, err
+		}
+		for _, value := range output.SecretValues {
+			values[value.Name] = value.SecretString
+		}
+	}
+
+	secrets := make([]models.SecretParameter, 0, len(listed))
+	for _, secret := range listed {
+		parameter := models.SecretParameter{
+			Name:      "/" + secret.Name,
+			Key:       secret.Name,
+			Value:     values[secret.Name],
+			UpdatedAt: epoch(secret.LastChangedDate),
+		}
+		for _, tag := range secret.Tags {
+			switch tag.Key {
+			case TagKey:
+				parameter.Key = tag.Value
+			case TagOwner:
+				parameter.Owner = tag.Value
+			case TagTypeValidator:
+				parameter.TypeValidatorName = tag.Value
+			}
+		}
+		secrets = append(secrets, parameter)
+	}
+
+	sort.Slice(secrets, func(i, j int) bool { return secrets[i].Name < secrets[j].Name })
+	return secrets, nil
+}
+
+// Delete removes the secrets without recovery window. A missing secret is reported as deleted.
+func (s *secretsManagerStore) Delete(ctx context.Context, names []string) operations.Results {
+	results := make(operations.Results, len(names))
+
+	for _, name := range names {
+		err := s.call(ctx, "DeleteSecret", map[string]any{"SecretId": secretId(name), "ForceDeleteWithoutRecovery": true}, nil)
+
+		var smErr *SecretsManagerError
+		if errors.As(err, &smErr) && smErr.notFound() {
+			s.logger.Warn("secret not found, nothing to delete", zap.String("name", name))
+			err = nil
+		}
+
+		if err != nil {
+			s.logger.Error("ErrSecureDelete", zap.String("name", name), zap.Error(err))
+			results[name] = operations.Result{Key: name, Type: operations.Error, Error: err}
+			continue
+		}
+		results[name] = operations.Result{Key: name, Type: operations.Deleted}
+	}
+
+	return results
+}
+
+// Reference partial ARN of the secret, arn:aws:secretsmanager:<region>:<account>:secret:<key>,
+// accepted as SecretId by the API
+func (s *secretsManagerStore) Reference(key string) string {
+	return fmt.Sprintf("%s%s:%s:secret:%s", models.ReferenceSecretsManagerArn, s.region, s.config.AccountId, secretId(key))
+}
+
+func (s *secretsManagerStore) value(ctx context.Context, key, versionId string) (*models.Entry, error) {
+	input := map[string]any{"SecretId": secretId(key)}
+	if versionId != "" {
+		input["VersionId"] = versionId
+	}
+
+	var output struct {
+		SecretString *string `json:"SecretString"`
+	}
+	if err := s.call(ctx, "GetSecretValue", input, &output); err != nil {
+		return nil, err
+	}
+
+	if output.SecretString == nil {
+		s.logger.Error("ErrParameterNotFound", zap.String("key", key))
+		return nil, ErrParameterNotFound
+	}
+
+	return &models.Entry{Key: key, Value: *output.SecretString, Secure: true}, nil
+}
+
+// call signed POST of the JSON 1.1 protocol, the action goes in X-Amz-Target
+func (s *secretsManagerStore) call(ctx context.Context, action string, input, output any) error {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager."+action)
+
+	credentials, err := s.credentials.Retrieve(ctx)
+	if err != nil {
+		return err
+	}
+	hash := sha256.Sum256(body)
+	if err := s.signer.SignHTTP(ctx, credentials, req, hex.EncodeToString(hash[:]), secretsManagerService, s.region, time.Now()); err != nil {
+		return err
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		smErr := &SecretsManagerError{StatusCode: res.StatusCode}
+		_ = json.NewDecoder(res.Body).Decode(smErr)
+		return smErr
+	}
+
+	if output == nil {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(output)
+}
+
+// secretId name of the secret, the key without the leading "/"
+func secretId(key string) string {
+	return strings.TrimPrefix(key, "/")
+}
+
+// epoch timestamps of the API are seconds with fraction
+func epoch(seconds float64) time.Time {
+	whole, fraction := math.Modf(seconds)
+	return time.Unix(int64(whole), int64(fraction*1e9)).UTC()
+}
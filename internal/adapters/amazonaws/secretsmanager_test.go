@@ -0,0 +1,200 @@
+package amazonaws
+
+import (
+	"context"
+	"encoding/json"
+	"nbox/internal/application"
+	"nbox/internal/domain/models"
+	"nbox/internal/domain/models/operations"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type fakeSecretVersion struct {
+	id      string
+	value   string
+	created float64
+}
+
+// fakeSecretsManager minimal Secrets Manager JSON API, one action per X-Amz-Target
+type fakeSecretsManager struct {
+	mu      sync.Mutex
+	secrets map[string][]fakeSecretVersion
+	tags    map[string][]secretsManagerTag
+	created map[string]string
+	now     float64
+	actions []string
+}
+
+func (f *fakeSecretsManager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	action := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "secretsmanager.")
+	f.actions = append(f.actions, action)
+
+	var input map[string]any
+	_ = json.NewDecoder(r.Body).Decode(&input)
+	id, _ := input["SecretId"].(string)
+	notFound := func() {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"__type": "ResourceNotFoundException", "message": "not found"})
+	}
+	reply := func(v any) { _ = json.NewEncoder(w).Encode(v) }
+
+	switch action {
+	case "PutSecretValue":
+		if _, ok := f.secrets[id]; !ok {
+			notFound()
+			return
+		}
+		f.secrets[id] = append(f.secrets[id], fakeSecretVersion{id: id + "-" + string(rune('a'+len(f.secrets[id]))), value: input["SecretString"].(string), created: f.now})
+		reply(map[string]any{})
+	case "CreateSecret":
+		name := input["Name"].(string)
+		f.secrets[name] = []fakeSecretVersion{{id: name + "-a", value: input["SecretString"].(string), created: f.now}}
+		raw, _ := json.Marshal(input["Tags"])
+		var tags []secretsManagerTag
+		_ = json.Unmarshal(raw, &tags)
+		f.tags[name] = tags
+		f.created[name], _ = input["KmsKeyId"].(string)
+		reply(map[string]any{})
+	case "TagResource", "UntagResource":
+		reply(map[string]any{})
+	case "GetSecretValue":
+		versions, ok := f.secrets[id]
+		if !ok {
+			notFound()
+			return
+		}
+		version := versions[len(versions)-1]
+		for _, v := range versions {
+			if v.id == input["VersionId"] {
+				version = v
+			}
+		}
+		reply(map[string]any{"SecretString": version.value})
+	case "ListSecretVersionIds":
+		list := make([]map[string]any, 0)
+		for _, v := range f.secrets[id] {
+			list = append(list, map[string]any{"VersionId": v.id, "CreatedDate": v.created})
+		}
+		reply(map[string]any{"Versions": list})
+	case "ListSecrets":
+		list := make([]map[string]any, 0)
+		for name, versions := range f.secrets {
+			list = append(list, map[string]any{"Name": name, "Tags": f.tags[name], "LastChangedDate": versions[len(versions)-1].created})
+		}
+		reply(map[string]any{"SecretList": list})
+	case "BatchGetSecretValue":
+		values := make([]map[string]any, 0)
+		for _, name := range input["SecretIdList"].([]any) {
+			versions := f.secrets[name.(string)]
+			values = append(values, map[string]any{"Name": name, "SecretString": versions[len(versions)-1].value})
+		}
+		reply(map[string]any{"SecretValues": values})
+	case "DeleteSecret":
+		if _, ok := f.secrets[id]; !ok {
+			notFound()
+			return
+		}
+		delete(f.secrets, id)
+		reply(map[string]any{})
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func newTestSecretsManager(t *testing.T) (*secretsManagerStore, *fakeSecretsManager) {
+	t.Helper()
+	fake := &fakeSecretsManager{
+		secrets: map[string][]fakeSecretVersion{},
+		tags:    map[string][]secretsManagerTag{},
+		created: map[string]string{},
+		now:     1704067200,
+	}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	cfg := &aws.Config{
+		Region: "us-east-1",
+		Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "SECRET"}, nil
+		}),
+	}
+	config := &application.Config{AccountId: "000000000000", SecretsManagerEndpoint: server.URL, SecretsManagerKeyId: "alias/nbox"}
+	return NewSecretsManagerStore(cfg, config, zap.NewNop()).(*secretsManagerStore), fake
+}
+
+func TestSecretsManagerStore_UpsertAndRetrieve(t *testing.T) {
+	store, fake := newTestSecretsManager(t)
+	ctx := context.Background()
+
+	results := store.Upsert(ctx, []models.Entry{{Key: "production/app/password", Value: "v1", Secure: true, TypeValidatorName: "password"}})
+	assert.Equal(t, operations.Created, results["production/app/password"].Type)
+	assert.Equal(t, "alias/nbox", fake.created["production/app/password"])
+	assert.Contains(t, fake.tags["production/app/password"], secretsManagerTag{Key: TagTypeValidator, Value: "password"})
+
+	fake.now += 3600
+	results = store.Upsert(ctx, []models.Entry{{Key: "production/app/password", Value: "v2", Secure: true}})
+	assert.Equal(t, operations.Updated, results["production/app/password"].Type)
+	assert.Equal(t, []string{"PutSecretValue", "CreateSecret", "PutSecretValue", "TagResource", "UntagResource"}, fake.actions)
+
+	entry, err := store.RetrieveSecretValue(ctx, "/production/app/password")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", entry.Value)
+
+	entry, err = store.RetrieveSecretValueAt(ctx, "/production/app/password", time.Unix(1704067200+60, 0))
+	require.NoError(t, err)
+	assert.Equal(t, "v1", entry.Value)
+
+	_, err = store.RetrieveSecretValueAt(ctx, "/production/app/password", time.Unix(1704067200-60, 0))
+	assert.ErrorIs(t, err, ErrParameterNotFound)
+
+	assert.Equal(t, "arn:aws:secretsmanager:us-east-1:000000000000:secret:production/app/password", store.Reference("production/app/password"))
+}
+
+func TestSecretsManagerStore_ListSecretsAndDelete(t *testing.T) {
+	store, _ := newTestSecretsManager(t)
+	ctx := context.Background()
+
+	store.Upsert(ctx, []models.Entry{
+		{Key: "production/app/password", Value: "a", Secure: true, TypeValidatorName: "password"},
+		{Key: "development/app/password", Value: "b", Secure: true},
+	})
+
+	secrets, err := store.ListSecrets(ctx, "production/")
+	require.NoError(t, err)
+	require.Len(t, secrets, 1)
+	assert.Equal(t, models.SecretParameter{
+		Name:              "/production/app/password",
+		Key:               "production/app/password",
+		TypeValidatorName: "password",
+		Owner:             "ghost",
+		Value:             "a",
+		UpdatedAt:         time.Unix(1704067200, 0).UTC(),
+	}, secrets[0])
+
+	results := store.Delete(ctx, []string{"/production/app/password", "/production/missing"})
+	assert.Equal(t, operations.Deleted, results["/production/app/password"].Type)
+	assert.Equal(t, operations.Deleted, results["/production/missing"].Type)
+
+	_, err = store.RetrieveSecretValue(ctx, "/production/app/password")
+	var smErr *SecretsManagerError
+	require.ErrorAs(t, err, &smErr)
+	assert.True(t, smErr.notFound())
+}
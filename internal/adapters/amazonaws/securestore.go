@@ -7,8 +7,12 @@ import (
 	"nbox/internal/domain"
 	"nbox/internal/domain/models"
 	"nbox/internal/domain/models/operations"
+	"nbox/internal/usecases"
+	"slices"
 	"strings"
 	"sync"
+	"time"
+	"unicode"
 
 	"go.uber.org/zap"
 
@@ -20,6 +24,18 @@ import (
 
 var ErrParameterNotFound = errors.New("parameter not found or has no value")
 
+// Tags written on every parameter, refreshed on each update
+const (
+	TagProject       = "project"
+	TagKey           = "nbox:key"
+	TagOwner         = "nbox:owner"
+	TagTypeValidator = "nbox:type-validator"
+
+	tagProjectValue = "nbox"
+	// GetParameters and DeleteParameters accept up to 10 names
+	getParametersBatch = 10
+)
+
 type Result models.Exchange[*ssm.PutParameterOutput, *models.Entry]
 
 type secureParameterStore struct {
@@ -54,6 +70,43 @@ func (s *secureParameterStore) RetrieveSecretValue(ctx context.Context, key stri
 	}, nil
 }
 
+// RetrieveSecretValueAt walks the parameter history (last 100 versions) and returns the last
+// version written before the given time
+func (s *secureParameterStore) RetrieveSecretValueAt(ctx context.Context, key string, at time.Time) (*models.Entry, error) {
+
+	var found *types.ParameterHistory
+	paginator := ssm.NewGetParameterHistoryPaginator(s.client, &ssm.GetParameterHistoryInput{
+		Name:           aws.String(key),
+		WithDecryption: aws.Bool(true),
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for i, p := range page.Parameters {
+			if p.LastModifiedDate == nil || p.LastModifiedDate.After(at) {
+				continue
+			}
+			if found == nil || p.Version > found.Version {
+				found = &page.Parameters[i]
+			}
+		}
+	}
+
+	if found == nil || found.Value == nil {
+		s.logger.Error("ErrParameterNotFound", zap.String("key", key), zap.Time("at", at))
+		return nil, ErrParameterNotFound
+	}
+
+	return &models.Entry{
+		Key:    key,
+		Value:  *found.Value,
+		Secure: true,
+	}, nil
+}
+
 func (s *secureParameterStore) Upsert(ctx context.Context, entries []models.Entry) operations.Results {
 	ch := make(chan operations.Result)
 	wg := sync.WaitGroup{}
@@ -97,21 +150,165 @@ func (s *secureParameterStore) Send(ctx context.Context, entry models.Entry) ope
 	opType := operations.Updated
 	if out.Version == 1 {
 		opType = operations.Created
-		s.AddTags(ctx, in.Name)
 	}
 
+	s.AddTags(ctx, in.Name, entry)
+
 	return operations.Result{Key: entry.Key, Type: opType, Error: nil}
 }
 
-func (s *secureParameterStore) AddTags(ctx context.Context, key *string) {
+// AddTags records the project, key, owner and type validator of the entry on the parameter.
+// An entry without validator loses the tag it may have had.
+func (s *secureParameterStore) AddTags(ctx context.Context, key *string, entry models.Entry) {
+	owner := "ghost"
+	if user, ok := application.UserFromContext(ctx); ok {
+		owner = user.Name
+	}
+
+	tags := []types.Tag{
+		{Key: aws.String(TagProject), Value: aws.String(tagProjectValue)},
+		{Key: aws.String(TagKey), Value: aws.String(tagValue(strings.TrimPrefix(entry.Key, "/")))},
+		{Key: aws.String(TagOwner), Value: aws.String(tagValue(owner))},
+	}
+	if entry.TypeValidatorName != "" {
+		tags = append(tags, types.Tag{Key: aws.String(TagTypeValidator), Value: aws.String(tagValue(entry.TypeValidatorName))})
+	}
+
 	_, err := s.client.AddTagsToResource(ctx, &ssm.AddTagsToResourceInput{
 		ResourceId:   key,
 		ResourceType: types.ResourceTypeForTaggingParameter,
-		Tags:         []types.Tag{{Key: aws.String("project"), Value: aws.String("nbox")}},
+		Tags:         tags,
 	})
 	if err != nil {
 		s.logger.Warn("ErrSecureAddingTags", zap.Error(err), zap.String("key", *key))
 	}
+
+	if entry.TypeValidatorName == "" {
+		_, err = s.client.RemoveTagsFromResource(ctx, &ssm.RemoveTagsFromResourceInput{
+			ResourceId:   key,
+			ResourceType: types.ResourceTypeForTaggingParameter,
+			TagKeys:      []string{TagTypeValidator},
+		})
+		if err != nil {
+			s.logger.Warn("ErrSecureRemovingTags", zap.Error(err), zap.String("key", *key))
+		}
+	}
+}
+
+// ListSecrets parameters tagged project=nbox under the prefix, with their decrypted values and tags
+func (s *secureParameterStore) ListSecrets(ctx context.Context, prefix string) ([]models.SecretParameter, error) {
+	filters := []types.ParameterStringFilter{
+		{Key: aws.String("tag:" + TagProject), Values: []string{tagProjectValue}},
+	}
+	if prefix = strings.Trim(prefix, "/"); prefix != "" {
+		filters = append(filters, types.ParameterStringFilter{
+			Key: aws.String("Name"), Option: aws.String("BeginsWith"), Values: []string{"/" + prefix},
+		})
+	}
+
+	var names []string
+	paginator := ssm.NewDescribeParametersPaginator(s.client, &ssm.DescribeParametersInput{ParameterFilters: filters})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range page.Parameters {
+			if p.Name != nil {
+				names = append(names, *p.Name)
+			}
+		}
+	}
+
+	secrets := make([]models.SecretParameter, 0, len(names))
+	for batch := range slices.Chunk(names, getParametersBatch) {
+		out, err := s.client.GetParameters(ctx, &ssm.GetParametersInput{
+			Names:          batch,
+			WithDecryption: aws.Bool(true),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, p := range out.Parameters {
+			secret := models.SecretParameter{
+				Name:  aws.ToString(p.Name),
+				Key:   strings.TrimPrefix(aws.ToString(p.Name), "/"),
+				Value: aws.ToString(p.Value),
+			}
+			if p.LastModifiedDate != nil {
+				secret.UpdatedAt = *p.LastModifiedDate
+			}
+
+			tags, err := s.client.ListTagsForResource(ctx, &ssm.ListTagsForResourceInput{
+				ResourceId:   p.Name,
+				ResourceType: types.ResourceTypeForTaggingParameter,
+			})
+			if err != nil {
+				return nil, err
+			}
+			for _, tag := range tags.TagList {
+				switch aws.ToString(tag.Key) {
+				case TagKey:
+					secret.Key = aws.ToString(tag.Value)
+				case TagOwner:
+					secret.Owner = aws.ToString(tag.Value)
+				case TagTypeValidator:
+					secret.TypeValidatorName = aws.ToString(tag.Value)
+				}
+			}
+
+			secrets = append(secrets, secret)
+		}
+	}
+
+	return secrets, nil
+}
+
+// Delete removes the parameters in batches of 10. A parameter that does not exist is reported as
+// deleted, the delete of an entry may be retried.
+func (s *secureParameterStore) Delete(ctx context.Context, names []string) operations.Results {
+	results := make(operations.Results, len(names))
+
+	for batch := range slices.Chunk(names, getParametersBatch) {
+		out, err := s.client.DeleteParameters(ctx, &ssm.DeleteParametersInput{Names: batch})
+		if err != nil {
+			s.logger.Error("ErrSecureDelete", zap.Strings("names", batch), zap.Error(err))
+			for _, name := range batch {
+				results[name] = operations.Result{Key: name, Type: operations.Error, Error: err}
+			}
+			continue
+		}
+
+		for _, name := range out.InvalidParameters {
+			s.logger.Warn("ErrSecureDeleteNotFound", zap.String("name", name))
+		}
+		for _, name := range slices.Concat(out.DeletedParameters, out.InvalidParameters) {
+			results[name] = operations.Result{Key: name, Type: operations.Deleted}
+		}
+	}
+
+	return results
+}
+
+// Reference parameter ARN, or its name with NBOX_PARAMETER_STORE_SHORT_ARN
+func (s *secureParameterStore) Reference(key string) string {
+	return usecases.ParameterReference(s.config, key)
+}
+
+// tagValue replaces the characters SSM does not accept in tag values, max 256 characters
+func tagValue(value string) string {
+	value = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.IsSpace(r) || strings.ContainsRune("_.:/=+-@", r) {
+			return r
+		}
+		return '_'
+	}, value)
+
+	if runes := []rune(value); len(runes) > 256 {
+		value = string(runes[:256])
+	}
+	return value
 }
 
 func prepareSecret(entry models.Entry, parameterStoreKeyId string) *ssm.PutParameterInput {
@@ -1,6 +1,7 @@
 package amazonaws
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -15,3 +16,24 @@ func TestSecureParameterStore_Upsert(t *testing.T) {
 	//	},
 	//})
 }
+
+func TestTagValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "key", value: "production/my-app/db_password", want: "production/my-app/db_password"},
+		{name: "email owner", value: "jane.doe+ops@example.com", want: "jane.doe+ops@example.com"},
+		{name: "invalid characters", value: "user#1 (ci)", want: "user_1 _ci_"},
+		{name: "too long", value: strings.Repeat("a", 300), want: strings.Repeat("a", 256)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tagValue(tt.value); got != tt.want {
+				t.Errorf("tagValue() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
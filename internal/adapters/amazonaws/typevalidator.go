@@ -2,6 +2,7 @@ package amazonaws
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"nbox/internal/application"
 	"nbox/internal/domain"
@@ -15,14 +16,54 @@ import (
 )
 
 type TypeValidatorRecord struct {
-	Name  string `dynamodbav:"Name"`
-	Regex string `dynamodbav:"Regex"`
+	Name      string   `dynamodbav:"Name"`
+	Kind      string   `dynamodbav:"Kind,omitempty"`
+	Regex     string   `dynamodbav:"Regex"`
+	Values    []string `dynamodbav:"Values,omitempty"`
+	Min       *float64 `dynamodbav:"Min,omitempty"`
+	Max       *float64 `dynamodbav:"Max,omitempty"`
+	MinLength *int     `dynamodbav:"MinLength,omitempty"`
+	MaxLength *int     `dynamodbav:"MaxLength,omitempty"`
+	Schema    string   `dynamodbav:"Schema,omitempty"` // JSON Schema as text
+}
+
+func newTypeValidatorRecord(validator models.TypeValidator) TypeValidatorRecord {
+	return TypeValidatorRecord{
+		Name:      validator.Name,
+		Kind:      string(validator.Kind),
+		Regex:     validator.Regex,
+		Values:    validator.Values,
+		Min:       validator.Min,
+		Max:       validator.Max,
+		MinLength: validator.MinLength,
+		MaxLength: validator.MaxLength,
+		Schema:    string(validator.Schema),
+	}
+}
+
+func (r TypeValidatorRecord) toModel() models.TypeValidator {
+	validator := models.TypeValidator{
+		Name:      r.Name,
+		Kind:      models.TypeValidatorKind(r.Kind),
+		Regex:     r.Regex,
+		Values:    r.Values,
+		Min:       r.Min,
+		Max:       r.Max,
+		MinLength: r.MinLength,
+		MaxLength: r.MaxLength,
+	}
+	if r.Schema != "" {
+		validator.Schema = json.RawMessage(r.Schema)
+	}
+	return validator
 }
 
 type typeValidatorBackend struct {
 	client *dynamodb.Client
 	config *application.Config
 	logger *zap.Logger
+	// schemas compiled schemas of the retrieved validators
+	schemas models.SchemaCache
 }
 
 func NewTypeValidatorBackend(dynamodb *dynamodb.Client, config *application.Config, logger *zap.Logger) domain.TypeValidatorAdapter {
@@ -40,11 +81,12 @@ func (t *typeValidatorBackend) Upsert(ctx context.Context, validator models.Type
 		return errors.New("cannot modify built-in type validator")
 	}
 
-	record := TypeValidatorRecord{
-		Name:  validator.Name,
-		Regex: validator.Regex,
+	if err := validator.Check(); err != nil {
+		return err
 	}
 
+	record := newTypeValidatorRecord(validator)
+
 	item, err := attributevalue.MarshalMap(record)
 	if err != nil {
 		t.logger.Error("ErrMarshalMap", zap.Error(err))
@@ -61,6 +103,7 @@ func (t *typeValidatorBackend) Upsert(ctx context.Context, validator models.Type
 		return err
 	}
 
+	t.schemas.Drop(validator.Name)
 	return nil
 }
 
@@ -96,10 +139,9 @@ func (t *typeValidatorBackend) Retrieve(ctx context.Context, name string) (*mode
 		return nil, err
 	}
 
-	return &models.TypeValidator{
-		Name:  record.Name,
-		Regex: record.Regex,
-	}, nil
+	validator := record.toModel()
+	t.schemas.Compile(&validator)
+	return &validator, nil
 }
 
 // List returns all type validators (built-in + custom)
@@ -135,10 +177,7 @@ func (t *typeValidatorBackend) List(ctx context.Context) ([]models.TypeValidator
 		}
 
 		for _, record := range records {
-			validators = append(validators, models.TypeValidator{
-				Name:  record.Name,
-				Regex: record.Regex,
-			})
+			validators = append(validators, record.toModel())
 		}
 	}
 
@@ -164,5 +203,7 @@ func (t *typeValidatorBackend) Delete(ctx context.Context, name string) error {
 		return err
 	}
 
+	t.schemas.Drop(name)
+
 	return nil
 }
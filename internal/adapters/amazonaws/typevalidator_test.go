@@ -2,9 +2,11 @@ package amazonaws
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"nbox/internal/application"
 	"nbox/internal/domain/models"
+	"reflect"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -101,11 +103,12 @@ func (t *testTypeValidatorBackend) Upsert(ctx context.Context, validator models.
 		return errors.New("cannot modify built-in type validator")
 	}
 
-	record := TypeValidatorRecord{
-		Name:  validator.Name,
-		Regex: validator.Regex,
+	if err := validator.Check(); err != nil {
+		return err
 	}
 
+	record := newTypeValidatorRecord(validator)
+
 	item, err := attributevalue.MarshalMap(record)
 	if err != nil {
 		t.logger.Error("ErrMarshalMap", zap.Error(err))
@@ -157,10 +160,8 @@ func (t *testTypeValidatorBackend) Retrieve(ctx context.Context, name string) (*
 		return nil, err
 	}
 
-	return &models.TypeValidator{
-		Name:  record.Name,
-		Regex: record.Regex,
-	}, nil
+	validator := record.toModel()
+	return &validator, nil
 }
 
 // List returns all type validators (built-in + custom)
@@ -192,10 +193,7 @@ func (t *testTypeValidatorBackend) List(ctx context.Context) ([]models.TypeValid
 	}
 
 	for _, record := range records {
-		validators = append(validators, models.TypeValidator{
-			Name:  record.Name,
-			Regex: record.Regex,
-		})
+		validators = append(validators, record.toModel())
 	}
 
 	return validators, nil
@@ -500,3 +498,31 @@ func TestTypeValidatorBackend_Delete(t *testing.T) {
 		})
 	}
 }
+
+func TestTypeValidatorRecord_RoundTrip(t *testing.T) {
+	minimum, maximum := 1.0, 65535.0
+	validators := []models.TypeValidator{
+		{Name: "legacy", Regex: `^\d+$`},
+		{Name: "port", Kind: models.KindInteger, Min: &minimum, Max: &maximum},
+		{Name: "level", Kind: models.KindEnum, Values: []string{"debug", "info"}},
+		{Name: "config", Kind: models.KindJSONSchema, Schema: json.RawMessage(`{"type":"object","required":["host"]}`)},
+	}
+
+	for _, validator := range validators {
+		t.Run(validator.Name, func(t *testing.T) {
+			item, err := attributevalue.MarshalMap(newTypeValidatorRecord(validator))
+			if err != nil {
+				t.Fatalf("MarshalMap() error = %v", err)
+			}
+
+			var record TypeValidatorRecord
+			if err = attributevalue.UnmarshalMap(item, &record); err != nil {
+				t.Fatalf("UnmarshalMap() error = %v", err)
+			}
+
+			if got := record.toModel(); !reflect.DeepEqual(got, validator) {
+				t.Errorf("round trip = %+v, want %+v", got, validator)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package amazonaws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"nbox/internal/application"
+	"nbox/internal/domain"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"go.uber.org/zap"
+)
+
+// webhookRepository webhooks table (PK ID) and dead letters table (PK WebhookID, SK ID)
+type webhookRepository struct {
+	client *dynamodb.Client
+	config *application.Config
+	logger *zap.Logger
+}
+
+func NewWebhookRepository(client *dynamodb.Client, config *application.Config, logger *zap.Logger) domain.WebhookRepository {
+	return &webhookRepository{
+		client: client,
+		config: config,
+		logger: logger.Named("webhook_repository"),
+	}
+}
+
+// FindByEventType the table holds a handful of webhooks, a filtered scan is enough
+func (w *webhookRepository) FindByEventType(ctx context.Context, eventType domain.EventType) ([]domain.Webhook, error) {
+	filter := expression.Contains(expression.Name("Events"), string(eventType))
+	expr, err := expression.NewBuilder().WithFilter(filter).Build()
+	if err != nil {
+		w.logger.Error("ErrExpressionBuilder", zap.Error(err))
+		return nil, err
+	}
+
+	return w.scan(ctx, &dynamodb.ScanInput{
+		TableName:                 aws.String(w.config.WebhookConfigTableName),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		FilterExpression:          expr.Filter(),
+	})
+}
+
+func (w *webhookRepository) List(ctx context.Context) ([]domain.Webhook, error) {
+	return w.scan(ctx, &dynamodb.ScanInput{
+		TableName: aws.String(w.config.WebhookConfigTableName),
+	})
+}
+
+func (w *webhookRepository) scan(ctx context.Context, input *dynamodb.ScanInput) ([]domain.Webhook, error) {
+	webhooks := make([]domain.Webhook, 0)
+
+	paginator := dynamodb.NewScanPaginator(w.client, input)
+	for paginator.HasMorePages() {
+		response, err := paginator.NextPage(ctx)
+		if err != nil {
+			w.logger.Error("ErrScanPaginator", zap.Error(err))
+			return nil, err
+		}
+
+		var records []domain.Webhook
+		if err = attributevalue.UnmarshalListOfMaps(response.Items, &records); err != nil {
+			w.logger.Error("ErrUnmarshalListOfMaps", zap.Error(err))
+			return nil, err
+		}
+		webhooks = append(webhooks, records...)
+	}
+
+	return webhooks, nil
+}
+
+func (w *webhookRepository) Create(ctx context.Context, webhook domain.Webhook) error {
+	item, err := attributevalue.MarshalMap(webhook)
+	if err != nil {
+		w.logger.Error("ErrMarshalMap", zap.Error(err))
+		return err
+	}
+
+	_, err = w.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(w.config.WebhookConfigTableName),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(ID)"),
+	})
+	if err != nil {
+		w.logger.Error("ErrPutItem", zap.Error(err), zap.String("id", webhook.ID))
+		return err
+	}
+
+	return nil
+}
+
+func (w *webhookRepository) Delete(ctx context.Context, webhookID string) error {
+	id, _ := attributevalue.Marshal(webhookID)
+
+	_, err := w.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:           aws.String(w.config.WebhookConfigTableName),
+		Key:                 map[string]types.AttributeValue{"ID": id},
+		ConditionExpression: aws.String("attribute_exists(ID)"),
+	})
+
+	var conditionErr *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		return fmt.Errorf("%w: %s", domain.ErrWebhookNotFound, webhookID)
+	}
+	if err != nil {
+		w.logger.Error("ErrDeleteItem", zap.Error(err), zap.String("id", webhookID))
+		return err
+	}
+
+	return nil
+}
+
+func (w *webhookRepository) SaveFailedDelivery(ctx context.Context, delivery domain.WebhookDelivery) error {
+	item, err := attributevalue.MarshalMap(delivery)
+	if err != nil {
+		w.logger.Error("ErrMarshalMap", zap.Error(err))
+		return err
+	}
+
+	_, err = w.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(w.config.WebhookDeliveryTableName),
+		Item:      item,
+	})
+	if err != nil {
+		w.logger.Error("ErrPutItem", zap.Error(err), zap.String("webhookId", delivery.WebhookID))
+		return err
+	}
+
+	return nil
+}
+
+func (w *webhookRepository) ListFailedDeliveries(ctx context.Context, webhookID string) ([]domain.WebhookDelivery, error) {
+	deliveries := make([]domain.WebhookDelivery, 0)
+
+	keyEx := expression.Key("WebhookID").Equal(expression.Value(webhookID))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyEx).Build()
+	if err != nil {
+		w.logger.Error("ErrExpressionBuilder", zap.Error(err))
+		return nil, err
+	}
+
+	paginator := dynamodb.NewQueryPaginator(w.client, &dynamodb.QueryInput{
+		TableName:                 aws.String(w.config.WebhookDeliveryTableName),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ScanIndexForward:          aws.Bool(false),
+	})
+
+	for paginator.HasMorePages() {
+		response, err := paginator.NextPage(ctx)
+		if err != nil {
+			w.logger.Error("ErrQueryPaginator", zap.Error(err), zap.String("webhookId", webhookID))
+			return nil, err
+		}
+
+		var records []domain.WebhookDelivery
+		if err = attributevalue.UnmarshalListOfMaps(response.Items, &records); err != nil {
+			w.logger.Error("ErrUnmarshalListOfMaps", zap.Error(err))
+			return nil, err
+		}
+		deliveries = append(deliveries, records...)
+	}
+
+	return deliveries, nil
+}
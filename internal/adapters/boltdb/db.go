@@ -0,0 +1,121 @@
+package boltdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"nbox/internal/application"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var (
+	bucketEntries        = []byte("entries")
+	bucketTracking       = []byte("tracking")
+	bucketSecrets        = []byte("secrets")
+	bucketSecretHistory  = []byte("secret_history")
+	bucketTemplates      = []byte("templates")
+	bucketBoxes          = []byte("boxes")
+	bucketTypeValidators = []byte("type_validators")
+	bucketWebhooks       = []byte("webhooks")
+	bucketDeliveries     = []byte("webhook_deliveries")
+	// bucketValidatorUsage reverse index type validator -> entries: (name, path, key) -> entry key
+	bucketValidatorUsage = []byte("type_validator_usage")
+	// bucketSecretReveals audit of plain secret reads: (key, unix nano, id) -> reveal
+	bucketSecretReveals = []byte("secret_reveals")
+	bucketRotations     = []byte("rotation_policies")
+	// bucketKeyIndex full key -> (path, key) of every entry, sorted for the key search
+	bucketKeyIndex = []byte("key_index")
+
+	buckets = [][]byte{
+		bucketEntries,
+		bucketTracking,
+		bucketSecrets,
+		bucketSecretHistory,
+		bucketTemplates,
+		bucketBoxes,
+		bucketTypeValidators,
+		bucketWebhooks,
+		bucketDeliveries,
+		bucketValidatorUsage,
+		bucketSecretReveals,
+		bucketRotations,
+		bucketKeyIndex,
+	}
+)
+
+// keySeparator separates the components of composite keys (path + key, key + timestamp).
+// It sorts before any printable character so prefix scans never bleed into sibling paths.
+const keySeparator = "\x00"
+
+var ErrBoltStoreCheckFailed = errors.New("bolt store check failed")
+
+// NewBoltDB opens (or creates) the embedded store used by the local backend.
+func NewBoltDB(lc fx.Lifecycle, config *application.Config, logger *zap.Logger) (*bolt.DB, error) {
+	db, err := bolt.Open(config.LocalStorePath, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("fallo al abrir el almacen local %s: %w", config.LocalStorePath, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		// stores created before the indexes existed are indexed once
+		reindexUsage := tx.Bucket(bucketValidatorUsage) == nil
+		reindexKeys := tx.Bucket(bucketKeyIndex) == nil
+
+		for _, name := range buckets {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+
+		if reindexUsage {
+			if err := indexTypeValidatorUsage(tx); err != nil {
+				return err
+			}
+		}
+		if reindexKeys {
+			return indexKeys(tx)
+		}
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			logger.Info("Closing local store", zap.String("path", config.LocalStorePath))
+			return db.Close()
+		},
+	})
+
+	return db, nil
+}
+
+type BoltChecker struct {
+	db *bolt.DB
+}
+
+func NewBoltChecker(db *bolt.DB) *BoltChecker {
+	return &BoltChecker{db: db}
+}
+
+func (c *BoltChecker) Check() error {
+	return c.db.View(func(tx *bolt.Tx) error {
+		for _, name := range buckets {
+			if tx.Bucket(name) == nil {
+				return fmt.Errorf("%w: missing bucket %s", ErrBoltStoreCheckFailed, name)
+			}
+		}
+		return nil
+	})
+}
+
+func compositeKey(parts ...string) []byte {
+	return []byte(strings.Join(parts, keySeparator))
+}
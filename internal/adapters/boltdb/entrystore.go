@@ -0,0 +1,584 @@
+package boltdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"nbox/internal/application"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+	"nbox/internal/domain/models/operations"
+	"nbox/internal/usecases"
+	"strconv"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+const lockPrefix = domain.ReservedKeyPrefix
+
+// record mirrors the DynamoDB entry item: Path is the partition and Key the last component.
+type record struct {
+	Path     string          `json:"path"`
+	Key      string          `json:"key"`
+	Value    []byte          `json:"value"`
+	Metadata models.Metadata `json:"metadata"`
+}
+
+type recordTracking struct {
+	Timestamp string `json:"timestamp"`
+	record
+}
+
+// boltEntryBackend entries and tracking history on a bbolt file
+type boltEntryBackend struct {
+	db          *bolt.DB
+	config      *application.Config
+	pathUseCase *usecases.PathUseCase
+	logger      *zap.Logger
+}
+
+func NewBoltEntryBackend(db *bolt.DB, config *application.Config, pathUseCase *usecases.PathUseCase, logger *zap.Logger) domain.EntryAdapter {
+	return &boltEntryBackend{
+		db:          db,
+		config:      config,
+		pathUseCase: pathUseCase,
+		logger:      logger.Named("bolt_entry_backend"),
+	}
+}
+
+// sanitize same normalization as the key policy, so the stored key is the one returned
+func (b *boltEntryBackend) sanitize(key string) string {
+	return usecases.NormalizeKey(b.config, key)
+}
+
+// Upsert is used to insert or update an entry
+func (b *boltEntryBackend) Upsert(ctx context.Context, entries []models.Entry) operations.Results {
+	results := make(operations.Results, len(entries))
+	updatedBy, action := writer(ctx)
+
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		for _, entry := range entries {
+			result := b.put(tx, entry, updatedBy, action)
+			results[result.Key] = result
+		}
+		return nil
+	})
+
+	if err != nil {
+		b.logger.Error("ErrBoltUpdate", zap.Error(err))
+		for k := range results {
+			results[k] = operations.Result{Key: k, Type: operations.Error, Error: err}
+		}
+	}
+
+	return results
+}
+
+// MaxAtomicBatch a bolt transaction has no size limit
+func (b *boltEntryBackend) MaxAtomicBatch() int {
+	return 0
+}
+
+// UpsertAll writes the entries in a single transaction, the first failure rolls it back
+func (b *boltEntryBackend) UpsertAll(ctx context.Context, entries []models.Entry) (operations.Results, error) {
+	results := make(operations.Results, len(entries))
+	updatedBy, action := writer(ctx)
+
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		for _, entry := range entries {
+			result := b.put(tx, entry, updatedBy, action)
+			results[result.Key] = result
+			if result.Error != nil {
+				return result.Error
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		b.logger.Error("ErrBoltUpsertAll", zap.Error(err))
+		failed := make(operations.Results)
+		for k, result := range results {
+			if result.Error != nil {
+				failed[k] = result
+			}
+		}
+		return failed, err
+	}
+
+	return results, nil
+}
+
+// writer user and action recorded in the tracking rows
+func writer(ctx context.Context) (string, string) {
+	updatedBy := "ghost"
+	action := models.ActionUpsert
+
+	if user, ok := application.UserFromContext(ctx); ok {
+		updatedBy = user.Name
+	}
+	if a, ok := application.ActionFromContext(ctx); ok {
+		action = a
+	}
+	return updatedBy, action
+}
+
+// trackingTimestamp key suffix of the tracking rows. Nanoseconds so writes of the same second don't
+// overwrite each other; it still sorts after the rows written with seconds.
+func trackingTimestamp(now time.Time) string {
+	return strconv.FormatInt(now.UnixNano(), 10)
+}
+
+// put writes the entry with its tracking row, folder markers and indexes
+func (b *boltEntryBackend) put(tx *bolt.Tx, entry models.Entry, updatedBy, action string) operations.Result {
+	entriesBucket := tx.Bucket(bucketEntries)
+	trackingBucket := tx.Bucket(bucketTracking)
+
+	now := time.Now().UTC()
+	entryKey := b.sanitize(entry.Key)
+
+	path := b.pathUseCase.PathWithoutKey(entryKey)
+	key := b.pathUseCase.BaseKey(entryKey)
+
+	opType := operations.Created
+	var current int64
+	var previousValidator string
+	if raw := entriesBucket.Get(compositeKey(path, key)); raw != nil {
+		opType = operations.Updated
+		var existing record
+		if err := json.Unmarshal(raw, &existing); err != nil {
+			return operations.Result{Key: entryKey, Type: operations.Error, Error: err}
+		}
+		current = existing.Metadata.Version
+		previousValidator = existing.Metadata.TypeValidatorName
+	}
+
+	// the write transaction is exclusive, no one can change the version between check and put
+	if entry.ExpectedVersion != nil && *entry.ExpectedVersion != current {
+		return operations.Result{
+			Key:  entryKey,
+			Type: operations.Conflict,
+			Error: fmt.Errorf("%w: key '%s' expected version %d, current %d",
+				domain.ErrVersionConflict, entryKey, *entry.ExpectedVersion, current),
+		}
+	}
+
+	metadata := models.Metadata{
+		UpdatedAt:         now,
+		UpdatedBy:         updatedBy,
+		Secure:            entry.Secure,
+		TypeValidatorName: entry.TypeValidatorName,
+		Version:           current + 1,
+	}
+
+	if err := putJSON(entriesBucket, compositeKey(path, key), record{
+		Path: path, Key: key, Value: []byte(entry.Value), Metadata: metadata,
+	}); err != nil {
+		return operations.Result{Key: entryKey, Type: operations.Error, Error: err}
+	}
+
+	if err := updateTypeValidatorUsage(tx, path, key, previousValidator, entry.TypeValidatorName); err != nil {
+		b.logger.Error("ErrSaveTypeValidatorUsage", zap.Error(err), zap.String("key", entryKey))
+	}
+
+	if err := tx.Bucket(bucketKeyIndex).Put([]byte(entryKey), compositeKey(path, key)); err != nil {
+		b.logger.Error("ErrSaveKeyIndex", zap.Error(err), zap.String("key", entryKey))
+	}
+
+	timestamp := trackingTimestamp(now)
+	err := putJSON(trackingBucket, compositeKey(entryKey, timestamp), recordTracking{
+		Timestamp: timestamp,
+		record: record{
+			Key:   entryKey,
+			Value: []byte(entry.Value),
+			Metadata: models.Metadata{
+				UpdatedAt:         now,
+				UpdatedBy:         updatedBy,
+				Secure:            entry.Secure,
+				Action:            action,
+				Version:           metadata.Version,
+				TypeValidatorName: entry.TypeValidatorName,
+			},
+		},
+	})
+	if err != nil {
+		b.logger.Error("ErrSaveTracking", zap.Error(err), zap.String("key", entryKey))
+	}
+
+	for _, prefix := range b.pathUseCase.Prefixes(entryKey) {
+		folderPath := b.pathUseCase.PathWithoutKey(prefix)
+		folderKey := fmt.Sprintf("%s/", b.pathUseCase.BaseKey(prefix))
+		err = putJSON(entriesBucket, compositeKey(folderPath, folderKey), record{
+			Path: folderPath,
+			Key:  folderKey,
+			Metadata: models.Metadata{
+				UpdatedAt: now,
+				UpdatedBy: updatedBy,
+			},
+		})
+		if err != nil {
+			b.logger.Error("ErrSaveFolder", zap.Error(err), zap.String("prefix", prefix))
+		}
+	}
+
+	return operations.Result{Key: entryKey, Type: opType}
+}
+
+// Retrieve is used to fetch an entry
+func (b *boltEntryBackend) Retrieve(_ context.Context, key string) (*models.Entry, error) {
+	var r *record
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		raw := tx.Bucket(bucketEntries).Get(compositeKey(b.pathUseCase.PathWithoutKey(key), b.pathUseCase.BaseKey(key)))
+		if raw == nil {
+			return nil
+		}
+		r = &record{}
+		return json.Unmarshal(raw, r)
+	})
+
+	if err != nil || r == nil {
+		return nil, err
+	}
+
+	return &models.Entry{
+		Key:               b.pathUseCase.Concat(r.Path, r.Key),
+		Value:             string(r.Value),
+		Secure:            r.Metadata.Secure,
+		TypeValidatorName: r.Metadata.TypeValidatorName,
+		Version:           r.Metadata.Version,
+	}, nil
+}
+
+// List is used to list all the keys under a given
+// prefix, up to the next prefix.
+func (b *boltEntryBackend) List(_ context.Context, prefix string) ([]models.Entry, error) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	prefix = b.pathUseCase.EscapeEmptyPath(prefix)
+	entries := make([]models.Entry, 0)
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		seek := compositeKey(prefix, "")
+		c := tx.Bucket(bucketEntries).Cursor()
+		for k, v := c.Seek(seek); k != nil && bytes.HasPrefix(k, seek); k, v = c.Next() {
+			var r record
+			if err := json.Unmarshal(v, &r); err != nil {
+				return err
+			}
+			if strings.HasPrefix(r.Key, lockPrefix) {
+				continue
+			}
+			entries = append(entries, models.Entry{
+				Key:               r.Key,
+				Value:             string(r.Value),
+				Path:              r.Path,
+				Secure:            r.Metadata.Secure,
+				TypeValidatorName: r.Metadata.TypeValidatorName,
+				Version:           r.Metadata.Version,
+			})
+		}
+		return nil
+	})
+
+	if err != nil {
+		b.logger.Error("ErrBoltList", zap.Error(err), zap.String("prefix", prefix))
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// ListPage one page of the entries of the path sorted by key, after the key `after`; next is
+// empty on the last page
+func (b *boltEntryBackend) ListPage(_ context.Context, path string, after string, limit int) ([]models.Entry, string, error) {
+	path = b.pathUseCase.EscapeEmptyPath(strings.TrimSuffix(path, "/"))
+	entries := make([]models.Entry, 0, limit)
+	next := ""
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		prefix := compositeKey(path, "")
+		c := tx.Bucket(bucketEntries).Cursor()
+		k, v := c.Seek(compositeKey(path, after))
+		for ; k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			var r record
+			if err := json.Unmarshal(v, &r); err != nil {
+				return err
+			}
+			if r.Key == after || strings.HasPrefix(r.Key, lockPrefix) {
+				continue
+			}
+			if len(entries) == limit {
+				next = entries[len(entries)-1].Key
+				break
+			}
+			entries = append(entries, models.Entry{
+				Key:               r.Key,
+				Value:             string(r.Value),
+				Path:              r.Path,
+				Secure:            r.Metadata.Secure,
+				TypeValidatorName: r.Metadata.TypeValidatorName,
+				Version:           r.Metadata.Version,
+			})
+		}
+		return nil
+	})
+
+	if err != nil {
+		b.logger.Error("ErrBoltList", zap.Error(err), zap.String("path", path))
+		return nil, "", err
+	}
+
+	return entries, next, nil
+}
+
+// Delete removes the key and its children, leaving a tombstone tracking row for each entry
+func (b *boltEntryBackend) Delete(ctx context.Context, key string) ([]string, error) {
+	children, err := b.List(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedBy := "ghost"
+	user, ok := application.UserFromContext(ctx)
+	if ok {
+		updatedBy = user.Name
+	}
+
+	deleted := make([]string, 0)
+
+	err = b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketEntries)
+		trackingBucket := tx.Bucket(bucketTracking)
+
+		now := time.Now().UTC()
+		timestamp := trackingTimestamp(now)
+
+		tombstone := func(raw []byte) error {
+			var r record
+			if err := json.Unmarshal(raw, &r); err != nil {
+				return err
+			}
+			// folder markers are not entries
+			if strings.HasSuffix(r.Key, "/") {
+				return nil
+			}
+			if err := updateTypeValidatorUsage(tx, r.Path, r.Key, r.Metadata.TypeValidatorName, ""); err != nil {
+				return err
+			}
+			entryKey := b.pathUseCase.Concat(r.Path, r.Key)
+			if err := tx.Bucket(bucketKeyIndex).Delete([]byte(entryKey)); err != nil {
+				return err
+			}
+			deleted = append(deleted, entryKey)
+			return putJSON(trackingBucket, compositeKey(entryKey, timestamp), recordTracking{
+				Timestamp: timestamp,
+				record: record{
+					Key:   entryKey,
+					Value: r.Value,
+					Metadata: models.Metadata{
+						UpdatedAt:         now,
+						UpdatedBy:         updatedBy,
+						Secure:            r.Metadata.Secure,
+						Action:            models.ActionDelete,
+						Version:           r.Metadata.Version,
+						TypeValidatorName: r.Metadata.TypeValidatorName,
+					},
+				},
+			})
+		}
+
+		targets := [][]byte{compositeKey(b.pathUseCase.PathWithoutKey(key), b.pathUseCase.BaseKey(key))}
+		for _, e := range children {
+			targets = append(targets, compositeKey(e.Path, e.Key))
+		}
+
+		for _, target := range targets {
+			raw := bucket.Get(target)
+			if raw == nil {
+				continue
+			}
+			if err := tombstone(raw); err != nil {
+				return err
+			}
+			if err := bucket.Delete(target); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return deleted, nil
+}
+
+func (b *boltEntryBackend) Tracking(_ context.Context, key string) ([]models.Tracking, error) {
+	entries := make([]models.Tracking, 0)
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		seek := compositeKey(key, "")
+		c := tx.Bucket(bucketTracking).Cursor()
+
+		// newest first, like ScanIndexForward=false on the tracking table
+		var raws [][]byte
+		for k, v := c.Seek(seek); k != nil && bytes.HasPrefix(k, seek); k, v = c.Next() {
+			raws = append(raws, v)
+		}
+		for i := len(raws) - 1; i >= 0; i-- {
+			var r recordTracking
+			if err := json.Unmarshal(raws[i], &r); err != nil {
+				return err
+			}
+			entries = append(entries, models.Tracking{
+				Key:       r.Key,
+				Value:     string(r.Value),
+				Secure:    r.Metadata.Secure,
+				UpdatedAt: r.Metadata.UpdatedAt,
+				UpdatedBy: r.Metadata.UpdatedBy,
+				Action:    r.Metadata.Action,
+				Version:   r.Metadata.Version,
+
+				TypeValidatorName: r.Metadata.TypeValidatorName,
+			})
+		}
+		return nil
+	})
+
+	if err != nil {
+		b.logger.Error("ErrBoltTracking", zap.Error(err), zap.String("key", key))
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// ListByTypeValidator entries validated by the type validator, read through the usage index
+func (b *boltEntryBackend) ListByTypeValidator(_ context.Context, name string) ([]models.Entry, error) {
+	entries := make([]models.Entry, 0)
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		entriesBucket := tx.Bucket(bucketEntries)
+		seek := compositeKey(name, "")
+		c := tx.Bucket(bucketValidatorUsage).Cursor()
+		for k, v := c.Seek(seek); k != nil && bytes.HasPrefix(k, seek); k, v = c.Next() {
+			raw := entriesBucket.Get(v)
+			if raw == nil {
+				continue
+			}
+			var r record
+			if err := json.Unmarshal(raw, &r); err != nil {
+				return err
+			}
+			entries = append(entries, models.Entry{
+				Key:               b.pathUseCase.Concat(r.Path, r.Key),
+				Value:             string(r.Value),
+				Path:              r.Path,
+				Secure:            r.Metadata.Secure,
+				TypeValidatorName: r.Metadata.TypeValidatorName,
+				Version:           r.Metadata.Version,
+			})
+		}
+		return nil
+	})
+
+	if err != nil {
+		b.logger.Error("ErrBoltListByTypeValidator", zap.Error(err), zap.String("validator", name))
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// SearchKeys entries under the prefix, a range of the key index
+func (b *boltEntryBackend) SearchKeys(_ context.Context, prefix string) ([]models.Entry, error) {
+	entries := make([]models.Entry, 0)
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		entriesBucket := tx.Bucket(bucketEntries)
+		seek := []byte(prefix)
+		c := tx.Bucket(bucketKeyIndex).Cursor()
+		for k, v := c.Seek(seek); k != nil && bytes.HasPrefix(k, seek); k, v = c.Next() {
+			raw := entriesBucket.Get(v)
+			if raw == nil {
+				continue
+			}
+			var r record
+			if err := json.Unmarshal(raw, &r); err != nil {
+				return err
+			}
+			entries = append(entries, models.Entry{
+				Key:               string(k),
+				Path:              r.Path,
+				Secure:            r.Metadata.Secure,
+				TypeValidatorName: r.Metadata.TypeValidatorName,
+				Version:           r.Metadata.Version,
+			})
+		}
+		return nil
+	})
+
+	if err != nil {
+		b.logger.Error("ErrBoltSearchKeys", zap.Error(err), zap.String("prefix", prefix))
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// updateTypeValidatorUsage moves the entry (path, key) from the previous validator to the current one
+func updateTypeValidatorUsage(tx *bolt.Tx, path, key, previous, current string) error {
+	bucket := tx.Bucket(bucketValidatorUsage)
+	if previous != "" && previous != current {
+		if err := bucket.Delete(compositeKey(previous, path, key)); err != nil {
+			return err
+		}
+	}
+	if current == "" {
+		return nil
+	}
+	return bucket.Put(compositeKey(current, path, key), compositeKey(path, key))
+}
+
+// indexTypeValidatorUsage builds the usage index from the entries bucket
+func indexTypeValidatorUsage(tx *bolt.Tx) error {
+	return tx.Bucket(bucketEntries).ForEach(func(_, v []byte) error {
+		var r record
+		if err := json.Unmarshal(v, &r); err != nil {
+			return err
+		}
+		if r.Metadata.TypeValidatorName == "" || strings.HasPrefix(r.Key, lockPrefix) || strings.HasSuffix(r.Key, "/") {
+			return nil
+		}
+		return updateTypeValidatorUsage(tx, r.Path, r.Key, "", r.Metadata.TypeValidatorName)
+	})
+}
+
+// indexKeys builds the key index from the entries bucket
+func indexKeys(tx *bolt.Tx) error {
+	pathUseCase := usecases.NewPathUseCase()
+	bucket := tx.Bucket(bucketKeyIndex)
+	return tx.Bucket(bucketEntries).ForEach(func(k, v []byte) error {
+		var r record
+		if err := json.Unmarshal(v, &r); err != nil {
+			return err
+		}
+		if strings.HasPrefix(r.Key, lockPrefix) || strings.HasSuffix(r.Key, "/") {
+			return nil
+		}
+		return bucket.Put([]byte(pathUseCase.Concat(r.Path, r.Key)), k)
+	})
+}
+
+func putJSON(bucket *bolt.Bucket, key []byte, v any) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return bucket.Put(key, raw)
+}
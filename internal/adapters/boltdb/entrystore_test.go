@@ -0,0 +1,560 @@
+package boltdb
+
+import (
+	"context"
+	"nbox/internal/application"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+	"nbox/internal/domain/models/operations"
+	"nbox/internal/usecases"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+)
+
+func newTestDB(t *testing.T) (*bolt.DB, *application.Config) {
+	config := &application.Config{
+		DefaultPrefix:   "global",
+		AllowedPrefixes: []string{"global/", "development/", "production/"},
+		LocalStorePath:  filepath.Join(t.TempDir(), "nbox.db"),
+	}
+
+	lc := fxtest.NewLifecycle(t)
+	db, err := NewBoltDB(lc, config, zap.NewNop())
+	require.NoError(t, err)
+	lc.RequireStart()
+	t.Cleanup(lc.RequireStop)
+
+	return db, config
+}
+
+func TestBoltEntryBackend_UpsertRetrieveList(t *testing.T) {
+	db, config := newTestDB(t)
+	backend := NewBoltEntryBackend(db, config, usecases.NewPathUseCase(), zap.NewNop())
+	ctx := application.NewContextWithUser(context.Background(), application.User{Name: "tester"})
+
+	results := backend.Upsert(ctx, []models.Entry{
+		{Key: "development/myapp/db_host", Value: "localhost"},
+		{Key: "development/myapp/db_port", Value: "5432", TypeValidatorName: "number"},
+		{Key: "api_url", Value: "https://example.com"},
+	})
+
+	require.Len(t, results, 3)
+	assert.Equal(t, operations.Created, results["development/myapp/db_host"].Type)
+	assert.Equal(t, operations.Created, results["global/api_url"].Type)
+
+	results = backend.Upsert(ctx, []models.Entry{{Key: "development/myapp/db_host", Value: "db.internal"}})
+	assert.Equal(t, operations.Updated, results["development/myapp/db_host"].Type)
+
+	entry, err := backend.Retrieve(ctx, "development/myapp/db_port")
+	require.NoError(t, err)
+	require.NotNil(t, entry)
+	assert.Equal(t, "5432", entry.Value)
+	assert.Equal(t, "number", entry.TypeValidatorName)
+
+	missing, err := backend.Retrieve(ctx, "development/myapp/missing")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	entries, err := backend.List(ctx, "development/myapp")
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	folders, err := backend.List(ctx, "development")
+	require.NoError(t, err)
+	require.Len(t, folders, 1)
+	assert.Equal(t, "myapp/", folders[0].Key)
+
+	history, err := backend.Tracking(ctx, "development/myapp/db_host")
+	require.NoError(t, err)
+	require.NotEmpty(t, history)
+	assert.Equal(t, "db.internal", history[0].Value)
+	assert.Equal(t, "tester", history[0].UpdatedBy)
+}
+
+func TestBoltEntryBackend_ListPage(t *testing.T) {
+	db, config := newTestDB(t)
+	backend := NewBoltEntryBackend(db, config, usecases.NewPathUseCase(), zap.NewNop())
+	ctx := application.NewContextWithUser(context.Background(), application.User{Name: "tester"})
+
+	backend.Upsert(ctx, []models.Entry{
+		{Key: "production/a", Value: "1"},
+		{Key: "production/b", Value: "2"},
+		{Key: "production/myapp/c", Value: "3"},
+		{Key: "production/z", Value: "4"},
+		{Key: "development/d", Value: "5"},
+	})
+
+	keys := func(entries []models.Entry) []string {
+		out := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			out = append(out, entry.Key)
+		}
+		return out
+	}
+
+	entries, next, err := backend.ListPage(ctx, "production/", "", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, keys(entries))
+	assert.Equal(t, "b", next)
+
+	entries, next, err = backend.ListPage(ctx, "production", next, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"myapp/", "z"}, keys(entries))
+	assert.Empty(t, next)
+
+	entries, _, err = backend.ListPage(ctx, "", "", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"development/", "production/"}, keys(entries))
+}
+
+func TestBoltEntryBackend_SearchKeys(t *testing.T) {
+	db, config := newTestDB(t)
+	backend := NewBoltEntryBackend(db, config, usecases.NewPathUseCase(), zap.NewNop())
+	ctx := application.NewContextWithUser(context.Background(), application.User{Name: "tester"})
+
+	backend.Upsert(ctx, []models.Entry{
+		{Key: "production/myapp/db_host", Value: "db.internal"},
+		{Key: "production/myapp/db_password", Value: "/production/myapp/db_password", Secure: true},
+		{Key: "production/billing/db_host", Value: "billing.internal"},
+		{Key: "development/myapp/db_host", Value: "localhost"},
+	})
+
+	entries, err := backend.SearchKeys(ctx, "production/myapp/")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, models.Entry{Key: "production/myapp/db_host", Path: "production/myapp", Version: 1}, entries[0])
+	assert.Equal(t, "production/myapp/db_password", entries[1].Key)
+	assert.True(t, entries[1].Secure)
+	assert.Empty(t, entries[1].Value)
+
+	_, err = backend.Delete(ctx, "production/myapp")
+	require.NoError(t, err)
+
+	entries, err = backend.SearchKeys(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"development/myapp/db_host", "production/billing/db_host"}, []string{entries[0].Key, entries[1].Key})
+	assert.Len(t, entries, 2)
+}
+
+func TestNewBoltDB_IndexesKeysOfExistingStores(t *testing.T) {
+	db, config := newTestDB(t)
+	backend := NewBoltEntryBackend(db, config, usecases.NewPathUseCase(), zap.NewNop())
+	ctx := application.NewContextWithUser(context.Background(), application.User{Name: "tester"})
+	backend.Upsert(ctx, []models.Entry{{Key: "production/myapp/db_host", Value: "db.internal"}})
+
+	// a store written before the key index existed
+	require.NoError(t, db.Update(func(tx *bolt.Tx) error { return tx.DeleteBucket(bucketKeyIndex) }))
+	require.NoError(t, db.Close())
+
+	lc := fxtest.NewLifecycle(t)
+	db, err := NewBoltDB(lc, config, zap.NewNop())
+	require.NoError(t, err)
+	lc.RequireStart()
+	t.Cleanup(lc.RequireStop)
+
+	entries, err := NewBoltEntryBackend(db, config, usecases.NewPathUseCase(), zap.NewNop()).SearchKeys(ctx, "production/")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "production/myapp/db_host", entries[0].Key)
+}
+
+func TestBoltEntryBackend_UpsertAll(t *testing.T) {
+	db, config := newTestDB(t)
+	backend := NewBoltEntryBackend(db, config, usecases.NewPathUseCase(), zap.NewNop())
+	ctx := application.NewContextWithUser(context.Background(), application.User{Name: "tester"})
+
+	results, err := backend.UpsertAll(ctx, []models.Entry{
+		{Key: "production/app/host", Value: "db.internal"},
+		{Key: "production/app/port", Value: "5432"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, operations.Created, results["production/app/host"].Type)
+
+	// a stale entry rolls back the whole batch
+	stale := int64(0)
+	results, err = backend.UpsertAll(ctx, []models.Entry{
+		{Key: "production/app/host", Value: "db2.internal"},
+		{Key: "production/app/user", Value: "admin"},
+		{Key: "production/app/port", Value: "6543", ExpectedVersion: &stale},
+	})
+	require.ErrorIs(t, err, domain.ErrVersionConflict)
+	assert.Equal(t, operations.Results{"production/app/port": results["production/app/port"]}, results)
+	assert.Equal(t, operations.Conflict, results["production/app/port"].Type)
+
+	entry, err := backend.Retrieve(ctx, "production/app/host")
+	require.NoError(t, err)
+	assert.Equal(t, "db.internal", entry.Value)
+	assert.Equal(t, int64(1), entry.Version)
+
+	entry, err = backend.Retrieve(ctx, "production/app/user")
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+
+	tracking, err := backend.Tracking(ctx, "production/app/host")
+	require.NoError(t, err)
+	assert.Len(t, tracking, 1)
+}
+
+func TestBoltEntryBackend_Delete(t *testing.T) {
+	db, config := newTestDB(t)
+	backend := NewBoltEntryBackend(db, config, usecases.NewPathUseCase(), zap.NewNop())
+	ctx := context.Background()
+
+	backend.Upsert(ctx, []models.Entry{
+		{Key: "development/myapp/db_host", Value: "localhost"},
+		{Key: "development/other/db_host", Value: "localhost"},
+	})
+
+	deleted, err := backend.Delete(ctx, "development/myapp")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"development/myapp/db_host"}, deleted)
+
+	entries, err := backend.List(ctx, "development/myapp")
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	other, err := backend.Retrieve(ctx, "development/other/db_host")
+	require.NoError(t, err)
+	assert.NotNil(t, other)
+}
+
+func TestBoltSecretStore_UpsertRetrieve(t *testing.T) {
+	db, _ := newTestDB(t)
+	store := NewBoltSecretStore(db, &application.Config{}, zap.NewNop())
+	ctx := context.Background()
+
+	results := store.Upsert(ctx, []models.Entry{{Key: "production/myapp/password", Value: "s3cr3t", Secure: true}})
+	assert.Equal(t, operations.Created, results["production/myapp/password"].Type)
+
+	results = store.Upsert(ctx, []models.Entry{{Key: "production/myapp/password", Value: "n3w", Secure: true}})
+	assert.Equal(t, operations.Updated, results["production/myapp/password"].Type)
+
+	entry, err := store.RetrieveSecretValue(ctx, "/production/myapp/password")
+	require.NoError(t, err)
+	assert.Equal(t, "n3w", entry.Value)
+
+	_, err = store.RetrieveSecretValue(ctx, "/production/myapp/missing")
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+}
+
+func TestBoltSecretStore_ListSecrets(t *testing.T) {
+	db, _ := newTestDB(t)
+	store := NewBoltSecretStore(db, &application.Config{}, zap.NewNop())
+	ctx := application.NewContextWithUser(context.Background(), application.User{Name: "tester"})
+
+	store.Upsert(ctx, []models.Entry{
+		{Key: "production/myapp/password", Value: "s3cr3t", Secure: true, TypeValidatorName: "password"},
+		{Key: "production/other/token", Value: "t0k3n", Secure: true},
+		{Key: "development/myapp/password", Value: "dev", Secure: true},
+	})
+	// the metadata follows every update
+	store.Upsert(ctx, []models.Entry{{Key: "production/other/token", Value: "t0k3n", Secure: true, TypeValidatorName: "token"}})
+
+	secrets, err := store.ListSecrets(ctx, "production/")
+	require.NoError(t, err)
+	require.Len(t, secrets, 2)
+
+	assert.False(t, secrets[0].UpdatedAt.IsZero())
+	secrets[0].UpdatedAt = time.Time{}
+	assert.Equal(t, models.SecretParameter{
+		Name: "/production/myapp/password", Key: "production/myapp/password", TypeValidatorName: "password", Owner: "tester", Value: "s3cr3t",
+	}, secrets[0])
+	assert.Equal(t, "token", secrets[1].TypeValidatorName)
+
+	secrets, err = store.ListSecrets(ctx, "")
+	require.NoError(t, err)
+	assert.Len(t, secrets, 3)
+}
+
+func TestBoltSecretStore_Delete(t *testing.T) {
+	db, _ := newTestDB(t)
+	store := NewBoltSecretStore(db, &application.Config{}, zap.NewNop())
+	ctx := context.Background()
+
+	store.Upsert(ctx, []models.Entry{{Key: "production/myapp/password", Value: "s3cr3t", Secure: true}})
+	store.Upsert(ctx, []models.Entry{{Key: "production/myapp/password", Value: "n3w", Secure: true}})
+	store.Upsert(ctx, []models.Entry{{Key: "production/myapp/password_old", Value: "0ld", Secure: true}})
+
+	results := store.Delete(ctx, []string{"/production/myapp/password", "/production/myapp/missing"})
+	assert.Equal(t, operations.Deleted, results["/production/myapp/password"].Type)
+	assert.Equal(t, operations.Deleted, results["/production/myapp/missing"].Type)
+
+	_, err := store.RetrieveSecretValue(ctx, "/production/myapp/password")
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+	_, err = store.RetrieveSecretValueAt(ctx, "/production/myapp/password", time.Now())
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+
+	// a key sharing the prefix is untouched
+	entry, err := store.RetrieveSecretValue(ctx, "/production/myapp/password_old")
+	require.NoError(t, err)
+	assert.Equal(t, "0ld", entry.Value)
+}
+
+func TestBoltSecretAuditRepository_List(t *testing.T) {
+	db, _ := newTestDB(t)
+	repository := NewBoltSecretAuditRepository(db, zap.NewNop())
+	ctx := context.Background()
+	at := time.Date(2026, 10, 5, 9, 0, 0, 0, time.UTC)
+
+	for i, reveal := range []models.SecretReveal{
+		{ID: "1", Key: "production/myapp/db_password", User: "jdoe", RevealedAt: at},
+		{ID: "2", Key: "production/myapp/db_password", User: "asmith", RevealedAt: at.Add(time.Hour)},
+		{ID: "3", Key: "production/myapp/db_password_old", User: "jdoe", RevealedAt: at.Add(2 * time.Hour)},
+		{ID: "4", Key: "production/myapp/db_password", User: "jdoe", RevealedAt: at.Add(48 * time.Hour)},
+	} {
+		require.NoError(t, repository.Save(ctx, reveal), i)
+	}
+
+	// the key is exact, sibling keys sharing the prefix are not included
+	reveals, err := repository.List(ctx, models.SecretRevealFilter{Key: "production/myapp/db_password"})
+	require.NoError(t, err)
+	require.Len(t, reveals, 3)
+	assert.Equal(t, []string{"4", "2", "1"}, []string{reveals[0].ID, reveals[1].ID, reveals[2].ID})
+
+	reveals, err = repository.List(ctx, models.SecretRevealFilter{User: "jdoe", Since: at, Until: at.Add(24 * time.Hour)})
+	require.NoError(t, err)
+	require.Len(t, reveals, 2)
+	assert.Equal(t, "3", reveals[0].ID)
+
+	reveals, err = repository.List(ctx, models.SecretRevealFilter{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, reveals, 1)
+	assert.Equal(t, "4", reveals[0].ID)
+}
+
+func TestBoltRotationRepository(t *testing.T) {
+	db, _ := newTestDB(t)
+	repository := NewBoltRotationRepository(db, zap.NewNop())
+	ctx := context.Background()
+
+	policy := models.RotationPolicy{
+		Key:            "production/myapp/db_password",
+		Interval:       "720h",
+		Generator:      models.SecretGenerator{Kind: models.GeneratorRandom, Length: 32},
+		NextRotationAt: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, repository.Save(ctx, policy))
+
+	stored, err := repository.Retrieve(ctx, policy.Key)
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	assert.Equal(t, policy, *stored)
+
+	policies, err := repository.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, policies, 1)
+
+	require.NoError(t, repository.Delete(ctx, policy.Key))
+	assert.ErrorIs(t, repository.Delete(ctx, policy.Key), domain.ErrRotationPolicyNotFound)
+
+	missing, err := repository.Retrieve(ctx, policy.Key)
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+func TestBoltEntryBackend_Versions(t *testing.T) {
+	db, config := newTestDB(t)
+	backend := NewBoltEntryBackend(db, config, usecases.NewPathUseCase(), zap.NewNop())
+	ctx := context.Background()
+
+	backend.Upsert(ctx, []models.Entry{{Key: "development/myapp/db_host", Value: "localhost"}})
+	backend.Upsert(ctx, []models.Entry{{Key: "development/myapp/db_host", Value: "db.internal"}})
+
+	entry, err := backend.Retrieve(ctx, "development/myapp/db_host")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), entry.Version)
+
+	stale := int64(1)
+	results := backend.Upsert(ctx, []models.Entry{{Key: "development/myapp/db_host", Value: "stale", ExpectedVersion: &stale}})
+	assert.Equal(t, operations.Conflict, results["development/myapp/db_host"].Type)
+	assert.ErrorIs(t, results["development/myapp/db_host"].Error, domain.ErrVersionConflict)
+
+	createOnly := int64(0)
+	results = backend.Upsert(ctx, []models.Entry{{Key: "development/myapp/db_host", Value: "new", ExpectedVersion: &createOnly}})
+	assert.Equal(t, operations.Conflict, results["development/myapp/db_host"].Type)
+
+	current := int64(2)
+	results = backend.Upsert(ctx, []models.Entry{{Key: "development/myapp/db_host", Value: "db.prod", ExpectedVersion: &current}})
+	require.NoError(t, results["development/myapp/db_host"].Error)
+
+	entry, err = backend.Retrieve(ctx, "development/myapp/db_host")
+	require.NoError(t, err)
+	assert.Equal(t, "db.prod", entry.Value)
+	assert.Equal(t, int64(3), entry.Version)
+}
+
+func TestBoltSecretStore_RetrieveSecretValueAt(t *testing.T) {
+	db, _ := newTestDB(t)
+	store := NewBoltSecretStore(db, &application.Config{}, zap.NewNop())
+	ctx := context.Background()
+
+	store.Upsert(ctx, []models.Entry{{Key: "production/myapp/password", Value: "first", Secure: true}})
+	store.Upsert(ctx, []models.Entry{{Key: "production/myapp/password", Value: "second", Secure: true}})
+
+	entry, err := store.RetrieveSecretValueAt(ctx, "/production/myapp/password", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, "second", entry.Value)
+
+	_, err = store.RetrieveSecretValueAt(ctx, "/production/myapp/password", time.Now().Add(-time.Hour))
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+}
+
+func TestBoltEntryBackend_TrackingAction(t *testing.T) {
+	db, config := newTestDB(t)
+	backend := NewBoltEntryBackend(db, config, usecases.NewPathUseCase(), zap.NewNop())
+	ctx := application.NewContextWithAction(context.Background(), models.ActionRollback)
+
+	backend.Upsert(ctx, []models.Entry{{Key: "development/myapp/db_host", Value: "localhost"}})
+
+	history, err := backend.Tracking(ctx, "development/myapp/db_host")
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, models.ActionRollback, history[0].Action)
+	assert.Equal(t, int64(1), history[0].Version)
+}
+
+func TestBoltEntryBackend_TrackingKeepsSameSecondWrites(t *testing.T) {
+	db, config := newTestDB(t)
+	backend := NewBoltEntryBackend(db, config, usecases.NewPathUseCase(), zap.NewNop())
+	ctx := context.Background()
+
+	for _, value := range []string{"localhost", "db.internal", "db.prod"} {
+		backend.Upsert(ctx, []models.Entry{{Key: "development/myapp/db_host", Value: value}})
+	}
+	_, err := backend.Delete(ctx, "development/myapp/db_host")
+	require.NoError(t, err)
+
+	history, err := backend.Tracking(ctx, "development/myapp/db_host")
+	require.NoError(t, err)
+	require.Len(t, history, 4)
+	assert.Equal(t, models.ActionDelete, history[0].Action)
+	for i, value := range []string{"db.prod", "db.internal", "localhost"} {
+		assert.Equal(t, value, history[i+1].Value)
+		assert.Equal(t, int64(3-i), history[i+1].Version)
+	}
+}
+
+func TestBoltEntryBackend_DeleteTombstones(t *testing.T) {
+	db, config := newTestDB(t)
+	backend := NewBoltEntryBackend(db, config, usecases.NewPathUseCase(), zap.NewNop())
+	ctx := application.NewContextWithUser(context.Background(), application.User{Name: "remover"})
+
+	backend.Upsert(ctx, []models.Entry{
+		{Key: "development/myapp/db_port", Value: "5432", TypeValidatorName: "number"},
+		{Key: "development/myapp/db_host", Value: "localhost"},
+	})
+
+	deleted, err := backend.Delete(ctx, "development/myapp")
+	require.NoError(t, err)
+	assert.Len(t, deleted, 2)
+
+	for _, key := range []string{"development/myapp/db_port", "development/myapp/db_host"} {
+		history, err := backend.Tracking(ctx, key)
+		require.NoError(t, err)
+		require.NotEmpty(t, history)
+		assert.Equal(t, models.ActionDelete, history[0].Action)
+		assert.Equal(t, "remover", history[0].UpdatedBy)
+	}
+
+	history, err := backend.Tracking(ctx, "development/myapp/db_port")
+	require.NoError(t, err)
+	assert.Equal(t, "number", history[0].TypeValidatorName)
+	assert.Equal(t, "5432", history[0].Value)
+
+	folders, err := backend.Tracking(ctx, "development/myapp/")
+	require.NoError(t, err)
+	assert.Empty(t, folders)
+}
+
+func TestBoltEntryBackend_ListByTypeValidator(t *testing.T) {
+	db, config := newTestDB(t)
+	backend := NewBoltEntryBackend(db, config, usecases.NewPathUseCase(), zap.NewNop())
+	ctx := context.Background()
+
+	backend.Upsert(ctx, []models.Entry{
+		{Key: "development/myapp/port", Value: "8080", TypeValidatorName: "port"},
+		{Key: "production/other/nested/port", Value: "443", TypeValidatorName: "port"},
+		{Key: "development/myapp/db_port", Value: "5432", TypeValidatorName: "number"},
+		{Key: "development/myapp/name", Value: "myapp"},
+	})
+
+	entries, err := backend.ListByTypeValidator(ctx, "port")
+	require.NoError(t, err)
+
+	keys := make([]string, 0, len(entries))
+	for _, e := range entries {
+		keys = append(keys, e.Key)
+	}
+	assert.ElementsMatch(t, []string{"development/myapp/port", "production/other/nested/port"}, keys)
+
+	entries, err = backend.ListByTypeValidator(ctx, "unused")
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	// deleting the entry removes it from the index
+	_, err = backend.Delete(ctx, "production/other/nested/port")
+	require.NoError(t, err)
+	entries, err = backend.ListByTypeValidator(ctx, "port")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "development/myapp/port", entries[0].Key)
+}
+
+func TestBoltEntryBackend_ReindexTypeValidatorUsage(t *testing.T) {
+	db, config := newTestDB(t)
+	backend := NewBoltEntryBackend(db, config, usecases.NewPathUseCase(), zap.NewNop())
+	ctx := context.Background()
+
+	backend.Upsert(ctx, []models.Entry{
+		{Key: "development/myapp/port", Value: "8080", TypeValidatorName: "port"},
+		{Key: "development/myapp/name", Value: "myapp"},
+	})
+
+	// a store written before the index existed
+	require.NoError(t, db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(bucketValidatorUsage); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucket(bucketValidatorUsage); err != nil {
+			return err
+		}
+		return indexTypeValidatorUsage(tx)
+	}))
+
+	entries, err := backend.ListByTypeValidator(ctx, "port")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "development/myapp/port", entries[0].Key)
+	assert.Equal(t, "8080", entries[0].Value)
+}
+
+func TestBoltTypeValidatorBackend_StructuredKinds(t *testing.T) {
+	db, _ := newTestDB(t)
+	backend := NewBoltTypeValidatorBackend(db, zap.NewNop())
+	ctx := context.Background()
+
+	maxLength := 8
+	validator := models.TypeValidator{
+		Name:      "log-level",
+		Kind:      models.KindEnum,
+		Values:    []string{"debug", "info", "warn"},
+		MaxLength: &maxLength,
+	}
+	require.NoError(t, backend.Upsert(ctx, validator))
+
+	stored, err := backend.Retrieve(ctx, "log-level")
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	assert.Equal(t, validator, *stored)
+
+	// definitions are checked before they are stored
+	err = backend.Upsert(ctx, models.TypeValidator{Name: "empty-enum", Kind: models.KindEnum})
+	assert.ErrorIs(t, err, models.ErrInvalidTypeValidator)
+}
@@ -0,0 +1,80 @@
+package boltdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+type boltRotationRepository struct {
+	db     *bolt.DB
+	logger *zap.Logger
+}
+
+func NewBoltRotationRepository(db *bolt.DB, logger *zap.Logger) domain.RotationRepository {
+	return &boltRotationRepository{db: db, logger: logger.Named("bolt_rotation_repository")}
+}
+
+func (r *boltRotationRepository) Save(_ context.Context, policy models.RotationPolicy) error {
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		return putJSON(tx.Bucket(bucketRotations), []byte(policy.Key), policy)
+	})
+	if err != nil {
+		r.logger.Error("ErrBoltPut", zap.Error(err), zap.String("key", policy.Key))
+	}
+	return err
+}
+
+// Retrieve nil when the key has no policy
+func (r *boltRotationRepository) Retrieve(_ context.Context, key string) (*models.RotationPolicy, error) {
+	var policy *models.RotationPolicy
+	err := r.db.View(func(tx *bolt.Tx) error {
+		raw := tx.Bucket(bucketRotations).Get([]byte(key))
+		if raw == nil {
+			return nil
+		}
+		policy = &models.RotationPolicy{}
+		return json.Unmarshal(raw, policy)
+	})
+	if err != nil {
+		r.logger.Error("ErrBoltGet", zap.Error(err), zap.String("key", key))
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (r *boltRotationRepository) List(_ context.Context) ([]models.RotationPolicy, error) {
+	policies := make([]models.RotationPolicy, 0)
+
+	err := r.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketRotations).ForEach(func(_, v []byte) error {
+			var policy models.RotationPolicy
+			if err := json.Unmarshal(v, &policy); err != nil {
+				return err
+			}
+			policies = append(policies, policy)
+			return nil
+		})
+	})
+	if err != nil {
+		r.logger.Error("ErrBoltList", zap.Error(err))
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+func (r *boltRotationRepository) Delete(_ context.Context, key string) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketRotations)
+		if bucket.Get([]byte(key)) == nil {
+			return fmt.Errorf("%w: %s", domain.ErrRotationPolicyNotFound, key)
+		}
+		return bucket.Delete([]byte(key))
+	})
+}
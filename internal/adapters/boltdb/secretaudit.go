@@ -0,0 +1,82 @@
+package boltdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+	"sort"
+
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+type boltSecretAuditRepository struct {
+	db     *bolt.DB
+	logger *zap.Logger
+}
+
+func NewBoltSecretAuditRepository(db *bolt.DB, logger *zap.Logger) domain.SecretAuditRepository {
+	return &boltSecretAuditRepository{db: db, logger: logger.Named("bolt_secret_audit_repository")}
+}
+
+func (s *boltSecretAuditRepository) Save(_ context.Context, reveal models.SecretReveal) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		return putJSON(tx.Bucket(bucketSecretReveals), revealKey(reveal), reveal)
+	})
+	if err != nil {
+		s.logger.Error("ErrBoltSaveReveal", zap.Error(err), zap.String("key", reveal.Key))
+	}
+	return err
+}
+
+// List with a key only the records of that key are read, otherwise the whole bucket
+func (s *boltSecretAuditRepository) List(_ context.Context, filter models.SecretRevealFilter) ([]models.SecretReveal, error) {
+	reveals := make([]models.SecretReveal, 0)
+
+	collect := func(v []byte) error {
+		var reveal models.SecretReveal
+		if err := json.Unmarshal(v, &reveal); err != nil {
+			return err
+		}
+		if filter.Match(reveal) {
+			reveals = append(reveals, reveal)
+		}
+		return nil
+	}
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketSecretReveals)
+		if filter.Key == "" {
+			return bucket.ForEach(func(_, v []byte) error { return collect(v) })
+		}
+
+		seek := compositeKey(filter.Key, "")
+		c := bucket.Cursor()
+		for k, v := c.Seek(seek); k != nil && bytes.HasPrefix(k, seek); k, v = c.Next() {
+			if err := collect(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("ErrBoltListReveals", zap.Error(err), zap.String("key", filter.Key))
+		return nil, err
+	}
+
+	sort.SliceStable(reveals, func(i, j int) bool {
+		return reveals[i].RevealedAt.After(reveals[j].RevealedAt)
+	})
+	if filter.Limit > 0 && len(reveals) > filter.Limit {
+		reveals = reveals[:filter.Limit]
+	}
+
+	return reveals, nil
+}
+
+func revealKey(reveal models.SecretReveal) []byte {
+	return compositeKey(reveal.Key, fmt.Sprintf("%020d", reveal.RevealedAt.UnixNano()), reveal.ID)
+}
@@ -0,0 +1,253 @@
+package boltdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"nbox/internal/application"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+	"nbox/internal/domain/models/operations"
+	"nbox/internal/usecases"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+var ErrSecretNotFound = errors.New("secret not found or has no value")
+
+type secretRecord struct {
+	Name      string    `json:"name"`
+	Value     string    `json:"value"`
+	Version   int64     `json:"version"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	// metadata kept as tags in Parameter Store
+	Key               string `json:"key,omitempty"`
+	Owner             string `json:"owner,omitempty"`
+	TypeValidatorName string `json:"typeValidatorName,omitempty"`
+}
+
+// boltSecretStore local replacement of Parameter Store. Values are kept in plain text
+// inside the bolt file, so it must only be used for development and CI.
+type boltSecretStore struct {
+	db     *bolt.DB
+	config *application.Config
+	logger *zap.Logger
+}
+
+func NewBoltSecretStore(db *bolt.DB, config *application.Config, logger *zap.Logger) domain.SecretAdapter {
+	return &boltSecretStore{db: db, config: config, logger: logger.Named("bolt_secret_store")}
+}
+
+func (s *boltSecretStore) Upsert(ctx context.Context, entries []models.Entry) operations.Results {
+	results := make(operations.Results, len(entries))
+
+	owner := "ghost"
+	if user, ok := application.UserFromContext(ctx); ok {
+		owner = user.Name
+	}
+
+	for _, entry := range entries {
+		var version int64
+
+		err := s.db.Update(func(tx *bolt.Tx) error {
+			bucket := tx.Bucket(bucketSecrets)
+			name := parameterName(entry.Key)
+
+			current := secretRecord{}
+			if raw := bucket.Get([]byte(name)); raw != nil {
+				if err := json.Unmarshal(raw, &current); err != nil {
+					return err
+				}
+			}
+
+			version = current.Version + 1
+			secret := secretRecord{
+				Name:      name,
+				Value:     entry.Value,
+				Version:   version,
+				UpdatedAt: time.Now().UTC(),
+
+				Key:               strings.TrimPrefix(entry.Key, "/"),
+				Owner:             owner,
+				TypeValidatorName: entry.TypeValidatorName,
+			}
+
+			// every version is kept, like the Parameter Store history
+			if err := putJSON(tx.Bucket(bucketSecretHistory), historyKey(name, version), secret); err != nil {
+				return err
+			}
+			return putJSON(bucket, []byte(name), secret)
+		})
+
+		if err != nil {
+			s.logger.Error("ErrSecureUpsert", zap.String("key", entry.Key), zap.Error(err))
+			results[entry.Key] = operations.Result{Key: entry.Key, Error: err}
+			continue
+		}
+
+		opType := operations.Updated
+		if version == 1 {
+			opType = operations.Created
+		}
+		results[entry.Key] = operations.Result{Key: entry.Key, Type: opType}
+	}
+
+	return results
+}
+
+func (s *boltSecretStore) RetrieveSecretValue(_ context.Context, key string) (*models.Entry, error) {
+	var secret *secretRecord
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		raw := tx.Bucket(bucketSecrets).Get([]byte(parameterName(key)))
+		if raw == nil {
+			return nil
+		}
+		secret = &secretRecord{}
+		return json.Unmarshal(raw, secret)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if secret == nil {
+		s.logger.Error("ErrParameterNotFound", zap.String("key", key))
+		return nil, ErrSecretNotFound
+	}
+
+	return &models.Entry{
+		Key:    key,
+		Value:  secret.Value,
+		Secure: true,
+	}, nil
+}
+
+// RetrieveSecretValueAt returns the last version written before the given time
+func (s *boltSecretStore) RetrieveSecretValueAt(_ context.Context, key string, at time.Time) (*models.Entry, error) {
+	var secret *secretRecord
+	name := parameterName(key)
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		seek := compositeKey(name, "")
+		c := tx.Bucket(bucketSecretHistory).Cursor()
+		for k, v := c.Seek(seek); k != nil && bytes.HasPrefix(k, seek); k, v = c.Next() {
+			var r secretRecord
+			if err := json.Unmarshal(v, &r); err != nil {
+				return err
+			}
+			if r.UpdatedAt.After(at) {
+				break
+			}
+			secret = &r
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if secret == nil {
+		s.logger.Error("ErrParameterNotFound", zap.String("key", key), zap.Time("at", at))
+		return nil, ErrSecretNotFound
+	}
+
+	return &models.Entry{
+		Key:    key,
+		Value:  secret.Value,
+		Secure: true,
+	}, nil
+}
+
+// ListSecrets secrets under the prefix with their current value and metadata
+func (s *boltSecretStore) ListSecrets(_ context.Context, prefix string) ([]models.SecretParameter, error) {
+	secrets := make([]models.SecretParameter, 0)
+	seek := []byte(parameterName(strings.Trim(prefix, "/")))
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucketSecrets).Cursor()
+		for k, v := c.Seek(seek); k != nil && bytes.HasPrefix(k, seek); k, v = c.Next() {
+			var r secretRecord
+			if err := json.Unmarshal(v, &r); err != nil {
+				return err
+			}
+			key := r.Key
+			if key == "" {
+				key = strings.TrimPrefix(r.Name, "/")
+			}
+			secrets = append(secrets, models.SecretParameter{
+				Name:              r.Name,
+				Key:               key,
+				TypeValidatorName: r.TypeValidatorName,
+				Owner:             r.Owner,
+				Value:             r.Value,
+				UpdatedAt:         r.UpdatedAt,
+			})
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return secrets, nil
+}
+
+// Delete removes the secrets with their whole history, like DeleteParameters. A missing secret is
+// reported as deleted.
+func (s *boltSecretStore) Delete(_ context.Context, names []string) operations.Results {
+	results := make(operations.Results, len(names))
+
+	for _, name := range names {
+		err := s.db.Update(func(tx *bolt.Tx) error {
+			rooted := parameterName(name)
+			if err := tx.Bucket(bucketSecrets).Delete([]byte(rooted)); err != nil {
+				return err
+			}
+
+			history := tx.Bucket(bucketSecretHistory)
+			seek := compositeKey(rooted, "")
+			c := history.Cursor()
+			for k, _ := c.Seek(seek); k != nil && bytes.HasPrefix(k, seek); k, _ = c.Seek(seek) {
+				if err := history.Delete(k); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+
+		if err != nil {
+			s.logger.Error("ErrSecureDelete", zap.String("name", name), zap.Error(err))
+			results[name] = operations.Result{Key: name, Type: operations.Error, Error: err}
+			continue
+		}
+		results[name] = operations.Result{Key: name, Type: operations.Deleted}
+	}
+
+	return results
+}
+
+// Reference same references as Parameter Store, the entries can move to AWS unchanged
+func (s *boltSecretStore) Reference(key string) string {
+	return usecases.ParameterReference(s.config, key)
+}
+
+// historyKey zero padded so versions sort numerically
+func historyKey(name string, version int64) []byte {
+	return compositeKey(name, fmt.Sprintf("%020d", version))
+}
+
+// parameterName same naming rule as Parameter Store: always rooted at "/"
+func parameterName(key string) string {
+	if !strings.HasPrefix(key, "/") {
+		return "/" + key
+	}
+	return key
+}
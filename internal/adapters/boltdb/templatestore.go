@@ -0,0 +1,140 @@
+package boltdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+	"path"
+
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+var ErrTemplateNotFound = errors.New("template not found")
+
+type boxRecord struct {
+	Service  string          `json:"service"`
+	Stage    string          `json:"stage"`
+	Template models.Template `json:"template"`
+}
+
+// boltTemplateStore keeps the template body (S3 object) and its box metadata (box table)
+type boltTemplateStore struct {
+	db     *bolt.DB
+	logger *zap.Logger
+}
+
+func NewBoltTemplateStore(db *bolt.DB, logger *zap.Logger) domain.TemplateAdapter {
+	return &boltTemplateStore{db: db, logger: logger.Named("bolt_template_store")}
+}
+
+func (b *boltTemplateStore) UpsertBox(_ context.Context, box *models.Box) []string {
+	result := make([]string, 0)
+
+	for stageName, stage := range box.Stage {
+		name := stage.Template.Name
+		templatePath := path.Join(box.Service, stageName, stage.Template.Name)
+
+		stage.Template.Name = templatePath
+		box.Stage[stageName] = stage
+
+		body, err := indentTemplate(stage.Template.Value)
+		if err != nil {
+			b.logger.Error("ErrStoreTemplate", zap.String("path", templatePath), zap.Error(err))
+			continue
+		}
+
+		err = b.db.Update(func(tx *bolt.Tx) error {
+			if err := tx.Bucket(bucketTemplates).Put([]byte(templatePath), body); err != nil {
+				return err
+			}
+			return putJSON(tx.Bucket(bucketBoxes), compositeKey(box.Service, stageName), boxRecord{
+				Service: box.Service,
+				Stage:   stageName,
+				Template: models.Template{
+					Name:  templatePath,
+					Value: name,
+				},
+			})
+		})
+
+		if err != nil {
+			b.logger.Error("ErrDbStoreTemplate", zap.String("path", templatePath), zap.Error(err))
+			continue
+		}
+
+		result = append(result, templatePath)
+	}
+
+	return result
+}
+
+func (b *boltTemplateStore) BoxExists(ctx context.Context, service string, stage string, template string) (bool, error) {
+	_, err := b.RetrieveBox(ctx, service, stage, template)
+	return err == nil, err
+}
+
+func (b *boltTemplateStore) RetrieveBox(_ context.Context, service string, stage string, template string) ([]byte, error) {
+	var body []byte
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		raw := tx.Bucket(bucketTemplates).Get([]byte(path.Join(service, stage, template)))
+		if raw == nil {
+			return ErrTemplateNotFound
+		}
+		body = bytes.Clone(raw)
+		return nil
+	})
+
+	return body, err
+}
+
+func (b *boltTemplateStore) List(_ context.Context) ([]models.Box, error) {
+	boxes := map[string]models.Box{}
+	results := make([]models.Box, 0)
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketBoxes).ForEach(func(_, v []byte) error {
+			var record boxRecord
+			if err := json.Unmarshal(v, &record); err != nil {
+				return nil
+			}
+
+			if _, ok := boxes[record.Service]; !ok {
+				boxes[record.Service] = models.Box{Service: record.Service, Stage: map[string]models.Stage{}}
+			}
+			boxes[record.Service].Stage[record.Stage] = models.Stage{Template: record.Template}
+			return nil
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, box := range boxes {
+		results = append(results, box)
+	}
+
+	return results, nil
+}
+
+// indentTemplate same transformation applied before uploading to S3
+func indentTemplate(value string) ([]byte, error) {
+	var out bytes.Buffer
+
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Indent(&out, decoded, "", "  "); err != nil {
+		return nil, err
+	}
+
+	return out.Bytes(), nil
+}
@@ -0,0 +1,116 @@
+package boltdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+type boltTypeValidatorBackend struct {
+	db     *bolt.DB
+	logger *zap.Logger
+	// schemas compiled schemas of the retrieved validators
+	schemas models.SchemaCache
+}
+
+func NewBoltTypeValidatorBackend(db *bolt.DB, logger *zap.Logger) domain.TypeValidatorAdapter {
+	return &boltTypeValidatorBackend{db: db, logger: logger.Named("bolt_type_validator_backend")}
+}
+
+// Upsert creates or updates a type validator
+func (t *boltTypeValidatorBackend) Upsert(_ context.Context, validator models.TypeValidator) error {
+	if _, isBuiltIn := models.BuiltInValidators[validator.Name]; isBuiltIn {
+		return errors.New("cannot modify built-in type validator")
+	}
+
+	if err := validator.Check(); err != nil {
+		return err
+	}
+
+	err := t.db.Update(func(tx *bolt.Tx) error {
+		return putJSON(tx.Bucket(bucketTypeValidators), []byte(validator.Name), validator)
+	})
+	if err != nil {
+		t.logger.Error("ErrBoltPut", zap.Error(err))
+		return err
+	}
+
+	t.schemas.Drop(validator.Name)
+	return nil
+}
+
+// Retrieve gets a type validator by name
+func (t *boltTypeValidatorBackend) Retrieve(_ context.Context, name string) (*models.TypeValidator, error) {
+	if validator, exists := models.BuiltInValidators[name]; exists {
+		return &validator, nil
+	}
+
+	var validator *models.TypeValidator
+	err := t.db.View(func(tx *bolt.Tx) error {
+		raw := tx.Bucket(bucketTypeValidators).Get([]byte(name))
+		if raw == nil {
+			return nil
+		}
+		validator = &models.TypeValidator{}
+		return json.Unmarshal(raw, validator)
+	})
+
+	if err != nil {
+		t.logger.Error("ErrBoltGet", zap.Error(err))
+		return nil, err
+	}
+
+	if validator != nil {
+		t.schemas.Compile(validator)
+	}
+	return validator, nil
+}
+
+// List returns all type validators (built-in + custom)
+func (t *boltTypeValidatorBackend) List(_ context.Context) ([]models.TypeValidator, error) {
+	validators := make([]models.TypeValidator, 0)
+
+	for _, validator := range models.BuiltInValidators {
+		validators = append(validators, validator)
+	}
+
+	err := t.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketTypeValidators).ForEach(func(_, v []byte) error {
+			var validator models.TypeValidator
+			if err := json.Unmarshal(v, &validator); err != nil {
+				return err
+			}
+			validators = append(validators, validator)
+			return nil
+		})
+	})
+
+	if err != nil {
+		t.logger.Error("ErrBoltList", zap.Error(err))
+		return nil, err
+	}
+
+	return validators, nil
+}
+
+// Delete removes a custom type validator
+func (t *boltTypeValidatorBackend) Delete(_ context.Context, name string) error {
+	if _, isBuiltIn := models.BuiltInValidators[name]; isBuiltIn {
+		return errors.New("cannot delete built-in type validator")
+	}
+
+	err := t.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketTypeValidators).Delete([]byte(name))
+	})
+	if err != nil {
+		return err
+	}
+
+	t.schemas.Drop(name)
+	return nil
+}
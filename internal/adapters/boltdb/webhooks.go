@@ -0,0 +1,109 @@
+package boltdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"nbox/internal/domain"
+
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+type boltWebhookRepository struct {
+	db     *bolt.DB
+	logger *zap.Logger
+}
+
+func NewBoltWebhookRepository(db *bolt.DB, logger *zap.Logger) domain.WebhookRepository {
+	return &boltWebhookRepository{db: db, logger: logger.Named("bolt_webhook_repository")}
+}
+
+func (w *boltWebhookRepository) FindByEventType(ctx context.Context, eventType domain.EventType) ([]domain.Webhook, error) {
+	all, err := w.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks := make([]domain.Webhook, 0)
+	for _, webhook := range all {
+		if webhook.Subscribed(eventType) {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	return webhooks, nil
+}
+
+func (w *boltWebhookRepository) List(_ context.Context) ([]domain.Webhook, error) {
+	webhooks := make([]domain.Webhook, 0)
+
+	err := w.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketWebhooks).ForEach(func(_, v []byte) error {
+			var webhook domain.Webhook
+			if err := json.Unmarshal(v, &webhook); err != nil {
+				return err
+			}
+			webhooks = append(webhooks, webhook)
+			return nil
+		})
+	})
+
+	if err != nil {
+		w.logger.Error("ErrBoltList", zap.Error(err))
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+func (w *boltWebhookRepository) Create(_ context.Context, webhook domain.Webhook) error {
+	return w.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketWebhooks)
+		if bucket.Get([]byte(webhook.ID)) != nil {
+			return fmt.Errorf("%w: duplicated id %s", domain.ErrInvalidWebhook, webhook.ID)
+		}
+		return putJSON(bucket, []byte(webhook.ID), webhook)
+	})
+}
+
+func (w *boltWebhookRepository) Delete(_ context.Context, webhookID string) error {
+	return w.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketWebhooks)
+		if bucket.Get([]byte(webhookID)) == nil {
+			return fmt.Errorf("%w: %s", domain.ErrWebhookNotFound, webhookID)
+		}
+		return bucket.Delete([]byte(webhookID))
+	})
+}
+
+func (w *boltWebhookRepository) SaveFailedDelivery(_ context.Context, delivery domain.WebhookDelivery) error {
+	return w.db.Update(func(tx *bolt.Tx) error {
+		return putJSON(tx.Bucket(bucketDeliveries), compositeKey(delivery.WebhookID, delivery.ID), delivery)
+	})
+}
+
+func (w *boltWebhookRepository) ListFailedDeliveries(_ context.Context, webhookID string) ([]domain.WebhookDelivery, error) {
+	deliveries := make([]domain.WebhookDelivery, 0)
+
+	err := w.db.View(func(tx *bolt.Tx) error {
+		seek := compositeKey(webhookID, "")
+		c := tx.Bucket(bucketDeliveries).Cursor()
+		// newest first, like the delivery table query
+		for k, v := c.Seek(seek); k != nil && bytes.HasPrefix(k, seek); k, v = c.Next() {
+			var delivery domain.WebhookDelivery
+			if err := json.Unmarshal(v, &delivery); err != nil {
+				return err
+			}
+			deliveries = append([]domain.WebhookDelivery{delivery}, deliveries...)
+		}
+		return nil
+	})
+
+	if err != nil {
+		w.logger.Error("ErrBoltDeliveries", zap.Error(err), zap.String("webhookId", webhookID))
+		return nil, err
+	}
+
+	return deliveries, nil
+}
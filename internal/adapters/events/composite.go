@@ -0,0 +1,169 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"nbox/internal/application"
+	"nbox/internal/domain"
+	"sync"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// Sink publisher that receives a copy of the events. An empty Events list receives every type.
+type Sink struct {
+	Name      string
+	Events    []domain.EventType
+	Publisher domain.EventPublisher
+}
+
+// Accepts verifica si el sink recibe el tipo de evento
+func (s Sink) Accepts(eventType domain.EventType) bool {
+	if len(s.Events) == 0 {
+		return true
+	}
+	for _, e := range s.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+type sinkWorker struct {
+	sink  Sink
+	queue chan domain.Event[json.RawMessage]
+}
+
+// CompositePublisher fans out every event to the configured sinks. Each sink has its own buffered
+// queue and goroutine: a slow or failing sink only drops its own events once its buffer is full
+// and never blocks the others nor the caller.
+// The queues are never closed: producers may still publish during shutdown, so stop signals done
+// and the workers drain what is left before exiting; later events are dropped.
+type CompositePublisher struct {
+	workers  []*sinkWorker
+	wg       sync.WaitGroup
+	done     chan struct{}
+	stopOnce sync.Once
+	logger   *zap.Logger
+}
+
+type CompositeParams struct {
+	fx.In
+	Lifecycle fx.Lifecycle
+	Config    *application.Config
+	Sinks     []Sink `group:"sinks"`
+	Logger    *zap.Logger
+}
+
+func NewCompositePublisher(params CompositeParams) domain.EventPublisher {
+	publisher := newCompositePublisher(params.Sinks, params.Config.EventSinkBuffer, params.Logger)
+
+	params.Lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			publisher.start()
+			return nil
+		},
+		OnStop: publisher.stop,
+	})
+
+	return publisher
+}
+
+func newCompositePublisher(sinks []Sink, buffer int, logger *zap.Logger) *CompositePublisher {
+	if buffer < 1 {
+		buffer = 1
+	}
+
+	publisher := &CompositePublisher{done: make(chan struct{}), logger: logger.Named("event_publisher")}
+	for _, sink := range sinks {
+		publisher.workers = append(publisher.workers, &sinkWorker{
+			sink:  sink,
+			queue: make(chan domain.Event[json.RawMessage], buffer),
+		})
+	}
+	return publisher
+}
+
+func (c *CompositePublisher) start() {
+	for _, worker := range c.workers {
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			for {
+				select {
+				case event := <-worker.queue:
+					c.deliver(worker, event)
+				case <-c.done:
+					for {
+						select {
+						case event := <-worker.queue:
+							c.deliver(worker, event)
+						default:
+							return
+						}
+					}
+				}
+			}
+		}()
+		c.logger.Info("Event sink started", zap.String("sink", worker.sink.Name), zap.Any("events", worker.sink.Events))
+	}
+}
+
+func (c *CompositePublisher) deliver(worker *sinkWorker, event domain.Event[json.RawMessage]) {
+	if err := worker.sink.Publisher.Publish(context.Background(), event); err != nil {
+		c.logger.Error("ErrSinkPublish",
+			zap.Error(err),
+			zap.String("sink", worker.sink.Name),
+			zap.String("eventType", string(event.Type)),
+		)
+	}
+}
+
+// stop signals the workers and waits until the pending events are published or ctx expires
+func (c *CompositePublisher) stop(ctx context.Context) error {
+	c.stopOnce.Do(func() { close(c.done) })
+
+	done := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *CompositePublisher) Publish(_ context.Context, event domain.Event[json.RawMessage]) error {
+	select {
+	case <-c.done:
+		c.logger.Warn("EventPublisherStopped, event dropped",
+			zap.String("eventType", string(event.Type)),
+			zap.String("transactionId", event.TransactionId),
+		)
+		return nil
+	default:
+	}
+
+	for _, worker := range c.workers {
+		if !worker.sink.Accepts(event.Type) {
+			continue
+		}
+
+		select {
+		case worker.queue <- event:
+		default:
+			c.logger.Warn("EventSinkFull, event dropped",
+				zap.String("sink", worker.sink.Name),
+				zap.String("eventType", string(event.Type)),
+				zap.String("transactionId", event.TransactionId),
+			)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"nbox/internal/domain"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type recordingPublisher struct {
+	mu     sync.Mutex
+	events []domain.Event[json.RawMessage]
+	block  chan struct{}
+	err    error
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, event domain.Event[json.RawMessage]) error {
+	if p.block != nil {
+		<-p.block
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, event)
+	return p.err
+}
+
+func (p *recordingPublisher) types() []domain.EventType {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var types []domain.EventType
+	for _, e := range p.events {
+		types = append(types, e.Type)
+	}
+	return types
+}
+
+func event(eventType domain.EventType) domain.Event[json.RawMessage] {
+	return domain.Event[json.RawMessage]{Type: eventType, Payload: json.RawMessage(`{}`)}
+}
+
+func TestCompositePublisher_FiltersByEventType(t *testing.T) {
+	all := &recordingPublisher{}
+	deletes := &recordingPublisher{}
+
+	publisher := newCompositePublisher([]Sink{
+		{Name: "all", Publisher: all},
+		{Name: "deletes", Events: []domain.EventType{domain.EventEntryDeleted}, Publisher: deletes},
+	}, 10, zap.NewNop())
+	publisher.start()
+
+	require.NoError(t, publisher.Publish(context.Background(), event(domain.EventEntryActions)))
+	require.NoError(t, publisher.Publish(context.Background(), event(domain.EventEntryDeleted)))
+	require.NoError(t, publisher.stop(context.Background()))
+
+	assert.Equal(t, []domain.EventType{domain.EventEntryActions, domain.EventEntryDeleted}, all.types())
+	assert.Equal(t, []domain.EventType{domain.EventEntryDeleted}, deletes.types())
+}
+
+func TestCompositePublisher_SlowSinkDoesNotBlockOthers(t *testing.T) {
+	slow := &recordingPublisher{block: make(chan struct{})}
+	fast := &recordingPublisher{}
+
+	publisher := newCompositePublisher([]Sink{
+		{Name: "slow", Publisher: slow},
+		{Name: "fast", Publisher: fast},
+	}, 1, zap.NewNop())
+	publisher.start()
+
+	done := make(chan struct{})
+	go func() {
+		// the slow sink holds one event and buffers one more, the rest are dropped for it
+		for range 5 {
+			_ = publisher.Publish(context.Background(), event(domain.EventEntryActions))
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Publish blocked on the slow sink")
+	}
+
+	assert.Eventually(t, func() bool { return len(fast.types()) > 0 }, time.Second, 5*time.Millisecond)
+
+	close(slow.block)
+	require.NoError(t, publisher.stop(context.Background()))
+	assert.LessOrEqual(t, len(slow.types()), 2)
+}
+
+func TestCompositePublisher_FailingSinkKeepsReceiving(t *testing.T) {
+	failing := &recordingPublisher{err: errors.New("sink down")}
+
+	publisher := newCompositePublisher([]Sink{{Name: "failing", Publisher: failing}}, 10, zap.NewNop())
+	publisher.start()
+
+	require.NoError(t, publisher.Publish(context.Background(), event(domain.EventTemplateCreated)))
+	require.NoError(t, publisher.Publish(context.Background(), event(domain.EventTemplateUpdated)))
+	require.NoError(t, publisher.stop(context.Background()))
+
+	assert.Len(t, failing.types(), 2)
+}
+
+func TestJSONLinesPublisher_Publish(t *testing.T) {
+	var buffer bytes.Buffer
+	publisher := NewJSONLinesPublisher(&buffer)
+
+	require.NoError(t, publisher.Publish(context.Background(), event(domain.EventEntryActions)))
+	require.NoError(t, publisher.Publish(context.Background(), event(domain.EventEntryDeleted)))
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var decoded domain.Event[json.RawMessage]
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &decoded))
+	assert.Equal(t, domain.EventEntryDeleted, decoded.Type)
+}
+
+func TestCompositePublisher_PublishAfterStop(t *testing.T) {
+	sink := &recordingPublisher{}
+
+	publisher := newCompositePublisher([]Sink{{Name: "sink", Publisher: sink}}, 10, zap.NewNop())
+	publisher.start()
+	require.NoError(t, publisher.stop(context.Background()))
+
+	// requests still running during shutdown publish after the hook, which must not panic
+	assert.NotPanics(t, func() {
+		require.NoError(t, publisher.Publish(context.Background(), event(domain.EventEntryActions)))
+	})
+	require.NoError(t, publisher.stop(context.Background()))
+	assert.Empty(t, sink.types())
+}
@@ -0,0 +1,57 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"nbox/internal/domain"
+	"os"
+	"sync"
+
+	"go.uber.org/fx"
+)
+
+// JSONLinesPublisher escribe cada evento como una línea JSON
+type JSONLinesPublisher struct {
+	mu     sync.Mutex
+	writer io.Writer
+}
+
+func NewJSONLinesPublisher(writer io.Writer) *JSONLinesPublisher {
+	return &JSONLinesPublisher{writer: writer}
+}
+
+// NewStdoutPublisher eventos por la salida estándar, útil con colectores de logs
+func NewStdoutPublisher() domain.EventPublisher {
+	return NewJSONLinesPublisher(os.Stdout)
+}
+
+// NewAuditFilePublisher appends the events to the file at path, created when missing
+func NewAuditFilePublisher(lc fx.Lifecycle, path string) (domain.EventPublisher, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600) // #nosec G304 -- path from config
+	if err != nil {
+		return nil, err
+	}
+
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			return file.Close()
+		},
+	})
+
+	return NewJSONLinesPublisher(file), nil
+}
+
+func (p *JSONLinesPublisher) Publish(_ context.Context, event domain.Event[json.RawMessage]) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, err = p.writer.Write(line)
+	return err
+}
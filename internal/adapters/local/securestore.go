@@ -0,0 +1,310 @@
+package local
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"nbox/internal/application"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+	"nbox/internal/domain/models/operations"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/nacl/secretbox"
+)
+
+var (
+	ErrSecretNotFound = errors.New("secret not found or has no value")
+	ErrInvalidKey     = errors.New("NBOX_LOCAL_SECRET_KEY must be a base64 encoded 32 bytes key")
+	ErrCorruptSecret  = errors.New("secret can't be opened with NBOX_LOCAL_SECRET_KEY")
+)
+
+const nonceSize = 24
+
+type sealedVersion struct {
+	Version   int64     `json:"version"`
+	Sealed    string    `json:"sealed"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type secretFile struct {
+	Key               string          `json:"key"`
+	Owner             string          `json:"owner,omitempty"`
+	TypeValidatorName string          `json:"typeValidatorName,omitempty"`
+	Versions          []sealedVersion `json:"versions"`
+}
+
+// secretStore secrets in a local JSON file, every version sealed with NaCl secretbox
+// (XSalsa20-Poly1305) under NBOX_LOCAL_SECRET_KEY. Only names and metadata are readable
+// without the key. The file is rewritten atomically on each change.
+type secretStore struct {
+	mu      sync.RWMutex
+	path    string
+	key     [32]byte
+	secrets map[string]*secretFile
+	logger  *zap.Logger
+}
+
+func NewSecretStore(config *application.Config, logger *zap.Logger) (domain.SecretAdapter, error) {
+	raw, err := base64.StdEncoding.DecodeString(config.LocalSecretKey)
+	if err != nil || len(raw) != 32 {
+		return nil, ErrInvalidKey
+	}
+
+	s := &secretStore{
+		path:    config.LocalSecretPath,
+		secrets: make(map[string]*secretFile),
+		logger:  logger.Named("local_secret_store"),
+	}
+	copy(s.key[:], raw)
+
+	content, err := os.ReadFile(s.path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return s, nil
+	case err != nil:
+		return nil, err
+	}
+	if err := json.Unmarshal(content, &s.secrets); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", s.path, err)
+	}
+	return s, nil
+}
+
+func (s *secretStore) Upsert(ctx context.Context, entries []models.Entry) operations.Results {
+	results := make(operations.Results, len(entries))
+
+	owner := "ghost"
+	if user, ok := application.UserFromContext(ctx); ok {
+		owner = user.Name
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, entry := range entries {
+		name := secretName(entry.Key)
+		sealed, err := s.seal(entry.Value)
+		if err != nil {
+			s.logger.Error("ErrSecureUpsert", zap.String("key", entry.Key), zap.Error(err))
+			results[entry.Key] = operations.Result{Key: entry.Key, Error: err}
+			continue
+		}
+
+		current, exists := s.secrets[name]
+		next := &secretFile{
+			Key:               strings.TrimPrefix(entry.Key, "/"),
+			Owner:             owner,
+			TypeValidatorName: entry.TypeValidatorName,
+		}
+		var version int64 = 1
+		if exists {
+			next.Versions = append(next.Versions, current.Versions...)
+			version = current.Versions[len(current.Versions)-1].Version + 1
+		}
+		next.Versions = append(next.Versions, sealedVersion{Version: version, Sealed: sealed, CreatedAt: time.Now().UTC()})
+
+		s.secrets[name] = next
+		if err := s.persist(); err != nil {
+			if exists {
+				s.secrets[name] = current
+			} else {
+				delete(s.secrets, name)
+			}
+			s.logger.Error("ErrSecureUpsert", zap.String("key", entry.Key), zap.Error(err))
+			results[entry.Key] = operations.Result{Key: entry.Key, Error: err}
+			continue
+		}
+
+		opType := operations.Updated
+		if !exists {
+			opType = operations.Created
+		}
+		results[entry.Key] = operations.Result{Key: entry.Key, Type: opType}
+	}
+
+	return results
+}
+
+func (s *secretStore) RetrieveSecretValue(_ context.Context, key string) (*models.Entry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	secret, ok := s.secrets[secretName(key)]
+	if !ok || len(secret.Versions) == 0 {
+		s.logger.Error("ErrParameterNotFound", zap.String("key", key))
+		return nil, ErrSecretNotFound
+	}
+
+	return s.open(key, secret.Versions[len(secret.Versions)-1])
+}
+
+// RetrieveSecretValueAt returns the last version written before the given time
+func (s *secretStore) RetrieveSecretValueAt(_ context.Context, key string, at time.Time) (*models.Entry, error) {
+	// tracking timestamps have second precision and are taken after the secret is written
+	limit := at.Truncate(time.Second).Add(time.Second)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var found *sealedVersion
+	if secret, ok := s.secrets[secretName(key)]; ok {
+		for i, version := range secret.Versions {
+			if version.CreatedAt.After(limit) {
+				break
+			}
+			found = &secret.Versions[i]
+		}
+	}
+
+	if found == nil {
+		s.logger.Error("ErrParameterNotFound", zap.String("key", key), zap.Time("at", at))
+		return nil, ErrSecretNotFound
+	}
+
+	return s.open(key, *found)
+}
+
+// ListSecrets secrets under the prefix with their current value and metadata
+func (s *secretStore) ListSecrets(_ context.Context, prefix string) ([]models.SecretParameter, error) {
+	seek := secretName(strings.Trim(prefix, "/"))
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	secrets := make([]models.SecretParameter, 0)
+	for name, secret := range s.secrets {
+		if !strings.HasPrefix(name, seek) || len(secret.Versions) == 0 {
+			continue
+		}
+		latest := secret.Versions[len(secret.Versions)-1]
+		entry, err := s.open(name, latest)
+		if err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, models.SecretParameter{
+			Name:              name,
+			Key:               secret.Key,
+			TypeValidatorName: secret.TypeValidatorName,
+			Owner:             secret.Owner,
+			Value:             entry.Value,
+			UpdatedAt:         latest.CreatedAt,
+		})
+	}
+
+	sort.Slice(secrets, func(i, j int) bool { return secrets[i].Name < secrets[j].Name })
+	return secrets, nil
+}
+
+// Delete removes the secrets with their whole history. A missing secret is reported as deleted.
+func (s *secretStore) Delete(_ context.Context, names []string) operations.Results {
+	results := make(operations.Results, len(names))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := make(map[string]*secretFile)
+	for _, name := range names {
+		rooted := secretName(name)
+		if secret, ok := s.secrets[rooted]; ok {
+			removed[rooted] = secret
+			delete(s.secrets, rooted)
+		}
+	}
+
+	err := s.persist()
+	if err != nil {
+		for name, secret := range removed {
+			s.secrets[name] = secret
+		}
+		s.logger.Error("ErrSecureDelete", zap.Strings("names", names), zap.Error(err))
+	}
+
+	for _, name := range names {
+		if err != nil {
+			results[name] = operations.Result{Key: name, Type: operations.Error, Error: err}
+			continue
+		}
+		results[name] = operations.Result{Key: name, Type: operations.Deleted}
+	}
+	return results
+}
+
+// Reference local:production/app/password
+func (s *secretStore) Reference(key string) string {
+	return models.ReferenceLocal + strings.TrimPrefix(key, "/")
+}
+
+// seal nonce + box, base64 encoded
+func (s *secretStore) seal(value string) (string, error) {
+	var nonce [nonceSize]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return "", err
+	}
+	box := secretbox.Seal(nonce[:], []byte(value), &nonce, &s.key)
+	return base64.StdEncoding.EncodeToString(box), nil
+}
+
+func (s *secretStore) open(key string, version sealedVersion) (*models.Entry, error) {
+	box, err := base64.StdEncoding.DecodeString(version.Sealed)
+	if err != nil || len(box) < nonceSize {
+		return nil, ErrCorruptSecret
+	}
+
+	var nonce [nonceSize]byte
+	copy(nonce[:], box[:nonceSize])
+	value, ok := secretbox.Open(nil, box[nonceSize:], &nonce, &s.key)
+	if !ok {
+		return nil, ErrCorruptSecret
+	}
+
+	return &models.Entry{Key: key, Value: string(value), Secure: true}, nil
+}
+
+// persist writes a temporary file next to the store and renames it over the previous one
+func (s *secretStore) persist() error {
+	content, err := json.MarshalIndent(s.secrets, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// secretName rooted at "/" like Parameter Store names
+func secretName(key string) string {
+	if !strings.HasPrefix(key, "/") {
+		return "/" + key
+	}
+	return key
+}
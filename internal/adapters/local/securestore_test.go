@@ -0,0 +1,110 @@
+package local
+
+import (
+	"context"
+	"encoding/base64"
+	"nbox/internal/application"
+	"nbox/internal/domain/models"
+	"nbox/internal/domain/models/operations"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func testConfig(t *testing.T) *application.Config {
+	t.Helper()
+	return &application.Config{
+		LocalSecretPath: filepath.Join(t.TempDir(), "secrets.json"),
+		LocalSecretKey:  base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32))),
+	}
+}
+
+func TestSecretStore_UpsertAndReopen(t *testing.T) {
+	config := testConfig(t)
+	ctx := context.Background()
+
+	store, err := NewSecretStore(config, zap.NewNop())
+	require.NoError(t, err)
+
+	results := store.Upsert(ctx, []models.Entry{{Key: "production/app/password", Value: "s3cr3t", Secure: true, TypeValidatorName: "password"}})
+	assert.Equal(t, operations.Created, results["production/app/password"].Type)
+	results = store.Upsert(ctx, []models.Entry{{Key: "production/app/password", Value: "rotated", Secure: true}})
+	assert.Equal(t, operations.Updated, results["production/app/password"].Type)
+
+	content, err := os.ReadFile(config.LocalSecretPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "s3cr3t")
+	assert.NotContains(t, string(content), "rotated")
+
+	info, err := os.Stat(config.LocalSecretPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	reopened, err := NewSecretStore(config, zap.NewNop())
+	require.NoError(t, err)
+	entry, err := reopened.RetrieveSecretValue(ctx, "/production/app/password")
+	require.NoError(t, err)
+	assert.Equal(t, "rotated", entry.Value)
+
+	secrets, err := reopened.ListSecrets(ctx, "production/")
+	require.NoError(t, err)
+	require.Len(t, secrets, 1)
+	assert.Equal(t, "/production/app/password", secrets[0].Name)
+	assert.Equal(t, "production/app/password", secrets[0].Key)
+	assert.Equal(t, "rotated", secrets[0].Value)
+	assert.Empty(t, secrets[0].TypeValidatorName)
+
+	assert.Equal(t, "local:production/app/password", reopened.Reference("production/app/password"))
+}
+
+func TestSecretStore_WrongKey(t *testing.T) {
+	config := testConfig(t)
+	ctx := context.Background()
+
+	store, err := NewSecretStore(config, zap.NewNop())
+	require.NoError(t, err)
+	store.Upsert(ctx, []models.Entry{{Key: "production/app/password", Value: "s3cr3t", Secure: true}})
+
+	config.LocalSecretKey = base64.StdEncoding.EncodeToString([]byte(strings.Repeat("x", 32)))
+	other, err := NewSecretStore(config, zap.NewNop())
+	require.NoError(t, err)
+	_, err = other.RetrieveSecretValue(ctx, "/production/app/password")
+	assert.ErrorIs(t, err, ErrCorruptSecret)
+
+	config.LocalSecretKey = "short"
+	_, err = NewSecretStore(config, zap.NewNop())
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestSecretStore_RetrieveAtAndDelete(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewSecretStore(testConfig(t), zap.NewNop())
+	require.NoError(t, err)
+	s := store.(*secretStore)
+
+	store.Upsert(ctx, []models.Entry{{Key: "production/app/password", Value: "v1", Secure: true}})
+	store.Upsert(ctx, []models.Entry{{Key: "production/app/password", Value: "v2", Secure: true}})
+	first := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.secrets["/production/app/password"].Versions[0].CreatedAt = first
+	s.secrets["/production/app/password"].Versions[1].CreatedAt = first.Add(time.Hour)
+
+	entry, err := store.RetrieveSecretValueAt(ctx, "/production/app/password", first.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, "v1", entry.Value)
+
+	_, err = store.RetrieveSecretValueAt(ctx, "/production/app/password", first.Add(-time.Hour))
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+
+	results := store.Delete(ctx, []string{"/production/app/password", "/production/missing"})
+	assert.Equal(t, operations.Deleted, results["/production/app/password"].Type)
+	assert.Equal(t, operations.Deleted, results["/production/missing"].Type)
+
+	_, err = store.RetrieveSecretValue(ctx, "/production/app/password")
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+}
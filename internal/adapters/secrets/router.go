@@ -0,0 +1,201 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"nbox/internal/application"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+	"nbox/internal/domain/models/operations"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var ErrBackendNotConfigured = errors.New("secret backend not configured")
+
+// Backend secret adapter that NBOX_SECRET_BACKENDS can route prefixes to. Its names are rooted
+// keys ("/production/app/password") and Reference returns the value stored in the secure entry.
+type Backend struct {
+	Name    string
+	Adapter domain.SecretAdapter
+}
+
+type RouterParams struct {
+	fx.In
+	// Default secret store of the storage backend (Parameter Store or bolt), backend "ssm"
+	Default  domain.SecretAdapter
+	Backends []Backend `group:"secret_backends"`
+	Config   *application.Config
+	Logger   *zap.Logger
+}
+
+type route struct {
+	prefix  string
+	backend string
+}
+
+// secretRouter writes each secure entry in the backend of its longest matching prefix. Reads and
+// deletes go to the backend named by the reference of the entry, so moving a prefix to another
+// backend does not break the entries already written.
+type secretRouter struct {
+	backends map[string]domain.SecretAdapter
+	routes   []route
+	logger   *zap.Logger
+}
+
+func NewSecretRouter(params RouterParams) (domain.SecretAdapter, error) {
+	if len(params.Config.SecretRoutes) == 0 {
+		return params.Default, nil
+	}
+
+	backends := map[string]domain.SecretAdapter{models.SecretBackendSSM: params.Default}
+	for _, backend := range params.Backends {
+		backends[backend.Name] = backend.Adapter
+	}
+
+	routes := make([]route, 0, len(params.Config.SecretRoutes))
+	for _, r := range params.Config.SecretRoutes {
+		if _, ok := backends[r.Backend]; !ok {
+			return nil, fmt.Errorf("%w: %q for prefix %q (NBOX_SECRET_BACKENDS)", ErrBackendNotConfigured, r.Backend, r.Prefix)
+		}
+		routes = append(routes, route{prefix: strings.TrimPrefix(r.Prefix, "/"), backend: r.Backend})
+	}
+	// longest prefix first, the first match wins
+	sort.SliceStable(routes, func(i, j int) bool { return len(routes[i].prefix) > len(routes[j].prefix) })
+
+	return &secretRouter{backends: backends, routes: routes, logger: params.Logger.Named("secret_router")}, nil
+}
+
+// backendFor backend that stores the key, ssm when no prefix matches
+func (r *secretRouter) backendFor(key string) string {
+	key = strings.TrimPrefix(key, "/")
+	for _, route := range r.routes {
+		if strings.HasPrefix(key, route.prefix) {
+			return route.backend
+		}
+	}
+	return models.SecretBackendSSM
+}
+
+// resolve adapter and name for a name given by the use cases: a parameter name for ssm, the
+// reference of the secure entry for the other backends
+func (r *secretRouter) resolve(name string) (domain.SecretAdapter, string, error) {
+	backend, rooted, ok := models.ParseSecretReference(name)
+	if !ok || backend == models.SecretBackendSSM {
+		return r.backends[models.SecretBackendSSM], name, nil
+	}
+
+	adapter, found := r.backends[backend]
+	if !found {
+		return nil, "", fmt.Errorf("%w: %q owns '%s'", ErrBackendNotConfigured, backend, name)
+	}
+	return adapter, rooted, nil
+}
+
+func (r *secretRouter) Upsert(ctx context.Context, entries []models.Entry) operations.Results {
+	grouped := make(map[string][]models.Entry)
+	for _, entry := range entries {
+		backend := r.backendFor(entry.Key)
+		grouped[backend] = append(grouped[backend], entry)
+	}
+
+	results := make(operations.Results, len(entries))
+	for backend, group := range grouped {
+		for key, result := range r.backends[backend].Upsert(ctx, group) {
+			results[key] = result
+		}
+	}
+	return results
+}
+
+func (r *secretRouter) RetrieveSecretValue(ctx context.Context, key string) (*models.Entry, error) {
+	adapter, name, err := r.resolve(key)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := adapter.RetrieveSecretValue(ctx, name)
+	if err != nil || entry == nil {
+		return entry, err
+	}
+	entry.Key = key
+	return entry, nil
+}
+
+func (r *secretRouter) RetrieveSecretValueAt(ctx context.Context, key string, at time.Time) (*models.Entry, error) {
+	adapter, name, err := r.resolve(key)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := adapter.RetrieveSecretValueAt(ctx, name, at)
+	if err != nil || entry == nil {
+		return entry, err
+	}
+	entry.Key = key
+	return entry, nil
+}
+
+// ListSecrets secrets of every backend, named as the use cases read them back: parameter names
+// for ssm, references for the others
+func (r *secretRouter) ListSecrets(ctx context.Context, prefix string) ([]models.SecretParameter, error) {
+	names := make([]string, 0, len(r.backends))
+	for name := range r.backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	secrets := make([]models.SecretParameter, 0)
+	for _, name := range names {
+		adapter := r.backends[name]
+		listed, err := adapter.ListSecrets(ctx, prefix)
+		if err != nil {
+			return nil, fmt.Errorf("listing secrets of backend %q: %w", name, err)
+		}
+		if name != models.SecretBackendSSM {
+			for i := range listed {
+				listed[i].Name = adapter.Reference(listed[i].Name)
+			}
+		}
+		secrets = append(secrets, listed...)
+	}
+	return secrets, nil
+}
+
+func (r *secretRouter) Delete(ctx context.Context, names []string) operations.Results {
+	results := make(operations.Results, len(names))
+
+	grouped := make(map[domain.SecretAdapter][]string)
+	original := make(map[domain.SecretAdapter]map[string]string)
+	for _, name := range names {
+		adapter, resolved, err := r.resolve(name)
+		if err != nil {
+			r.logger.Error("ErrSecureDelete", zap.String("name", name), zap.Error(err))
+			results[name] = operations.Result{Key: name, Type: operations.Error, Error: err}
+			continue
+		}
+		if original[adapter] == nil {
+			original[adapter] = make(map[string]string)
+		}
+		grouped[adapter] = append(grouped[adapter], resolved)
+		original[adapter][resolved] = name
+	}
+
+	for adapter, group := range grouped {
+		for resolved, result := range adapter.Delete(ctx, group) {
+			name := original[adapter][resolved]
+			result.Key = name
+			results[name] = result
+		}
+	}
+	return results
+}
+
+func (r *secretRouter) Reference(key string) string {
+	return r.backends[r.backendFor(key)].Reference(key)
+}
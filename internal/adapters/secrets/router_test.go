@@ -0,0 +1,164 @@
+package secrets
+
+import (
+	"context"
+	"nbox/internal/application"
+	"nbox/internal/domain/models"
+	"nbox/internal/domain/models/operations"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryStore secret adapter keyed by rooted name, references are the name with a prefix
+type memoryStore struct {
+	reference string
+	values    map[string]string
+	deleted   []string
+}
+
+func newMemoryStore(reference string) *memoryStore {
+	return &memoryStore{reference: reference, values: map[string]string{}}
+}
+
+func (m *memoryStore) Upsert(_ context.Context, entries []models.Entry) operations.Results {
+	results := make(operations.Results)
+	for _, entry := range entries {
+		m.values["/"+strings.TrimPrefix(entry.Key, "/")] = entry.Value
+		results[entry.Key] = operations.Result{Key: entry.Key, Type: operations.Created}
+	}
+	return results
+}
+
+func (m *memoryStore) RetrieveSecretValue(_ context.Context, key string) (*models.Entry, error) {
+	value, ok := m.values[key]
+	if !ok {
+		return nil, assert.AnError
+	}
+	return &models.Entry{Key: key, Value: value, Secure: true}, nil
+}
+
+func (m *memoryStore) RetrieveSecretValueAt(ctx context.Context, key string, _ time.Time) (*models.Entry, error) {
+	return m.RetrieveSecretValue(ctx, key)
+}
+
+func (m *memoryStore) ListSecrets(_ context.Context, prefix string) ([]models.SecretParameter, error) {
+	secrets := make([]models.SecretParameter, 0)
+	for name, value := range m.values {
+		if strings.HasPrefix(name, "/"+prefix) {
+			secrets = append(secrets, models.SecretParameter{Name: name, Key: strings.TrimPrefix(name, "/"), Value: value})
+		}
+	}
+	return secrets, nil
+}
+
+func (m *memoryStore) Delete(_ context.Context, names []string) operations.Results {
+	results := make(operations.Results)
+	for _, name := range names {
+		delete(m.values, name)
+		m.deleted = append(m.deleted, name)
+		results[name] = operations.Result{Key: name, Type: operations.Deleted}
+	}
+	return results
+}
+
+func (m *memoryStore) Reference(key string) string {
+	return m.reference + strings.TrimPrefix(key, "/")
+}
+
+func newTestRouter(t *testing.T, routes string) (*secretRouter, *memoryStore, *memoryStore) {
+	t.Helper()
+	ssm := newMemoryStore("/")
+	vault := newMemoryStore(models.ReferenceVault)
+
+	adapter, err := NewSecretRouter(RouterParams{
+		Default:  ssm,
+		Backends: []Backend{{Name: models.SecretBackendVault, Adapter: vault}},
+		Config:   &application.Config{SecretRoutes: application.ParseSecretRoutes(routes)},
+		Logger:   zap.NewNop(),
+	})
+	require.NoError(t, err)
+	return adapter.(*secretRouter), ssm, vault
+}
+
+func TestSecretRouter_RoutesByLongestPrefix(t *testing.T) {
+	router, ssm, vault := newTestRouter(t, "production/=vault,production/legacy/=ssm")
+	ctx := context.Background()
+
+	results := router.Upsert(ctx, []models.Entry{
+		{Key: "production/app/password", Value: "p1", Secure: true},
+		{Key: "production/legacy/password", Value: "p2", Secure: true},
+		{Key: "development/app/password", Value: "p3", Secure: true},
+	})
+	assert.Len(t, results, 3)
+
+	assert.Equal(t, map[string]string{"/production/app/password": "p1"}, vault.values)
+	assert.Equal(t, map[string]string{"/production/legacy/password": "p2", "/development/app/password": "p3"}, ssm.values)
+
+	assert.Equal(t, "vault:production/app/password", router.Reference("production/app/password"))
+	assert.Equal(t, "/production/legacy/password", router.Reference("production/legacy/password"))
+	assert.Equal(t, "/development/app/password", router.Reference("development/app/password"))
+}
+
+func TestSecretRouter_ReadsFromTheBackendOfTheReference(t *testing.T) {
+	router, ssm, vault := newTestRouter(t, "production/=vault")
+	ctx := context.Background()
+	vault.values["/production/app/password"] = "from-vault"
+	// written before the prefix was routed to vault
+	ssm.values["/production/old/password"] = "from-ssm"
+
+	entry, err := router.RetrieveSecretValue(ctx, "vault:production/app/password")
+	require.NoError(t, err)
+	assert.Equal(t, "from-vault", entry.Value)
+	assert.Equal(t, "vault:production/app/password", entry.Key)
+
+	entry, err = router.RetrieveSecretValueAt(ctx, "/production/old/password", time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, "from-ssm", entry.Value)
+
+	_, err = router.RetrieveSecretValue(ctx, "local:production/app/password")
+	assert.ErrorIs(t, err, ErrBackendNotConfigured)
+}
+
+func TestSecretRouter_ListAndDelete(t *testing.T) {
+	router, ssm, vault := newTestRouter(t, "production/=vault")
+	ctx := context.Background()
+	vault.values["/production/app/password"] = "v"
+	ssm.values["/production/old/password"] = "s"
+
+	secrets, err := router.ListSecrets(ctx, "production/")
+	require.NoError(t, err)
+	names := make([]string, 0, len(secrets))
+	for _, secret := range secrets {
+		names = append(names, secret.Name)
+	}
+	assert.ElementsMatch(t, []string{"vault:production/app/password", "/production/old/password"}, names)
+
+	results := router.Delete(ctx, []string{"vault:production/app/password", "/production/old/password", "local:x"})
+	assert.Equal(t, operations.Deleted, results["vault:production/app/password"].Type)
+	assert.Equal(t, "vault:production/app/password", results["vault:production/app/password"].Key)
+	assert.Equal(t, operations.Deleted, results["/production/old/password"].Type)
+	assert.ErrorIs(t, results["local:x"].Error, ErrBackendNotConfigured)
+
+	assert.Equal(t, []string{"/production/app/password"}, vault.deleted)
+	assert.Equal(t, []string{"/production/old/password"}, ssm.deleted)
+}
+
+func TestNewSecretRouter(t *testing.T) {
+	ssm := newMemoryStore("/")
+
+	adapter, err := NewSecretRouter(RouterParams{Default: ssm, Config: &application.Config{}, Logger: zap.NewNop()})
+	require.NoError(t, err)
+	assert.Same(t, ssm, adapter, "without routes the secret store is not wrapped")
+
+	_, err = NewSecretRouter(RouterParams{
+		Default: ssm,
+		Config:  &application.Config{SecretRoutes: application.ParseSecretRoutes("production/=vault")},
+		Logger:  zap.NewNop(),
+	})
+	assert.ErrorIs(t, err, ErrBackendNotConfigured)
+}
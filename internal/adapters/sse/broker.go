@@ -3,8 +3,11 @@ package sse
 import (
 	"context"
 	"fmt"
+	"nbox/internal/application"
 	"net/http"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"go.uber.org/fx"
@@ -14,35 +17,61 @@ import (
 
 // Message es la estructura que se pasa por los canales internos y se envía a los clientes.
 type Message struct {
-	Name    string
-	Payload []byte
+	ID        uint64
+	Name      string
+	Payload   []byte
+	Resources []Resource
 }
 
 // client representa a un único cliente suscrito, identificado por su ID.
 type client struct {
 	id      string
 	channel chan Message
+	// replay a client that reconnects with Last-Event-ID receives the messages it missed
+	lastEventID *uint64
+	replay      chan replay
+}
+
+type replay struct {
+	messages []Message
+	complete bool
 }
 
 // EventBroker gestiona los clientes conectados y la difusión de eventos.
 type EventBroker struct {
 	logger      *zap.Logger
+	authorizer  Authorizer
 	clients     map[string]chan Message // Clave: ClientID
 	newClients  chan client
 	deadClients chan client
 	events      chan Message
 	mu          sync.Mutex
+	// history y nextID solo se usan desde listen
+	history   *ring
+	nextID    uint64
+	heartbeat time.Duration
 }
 
 // NewEventBroker ahora se integra con el ciclo de vida de fx.
-func NewEventBroker(lc fx.Lifecycle, logger *zap.Logger) *EventBroker {
+func NewEventBroker(lc fx.Lifecycle, config *application.Config, authorizer Authorizer, logger *zap.Logger) *EventBroker {
+	heartbeat := time.Duration(config.SSEHeartbeatSeconds) * time.Second
+	if heartbeat <= 0 {
+		heartbeat = 15 * time.Second
+	}
+
 	broker := &EventBroker{
-		logger:  logger,
-		clients: make(map[string]chan Message),
+		logger:     logger,
+		authorizer: authorizer,
+		clients:    make(map[string]chan Message),
 		// Añadimos un búfer para evitar bloqueos en el registro/desregistro.
 		newClients:  make(chan client, 10),
 		deadClients: make(chan client, 10),
 		events:      make(chan Message),
+		history:     newRing(config.SSEReplayBuffer),
+		// IDs start at the startup time, so a client reconnecting after a restart replays
+		// everything published by the new process
+		nextID:    uint64(time.Now().UnixMicro()),
+		heartbeat: heartbeat,
 	}
 
 	brokerCtx, cancel := context.WithCancel(context.Background())
@@ -73,21 +102,38 @@ func (b *EventBroker) listen(ctx context.Context) {
 			b.mu.Lock()
 			b.clients[c.id] = c.channel
 			b.mu.Unlock()
+			// registering and taking the snapshot in the same goroutine that broadcasts means
+			// no message is missed nor sent twice between the replay and the live stream
+			if c.lastEventID != nil {
+				messages, complete := b.history.since(*c.lastEventID)
+				c.replay <- replay{messages: messages, complete: complete}
+			}
 			b.logger.Info("New SSE client connected", zap.String("clientId", c.id))
 		case c := <-b.deadClients:
 			b.mu.Lock()
-			delete(b.clients, c.id)
+			// a lagging client was already removed, and its id may belong to a new connection
+			if channel, ok := b.clients[c.id]; ok && channel == c.channel {
+				delete(b.clients, c.id)
+				close(c.channel)
+			}
 			b.mu.Unlock()
-			close(c.channel)
 			b.logger.Info("SSE client disconnected", zap.String("clientId", c.id))
 		case event := <-b.events:
+			b.nextID++
+			event.ID = b.nextID
+			b.history.push(event)
+
 			b.mu.Lock()
 			// Iteramos sobre los clientes y usamos un envío no bloqueante.
 			for id, c := range b.clients {
 				select {
 				case c <- event:
 				default:
-					b.logger.Warn("Client channel is full, skipping event", zap.String("clientId", id))
+					// disconnect it instead of skipping the event: the client reconnects with
+					// Last-Event-ID and replays what it missed from the history
+					b.logger.Warn("Client channel is full, disconnecting client", zap.String("clientId", id))
+					delete(b.clients, id)
+					close(c)
 				}
 			}
 			b.mu.Unlock()
@@ -95,38 +141,102 @@ func (b *EventBroker) listen(ctx context.Context) {
 	}
 }
 
-// ServeHTTP gestiona las conexiones entrantes de SSE.
+// ServeHTTP gestiona las conexiones entrantes de SSE. It runs behind the auth middleware: each
+// event is sent only if it matches ?types= and ?prefix= and the user may read its keys.
+// Events carry an id; a client reconnecting with Last-Event-ID receives the events it missed.
 func (b *EventBroker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	clientID := r.URL.Query().Get("clientId")
+	query := r.URL.Query()
+	clientID := query.Get("clientId")
 	if clientID == "" {
 		clientID = uuid.NewString()
 	}
 
-	w.Header().Set("Content-Type", "text/event-stream")
-	w.Header().Set("Cache-Control", "no-cache")
-	w.Header().Set("Connection", "keep-alive")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
+	var roles []string
+	if user, ok := application.UserFromContext(r.Context()); ok {
+		roles = user.Roles
+	}
+
+	sub, err := newSubscription(query.Get("types"), query.Get("prefix"), roles)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	newClient := client{
 		id:      clientID,
 		channel: make(chan Message, 20),
 	}
+
+	if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" {
+		id, err := strconv.ParseUint(lastEventID, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid Last-Event-ID", http.StatusBadRequest)
+			return
+		}
+		newClient.lastEventID = &id
+		newClient.replay = make(chan replay, 1)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
 	b.newClients <- newClient
 
 	defer func() {
 		b.deadClients <- newClient
 	}()
 
+	flush := func() {
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+	}
+
+	if newClient.replay != nil {
+		select {
+		case <-r.Context().Done():
+			return
+		case missed := <-newClient.replay:
+			if !missed.complete {
+				_, _ = fmt.Fprint(w, ": replay incomplete, older events are no longer buffered\n\n")
+			}
+			for _, msg := range missed.messages {
+				if b.visible(r.Context(), sub, msg) {
+					writeMessage(w, msg)
+				}
+			}
+		}
+	}
+	flush()
+
+	heartbeat := time.NewTicker(b.heartbeat)
+	defer heartbeat.Stop()
+
 	for {
 		select {
 		case <-r.Context().Done():
 			return
-		case msg := <-newClient.channel:
-			_, _ = fmt.Fprintf(w, "event: %s\n", msg.Name)
-			_, _ = fmt.Fprintf(w, "data: %s\n\n", msg.Payload)
-			if f, ok := w.(http.Flusher); ok {
-				f.Flush()
+		case <-heartbeat.C:
+			// comentario SSE, evita que los proxies corten conexiones inactivas
+			_, _ = fmt.Fprint(w, ": heartbeat\n\n")
+			flush()
+		case msg, ok := <-newClient.channel:
+			if !ok {
+				return // too slow, the client reconnects with Last-Event-ID
+			}
+			if !b.visible(r.Context(), sub, msg) {
+				continue
 			}
+			writeMessage(w, msg)
+			flush()
 		}
 	}
 }
+
+func writeMessage(w http.ResponseWriter, msg Message) {
+	_, _ = fmt.Fprintf(w, "id: %d\n", msg.ID)
+	_, _ = fmt.Fprintf(w, "event: %s\n", msg.Name)
+	_, _ = fmt.Fprintf(w, "data: %s\n\n", msg.Payload)
+}
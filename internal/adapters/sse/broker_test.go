@@ -0,0 +1,148 @@
+package sse
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"nbox/internal/application"
+	"nbox/internal/domain"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+)
+
+type allowAll struct{}
+
+func (allowAll) Allow(context.Context, map[string]any) (bool, error) { return true, nil }
+
+func TestRing_Since(t *testing.T) {
+	r := newRing(3)
+	for id := uint64(1); id <= 5; id++ {
+		r.push(Message{ID: id})
+	}
+
+	ids := func(messages []Message) []uint64 {
+		var result []uint64
+		for _, m := range messages {
+			result = append(result, m.ID)
+		}
+		return result
+	}
+
+	messages, complete := r.since(3)
+	assert.Equal(t, []uint64{4, 5}, ids(messages))
+	assert.True(t, complete)
+
+	messages, complete = r.since(2)
+	assert.Equal(t, []uint64{3, 4, 5}, ids(messages))
+	assert.True(t, complete)
+
+	// 2 was evicted
+	messages, complete = r.since(1)
+	assert.Equal(t, []uint64{3, 4, 5}, ids(messages))
+	assert.False(t, complete)
+}
+
+// sseStream reads the id of every event of the stream
+type sseStream struct {
+	ids chan string
+}
+
+func connect(t *testing.T, ctx context.Context, url, lastEventID string) *sseStream {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	require.NoError(t, err)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	stream := &sseStream{ids: make(chan string, 10)}
+	go func() {
+		defer func() { _ = resp.Body.Close() }()
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if id, ok := strings.CutPrefix(scanner.Text(), "id: "); ok {
+				stream.ids <- id
+			}
+		}
+	}()
+	return stream
+}
+
+func (s *sseStream) next(t *testing.T) string {
+	t.Helper()
+	select {
+	case id := <-s.ids:
+		return id
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for event")
+		return ""
+	}
+}
+
+func TestEventBroker_ReplayWithLastEventID(t *testing.T) {
+	lc := fxtest.NewLifecycle(t)
+	broker := NewEventBroker(lc, &application.Config{SSEReplayBuffer: 10, SSEHeartbeatSeconds: 1}, allowAll{}, zap.NewNop())
+	lc.RequireStart()
+	defer lc.RequireStop()
+
+	server := httptest.NewServer(broker)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	publisher := NewInMemoryEventPublisher(broker, zap.NewNop())
+	publish := func(i int) {
+		payload, _ := json.Marshal([]map[string]string{{"key": fmt.Sprintf("development/app/key%d", i)}})
+		require.NoError(t, publisher.Publish(ctx, domain.Event[json.RawMessage]{Type: domain.EventEntryActions, Payload: payload}))
+	}
+
+	live := connect(t, ctx, server.URL, "")
+	// events published before the registration are not sent to the live client
+	require.Eventually(t, func() bool {
+		broker.mu.Lock()
+		defer broker.mu.Unlock()
+		return len(broker.clients) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	for i := range 3 {
+		publish(i)
+	}
+	first, second, third := live.next(t), live.next(t), live.next(t)
+	assert.Less(t, first, second)
+	assert.Less(t, second, third)
+
+	reconnected := connect(t, ctx, server.URL, first)
+	assert.Equal(t, second, reconnected.next(t))
+	assert.Equal(t, third, reconnected.next(t))
+
+	publish(3)
+	fourth := live.next(t)
+	assert.Equal(t, fourth, reconnected.next(t))
+}
+
+func TestEventBroker_InvalidLastEventID(t *testing.T) {
+	lc := fxtest.NewLifecycle(t)
+	broker := NewEventBroker(lc, &application.Config{SSEReplayBuffer: 10}, allowAll{}, zap.NewNop())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/events", nil)
+	req.Header.Set("Last-Event-ID", "not-a-number")
+	rec := httptest.NewRecorder()
+
+	broker.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
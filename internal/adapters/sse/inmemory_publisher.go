@@ -29,8 +29,9 @@ func (p *InMemoryEventPublisher) Publish(ctx context.Context, event domain.Event
 	}
 
 	message := Message{
-		Name:    string(event.Type),
-		Payload: payloadBytes,
+		Name:      string(event.Type),
+		Payload:   payloadBytes,
+		Resources: eventResources(event),
 	}
 	p.broker.events <- message
 	return nil
@@ -0,0 +1,45 @@
+package sse
+
+// ring últimos mensajes enviados, para reenviarlos a los clientes que se reconectan
+type ring struct {
+	items   []Message
+	start   int
+	size    int
+	evicted bool
+}
+
+func newRing(capacity int) *ring {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &ring{items: make([]Message, capacity)}
+}
+
+func (r *ring) push(msg Message) {
+	if r.size < len(r.items) {
+		r.items[(r.start+r.size)%len(r.items)] = msg
+		r.size++
+		return
+	}
+
+	r.items[r.start] = msg
+	r.start = (r.start + 1) % len(r.items)
+	r.evicted = true
+}
+
+// since messages with an ID greater than lastID, oldest first. complete is false when some of
+// the missed messages were already evicted from the buffer.
+func (r *ring) since(lastID uint64) (messages []Message, complete bool) {
+	for i := range r.size {
+		msg := r.items[(r.start+i)%len(r.items)]
+		if msg.ID > lastID {
+			messages = append(messages, msg)
+		}
+	}
+
+	complete = true
+	if r.evicted && r.size > 0 && r.items[r.start].ID > lastID+1 {
+		complete = false
+	}
+	return messages, complete
+}
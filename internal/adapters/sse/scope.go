@@ -0,0 +1,155 @@
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"nbox/internal/domain"
+	"strings"
+)
+
+// Authorizer evaluates the OPA policy (data.authz.allow) for an input {"payload", "action"}
+type Authorizer interface {
+	Allow(ctx context.Context, input map[string]any) (bool, error)
+}
+
+// Resource key or template path touched by an event, with the request that reads it.
+// The action is checked against the same policy as the HTTP API.
+type Resource struct {
+	Path   string
+	Action string
+}
+
+func entryResource(key string) Resource {
+	key = strings.TrimPrefix(key, "/")
+	return Resource{Path: key, Action: "GET:/api/entry/key?v=" + key}
+}
+
+// revealResource secret reveals are only visible to whoever may read the reveal audit
+func revealResource(key string) Resource {
+	key = strings.TrimPrefix(key, "/")
+	return Resource{Path: key, Action: "GET:/api/audit/secret-reveals?key=" + key}
+}
+
+func templateResource(path string) Resource {
+	path = strings.TrimPrefix(path, "/")
+	return Resource{Path: path, Action: "GET:/api/box/" + path}
+}
+
+// eventResources keys of the entries or paths of the templates in the event payload
+func eventResources(event domain.Event[json.RawMessage]) []Resource {
+	var resources []Resource
+
+	switch event.Type {
+	case domain.EventEntryActions:
+		var results []struct {
+			Key string `json:"key"`
+		}
+		_ = json.Unmarshal(event.Payload, &results)
+		for _, result := range results {
+			resources = append(resources, entryResource(result.Key))
+		}
+	case domain.EventEntryDeleted:
+		var payload struct {
+			Key     string   `json:"key"`
+			Deleted []string `json:"deleted"`
+		}
+		_ = json.Unmarshal(event.Payload, &payload)
+		for _, key := range append(payload.Deleted, payload.Key) {
+			resources = append(resources, entryResource(key))
+		}
+	case domain.EventEntryRollback, domain.EventEntryRetype, domain.EventSecretRotated:
+		var payload struct {
+			Key string `json:"key"`
+		}
+		_ = json.Unmarshal(event.Payload, &payload)
+		resources = append(resources, entryResource(payload.Key))
+	case domain.EventSecretRevealed:
+		var payload struct {
+			Key string `json:"key"`
+		}
+		_ = json.Unmarshal(event.Payload, &payload)
+		resources = append(resources, revealResource(payload.Key))
+	case domain.EventTemplateCreated, domain.EventTemplateUpdated:
+		var payload struct {
+			Paths []string `json:"paths"`
+		}
+		_ = json.Unmarshal(event.Payload, &payload)
+		for _, path := range payload.Paths {
+			resources = append(resources, templateResource(path))
+		}
+	}
+
+	// a payload without keys can't be authorized
+	valid := resources[:0]
+	for _, r := range resources {
+		if r.Path != "" {
+			valid = append(valid, r)
+		}
+	}
+	return valid
+}
+
+// subscription filters of a client: ?types=entry.upsert,entry.deleted&prefix=production/myapp/
+type subscription struct {
+	types  map[string]bool
+	prefix string
+	roles  []string
+}
+
+func newSubscription(types, prefix string, roles []string) (subscription, error) {
+	sub := subscription{
+		types:  make(map[string]bool),
+		prefix: strings.TrimPrefix(prefix, "/"),
+		roles:  roles,
+	}
+
+	for _, name := range strings.Split(types, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if !domain.EventType(name).IsValid() {
+			return sub, fmt.Errorf("unknown event type %q", name)
+		}
+		sub.types[name] = true
+	}
+
+	return sub, nil
+}
+
+// visible the message matches the filters and the user may read every resource in it.
+// Events mixing keys the user can't read are not sent at all.
+func (b *EventBroker) visible(ctx context.Context, sub subscription, msg Message) bool {
+	if len(sub.types) > 0 && !sub.types[msg.Name] {
+		return false
+	}
+
+	if len(msg.Resources) == 0 {
+		return false
+	}
+
+	if sub.prefix != "" {
+		matches := false
+		for _, r := range msg.Resources {
+			if strings.HasPrefix(r.Path, sub.prefix) {
+				matches = true
+				break
+			}
+		}
+		if !matches {
+			return false
+		}
+	}
+
+	for _, r := range msg.Resources {
+		allowed, err := b.authorizer.Allow(ctx, map[string]any{
+			"payload": map[string]any{"roles": sub.roles},
+			"action":  r.Action,
+		})
+		if err != nil || !allowed {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,137 @@
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"nbox/internal/domain"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// patternAuthorizer allows the actions that match the patterns of the role
+type patternAuthorizer map[string]*regexp.Regexp
+
+func (a patternAuthorizer) Allow(_ context.Context, input map[string]any) (bool, error) {
+	roles := input["payload"].(map[string]any)["roles"].([]string)
+	for _, role := range roles {
+		if pattern, ok := a[role]; ok && pattern.MatchString(input["action"].(string)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func message(t *testing.T, eventType domain.EventType, payload any) Message {
+	t.Helper()
+	raw, err := json.Marshal(payload)
+	require.NoError(t, err)
+
+	event := domain.Event[json.RawMessage]{Type: eventType, Payload: raw}
+	return Message{Name: string(eventType), Payload: raw, Resources: eventResources(event)}
+}
+
+func TestEventResources(t *testing.T) {
+	tests := []struct {
+		name      string
+		eventType domain.EventType
+		payload   any
+		want      []string
+	}{
+		{
+			name:      "upsert results",
+			eventType: domain.EventEntryActions,
+			payload:   []map[string]string{{"key": "development/app/db"}, {"key": "/qa/app/db"}},
+			want:      []string{"GET:/api/entry/key?v=development/app/db", "GET:/api/entry/key?v=qa/app/db"},
+		},
+		{
+			name:      "deleted keys and requested key",
+			eventType: domain.EventEntryDeleted,
+			payload:   map[string]any{"key": "development/app", "deleted": []string{"development/app/db"}},
+			want:      []string{"GET:/api/entry/key?v=development/app/db", "GET:/api/entry/key?v=development/app"},
+		},
+		{
+			name:      "template paths",
+			eventType: domain.EventTemplateCreated,
+			payload:   map[string]any{"service": "api", "paths": []string{"api/production/task.json"}},
+			want:      []string{"GET:/api/box/api/production/task.json"},
+		},
+		{
+			name:      "secret reveal checked against the audit",
+			eventType: domain.EventSecretRevealed,
+			payload:   map[string]any{"key": "production/app/db_password", "user": "jdoe"},
+			want:      []string{"GET:/api/audit/secret-reveals?key=production/app/db_password"},
+		},
+		{
+			name:      "secret rotation",
+			eventType: domain.EventSecretRotated,
+			payload:   map[string]any{"key": "production/app/db_password", "trigger": "scheduled"},
+			want:      []string{"GET:/api/entry/key?v=production/app/db_password"},
+		},
+		{
+			name:      "payload without keys",
+			eventType: domain.EventEntryRollback,
+			payload:   map[string]any{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var actions []string
+			for _, r := range message(t, tt.eventType, tt.payload).Resources {
+				actions = append(actions, r.Action)
+			}
+			assert.Equal(t, tt.want, actions)
+		})
+	}
+}
+
+func TestEventBroker_Visible(t *testing.T) {
+	broker := &EventBroker{
+		logger: zap.NewNop(),
+		authorizer: patternAuthorizer{
+			"viewer": regexp.MustCompile(`^GET:/api/entry/key\?v=(development|qa)/`),
+			"admin":  regexp.MustCompile(`.*`),
+		},
+	}
+
+	development := message(t, domain.EventEntryActions, []map[string]string{{"key": "development/app/db"}})
+	production := message(t, domain.EventEntryActions, []map[string]string{{"key": "production/app/db"}})
+	mixed := message(t, domain.EventEntryActions, []map[string]string{{"key": "development/app/db"}, {"key": "production/app/db"}})
+	deleted := message(t, domain.EventEntryDeleted, map[string]any{"key": "production/myapp/db", "deleted": []string{"production/myapp/db"}})
+
+	tests := []struct {
+		name   string
+		types  string
+		prefix string
+		roles  []string
+		msg    Message
+		want   bool
+	}{
+		{name: "readable prefix", roles: []string{"viewer"}, msg: development, want: true},
+		{name: "production hidden from viewer", roles: []string{"viewer"}, msg: production, want: false},
+		{name: "mixed batch hidden from viewer", roles: []string{"viewer"}, msg: mixed, want: false},
+		{name: "no roles", msg: development, want: false},
+		{name: "admin receives production", roles: []string{"admin"}, msg: production, want: true},
+		{name: "prefix filter matches", prefix: "/production/myapp/", roles: []string{"admin"}, msg: deleted, want: true},
+		{name: "prefix filter excludes", prefix: "production/myapp/", roles: []string{"admin"}, msg: production, want: false},
+		{name: "types filter excludes", types: "entry.deleted", roles: []string{"admin"}, msg: production, want: false},
+		{name: "types filter matches", types: "entry.upsert, entry.deleted", roles: []string{"admin"}, msg: deleted, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, err := newSubscription(tt.types, tt.prefix, tt.roles)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, broker.visible(context.Background(), sub, tt.msg))
+		})
+	}
+}
+
+func TestNewSubscription_UnknownType(t *testing.T) {
+	_, err := newSubscription("entry.upsert,entry.unknown", "", nil)
+	assert.Error(t, err)
+}
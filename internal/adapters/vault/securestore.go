@@ -0,0 +1,363 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"nbox/internal/application"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+	"nbox/internal/domain/models/operations"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrSecretNotFound   = errors.New("secret not found or has no value")
+	ErrMissingVaultAddr = errors.New("NBOX_VAULT_ADDR and NBOX_VAULT_TOKEN are required by the vault secret backend")
+)
+
+// custom metadata written on every secret, same keys as the Parameter Store tags
+const (
+	metadataProject       = "project"
+	metadataKey           = "nbox:key"
+	metadataOwner         = "nbox:owner"
+	metadataTypeValidator = "nbox:type-validator"
+
+	metadataProjectValue = "nbox"
+	// field of the KV v2 data that holds the value
+	valueField = "value"
+)
+
+// APIError error response of Vault
+type APIError struct {
+	StatusCode int
+	Errors     []string `json:"errors"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("vault: status %d: %s", e.StatusCode, strings.Join(e.Errors, "; "))
+}
+
+type secretVersion struct {
+	CreatedTime  time.Time `json:"created_time"`
+	DeletionTime string    `json:"deletion_time"`
+	Destroyed    bool      `json:"destroyed"`
+}
+
+type secretMetadata struct {
+	CustomMetadata map[string]string        `json:"custom_metadata"`
+	UpdatedTime    time.Time                `json:"updated_time"`
+	Versions       map[string]secretVersion `json:"versions"`
+}
+
+// secretStore secrets in a Vault KV v2 mount (NBOX_VAULT_MOUNT), one secret per key with the value
+// in the "value" field. The key, owner and type validator are kept in the custom metadata.
+type secretStore struct {
+	client *http.Client
+	addr   string
+	token  string
+	mount  string
+	logger *zap.Logger
+}
+
+func NewSecretStore(config *application.Config, logger *zap.Logger) (domain.SecretAdapter, error) {
+	if config.VaultAddr == "" || config.VaultToken == "" {
+		return nil, ErrMissingVaultAddr
+	}
+
+	return &secretStore{
+		client: &http.Client{Timeout: 10 * time.Second},
+		addr:   strings.TrimSuffix(config.VaultAddr, "/"),
+		token:  config.VaultToken,
+		mount:  strings.Trim(config.VaultMount, "/"),
+		logger: logger.Named("vault_secret_store"),
+	}, nil
+}
+
+func (s *secretStore) Upsert(ctx context.Context, entries []models.Entry) operations.Results {
+	results := make(operations.Results, len(entries))
+
+	owner := "ghost"
+	if user, ok := application.UserFromContext(ctx); ok {
+		owner = user.Name
+	}
+
+	for _, entry := range entries {
+		path := secretPath(entry.Key)
+
+		var written struct {
+			Data struct {
+				Version int64 `json:"version"`
+			} `json:"data"`
+		}
+		body := map[string]any{"data": map[string]string{valueField: entry.Value}}
+		if err := s.do(ctx, http.MethodPost, "/data/"+path, body, &written); err != nil {
+			s.logger.Error("ErrSecureUpsert", zap.String("key", entry.Key), zap.Error(err))
+			results[entry.Key] = operations.Result{Key: entry.Key, Error: err}
+			continue
+		}
+
+		// custom_metadata is replaced as a whole, a removed type validator is dropped
+		metadata := map[string]string{
+			metadataProject: metadataProjectValue,
+			metadataKey:     strings.TrimPrefix(entry.Key, "/"),
+			metadataOwner:   owner,
+		}
+		if entry.TypeValidatorName != "" {
+			metadata[metadataTypeValidator] = entry.TypeValidatorName
+		}
+		if err := s.do(ctx, http.MethodPost, "/metadata/"+path, map[string]any{"custom_metadata": metadata}, nil); err != nil {
+			s.logger.Warn("ErrSecureAddingTags", zap.Error(err), zap.String("key", entry.Key))
+		}
+
+		opType := operations.Updated
+		if written.Data.Version == 1 {
+			opType = operations.Created
+		}
+		results[entry.Key] = operations.Result{Key: entry.Key, Type: opType}
+	}
+
+	return results
+}
+
+func (s *secretStore) RetrieveSecretValue(ctx context.Context, key string) (*models.Entry, error) {
+	return s.read(ctx, key, "")
+}
+
+// RetrieveSecretValueAt returns the last version written before the given time, destroyed or
+// deleted versions can't be read back
+func (s *secretStore) RetrieveSecretValueAt(ctx context.Context, key string, at time.Time) (*models.Entry, error) {
+	// tracking timestamps have second precision and are taken after the secret is written
+	limit := at.Truncate(time.Second).Add(time.Second)
+
+	metadata, err := s.metadata(ctx, secretPath(key))
+	if err != nil {
+		return nil, err
+	}
+
+	var found int64
+	var foundTime time.Time
+	for number, version := range metadata.Versions {
+		n, err := strconv.ParseInt(number, 10, 64)
+		if err != nil || version.CreatedTime.After(limit) {
+			continue
+		}
+		if version.CreatedTime.After(foundTime) || (version.CreatedTime.Equal(foundTime) && n > found) {
+			found, foundTime = n, version.CreatedTime
+		}
+	}
+
+	if found == 0 {
+		s.logger.Error("ErrParameterNotFound", zap.String("key", key), zap.Time("at", at))
+		return nil, ErrSecretNotFound
+	}
+
+	return s.read(ctx, key, strconv.FormatInt(found, 10))
+}
+
+// ListSecrets secrets under the prefix with the project=nbox metadata, walking the mount from the
+// folder of the prefix
+func (s *secretStore) ListSecrets(ctx context.Context, prefix string) ([]models.SecretParameter, error) {
+	prefix = strings.Trim(prefix, "/")
+	folder := ""
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		folder = prefix[:i+1]
+	}
+
+	paths, err := s.list(ctx, folder)
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := make([]models.SecretParameter, 0)
+	for _, path := range paths {
+		if !strings.HasPrefix(path, prefix) {
+			continue
+		}
+
+		metadata, err := s.metadata(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		if metadata.CustomMetadata[metadataProject] != metadataProjectValue {
+			continue
+		}
+
+		entry, err := s.read(ctx, path, "")
+		if err != nil {
+			return nil, err
+		}
+
+		key := metadata.CustomMetadata[metadataKey]
+		if key == "" {
+			key = path
+		}
+		secrets = append(secrets, models.SecretParameter{
+			Name:              "/" + path,
+			Key:               key,
+			TypeValidatorName: metadata.CustomMetadata[metadataTypeValidator],
+			Owner:             metadata.CustomMetadata[metadataOwner],
+			Value:             entry.Value,
+			UpdatedAt:         metadata.UpdatedTime,
+		})
+	}
+
+	sort.Slice(secrets, func(i, j int) bool { return secrets[i].Name < secrets[j].Name })
+	return secrets, nil
+}
+
+// Delete removes the metadata and every version of the secrets. A missing secret is reported as deleted.
+func (s *secretStore) Delete(ctx context.Context, names []string) operations.Results {
+	results := make(operations.Results, len(names))
+
+	for _, name := range names {
+		err := s.do(ctx, http.MethodDelete, "/metadata/"+secretPath(name), nil, nil)
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			err = nil
+		}
+
+		if err != nil {
+			s.logger.Error("ErrSecureDelete", zap.String("name", name), zap.Error(err))
+			results[name] = operations.Result{Key: name, Type: operations.Error, Error: err}
+			continue
+		}
+		results[name] = operations.Result{Key: name, Type: operations.Deleted}
+	}
+
+	return results
+}
+
+// Reference vault:production/app/password
+func (s *secretStore) Reference(key string) string {
+	return models.ReferenceVault + strings.TrimPrefix(key, "/")
+}
+
+func (s *secretStore) read(ctx context.Context, key, version string) (*models.Entry, error) {
+	endpoint := "/data/" + secretPath(key)
+	if version != "" {
+		endpoint += "?version=" + version
+	}
+
+	var output struct {
+		Data struct {
+			Data map[string]string `json:"data"`
+		} `json:"data"`
+	}
+	err := s.do(ctx, http.MethodGet, endpoint, nil, &output)
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		s.logger.Error("ErrParameterNotFound", zap.String("key", key))
+		return nil, ErrSecretNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok := output.Data.Data[valueField]
+	if !ok {
+		return nil, ErrSecretNotFound
+	}
+	return &models.Entry{Key: key, Value: value, Secure: true}, nil
+}
+
+func (s *secretStore) metadata(ctx context.Context, path string) (*secretMetadata, error) {
+	var output struct {
+		Data secretMetadata `json:"data"`
+	}
+	err := s.do(ctx, http.MethodGet, "/metadata/"+path, nil, &output)
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return nil, ErrSecretNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &output.Data, nil
+}
+
+// list paths of the secrets under the folder, recursively
+func (s *secretStore) list(ctx context.Context, folder string) ([]string, error) {
+	var output struct {
+		Data struct {
+			Keys []string `json:"keys"`
+		} `json:"data"`
+	}
+	err := s.do(ctx, http.MethodGet, "/metadata/"+folder+"?list=true", nil, &output)
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(output.Data.Keys))
+	for _, key := range output.Data.Keys {
+		if !strings.HasSuffix(key, "/") {
+			paths = append(paths, folder+key)
+			continue
+		}
+		children, err := s.list(ctx, folder+key)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, children...)
+	}
+	return paths, nil
+}
+
+// do request to the KV v2 mount, the endpoint starts with /data/ or /metadata/
+func (s *secretStore) do(ctx context.Context, method, endpoint string, input, output any) error {
+	var body io.Reader
+	if input != nil {
+		raw, err := json.Marshal(input)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(raw)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.addr+"/v1/"+s.mount+endpoint, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", s.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		apiErr := &APIError{StatusCode: res.StatusCode}
+		_ = json.NewDecoder(res.Body).Decode(apiErr)
+		return apiErr
+	}
+
+	if output == nil || res.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(output)
+}
+
+// secretPath path of the key inside the mount, without the leading "/"
+func secretPath(key string) string {
+	return strings.TrimPrefix(key, "/")
+}
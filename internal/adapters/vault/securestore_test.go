@@ -0,0 +1,193 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"nbox/internal/application"
+	"nbox/internal/domain/models"
+	"nbox/internal/domain/models/operations"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type fakeSecret struct {
+	versions []string
+	created  []time.Time
+	metadata map[string]string
+}
+
+// fakeKV minimal KV v2 engine mounted at "secret"
+type fakeKV struct {
+	mu      sync.Mutex
+	secrets map[string]*fakeSecret
+	now     time.Time
+}
+
+func (f *fakeKV) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Header.Get("X-Vault-Token") != "token" {
+		w.WriteHeader(http.StatusForbidden)
+		_ = json.NewEncoder(w).Encode(map[string]any{"errors": []string{"permission denied"}})
+		return
+	}
+
+	kind, path, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/v1/secret/"), "/")
+	secret := f.secrets[path]
+	notFound := func() {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]any{"errors": []string{}})
+	}
+
+	switch {
+	case kind == "data" && r.Method == http.MethodPost:
+		var body struct {
+			Data map[string]string `json:"data"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if secret == nil {
+			secret = &fakeSecret{}
+			f.secrets[path] = secret
+		}
+		secret.versions = append(secret.versions, body.Data["value"])
+		secret.created = append(secret.created, f.now)
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"version": len(secret.versions)}})
+	case kind == "data" && r.Method == http.MethodGet:
+		if secret == nil {
+			notFound()
+			return
+		}
+		version := len(secret.versions)
+		if v := r.URL.Query().Get("version"); v != "" {
+			version, _ = strconv.Atoi(v)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"data": map[string]string{"value": secret.versions[version-1]}}})
+	case kind == "metadata" && r.Method == http.MethodPost:
+		var body struct {
+			CustomMetadata map[string]string `json:"custom_metadata"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		secret.metadata = body.CustomMetadata
+		w.WriteHeader(http.StatusNoContent)
+	case kind == "metadata" && r.Method == http.MethodGet && r.URL.Query().Get("list") == "true":
+		keys := map[string]struct{}{}
+		for name := range f.secrets {
+			if rest, ok := strings.CutPrefix(name, path); ok {
+				if i := strings.Index(rest, "/"); i >= 0 {
+					rest = rest[:i+1]
+				}
+				keys[rest] = struct{}{}
+			}
+		}
+		if len(keys) == 0 {
+			notFound()
+			return
+		}
+		list := make([]string, 0, len(keys))
+		for key := range keys {
+			list = append(list, key)
+		}
+		sort.Strings(list)
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"keys": list}})
+	case kind == "metadata" && r.Method == http.MethodGet:
+		if secret == nil {
+			notFound()
+			return
+		}
+		versions := map[string]any{}
+		for i, created := range secret.created {
+			versions[strconv.Itoa(i+1)] = map[string]any{"created_time": created}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{
+			"custom_metadata": secret.metadata,
+			"updated_time":    secret.created[len(secret.created)-1],
+			"versions":        versions,
+		}})
+	case kind == "metadata" && r.Method == http.MethodDelete:
+		delete(f.secrets, path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func newTestStore(t *testing.T) (*secretStore, *fakeKV) {
+	t.Helper()
+	kv := &fakeKV{secrets: map[string]*fakeSecret{}, now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	server := httptest.NewServer(kv)
+	t.Cleanup(server.Close)
+
+	store, err := NewSecretStore(&application.Config{VaultAddr: server.URL, VaultToken: "token", VaultMount: "secret"}, zap.NewNop())
+	require.NoError(t, err)
+	return store.(*secretStore), kv
+}
+
+func TestSecretStore_UpsertAndRetrieve(t *testing.T) {
+	store, kv := newTestStore(t)
+	ctx := context.Background()
+
+	results := store.Upsert(ctx, []models.Entry{{Key: "production/app/password", Value: "v1", Secure: true, TypeValidatorName: "password"}})
+	assert.Equal(t, operations.Created, results["production/app/password"].Type)
+
+	kv.now = kv.now.Add(time.Hour)
+	results = store.Upsert(ctx, []models.Entry{{Key: "production/app/password", Value: "v2", Secure: true}})
+	assert.Equal(t, operations.Updated, results["production/app/password"].Type)
+
+	entry, err := store.RetrieveSecretValue(ctx, "/production/app/password")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", entry.Value)
+
+	entry, err = store.RetrieveSecretValueAt(ctx, "/production/app/password", kv.now.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, "v1", entry.Value)
+
+	_, err = store.RetrieveSecretValue(ctx, "/production/app/missing")
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+
+	assert.Equal(t, map[string]string{"project": "nbox", "nbox:key": "production/app/password", "nbox:owner": "ghost"},
+		kv.secrets["production/app/password"].metadata)
+	assert.Equal(t, "vault:production/app/password", store.Reference("production/app/password"))
+}
+
+func TestSecretStore_ListSecretsAndDelete(t *testing.T) {
+	store, kv := newTestStore(t)
+	ctx := context.Background()
+
+	store.Upsert(ctx, []models.Entry{
+		{Key: "production/app/password", Value: "a", Secure: true, TypeValidatorName: "password"},
+		{Key: "production/app/db/password", Value: "b", Secure: true},
+		{Key: "development/app/password", Value: "c", Secure: true},
+	})
+	// written outside nbox
+	kv.secrets["production/app/manual"] = &fakeSecret{versions: []string{"x"}, created: []time.Time{kv.now}}
+
+	secrets, err := store.ListSecrets(ctx, "production/app")
+	require.NoError(t, err)
+	require.Len(t, secrets, 2)
+	assert.Equal(t, "/production/app/db/password", secrets[0].Name)
+	assert.Equal(t, "b", secrets[0].Value)
+	assert.Equal(t, "/production/app/password", secrets[1].Name)
+	assert.Equal(t, "password", secrets[1].TypeValidatorName)
+	assert.Equal(t, kv.now, secrets[1].UpdatedAt)
+
+	results := store.Delete(ctx, []string{"/production/app/password", "/production/app/missing"})
+	assert.Equal(t, operations.Deleted, results["/production/app/password"].Type)
+	assert.Equal(t, operations.Deleted, results["/production/app/missing"].Type)
+	assert.NotContains(t, kv.secrets, "production/app/password")
+}
+
+func TestNewSecretStore_RequiresAddressAndToken(t *testing.T) {
+	_, err := NewSecretStore(&application.Config{VaultAddr: "http://127.0.0.1:8200"}, zap.NewNop())
+	assert.ErrorIs(t, err, ErrMissingVaultAddr)
+}
@@ -0,0 +1,193 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"nbox/internal/application"
+	"nbox/internal/domain"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	HeaderSignature = "X-Nbox-Signature"
+	HeaderEvent     = "X-Nbox-Event"
+	HeaderDelivery  = "X-Nbox-Delivery"
+	HeaderTimestamp = "X-Nbox-Timestamp"
+
+	requestTimeout = 10 * time.Second
+)
+
+// statusError respuesta no exitosa del receptor
+type statusError struct {
+	status int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("webhook responded with status %d", e.status)
+}
+
+// Publisher delivers events to the webhooks subscribed to their type. Every delivery runs in its
+// own goroutine with exponential retries, so a slow receiver never delays the others. Deliveries
+// that exhaust their attempts are stored as dead letters.
+type Publisher struct {
+	repository  domain.WebhookRepository
+	client      *http.Client
+	maxAttempts int
+	newBackOff  func() backoff.BackOff
+	logger      *zap.Logger
+}
+
+func NewPublisher(repository domain.WebhookRepository, config *application.Config, logger *zap.Logger) *Publisher {
+	maxAttempts := config.WebhookMaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	return &Publisher{
+		repository:  repository,
+		client:      &http.Client{Timeout: requestTimeout},
+		maxAttempts: maxAttempts,
+		newBackOff: func() backoff.BackOff {
+			b := backoff.NewExponentialBackOff()
+			b.InitialInterval = time.Second
+			b.MaxElapsedTime = 0 // the number of attempts is the limit
+			return b
+		},
+		logger: logger.Named("webhook_publisher"),
+	}
+}
+
+func (p *Publisher) Publish(ctx context.Context, event domain.Event[json.RawMessage]) error {
+	webhooks, err := p.repository.FindByEventType(ctx, event.Type)
+	if err != nil {
+		p.logger.Error("ErrFindWebhooks", zap.Error(err), zap.String("eventType", string(event.Type)))
+		return err
+	}
+
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		p.logger.Error("ErrWebhookEventEncode", zap.Error(err))
+		return err
+	}
+
+	for _, webhook := range webhooks {
+		go p.deliver(context.WithoutCancel(ctx), webhook, event, body)
+	}
+
+	return nil
+}
+
+func (p *Publisher) deliver(ctx context.Context, webhook domain.Webhook, event domain.Event[json.RawMessage], body []byte) {
+	deliveryID := uuid.NewString()
+	attempts := 0
+	lastStatus := 0
+
+	operation := func() error {
+		attempts++
+		status, err := p.send(ctx, webhook, event.Type, deliveryID, body)
+		lastStatus = status
+		if err == nil {
+			return nil
+		}
+
+		// client errors will not get better by retrying, except rate limits and timeouts
+		var se *statusError
+		if errors.As(err, &se) && se.status < 500 && se.status != http.StatusTooManyRequests && se.status != http.StatusRequestTimeout {
+			return backoff.Permanent(err)
+		}
+		return err
+	}
+
+	policy := backoff.WithContext(backoff.WithMaxRetries(p.newBackOff(), uint64(p.maxAttempts-1)), ctx)
+	err := backoff.Retry(operation, policy)
+	if err == nil {
+		p.logger.Info("WebhookDelivered",
+			zap.String("webhookId", webhook.ID),
+			zap.String("deliveryId", deliveryID),
+			zap.String("eventType", string(event.Type)),
+			zap.Int("attempts", attempts),
+		)
+		return
+	}
+
+	p.logger.Error("ErrWebhookDelivery",
+		zap.Error(err),
+		zap.String("webhookId", webhook.ID),
+		zap.String("deliveryId", deliveryID),
+		zap.Int("attempts", attempts),
+	)
+
+	now := time.Now().UTC()
+	deadLetter := domain.WebhookDelivery{
+		// sorts by failure time inside the webhook partition
+		ID:            fmt.Sprintf("%020d-%s", now.UnixNano(), deliveryID),
+		WebhookID:     webhook.ID,
+		URL:           webhook.URL,
+		EventType:     event.Type,
+		TransactionId: event.TransactionId,
+		Payload:       body,
+		Attempts:      attempts,
+		LastStatus:    lastStatus,
+		LastError:     err.Error(),
+		FailedAt:      now,
+	}
+
+	if err = p.repository.SaveFailedDelivery(ctx, deadLetter); err != nil {
+		p.logger.Error("ErrSaveFailedDelivery", zap.Error(err), zap.String("webhookId", webhook.ID))
+	}
+}
+
+func (p *Publisher) send(ctx context.Context, webhook domain.Webhook, eventType domain.EventType, deliveryID string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, backoff.Permanent(err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, string(eventType))
+	req.Header.Set(HeaderDelivery, deliveryID)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(webhook.Secret, timestamp, body))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, &statusError{status: resp.StatusCode}
+	}
+
+	return resp.StatusCode, nil
+}
+
+// Sign firma HMAC-SHA256 de timestamp + "." + body, con el formato "sha256=<hex>". Firmar el
+// timestamp impide reenviar una entrega capturada con un X-Nbox-Timestamp nuevo.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
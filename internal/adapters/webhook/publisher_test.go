@@ -0,0 +1,189 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"nbox/internal/application"
+	"nbox/internal/domain"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type mockWebhookRepository struct {
+	mu         sync.Mutex
+	webhooks   []domain.Webhook
+	deadLetter []domain.WebhookDelivery
+}
+
+func (m *mockWebhookRepository) FindByEventType(_ context.Context, eventType domain.EventType) ([]domain.Webhook, error) {
+	var result []domain.Webhook
+	for _, w := range m.webhooks {
+		if w.Subscribed(eventType) {
+			result = append(result, w)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockWebhookRepository) List(_ context.Context) ([]domain.Webhook, error) {
+	return m.webhooks, nil
+}
+
+func (m *mockWebhookRepository) Create(_ context.Context, webhook domain.Webhook) error {
+	m.webhooks = append(m.webhooks, webhook)
+	return nil
+}
+
+func (m *mockWebhookRepository) Delete(_ context.Context, _ string) error {
+	return nil
+}
+
+func (m *mockWebhookRepository) SaveFailedDelivery(_ context.Context, delivery domain.WebhookDelivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deadLetter = append(m.deadLetter, delivery)
+	return nil
+}
+
+func (m *mockWebhookRepository) ListFailedDeliveries(_ context.Context, _ string) ([]domain.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.deadLetter, nil
+}
+
+func newTestPublisher(repository domain.WebhookRepository, maxAttempts int) *Publisher {
+	p := NewPublisher(repository, &application.Config{WebhookMaxAttempts: maxAttempts}, zap.NewNop())
+	p.newBackOff = func() backoff.BackOff { return &backoff.ZeroBackOff{} }
+	return p
+}
+
+func testEvent() domain.Event[json.RawMessage] {
+	return domain.Event[json.RawMessage]{
+		Type:          domain.EventEntryDeleted,
+		TransactionId: "tx-1",
+		Username:      "admin",
+		Payload:       json.RawMessage(`{"key":"development/app/db"}`),
+	}
+}
+
+func TestPublisher_Deliver_SignsPayload(t *testing.T) {
+	var received atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp := r.Header.Get(HeaderTimestamp)
+		assert.Equal(t, Sign("s3cr3t", timestamp, body), r.Header.Get(HeaderSignature))
+
+		// what a receiver computes
+		mac := hmac.New(sha256.New, []byte("s3cr3t"))
+		mac.Write([]byte(timestamp + "." + string(body)))
+		assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), r.Header.Get(HeaderSignature))
+		assert.NotEqual(t, Sign("s3cr3t", "0", body), r.Header.Get(HeaderSignature))
+		assert.Equal(t, string(domain.EventEntryDeleted), r.Header.Get(HeaderEvent))
+		assert.NotEmpty(t, r.Header.Get(HeaderDelivery))
+		assert.NotEmpty(t, r.Header.Get(HeaderTimestamp))
+		received.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	repository := &mockWebhookRepository{}
+	p := newTestPublisher(repository, 3)
+
+	event := testEvent()
+	body, _ := json.Marshal(event)
+	p.deliver(context.Background(), domain.Webhook{ID: "w1", URL: server.URL, Secret: "s3cr3t"}, event, body)
+
+	assert.Equal(t, int32(1), received.Load())
+	assert.Empty(t, repository.deadLetter)
+}
+
+func TestPublisher_Deliver_RetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	repository := &mockWebhookRepository{}
+	p := newTestPublisher(repository, 5)
+
+	event := testEvent()
+	body, _ := json.Marshal(event)
+	p.deliver(context.Background(), domain.Webhook{ID: "w1", URL: server.URL}, event, body)
+
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Empty(t, repository.deadLetter)
+}
+
+func TestPublisher_Deliver_DeadLetterAfterMaxAttempts(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	repository := &mockWebhookRepository{}
+	p := newTestPublisher(repository, 3)
+
+	event := testEvent()
+	body, _ := json.Marshal(event)
+	p.deliver(context.Background(), domain.Webhook{ID: "w1", URL: server.URL}, event, body)
+
+	assert.Equal(t, int32(3), calls.Load())
+	require.Len(t, repository.deadLetter, 1)
+
+	deadLetter := repository.deadLetter[0]
+	assert.Equal(t, "w1", deadLetter.WebhookID)
+	assert.Equal(t, 3, deadLetter.Attempts)
+	assert.Equal(t, http.StatusServiceUnavailable, deadLetter.LastStatus)
+	assert.Equal(t, "tx-1", deadLetter.TransactionId)
+	assert.JSONEq(t, string(body), string(deadLetter.Payload))
+}
+
+func TestPublisher_Deliver_ClientErrorIsNotRetried(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer server.Close()
+
+	repository := &mockWebhookRepository{}
+	p := newTestPublisher(repository, 5)
+
+	event := testEvent()
+	body, _ := json.Marshal(event)
+	p.deliver(context.Background(), domain.Webhook{ID: "w1", URL: server.URL}, event, body)
+
+	assert.Equal(t, int32(1), calls.Load())
+	require.Len(t, repository.deadLetter, 1)
+	assert.Equal(t, 1, repository.deadLetter[0].Attempts)
+}
+
+func TestPublisher_Publish_OnlySubscribedWebhooks(t *testing.T) {
+	repository := &mockWebhookRepository{webhooks: []domain.Webhook{
+		{ID: "w1", URL: "http://127.0.0.1:0", Events: []domain.EventType{domain.EventTemplateCreated}},
+	}}
+	p := newTestPublisher(repository, 1)
+
+	// no subscriber for entry.deleted: nothing is delivered nor dead-lettered
+	require.NoError(t, p.Publish(context.Background(), testEvent()))
+	assert.Empty(t, repository.deadLetter)
+}
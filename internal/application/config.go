@@ -18,6 +18,38 @@ const (
 	SourceFile CredentialsSource = "file"
 )
 
+type StorageBackend string
+
+const (
+	// StorageBackendAWS usa DynamoDB, Parameter Store y S3 (producción)
+	StorageBackendAWS StorageBackend = "aws"
+	// StorageBackendLocal usa un archivo bbolt embebido (desarrollo/CI)
+	StorageBackendLocal StorageBackend = "local"
+)
+
+const (
+	// EventSinkSSE broker en memoria de la UI (/events)
+	EventSinkSSE = "sse"
+	// EventSinkWebhook webhooks registrados en /api/webhook
+	EventSinkWebhook = "webhook"
+	// EventSinkAudit archivo JSON lines (NBOX_EVENT_AUDIT_PATH)
+	EventSinkAudit = "audit"
+	// EventSinkStdout JSON lines por la salida estándar
+	EventSinkStdout = "stdout"
+)
+
+// SecretRoute secure entries under Prefix are stored in Backend (ssm, secretsmanager, vault o local)
+type SecretRoute struct {
+	Prefix  string
+	Backend string
+}
+
+// EventSinkConfig sink that receives events; an empty Events list receives every event type
+type EventSinkConfig struct {
+	Name   string
+	Events []string
+}
+
 type CredentialsLoaderConfig struct {
 	Source    CredentialsSource
 	EnvVarKey string // Nombre de la variable de entorno
@@ -26,19 +58,51 @@ type CredentialsLoaderConfig struct {
 }
 
 type Config struct {
-	BucketName             string   `pkl:"bucketName"`
-	EntryTableName         string   `pkl:"entryTableName"`
-	TrackingEntryTableName string   `pkl:"trackingEntryTableName"`
-	TypeValidatorTableName string   `pkl:"typeValidatorTableName"`
-	BoxTableName           string   `pkl:"boxTableName"`
-	RegionName             string   `pkl:"regionName"`
-	AccountId              string   `pkl:"accountId"`
-	ParameterStoreKeyId    string   `pkl:"parameterStoreKeyId"`
-	ParameterShortArn      bool     `pkl:"parameterShortArn"`
-	DefaultPrefix          string   `pkl:"defaultPrefix"`
-	AllowedPrefixes        []string `pkl:"allowedPrefixes"`
-	HmacSecretKey          []byte
-	CredentialsLoader      CredentialsLoaderConfig
+	BucketName               string         `pkl:"bucketName"`
+	EntryTableName           string         `pkl:"entryTableName"`
+	EntryValidatorIndex      string         `pkl:"entryValidatorIndex"`
+	EntryKeyIndex            string         `pkl:"entryKeyIndex"`
+	TrackingEntryTableName   string         `pkl:"trackingEntryTableName"`
+	TypeValidatorTableName   string         `pkl:"typeValidatorTableName"`
+	BoxTableName             string         `pkl:"boxTableName"`
+	RegionName               string         `pkl:"regionName"`
+	AccountId                string         `pkl:"accountId"`
+	ParameterStoreKeyId      string         `pkl:"parameterStoreKeyId"`
+	ParameterShortArn        bool           `pkl:"parameterShortArn"`
+	DefaultPrefix            string         `pkl:"defaultPrefix"`
+	AllowedPrefixes          []string       `pkl:"allowedPrefixes"`
+	KeyCharset               string         `pkl:"keyCharset"`
+	KeyMaxDepth              int            `pkl:"keyMaxDepth"`
+	KeyMaxLength             int            `pkl:"keyMaxLength"`
+	ValueMaxLength           int            `pkl:"valueMaxLength"`
+	BatchMaxSize             int            `pkl:"batchMaxSize"`
+	StorageBackend           StorageBackend `pkl:"storageBackend"`
+	LocalStorePath           string         `pkl:"localStorePath"`
+	WebhookConfigTableName   string         `pkl:"webhookConfigTableName"`
+	WebhookDeliveryTableName string         `pkl:"webhookDeliveryTableName"`
+	WebhookMaxAttempts       int            `pkl:"webhookMaxAttempts"`
+	SecretAuditTableName     string         `pkl:"secretAuditTableName"`
+	SecretRevealReasons      []string       `pkl:"secretRevealReasons"`
+	RotationTableName        string         `pkl:"rotationTableName"`
+	RotationCheckSeconds     int            `pkl:"rotationCheckSeconds"`
+	ReconcileSeconds         int            `pkl:"reconcileSeconds"`
+	ReconcileRepair          bool           `pkl:"reconcileRepair"`
+	EventSinkBuffer          int            `pkl:"eventSinkBuffer"`
+	EventAuditPath           string         `pkl:"eventAuditPath"`
+	SSEReplayBuffer          int            `pkl:"sseReplayBuffer"`
+	SSEHeartbeatSeconds      int            `pkl:"sseHeartbeatSeconds"`
+	EventSinks               []EventSinkConfig
+	SecretRoutes             []SecretRoute
+	ResolveLayers            []string
+	SecretsManagerKeyId      string
+	SecretsManagerEndpoint   string
+	VaultAddr                string
+	VaultToken               string
+	VaultMount               string
+	LocalSecretPath          string
+	LocalSecretKey           string
+	HmacSecretKey            []byte
+	CredentialsLoader        CredentialsLoaderConfig
 }
 
 // #nosec G101
@@ -56,6 +120,12 @@ func NewConfigFromEnv() *Config {
 		strings.Split(env("NBOX_ALLOWED_PREFIXES", "development/,qa/,beta/,staging/,sandbox/,production/"), ",")...,
 	)
 
+	// capas de la configuración efectiva, de menor a mayor precedencia
+	resolveLayers := envList("NBOX_RESOLVE_LAYERS")
+	if len(resolveLayers) == 0 {
+		resolveLayers = []string{defaultPrefix, ":stage", ":stage/:service"}
+	}
+
 	// Configurar estrategia de carga de credenciales
 	credSource := CredentialsSource(env("NBOX_CREDENTIALS_SOURCE", "env"))
 	credConfig := CredentialsLoaderConfig{
@@ -66,19 +136,51 @@ func NewConfigFromEnv() *Config {
 	}
 
 	return &Config{
-		BucketName:             env("NBOX_BUCKET_NAME", "nbox-store"),
-		EntryTableName:         env("NBOX_ENTRIES_TABLE_NAME", "nbox-entry-table"),
-		TrackingEntryTableName: env("NBOX_TRACKING_ENTRIES_TABLE_NAME", "nbox-tracking-entry-table"),
-		TypeValidatorTableName: env("NBOX_TYPE_VALIDATOR_TABLE_NAME", "nbox-type-validator-table"),
-		BoxTableName:           env("NBOX_BOX_TABLE_NAME", "nbox-box-table"),
-		AccountId:              env("ACCOUNT_ID", ""),
-		RegionName:             env("AWS_REGION", "us-east-1"),
-		ParameterStoreKeyId:    env("NBOX_PARAMETER_STORE_KEY_ID", ""), // KMS KEY ID
-		ParameterShortArn:      envBool("NBOX_PARAMETER_STORE_SHORT_ARN"),
-		DefaultPrefix:          defaultPrefix,
-		AllowedPrefixes:        prefixes,
-		HmacSecretKey:          []byte(env("HMAC_SECRET_KEY", "")),
-		CredentialsLoader:      credConfig,
+		BucketName:               env("NBOX_BUCKET_NAME", "nbox-store"),
+		EntryTableName:           env("NBOX_ENTRIES_TABLE_NAME", "nbox-entry-table"),
+		EntryValidatorIndex:      env("NBOX_ENTRIES_VALIDATOR_INDEX", ""),
+		EntryKeyIndex:            env("NBOX_ENTRIES_KEY_INDEX", ""),
+		TrackingEntryTableName:   env("NBOX_TRACKING_ENTRIES_TABLE_NAME", "nbox-tracking-entry-table"),
+		TypeValidatorTableName:   env("NBOX_TYPE_VALIDATOR_TABLE_NAME", "nbox-type-validator-table"),
+		BoxTableName:             env("NBOX_BOX_TABLE_NAME", "nbox-box-table"),
+		AccountId:                env("ACCOUNT_ID", ""),
+		RegionName:               env("AWS_REGION", "us-east-1"),
+		ParameterStoreKeyId:      env("NBOX_PARAMETER_STORE_KEY_ID", ""), // KMS KEY ID
+		ParameterShortArn:        envBool("NBOX_PARAMETER_STORE_SHORT_ARN"),
+		DefaultPrefix:            defaultPrefix,
+		AllowedPrefixes:          prefixes,
+		KeyCharset:               env("NBOX_KEY_CHARSET", "a-z0-9._-"),
+		KeyMaxDepth:              envInt("NBOX_KEY_MAX_DEPTH", 8),
+		KeyMaxLength:             envInt("NBOX_KEY_MAX_LENGTH", 512),
+		ValueMaxLength:           envInt("NBOX_VALUE_MAX_LENGTH", 65536),
+		BatchMaxSize:             envInt("NBOX_BATCH_MAX_SIZE", 100),
+		StorageBackend:           StorageBackend(env("NBOX_STORAGE_BACKEND", string(StorageBackendAWS))),
+		LocalStorePath:           env("NBOX_LOCAL_STORE_PATH", "nbox.db"),
+		WebhookConfigTableName:   env("NBOX_WEBHOOK_CONFIG_TABLE_NAME", "nbox-webhook-config-table"),
+		WebhookDeliveryTableName: env("NBOX_WEBHOOK_DELIVERY_TABLE_NAME", "nbox-webhook-delivery-table"),
+		WebhookMaxAttempts:       envInt("NBOX_WEBHOOK_MAX_ATTEMPTS", 5),
+		SecretAuditTableName:     env("NBOX_SECRET_AUDIT_TABLE_NAME", "nbox-secret-audit-table"),
+		SecretRevealReasons:      envList("NBOX_SECRET_REVEAL_REASONS"),
+		RotationTableName:        env("NBOX_ROTATION_TABLE_NAME", "nbox-rotation-table"),
+		RotationCheckSeconds:     envInt("NBOX_ROTATION_CHECK_SECONDS", 60),
+		ReconcileSeconds:         envInt("NBOX_RECONCILE_SECONDS", 0),
+		ReconcileRepair:          envBool("NBOX_RECONCILE_REPAIR"),
+		EventSinks:               ParseEventSinks(env("NBOX_EVENT_SINKS", "sse,webhook")),
+		SecretRoutes:             ParseSecretRoutes(env("NBOX_SECRET_BACKENDS", "")),
+		ResolveLayers:            resolveLayers,
+		SecretsManagerKeyId:      env("NBOX_SECRETS_MANAGER_KEY_ID", ""), // KMS KEY ID
+		SecretsManagerEndpoint:   env("NBOX_SECRETS_MANAGER_ENDPOINT", ""),
+		VaultAddr:                env("NBOX_VAULT_ADDR", env("VAULT_ADDR", "")),
+		VaultToken:               env("NBOX_VAULT_TOKEN", env("VAULT_TOKEN", "")),
+		VaultMount:               env("NBOX_VAULT_MOUNT", "secret"),
+		LocalSecretPath:          env("NBOX_LOCAL_SECRET_PATH", "nbox-secrets.json"),
+		LocalSecretKey:           env("NBOX_LOCAL_SECRET_KEY", ""),
+		EventSinkBuffer:          envInt("NBOX_EVENT_SINK_BUFFER", 256),
+		EventAuditPath:           env("NBOX_EVENT_AUDIT_PATH", "nbox-events.jsonl"),
+		SSEReplayBuffer:          envInt("NBOX_SSE_REPLAY_BUFFER", 1000),
+		SSEHeartbeatSeconds:      envInt("NBOX_SSE_HEARTBEAT_SECONDS", 15),
+		HmacSecretKey:            []byte(env("HMAC_SECRET_KEY", "")),
+		CredentialsLoader:        credConfig,
 	}
 }
 
@@ -98,3 +200,59 @@ func envBool(key string) bool {
 	}
 	return v
 }
+
+func envInt(key string, defaultValue int) int {
+	v, err := strconv.Atoi(env(key, strconv.Itoa(defaultValue)))
+	if err != nil {
+		return defaultValue
+	}
+	return v
+}
+
+// envList comma separated values, blanks removed
+func envList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(env(key, ""), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// ParseEventSinks format "sse,webhook:entry.upsert|entry.deleted,audit"
+func ParseEventSinks(value string) []EventSinkConfig {
+	var sinks []EventSinkConfig
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		name, filter, _ := strings.Cut(item, ":")
+		sink := EventSinkConfig{Name: strings.TrimSpace(name)}
+		for _, eventType := range strings.Split(filter, "|") {
+			if eventType = strings.TrimSpace(eventType); eventType != "" {
+				sink.Events = append(sink.Events, eventType)
+			}
+		}
+		sinks = append(sinks, sink)
+	}
+	return sinks
+}
+
+// ParseSecretRoutes "production/payments/=secretsmanager,development/=local"
+func ParseSecretRoutes(value string) []SecretRoute {
+	var routes []SecretRoute
+	for _, item := range strings.Split(value, ",") {
+		prefix, backend, found := strings.Cut(strings.TrimSpace(item), "=")
+		if !found {
+			continue
+		}
+		routes = append(routes, SecretRoute{
+			Prefix:  strings.TrimPrefix(strings.TrimSpace(prefix), "/"),
+			Backend: strings.TrimSpace(backend),
+		})
+	}
+	return routes
+}
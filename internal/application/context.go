@@ -17,3 +17,27 @@ func UserFromContext(ctx context.Context) (User, bool) {
 	u, ok := ctx.Value(userSessionKey{}).(User)
 	return u, ok
 }
+
+type actionKey struct{}
+
+// NewContextWithAction overrides the Action recorded in tracking rows (default "upsert")
+func NewContextWithAction(ctx context.Context, action string) context.Context {
+	return context.WithValue(ctx, actionKey{}, action)
+}
+
+func ActionFromContext(ctx context.Context) (string, bool) {
+	a, ok := ctx.Value(actionKey{}).(string)
+	return a, ok
+}
+
+type atomicKey struct{}
+
+// NewContextWithAtomic marks an upsert that must apply every entry or none
+func NewContextWithAtomic(ctx context.Context) context.Context {
+	return context.WithValue(ctx, atomicKey{}, true)
+}
+
+func AtomicFromContext(ctx context.Context) bool {
+	atomic, _ := ctx.Value(atomicKey{}).(bool)
+	return atomic
+}
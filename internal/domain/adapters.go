@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"nbox/internal/domain/models"
 	"nbox/internal/domain/models/operations"
+	"time"
 )
 
 //var (
@@ -16,6 +17,16 @@ type EntryUseCase interface {
 	Upsert(ctx context.Context, entries []models.Entry) []operations.Result
 }
 
+type EntryDeleteUseCase interface {
+	// Delete removes the key and its children, returns the deleted keys and the result of
+	// removing the secrets of the secure ones
+	Delete(ctx context.Context, key string) ([]string, []operations.Result, error)
+}
+
+type TemplateUseCase interface {
+	UpsertBox(ctx context.Context, box *models.Box) models.TemplateChanges
+}
+
 // TemplateAdapter store templates
 type TemplateAdapter interface {
 	UpsertBox(ctx context.Context, box *models.Box) []string
@@ -27,16 +38,53 @@ type TemplateAdapter interface {
 // EntryAdapter vars backend operations
 type EntryAdapter interface {
 	Upsert(ctx context.Context, entries []models.Entry) operations.Results
+	// UpsertAll writes every entry or none. On error nothing was written and results only hold
+	// the entries that caused it.
+	UpsertAll(ctx context.Context, entries []models.Entry) (operations.Results, error)
+	// MaxAtomicBatch entries accepted by UpsertAll, 0 without limit
+	MaxAtomicBatch() int
 	Retrieve(ctx context.Context, key string) (*models.Entry, error)
 	List(ctx context.Context, prefix string) ([]models.Entry, error)
-	Delete(ctx context.Context, key string) error
+	// ListPage entries of the path sorted by key, at most limit after the key `after`. next is the key
+	// to continue from, empty on the last page. Folder markers ("db/") are returned like any entry.
+	ListPage(ctx context.Context, path string, after string, limit int) (entries []models.Entry, next string, err error)
+	// Delete removes the key and its children, returns the keys of the deleted entries
+	Delete(ctx context.Context, key string) ([]string, error)
 	Tracking(ctx context.Context, key string) ([]models.Tracking, error)
+	// ListByTypeValidator entries of any path validated by the type validator, with the full key
+	ListByTypeValidator(ctx context.Context, name string) ([]models.Entry, error)
+	// SearchKeys entries whose full key starts with the prefix, read from the key index. Key is the
+	// full key and Value is always empty; folder markers are not returned.
+	SearchKeys(ctx context.Context, prefix string) ([]models.Entry, error)
 }
 
 // SecretAdapter vars encrypt
 type SecretAdapter interface {
 	Upsert(ctx context.Context, entries []models.Entry) operations.Results
 	RetrieveSecretValue(ctx context.Context, key string) (*models.Entry, error)
+	// RetrieveSecretValueAt returns the value the secret had at the given time
+	RetrieveSecretValueAt(ctx context.Context, key string, at time.Time) (*models.Entry, error)
+	// ListSecrets secrets written by nbox under the prefix, with their decrypted value and metadata
+	ListSecrets(ctx context.Context, prefix string) ([]models.SecretParameter, error)
+	// Delete removes the secrets by parameter name with their whole history, results are keyed by name
+	Delete(ctx context.Context, names []string) operations.Results
+	// Reference value stored in the secure entry of the key to find its secret
+	Reference(key string) string
+}
+
+// SecretAuditRepository audit trail of the secret values returned in plain text
+type SecretAuditRepository interface {
+	Save(ctx context.Context, reveal models.SecretReveal) error
+	// List records matching the filter, newest first
+	List(ctx context.Context, filter models.SecretRevealFilter) ([]models.SecretReveal, error)
+}
+
+// RotationRepository rotation policies of secure entries, keyed by entry key
+type RotationRepository interface {
+	Save(ctx context.Context, policy models.RotationPolicy) error
+	Retrieve(ctx context.Context, key string) (*models.RotationPolicy, error)
+	List(ctx context.Context) ([]models.RotationPolicy, error)
+	Delete(ctx context.Context, key string) error
 }
 
 type EventNotifier interface {
@@ -45,9 +93,12 @@ type EventNotifier interface {
 
 type WebhookRepository interface {
 	FindByEventType(ctx context.Context, eventType EventType) ([]Webhook, error)
-	// TODO
-	// Create(ctx context.Context, webhook Webhook) error
-	// Delete(ctx context.Context, webhookID string) error
+	List(ctx context.Context) ([]Webhook, error)
+	Create(ctx context.Context, webhook Webhook) error
+	Delete(ctx context.Context, webhookID string) error
+	// SaveFailedDelivery dead letter of deliveries that exhausted their retries
+	SaveFailedDelivery(ctx context.Context, delivery WebhookDelivery) error
+	ListFailedDeliveries(ctx context.Context, webhookID string) ([]WebhookDelivery, error)
 }
 
 type EventPublisher interface {
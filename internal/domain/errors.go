@@ -16,6 +16,7 @@ var (
 	ErrInsufficientPermissions  = errors.New("insufficient permissions")
 	ErrConflictsDetected        = errors.New("conflicts detected during import")
 	ErrInvalidFileFormat        = errors.New("invalid file format")
+	ErrLossyDotEnvImport        = errors.New("dotenv names can't restore keys with '-' or '.', import them as json or yaml")
 	ErrValidationFailed         = errors.New("validation failed")
 
 	// Entry errors
@@ -24,13 +25,35 @@ var (
 	ErrKeyTooLong        = errors.New("key exceeds maximum length")
 	ErrValueTooLong      = errors.New("value exceeds maximum length")
 	ErrBatchSizeTooLarge = errors.New("batch size exceeds maximum")
+	ErrVersionConflict   = errors.New("version conflict")
+	ErrRevisionNotFound  = errors.New("tracking revision not found")
+	ErrSecretHistoryGone = errors.New("the secret history was deleted with the key")
+	ErrInvalidScope      = errors.New("service and stage are required and can't contain '/'")
+	ErrInvalidCursor     = errors.New("invalid pagination cursor")
+	ErrInvalidSearch     = errors.New("invalid search")
+	ErrAtomicAborted     = errors.New("atomic batch aborted, no entry was written")
+
+	// Type validator errors
+	ErrTypeValidatorInUse = errors.New("type validator is referenced by existing entries")
+	ErrRevalidationFailed = errors.New("existing entries fail the new type validator")
 
 	// Template errors
 	ErrTemplateNotFound = errors.New("template not found")
 	ErrInvalidTemplate  = errors.New("invalid template")
 	ErrMissingVariables = errors.New("template has missing variables")
 
+	// Webhook errors
+	ErrWebhookNotFound = errors.New("webhook not found")
+	ErrInvalidWebhook  = errors.New("invalid webhook")
+
 	// Secret errors
-	ErrSecretAccessDenied = errors.New("access denied to secret")
-	ErrSecretNotFound     = errors.New("secret not found")
+	ErrSecretAccessDenied  = errors.New("access denied to secret")
+	ErrSecretNotFound      = errors.New("secret not found")
+	ErrInvalidRevealReason = errors.New("invalid reveal reason")
+	ErrSecretAuditFailed   = errors.New("secret reveal could not be audited")
+
+	// Rotation errors
+	ErrRotationPolicyNotFound = errors.New("rotation policy not found")
+	ErrInvalidRotationPolicy  = errors.New("invalid rotation policy")
+	ErrRotationGraceExpired   = errors.New("previous secret version is no longer available")
 )
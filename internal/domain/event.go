@@ -5,13 +5,40 @@ import "time"
 type EventType string
 
 const (
-	EventEntryActions EventType = "entry.upsert"
-	EventEntryDeleted EventType = "entry.deleted"
+	EventEntryActions  EventType = "entry.upsert"
+	EventEntryDeleted  EventType = "entry.deleted"
+	EventEntryRollback EventType = "entry.rollback"
+	EventEntryRetype   EventType = "entry.retype"
+
+	EventSecretRevealed EventType = "secret.revealed"
+	EventSecretRotated  EventType = "secret.rotated"
 
 	EventTemplateCreated EventType = "template.created"
 	EventTemplateUpdated EventType = "template.updated"
 )
 
+// EventTypes every event type nbox publishes
+var EventTypes = []EventType{
+	EventEntryActions,
+	EventEntryDeleted,
+	EventEntryRollback,
+	EventEntryRetype,
+	EventSecretRevealed,
+	EventSecretRotated,
+	EventTemplateCreated,
+	EventTemplateUpdated,
+}
+
+// IsValid verifica si el tipo de evento existe
+func (t EventType) IsValid() bool {
+	for _, e := range EventTypes {
+		if e == t {
+			return true
+		}
+	}
+	return false
+}
+
 type Event[T any] struct {
 	Type          EventType `json:"type"`
 	TransactionId string    `json:"transactionId"`
@@ -2,6 +2,9 @@ package domain
 
 import "strings"
 
+// ReservedKeyPrefix no segment of a key may start with it, the backends keep their locks there
+const ReservedKeyPrefix = "_"
+
 // ConvertToEnvVarName convierte una key de NBOX a formato de variable de entorno
 // example: "production/myapp/database/host" -> "PRODUCTION_MYAPP_DATABASE_HOST"
 func ConvertToEnvVarName(key string) string {
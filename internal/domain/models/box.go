@@ -9,6 +9,17 @@ type Stage struct {
 	Template Template `json:"template"`
 }
 
+// TemplateChanges paths stored by an upsert, split by whether they already existed
+type TemplateChanges struct {
+	Created []string `json:"created"`
+	Updated []string `json:"updated"`
+}
+
+// Paths all the stored paths
+func (c TemplateChanges) Paths() []string {
+	return append(append(make([]string, 0, len(c.Created)+len(c.Updated)), c.Created...), c.Updated...)
+}
+
 type Template struct {
 	Name  string `json:"name" dynamodbav:"path"` // s3 path
 	Value string `json:"value" dynamodbav:"value"`
@@ -17,6 +17,10 @@ type Entry struct {
 	Value             string `json:"value" yaml:"value" example:"value 123"`
 	Secure            bool   `json:"secure" yaml:"secure" example:"false"`
 	TypeValidatorName string `json:"type_validator_name,omitempty" yaml:"type_validator_name,omitempty" example:"json"`
+	// Version current version of the stored entry, ignored on writes
+	Version int64 `json:"version,omitempty" yaml:"version,omitempty" example:"3"`
+	// ExpectedVersion rejects the write when the stored version is different, 0 means the key must not exist
+	ExpectedVersion *int64 `json:"expected_version,omitempty" yaml:"-" example:"3"`
 	//Metadata *Metadata `json:"metadata,omitempty" yaml:"metadata,omitempty"`
 }
 
@@ -30,8 +34,28 @@ type Tracking struct {
 	Secure    bool      `json:"secure"`
 	UpdatedAt time.Time `json:"updatedAt"`
 	UpdatedBy string    `json:"updatedBy"`
+	Action    string    `json:"action,omitempty"`
+	Version   int64     `json:"version,omitempty"`
+
+	TypeValidatorName string `json:"type_validator_name,omitempty"`
 }
 
 func (e *Tracking) String() string {
 	return fmt.Sprintf("Key: %s. Value: %s", e.Key, e.Value)
 }
+
+// RetypeRequest moves an entry to another type validator. Without value the current value is
+// validated against the new validator; an empty type_validator_name removes the validator.
+type RetypeRequest struct {
+	Key               string  `json:"key" example:"development/service/port"`
+	TypeValidatorName string  `json:"type_validator_name" example:"port"`
+	Value             *string `json:"value,omitempty" example:"8080"`
+	ExpectedVersion   *int64  `json:"expected_version,omitempty"`
+}
+
+// RollbackRequest restores the value a key had at a tracking revision. Versions start again
+// when a deleted key is created again, the newest revision with the version is restored.
+type RollbackRequest struct {
+	Key     string `json:"key" example:"development/service/var-example"`
+	Version int64  `json:"version" example:"3"`
+}
@@ -14,3 +14,7 @@ func (e *ValidationError) Error() string {
 
 // ErrInvalidExportFormat error cuando el formato no es válido
 var ErrInvalidExportFormat = &ValidationError{Field: "format", Message: "invalid export format"}
+
+// ErrRecursiveEnvExport dotenv y ecs nombran cada variable con el último componente de la key,
+// las subcarpetas producirían nombres repetidos
+var ErrRecursiveEnvExport = &ValidationError{Field: "recursive", Message: "dotenv and ecs formats can't export sub folders, variable names would repeat"}
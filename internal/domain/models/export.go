@@ -54,12 +54,28 @@ func (f ExportFormat) FileExtension() string {
 type ExportOptions struct {
 	Prefix string       `json:"prefix,omitempty"`
 	Format ExportFormat `json:"format"`
+	// Service y Stage exportan la configuración efectiva en lugar del prefijo
+	Service string `json:"service,omitempty"`
+	Stage   string `json:"stage,omitempty"`
+	// Recursive incluye las keys de las subcarpetas del prefijo
+	Recursive bool `json:"recursive,omitempty"`
+	// Limit y Next exportan una página del prefijo, Next es el cursor de la página anterior
+	Limit int    `json:"limit,omitempty"`
+	Next  string `json:"next,omitempty"`
+}
+
+// Resolved exporta la configuración efectiva de un servicio
+func (o *ExportOptions) Resolved() bool {
+	return o.Service != "" || o.Stage != ""
 }
 
 func (o *ExportOptions) Validate() error {
 	if !o.Format.IsValid() {
 		return ErrInvalidExportFormat
 	}
+	if o.Recursive && (o.Format == ExportFormatDotEnv || o.Format == ExportFormatECSTaskDef) {
+		return ErrRecursiveEnvExport
+	}
 	return nil
 }
 
@@ -68,4 +84,6 @@ type ExportResult struct {
 	Entries []Entry `json:"entries" yaml:"entries"`
 	Content []byte  `json:"-" yaml:"-"`
 	Size    int64   `json:"-" yaml:"-"`
+	// Next cursor de la siguiente página, vacío en la última
+	Next string `json:"-" yaml:"-"`
 }
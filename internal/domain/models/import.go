@@ -0,0 +1,60 @@
+package models
+
+import "nbox/internal/domain/models/operations"
+
+type OverwriteStrategy string
+
+const (
+	// OverwriteSkip keeps the stored value of existing keys
+	OverwriteSkip OverwriteStrategy = "skip"
+	// OverwriteAll replaces the stored value of existing keys
+	OverwriteAll OverwriteStrategy = "overwrite"
+	// OverwriteFail aborts the whole import when any existing key would change
+	OverwriteFail OverwriteStrategy = "fail"
+)
+
+// IsValid verifica si la estrategia es válida
+func (s OverwriteStrategy) IsValid() bool {
+	switch s {
+	case OverwriteSkip, OverwriteAll, OverwriteFail:
+		return true
+	}
+	return false
+}
+
+// IsImportable verifica si el formato se puede importar.
+// ECS task definitions only keep env var names, they cannot be mapped back to keys.
+func (f ExportFormat) IsImportable() bool {
+	switch f {
+	case ExportFormatJSON, ExportFormatYAML, ExportFormatDotEnv:
+		return true
+	}
+	return false
+}
+
+// ImportOptions opciones para importación
+type ImportOptions struct {
+	// Prefix is prepended to dotenv variable names; json/yaml files carry full keys
+	Prefix   string            `json:"prefix,omitempty"`
+	Format   ExportFormat      `json:"format"`
+	Strategy OverwriteStrategy `json:"strategy"`
+	DryRun   bool              `json:"dryRun"`
+}
+
+// ImportConflict an entry of the file whose key already exists with a different value
+type ImportConflict struct {
+	Key           string `json:"key"`
+	Secure        bool   `json:"secure"`
+	CurrentSecure bool   `json:"currentSecure"`
+}
+
+// ImportResult resultado de una importación
+type ImportResult struct {
+	DryRun    bool                `json:"dryRun"`
+	Strategy  OverwriteStrategy   `json:"strategy"`
+	Total     int                 `json:"total"`
+	Conflicts []ImportConflict    `json:"conflicts"`
+	Unchanged []string            `json:"unchanged"`
+	Skipped   []string            `json:"skipped"`
+	Results   []operations.Result `json:"results"`
+}
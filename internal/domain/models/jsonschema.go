@@ -0,0 +1,407 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// jsonSchema subset of JSON Schema used by the jsonschema type validators: type, enum, const,
+// properties, required, additionalProperties, items, minItems/maxItems, minimum/maximum,
+// exclusiveMinimum/exclusiveMaximum, minLength/maxLength, pattern, allOf/anyOf/oneOf and not.
+// References ($ref) and any other keyword are rejected when the schema is compiled, so a schema
+// never accepts values it was meant to reject; annotations ($schema, title, ...) are allowed.
+type jsonSchema struct {
+	Type                 schemaTypes            `json:"type"`
+	Enum                 []any                  `json:"enum"`
+	Const                *json.RawMessage       `json:"const"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	Required             []string               `json:"required"`
+	AdditionalProperties *additionalProperties  `json:"additionalProperties"`
+	Items                *jsonSchema            `json:"items"`
+	MinItems             *int                   `json:"minItems"`
+	MaxItems             *int                   `json:"maxItems"`
+	Minimum              *float64               `json:"minimum"`
+	Maximum              *float64               `json:"maximum"`
+	ExclusiveMinimum     *float64               `json:"exclusiveMinimum"`
+	ExclusiveMaximum     *float64               `json:"exclusiveMaximum"`
+	MinLength            *int                   `json:"minLength"`
+	MaxLength            *int                   `json:"maxLength"`
+	Pattern              string                 `json:"pattern"`
+	AllOf                []*jsonSchema          `json:"allOf"`
+	AnyOf                []*jsonSchema          `json:"anyOf"`
+	OneOf                []*jsonSchema          `json:"oneOf"`
+	Not                  *jsonSchema            `json:"not"`
+	Ref                  string                 `json:"$ref"`
+
+	pattern *regexp.Regexp
+}
+
+var schemaTypeNames = []string{"null", "boolean", "object", "array", "number", "integer", "string"}
+
+// schemaKeywords validation keywords of the subset and annotations without effect on validation
+var schemaKeywords = []string{
+	"type", "enum", "const", "properties", "required", "additionalProperties", "items",
+	"minItems", "maxItems", "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum",
+	"minLength", "maxLength", "pattern", "allOf", "anyOf", "oneOf", "not", "$ref",
+	"$schema", "$id", "$comment", "title", "description", "default", "examples", "deprecated", "readOnly", "writeOnly",
+}
+
+// UnmarshalJSON rejects the keywords outside the subset, at every level of the schema
+func (s *jsonSchema) UnmarshalJSON(data []byte) error {
+	var keywords map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keywords); err != nil {
+		return errors.New("schema must be an object")
+	}
+	for keyword := range keywords {
+		if !slices.Contains(schemaKeywords, keyword) {
+			return fmt.Errorf("unsupported keyword '%s'", keyword)
+		}
+	}
+
+	type plain jsonSchema
+	return json.Unmarshal(data, (*plain)(s))
+}
+
+// schemaTypes "type" puede ser un string o una lista
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = schemaTypes{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return errors.New("type must be a string or a list of strings")
+	}
+	*t = list
+	return nil
+}
+
+// additionalProperties puede ser un booleano o un schema
+type additionalProperties struct {
+	allowed bool
+	schema  *jsonSchema
+}
+
+func (a *additionalProperties) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &a.allowed); err == nil {
+		return nil
+	}
+
+	a.allowed = true
+	a.schema = &jsonSchema{}
+	return json.Unmarshal(data, a.schema)
+}
+
+// SchemaCache compiled schemas of the stored validators, by name. The adapters drop the validator
+// on update or delete; a cached schema is only used while the stored one is the same, so changes
+// made by other instances are compiled again.
+type SchemaCache struct {
+	schemas sync.Map
+}
+
+type cachedSchema struct {
+	raw    string
+	schema *jsonSchema
+}
+
+// Compile sets the compiled schema of the validator, compiling it when it is not cached.
+// Validators whose schema doesn't compile are left as they are, Validate reports the error.
+func (c *SchemaCache) Compile(tv *TypeValidator) {
+	if tv.Kind != KindJSONSchema {
+		return
+	}
+	if cached, ok := c.schemas.Load(tv.Name); ok && cached.(cachedSchema).raw == string(tv.Schema) {
+		tv.schema = cached.(cachedSchema).schema
+		return
+	}
+	if err := tv.Compile(); err != nil {
+		return
+	}
+	c.schemas.Store(tv.Name, cachedSchema{raw: string(tv.Schema), schema: tv.schema})
+}
+
+// Drop forgets the schema of the validator
+func (c *SchemaCache) Drop(name string) {
+	c.schemas.Delete(name)
+}
+
+func compileJSONSchema(raw json.RawMessage) (*jsonSchema, error) {
+	schema := &jsonSchema{}
+	if err := json.Unmarshal(raw, schema); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+
+	if err := schema.compile("#"); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// compile verifies the keywords and compiles the patterns of the schema and its subschemas
+func (s *jsonSchema) compile(path string) error {
+	if s.Ref != "" {
+		return fmt.Errorf("%s: $ref is not supported", path)
+	}
+
+	for _, t := range s.Type {
+		if !slices.Contains(schemaTypeNames, t) {
+			return fmt.Errorf("%s: unknown type '%s'", path, t)
+		}
+	}
+
+	if s.Pattern != "" {
+		pattern, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("%s: invalid pattern: %w", path, err)
+		}
+		s.pattern = pattern
+	}
+
+	children := map[string]*jsonSchema{}
+	for name, property := range s.Properties {
+		children[path+"/properties/"+name] = property
+	}
+	if s.AdditionalProperties != nil && s.AdditionalProperties.schema != nil {
+		children[path+"/additionalProperties"] = s.AdditionalProperties.schema
+	}
+	if s.Items != nil {
+		children[path+"/items"] = s.Items
+	}
+	if s.Not != nil {
+		children[path+"/not"] = s.Not
+	}
+	for keyword, list := range map[string][]*jsonSchema{"allOf": s.AllOf, "anyOf": s.AnyOf, "oneOf": s.OneOf} {
+		for i, child := range list {
+			children[fmt.Sprintf("%s/%s/%d", path, keyword, i)] = child
+		}
+	}
+
+	for childPath, child := range children {
+		if child == nil {
+			return fmt.Errorf("%s: schema can't be null", childPath)
+		}
+		if err := child.compile(childPath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *jsonSchema) validate(document []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("value is not valid JSON")
+	}
+	if decoder.More() {
+		return fmt.Errorf("value is not valid JSON")
+	}
+
+	return s.validateValue(value, "$")
+}
+
+func (s *jsonSchema) validateValue(value any, path string) error {
+	if len(s.Type) > 0 && !slices.ContainsFunc(s.Type, func(t string) bool { return matchesType(t, value) }) {
+		return fmt.Errorf("%s: must be %s", path, strings.Join(s.Type, " or "))
+	}
+
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(e any) bool { return jsonEqual(e, value) }) {
+		return fmt.Errorf("%s: must be one of %v", path, s.Enum)
+	}
+
+	if s.Const != nil {
+		var constant any
+		_ = json.Unmarshal(*s.Const, &constant)
+		if !jsonEqual(constant, value) {
+			return fmt.Errorf("%s: must be %s", path, string(*s.Const))
+		}
+	}
+
+	switch v := value.(type) {
+	case json.Number:
+		if err := s.validateNumber(v, path); err != nil {
+			return err
+		}
+	case string:
+		if err := s.validateString(v, path); err != nil {
+			return err
+		}
+	case []any:
+		if err := s.validateArray(v, path); err != nil {
+			return err
+		}
+	case map[string]any:
+		if err := s.validateObject(v, path); err != nil {
+			return err
+		}
+	}
+
+	return s.validateCombinators(value, path)
+}
+
+func (s *jsonSchema) validateNumber(value json.Number, path string) error {
+	n, _ := value.Float64()
+	if s.Minimum != nil && n < *s.Minimum {
+		return fmt.Errorf("%s: must be >= %v", path, *s.Minimum)
+	}
+	if s.Maximum != nil && n > *s.Maximum {
+		return fmt.Errorf("%s: must be <= %v", path, *s.Maximum)
+	}
+	if s.ExclusiveMinimum != nil && n <= *s.ExclusiveMinimum {
+		return fmt.Errorf("%s: must be > %v", path, *s.ExclusiveMinimum)
+	}
+	if s.ExclusiveMaximum != nil && n >= *s.ExclusiveMaximum {
+		return fmt.Errorf("%s: must be < %v", path, *s.ExclusiveMaximum)
+	}
+	return nil
+}
+
+func (s *jsonSchema) validateString(value string, path string) error {
+	length := utf8.RuneCountInString(value)
+	if s.MinLength != nil && length < *s.MinLength {
+		return fmt.Errorf("%s: must have at least %d characters", path, *s.MinLength)
+	}
+	if s.MaxLength != nil && length > *s.MaxLength {
+		return fmt.Errorf("%s: must have at most %d characters", path, *s.MaxLength)
+	}
+	if s.pattern != nil && !s.pattern.MatchString(value) {
+		return fmt.Errorf("%s: must match %s", path, s.Pattern)
+	}
+	return nil
+}
+
+func (s *jsonSchema) validateArray(value []any, path string) error {
+	if s.MinItems != nil && len(value) < *s.MinItems {
+		return fmt.Errorf("%s: must have at least %d items", path, *s.MinItems)
+	}
+	if s.MaxItems != nil && len(value) > *s.MaxItems {
+		return fmt.Errorf("%s: must have at most %d items", path, *s.MaxItems)
+	}
+	if s.Items != nil {
+		for i, item := range value {
+			if err := s.Items.validateValue(item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *jsonSchema) validateObject(value map[string]any, path string) error {
+	for _, name := range s.Required {
+		if _, ok := value[name]; !ok {
+			return fmt.Errorf("%s: missing required property '%s'", path, name)
+		}
+	}
+
+	// orden estable de los errores
+	names := make([]string, 0, len(value))
+	for name := range value {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		propertyPath := path + "." + name
+		if property, ok := s.Properties[name]; ok {
+			if err := property.validateValue(value[name], propertyPath); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if s.AdditionalProperties == nil {
+			continue
+		}
+		if !s.AdditionalProperties.allowed {
+			return fmt.Errorf("%s: property is not allowed", propertyPath)
+		}
+		if s.AdditionalProperties.schema != nil {
+			if err := s.AdditionalProperties.schema.validateValue(value[name], propertyPath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *jsonSchema) validateCombinators(value any, path string) error {
+	for _, schema := range s.AllOf {
+		if err := schema.validateValue(value, path); err != nil {
+			return err
+		}
+	}
+
+	if len(s.AnyOf) > 0 && !slices.ContainsFunc(s.AnyOf, func(schema *jsonSchema) bool {
+		return schema.validateValue(value, path) == nil
+	}) {
+		return fmt.Errorf("%s: must match at least one schema of anyOf", path)
+	}
+
+	if len(s.OneOf) > 0 {
+		matches := 0
+		for _, schema := range s.OneOf {
+			if schema.validateValue(value, path) == nil {
+				matches++
+			}
+		}
+		if matches != 1 {
+			return fmt.Errorf("%s: must match exactly one schema of oneOf, matched %d", path, matches)
+		}
+	}
+
+	if s.Not != nil && s.Not.validateValue(value, path) == nil {
+		return fmt.Errorf("%s: must not match the schema of not", path)
+	}
+
+	return nil
+}
+
+func matchesType(schemaType string, value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return schemaType == "null"
+	case bool:
+		return schemaType == "boolean"
+	case string:
+		return schemaType == "string"
+	case []any:
+		return schemaType == "array"
+	case map[string]any:
+		return schemaType == "object"
+	case json.Number:
+		if schemaType == "number" {
+			return true
+		}
+		n, err := v.Float64()
+		return schemaType == "integer" && err == nil && n == math.Trunc(n)
+	}
+	return false
+}
+
+// jsonEqual compara valores JSON, los números por su valor (1 == 1.0)
+func jsonEqual(a, b any) bool {
+	normalize := func(v any) any {
+		raw, _ := json.Marshal(v)
+		var out any
+		_ = json.Unmarshal(raw, &out)
+		return out
+	}
+	return reflect.DeepEqual(normalize(a), normalize(b))
+}
@@ -0,0 +1,138 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestJSONSchema_Validate(t *testing.T) {
+	schema := `{
+		"type": "object",
+		"required": ["host", "port"],
+		"additionalProperties": false,
+		"properties": {
+			"host": {"type": "string", "minLength": 1},
+			"port": {"type": "integer", "minimum": 1, "maximum": 65535},
+			"mode": {"enum": ["primary", "replica"]},
+			"tags": {"type": "array", "items": {"type": "string", "pattern": "^[a-z]+$"}, "maxItems": 2},
+			"timeout": {"type": ["number", "null"], "exclusiveMinimum": 0}
+		}
+	}`
+	validator := TypeValidator{Name: "db", Kind: KindJSONSchema, Schema: []byte(schema)}
+
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{name: "valid", value: `{"host":"db.local","port":5432,"mode":"replica","tags":["a","b"],"timeout":1.5}`},
+		{name: "null allowed by type list", value: `{"host":"db.local","port":5432,"timeout":null}`},
+		{name: "integer written as decimal", value: `{"host":"db.local","port":5432.0}`},
+		{name: "not json", value: `{host}`, wantErr: true},
+		{name: "trailing data", value: `{"host":"h","port":1} {}`, wantErr: true},
+		{name: "wrong root type", value: `[]`, wantErr: true},
+		{name: "missing required", value: `{"host":"db.local"}`, wantErr: true},
+		{name: "additional property", value: `{"host":"db.local","port":1,"user":"root"}`, wantErr: true},
+		{name: "port out of range", value: `{"host":"db.local","port":70000}`, wantErr: true},
+		{name: "port not integer", value: `{"host":"db.local","port":1.5}`, wantErr: true},
+		{name: "enum mismatch", value: `{"host":"db.local","port":1,"mode":"arbiter"}`, wantErr: true},
+		{name: "item pattern", value: `{"host":"db.local","port":1,"tags":["A"]}`, wantErr: true},
+		{name: "too many items", value: `{"host":"db.local","port":1,"tags":["a","b","c"]}`, wantErr: true},
+		{name: "exclusive minimum", value: `{"host":"db.local","port":1,"timeout":0}`, wantErr: true},
+		{name: "empty host", value: `{"host":"","port":1}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateValue(&validator, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateValue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestJSONSchema_Combinators(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		value   string
+		wantErr bool
+	}{
+		{name: "anyOf match", schema: `{"anyOf":[{"type":"string"},{"type":"integer"}]}`, value: `3`},
+		{name: "anyOf no match", schema: `{"anyOf":[{"type":"string"},{"type":"integer"}]}`, value: `true`, wantErr: true},
+		{name: "oneOf matches both", schema: `{"oneOf":[{"type":"number"},{"type":"integer"}]}`, value: `3`, wantErr: true},
+		{name: "oneOf matches one", schema: `{"oneOf":[{"type":"number"},{"type":"integer"}]}`, value: `3.5`},
+		{name: "allOf", schema: `{"allOf":[{"minimum":1},{"maximum":5}]}`, value: `7`, wantErr: true},
+		{name: "not", schema: `{"not":{"const":"root"}}`, value: `"root"`, wantErr: true},
+		{name: "additionalProperties schema", schema: `{"additionalProperties":{"type":"integer"}}`, value: `{"a":1,"b":"x"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := TypeValidator{Name: "s", Kind: KindJSONSchema, Schema: []byte(tt.schema)}
+			if err := validator.Check(); err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+
+			err := validator.Validate(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestJSONSchema_RejectsUnsupportedKeywords(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		wantErr bool
+	}{
+		{name: "annotations", schema: `{"$schema": "https://json-schema.org/draft/2020-12/schema", "title": "db", "type": "string", "default": "x"}`},
+		{name: "format", schema: `{"type": "string", "format": "email"}`, wantErr: true},
+		{name: "nested uniqueItems", schema: `{"properties": {"tags": {"type": "array", "uniqueItems": true}}}`, wantErr: true},
+		{name: "multipleOf in items", schema: `{"items": {"multipleOf": 2}}`, wantErr: true},
+		{name: "if then else", schema: `{"if": {"type": "string"}, "then": {"minLength": 1}}`, wantErr: true},
+		{name: "additionalProperties schema", schema: `{"additionalProperties": {"minProperties": 1}}`, wantErr: true},
+		{name: "ref", schema: `{"$ref": "#/$defs/x"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := TypeValidator{Name: "schema", Kind: KindJSONSchema, Schema: []byte(tt.schema)}
+			err := validator.Check()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSchemaCache(t *testing.T) {
+	var cache SchemaCache
+	stored := TypeValidator{Name: "slug", Kind: KindJSONSchema, Schema: []byte(`{"type": "string", "pattern": "^[a-z]+$"}`)}
+
+	first, second := stored, stored
+	cache.Compile(&first)
+	cache.Compile(&second)
+	if first.schema == nil || first.schema != second.schema {
+		t.Error("the schema must be compiled once")
+	}
+
+	// updated by another instance
+	changed := TypeValidator{Name: "slug", Kind: KindJSONSchema, Schema: []byte(`{"type": "string", "pattern": "^[0-9]+$"}`)}
+	cache.Compile(&changed)
+	if changed.schema == first.schema {
+		t.Error("a changed schema must be compiled again")
+	}
+	if err := changed.Validate("abc"); err == nil {
+		t.Error("the changed schema must be used")
+	}
+
+	cache.Drop("slug")
+	dropped := stored
+	cache.Compile(&dropped)
+	if dropped.schema == first.schema || dropped.schema == changed.schema {
+		t.Error("a dropped validator must be compiled again")
+	}
+}
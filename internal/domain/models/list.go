@@ -0,0 +1,18 @@
+package models
+
+// ListOptions listing of the entries under a prefix, one page at a time
+type ListOptions struct {
+	Prefix string
+	// Recursive walks the folders under the prefix; folder markers are not returned
+	Recursive bool
+	// Limit max entries of the page
+	Limit int
+	// Next cursor returned by the previous page, empty for the first one
+	Next string
+}
+
+// EntryPage page of a listing, Next is empty on the last page
+type EntryPage struct {
+	Entries []Entry `json:"entries"`
+	Next    string  `json:"next,omitempty" example:"eyJwYXRoIjoicHJvZHVjdGlvbi9teWFwcCIsImtleSI6InBvcnQifQ"`
+}
@@ -4,6 +4,19 @@ import (
 	"time"
 )
 
+// Tracking actions
+const (
+	ActionUpsert   = "upsert"
+	ActionDelete   = "delete"
+	ActionRollback = "rollback"
+	// ActionRetype the entry moved to another type validator
+	ActionRetype = "retype"
+	// ActionRotate value generated by the rotation of a secret
+	ActionRotate = "rotate"
+	// ActionReconcile the parameter reference was rewritten by the reconciliation
+	ActionReconcile = "reconcile"
+)
+
 type Metadata struct {
 	Hash              string    `json:"hash" dynamodbav:"Hash,omitempty"`
 	Secure            bool      `json:"secure" dynamodbav:"Secure"`
@@ -11,4 +24,6 @@ type Metadata struct {
 	UpdatedAt         time.Time `json:"updatedAt" dynamodbav:"UpdatedAt,unixtime"`
 	UpdatedBy         string    `json:"updatedBy" dynamodbav:"UpdatedBy,omitempty"`
 	TypeValidatorName string    `json:"type_validator_name,omitempty" dynamodbav:"TypeValidatorName,omitempty"`
+	// Version increases by one on every write of the key, folder markers do not carry it
+	Version int64 `json:"version,omitempty" dynamodbav:"Version,omitempty"`
 }
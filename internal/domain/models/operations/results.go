@@ -8,6 +8,12 @@ const (
 	Created OperationType = "created"
 	Updated OperationType = "updated"
 	Error   OperationType = "error"
+	// Conflict the write was rejected because the stored version changed
+	Conflict OperationType = "conflict"
+	// Deleted the secret was removed, or did not exist anymore
+	Deleted OperationType = "deleted"
+	// Aborted the entry was valid but not written because another entry of the atomic batch failed
+	Aborted OperationType = "aborted"
 )
 
 type Result struct {
@@ -0,0 +1,39 @@
+package models
+
+// ResolvedEntry effective value of a variable for a service and stage, with the layer it comes from
+type ResolvedEntry struct {
+	Key               string `json:"key" example:"DB_HOST"`
+	Value             string `json:"value" example:"db.production.internal"`
+	Secure            bool   `json:"secure" example:"false"`
+	TypeValidatorName string `json:"type_validator_name,omitempty" example:"url-https"`
+	Version           int64  `json:"version,omitempty" example:"3"`
+	// Layer path of the layer that sets the value
+	Layer string `json:"layer" example:"production/myapp"`
+	// Overrides lower layers that also define the variable, lowest first
+	Overrides []string `json:"overrides,omitempty" example:"global,production"`
+}
+
+// ResolveResult effective configuration of a service in a stage
+type ResolveResult struct {
+	Service string `json:"service" example:"myapp"`
+	Stage   string `json:"stage" example:"production"`
+	// Layers paths overlaid, lowest precedence first
+	Layers  []string        `json:"layers" example:"global,production,production/myapp"`
+	Entries []ResolvedEntry `json:"entries"`
+}
+
+// AsEntries resolved variables as entries of their layer, for the exporters and templates
+func (r *ResolveResult) AsEntries() []Entry {
+	entries := make([]Entry, 0, len(r.Entries))
+	for _, resolved := range r.Entries {
+		entries = append(entries, Entry{
+			Path:              resolved.Layer,
+			Key:               resolved.Key,
+			Value:             resolved.Value,
+			Secure:            resolved.Secure,
+			TypeValidatorName: resolved.TypeValidatorName,
+			Version:           resolved.Version,
+		})
+	}
+	return entries
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// Secret generators
+const (
+	GeneratorRandom = "random"
+)
+
+// Charsets of the random generator
+const (
+	CharsetAlphanumeric = "alphanumeric"
+	CharsetLetters      = "letters"
+	CharsetDigits       = "digits"
+	CharsetHex          = "hex"
+	// CharsetSymbols alphanumeric plus the generator symbols (DefaultSymbols when empty)
+	CharsetSymbols = "symbols"
+)
+
+const (
+	DefaultSymbols     = "!@#$%^&*()-_=+[]{}:,.?"
+	MinGeneratedLength = 8
+	MaxGeneratedLength = 4096
+	lowerLetters       = "abcdefghijklmnopqrstuvwxyz"
+	upperLetters       = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	digits             = "0123456789"
+)
+
+// Rotation triggers
+const (
+	RotationScheduled = "scheduled"
+	RotationManual    = "manual"
+)
+
+// SecretGenerator produces the new value of a rotated secret
+type SecretGenerator struct {
+	Kind    string `json:"kind" dynamodbav:"Kind" example:"random"`
+	Length  int    `json:"length" dynamodbav:"Length" example:"32"`
+	Charset string `json:"charset,omitempty" dynamodbav:"Charset,omitempty" example:"alphanumeric"`
+	// Symbols replaces DefaultSymbols for the symbols charset
+	Symbols string `json:"symbols,omitempty" dynamodbav:"Symbols,omitempty" example:"!#%+-_"`
+}
+
+func (g SecretGenerator) alphabet() (string, error) {
+	switch g.Charset {
+	case "", CharsetAlphanumeric:
+		return lowerLetters + upperLetters + digits, nil
+	case CharsetLetters:
+		return lowerLetters + upperLetters, nil
+	case CharsetDigits:
+		return digits, nil
+	case CharsetHex:
+		return digits + "abcdef", nil
+	case CharsetSymbols:
+		symbols := g.Symbols
+		if symbols == "" {
+			symbols = DefaultSymbols
+		}
+		return lowerLetters + upperLetters + digits + symbols, nil
+	default:
+		return "", fmt.Errorf("unknown charset '%s'", g.Charset)
+	}
+}
+
+// Check the generator can produce values
+func (g SecretGenerator) Check() error {
+	if g.Kind != GeneratorRandom {
+		return fmt.Errorf("unknown generator '%s'", g.Kind)
+	}
+	if g.Length < MinGeneratedLength || g.Length > MaxGeneratedLength {
+		return fmt.Errorf("length must be between %d and %d", MinGeneratedLength, MaxGeneratedLength)
+	}
+	_, err := g.alphabet()
+	return err
+}
+
+// Generate random value using crypto/rand
+func (g SecretGenerator) Generate() (string, error) {
+	alphabet, err := g.alphabet()
+	if err != nil {
+		return "", err
+	}
+
+	chars := []rune(alphabet)
+	size := big.NewInt(int64(len(chars)))
+	value := make([]rune, g.Length)
+	for i := range value {
+		n, err := rand.Int(rand.Reader, size)
+		if err != nil {
+			return "", err
+		}
+		value[i] = chars[n.Int64()]
+	}
+	return string(value), nil
+}
+
+// RotationPolicy rotation of a secure entry. Interval and GracePeriod are Go durations ("720h").
+type RotationPolicy struct {
+	Key         string          `json:"key" dynamodbav:"Key" example:"production/service/db_password"`
+	Interval    string          `json:"interval" dynamodbav:"Interval" example:"720h"`
+	GracePeriod string          `json:"grace_period,omitempty" dynamodbav:"GracePeriod,omitempty" example:"24h"`
+	Generator   SecretGenerator `json:"generator" dynamodbav:"Generator"`
+
+	// state, ignored on writes
+	NextRotationAt time.Time  `json:"next_rotation_at" dynamodbav:"NextRotationAt"`
+	LastRotatedAt  *time.Time `json:"last_rotated_at,omitempty" dynamodbav:"LastRotatedAt,omitempty"`
+	// PreviousVersionAt write time of the value replaced by the last rotation
+	PreviousVersionAt  *time.Time `json:"previous_version_at,omitempty" dynamodbav:"PreviousVersionAt,omitempty"`
+	PreviousValidUntil *time.Time `json:"previous_valid_until,omitempty" dynamodbav:"PreviousValidUntil,omitempty"`
+	LastError          string     `json:"last_error,omitempty" dynamodbav:"LastError,omitempty"`
+	UpdatedBy          string     `json:"updated_by,omitempty" dynamodbav:"UpdatedBy,omitempty"`
+}
+
+// Due the scheduler must rotate the secret
+func (p RotationPolicy) Due(now time.Time) bool {
+	return !p.NextRotationAt.After(now)
+}
+
+// RotationResult outcome of a rotation, the new value is never returned
+type RotationResult struct {
+	Key                string     `json:"key"`
+	Trigger            string     `json:"trigger"`
+	RotatedAt          time.Time  `json:"rotated_at"`
+	NextRotationAt     time.Time  `json:"next_rotation_at"`
+	PreviousValidUntil *time.Time `json:"previous_valid_until,omitempty"`
+}
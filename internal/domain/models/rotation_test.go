@@ -0,0 +1,40 @@
+package models
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretGenerator_Generate(t *testing.T) {
+	tests := []struct {
+		name      string
+		generator SecretGenerator
+		pattern   string
+		wantErr   bool
+	}{
+		{name: "default alphanumeric", generator: SecretGenerator{Kind: GeneratorRandom, Length: 24}, pattern: `^[A-Za-z0-9]{24}$`},
+		{name: "hex", generator: SecretGenerator{Kind: GeneratorRandom, Length: 40, Charset: CharsetHex}, pattern: `^[0-9a-f]{40}$`},
+		{name: "custom symbols", generator: SecretGenerator{Kind: GeneratorRandom, Length: 16, Charset: CharsetSymbols, Symbols: "#"}, pattern: `^[A-Za-z0-9#]{16}$`},
+		{name: "too short", generator: SecretGenerator{Kind: GeneratorRandom, Length: 4}, wantErr: true},
+		{name: "unknown charset", generator: SecretGenerator{Kind: GeneratorRandom, Length: 16, Charset: "emoji"}, wantErr: true},
+		{name: "unknown kind", generator: SecretGenerator{Kind: "uuid", Length: 16}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.generator.Check()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			value, err := tt.generator.Generate()
+			require.NoError(t, err)
+			assert.Regexp(t, regexp.MustCompile(tt.pattern), value)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package models
+
+// SearchMode how the query is matched against the full key
+type SearchMode string
+
+const (
+	// SearchModeGlob path.Match pattern on the whole key, `*` does not cross `/`
+	SearchModeGlob SearchMode = "glob"
+	// SearchModeSubstring keys containing the query
+	SearchModeSubstring SearchMode = "substring"
+	// SearchModeRegex RE2 expression, unanchored unless it starts with `^`
+	SearchModeRegex SearchMode = "regex"
+)
+
+func (m SearchMode) IsValid() bool {
+	switch m {
+	case SearchModeGlob, SearchModeSubstring, SearchModeRegex:
+		return true
+	}
+	return false
+}
+
+// SearchFilter key search across the store
+type SearchFilter struct {
+	Query string
+	Mode  SearchMode
+	// Secure only secure (true) or plain (false) entries, any when nil
+	Secure            *bool
+	TypeValidatorName string
+	Limit             int
+}
+
+// SearchResult key found by a search, the value is never returned
+type SearchResult struct {
+	Key               string `json:"key" example:"production/myapp/db_host"`
+	Secure            bool   `json:"secure" example:"false"`
+	TypeValidatorName string `json:"type_validator_name,omitempty" example:"url-https"`
+	Version           int64  `json:"version,omitempty" example:"3"`
+}
+
+// SearchResponse keys found, Truncated when more keys match than the limit
+type SearchResponse struct {
+	Entries   []SearchResult `json:"entries"`
+	Truncated bool           `json:"truncated" example:"false"`
+}
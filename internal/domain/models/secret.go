@@ -0,0 +1,147 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Secret backends, a secure entry is routed to one of them by prefix (NBOX_SECRET_BACKENDS)
+const (
+	// SecretBackendSSM Parameter Store, or its bolt replacement with NBOX_STORAGE_BACKEND=local (default)
+	SecretBackendSSM = "ssm"
+	// SecretBackendSecretsManager AWS Secrets Manager
+	SecretBackendSecretsManager = "secretsmanager"
+	// SecretBackendVault HashiCorp Vault KV v2
+	SecretBackendVault = "vault"
+	// SecretBackendLocal local file sealed with a NaCl key
+	SecretBackendLocal = "local"
+)
+
+// References stored in the value of secure entries, the prefix tells the backend that owns the secret
+const (
+	ReferenceParameterArn      = "arn:aws:ssm:"
+	ReferenceSecretsManagerArn = "arn:aws:secretsmanager:"
+	ReferenceVault             = "vault:"
+	ReferenceLocal             = "local:"
+)
+
+// ParseSecretReference backend and rooted key ("/production/app/password") of a secret reference.
+// Parameter names ("/production/app/password") belong to ssm; ok is false for anything else.
+func ParseSecretReference(reference string) (backend, name string, ok bool) {
+	switch {
+	case strings.HasPrefix(reference, ReferenceParameterArn):
+		_, name, ok = strings.Cut(reference, ":parameter/")
+		return SecretBackendSSM, "/" + name, ok
+	case strings.HasPrefix(reference, ReferenceSecretsManagerArn):
+		_, name, ok = strings.Cut(reference, ":secret:")
+		return SecretBackendSecretsManager, "/" + name, ok
+	case strings.HasPrefix(reference, ReferenceVault):
+		return SecretBackendVault, "/" + strings.TrimPrefix(reference, ReferenceVault), true
+	case strings.HasPrefix(reference, ReferenceLocal):
+		return SecretBackendLocal, "/" + strings.TrimPrefix(reference, ReferenceLocal), true
+	case strings.HasPrefix(reference, "/"):
+		return SecretBackendSSM, reference, true
+	}
+	return "", "", false
+}
+
+// SecretParameter secret written by nbox, with the metadata kept in the secret store (SSM tags)
+type SecretParameter struct {
+	// Name parameter name, "/development/service/password", or the reference of the secret in other backends
+	Name              string `json:"name"`
+	Key               string `json:"key"`
+	TypeValidatorName string `json:"type_validator_name,omitempty"`
+	Owner             string `json:"owner,omitempty"`
+	// Value decrypted value, never serialized
+	Value string `json:"-"`
+	// UpdatedAt last write of the parameter
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Secret consistency problems
+const (
+	// SecretIssueInvalidValue the decrypted value fails the declared type validator
+	SecretIssueInvalidValue = "invalid_value"
+	// SecretIssueMissingValidator the declared type validator does not exist
+	SecretIssueMissingValidator = "missing_validator"
+	// SecretIssueTypeMismatch the entry and the parameter declare different validators
+	SecretIssueTypeMismatch = "type_mismatch"
+	// SecretIssueOrphan no secure entry references the parameter
+	SecretIssueOrphan = "orphan"
+	// SecretIssueMissingParameter the parameter referenced by a secure entry does not exist
+	SecretIssueMissingParameter = "missing_parameter"
+	// SecretIssueReferenceMismatch the entry references the parameter in another format than the
+	// configured one (NBOX_PARAMETER_STORE_SHORT_ARN)
+	SecretIssueReferenceMismatch = "reference_mismatch"
+)
+
+type SecretCheckIssue struct {
+	Key               string `json:"key" example:"development/service/password"`
+	Parameter         string `json:"parameter" example:"/development/service/password"`
+	TypeValidatorName string `json:"type_validator_name,omitempty" example:"password"`
+	Problem           string `json:"problem" example:"invalid_value"`
+	Detail            string `json:"detail" example:"value does not match type validator 'password'"`
+}
+
+// SecretCheckReport result of checking the secrets against their declared type validators
+type SecretCheckReport struct {
+	Prefix  string             `json:"prefix"`
+	Checked int                `json:"checked"`
+	Issues  []SecretCheckIssue `json:"issues"`
+}
+
+// ReconcileIssue difference between the secure entries and the parameters written by nbox
+type ReconcileIssue struct {
+	Key       string `json:"key,omitempty" example:"production/service/password"`
+	Parameter string `json:"parameter" example:"/production/service/password"`
+	Problem   string `json:"problem" example:"orphan"`
+	Detail    string `json:"detail"`
+	Repaired  bool   `json:"repaired"`
+	// Error the repair failed
+	Error string `json:"error,omitempty"`
+}
+
+// ReconcileReport result of comparing the entry store with the secret store
+type ReconcileReport struct {
+	Prefix     string           `json:"prefix"`
+	Repair     bool             `json:"repair"`
+	Entries    int              `json:"entries"`
+	Parameters int              `json:"parameters"`
+	Issues     []ReconcileIssue `json:"issues"`
+}
+
+// SecretReveal audit record of a plain secret value returned by GET /api/entry/secret-value
+type SecretReveal struct {
+	ID            string    `json:"id" dynamodbav:"ID"`
+	Key           string    `json:"key" dynamodbav:"Key" example:"production/service/db_password"`
+	User          string    `json:"user" dynamodbav:"User" example:"jdoe"`
+	TransactionId string    `json:"transactionId" dynamodbav:"TransactionId"`
+	Reason        string    `json:"reason,omitempty" dynamodbav:"Reason,omitempty" example:"incident-4521"`
+	RevealedAt    time.Time `json:"revealedAt" dynamodbav:"RevealedAt"`
+	// Previous the value replaced by the last rotation was read (GET /api/rotation/previous)
+	Previous bool `json:"previous,omitempty" dynamodbav:"Previous,omitempty"`
+}
+
+// SecretRevealFilter query of the reveal audit, zero values don't filter
+type SecretRevealFilter struct {
+	Key   string
+	User  string
+	Since time.Time
+	Until time.Time
+	// Limit max records, newest first
+	Limit int
+}
+
+// Match the record passes the user and time filters (the key is resolved by the store)
+func (f SecretRevealFilter) Match(reveal SecretReveal) bool {
+	if f.User != "" && reveal.User != f.User {
+		return false
+	}
+	if !f.Since.IsZero() && reveal.RevealedAt.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && reveal.RevealedAt.After(f.Until) {
+		return false
+	}
+	return true
+}
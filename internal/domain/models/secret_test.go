@@ -0,0 +1,29 @@
+package models
+
+import "testing"
+
+func TestParseSecretReference(t *testing.T) {
+	tests := []struct {
+		reference string
+		backend   string
+		name      string
+		ok        bool
+	}{
+		{"arn:aws:ssm:us-east-1:000000000000:parameter/production/app/password", SecretBackendSSM, "/production/app/password", true},
+		{"/production/app/password", SecretBackendSSM, "/production/app/password", true},
+		{"arn:aws:secretsmanager:us-east-1:000000000000:secret:production/app/password", SecretBackendSecretsManager, "/production/app/password", true},
+		{"vault:production/app/password", SecretBackendVault, "/production/app/password", true},
+		{"local:production/app/password", SecretBackendLocal, "/production/app/password", true},
+		{"arn:aws:ssm:us-east-1:000000000000:document/x", SecretBackendSSM, "/", false},
+		{"plain-secret", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.reference, func(t *testing.T) {
+			backend, name, ok := ParseSecretReference(tt.reference)
+			if backend != tt.backend || name != tt.name || ok != tt.ok {
+				t.Errorf("ParseSecretReference() = (%q, %q, %v), want (%q, %q, %v)", backend, name, ok, tt.backend, tt.name, tt.ok)
+			}
+		})
+	}
+}
@@ -2,23 +2,205 @@ package models
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"net/mail"
+	"net/netip"
 	"net/url"
 	"regexp"
+	"slices"
 	"strconv"
+	"time"
+	"unicode/utf8"
 )
 
+type TypeValidatorKind string
+
+const (
+	// KindRegex the value must match Regex. Validators stored before kinds existed have no kind
+	// and behave as regex.
+	KindRegex TypeValidatorKind = "regex"
+	// KindEnum the value must be one of Values
+	KindEnum TypeValidatorKind = "enum"
+	// KindInteger integer between Min and Max
+	KindInteger TypeValidatorKind = "integer"
+	// KindNumber decimal number between Min and Max
+	KindNumber TypeValidatorKind = "number"
+	// KindString length between MinLength and MaxLength (runes), optionally matching Regex
+	KindString TypeValidatorKind = "string"
+	// KindDuration Go duration ("1h30m"), Min and Max in seconds
+	KindDuration TypeValidatorKind = "duration"
+	// KindBoolean "true" or "false"
+	KindBoolean TypeValidatorKind = "boolean"
+	// KindEmail a bare address, without display name
+	KindEmail TypeValidatorKind = "email"
+	// KindCIDR IPv4 or IPv6 network in CIDR notation
+	KindCIDR TypeValidatorKind = "cidr"
+	// KindJSONSchema JSON document valid against Schema
+	KindJSONSchema TypeValidatorKind = "jsonschema"
+)
+
+var TypeValidatorKinds = []TypeValidatorKind{
+	KindRegex, KindEnum, KindInteger, KindNumber, KindString, KindDuration, KindBoolean, KindEmail, KindCIDR, KindJSONSchema,
+}
+
+var ErrInvalidTypeValidator = errors.New("invalid type validator")
+
 type TypeValidator struct {
-	Name  string `json:"name" example:"email"`
-	Regex string `json:"regex" example:"^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\\.[a-zA-Z]{2,}$"`
+	Name  string            `json:"name" example:"email"`
+	Kind  TypeValidatorKind `json:"kind,omitempty" example:"regex"`
+	Regex string            `json:"regex" example:"^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\\.[a-zA-Z]{2,}$"`
+	// Values allowed values of enum
+	Values []string `json:"values,omitempty"`
+	// Min, Max range of integer, number and duration (seconds)
+	Min *float64 `json:"min,omitempty"`
+	Max *float64 `json:"max,omitempty"`
+	// MinLength, MaxLength length of string
+	MinLength *int `json:"minLength,omitempty"`
+	MaxLength *int `json:"maxLength,omitempty"`
+	// Schema JSON Schema of jsonschema
+	Schema json.RawMessage `json:"schema,omitempty" swaggertype:"object"`
+
+	// schema compiled Schema, set by Compile
+	schema *jsonSchema
 }
 
 func (tv *TypeValidator) String() string {
-	return fmt.Sprintf("Name: %s. Regex: %s", tv.Name, tv.Regex)
+	if tv.Kind == "" || tv.Kind == KindRegex {
+		return fmt.Sprintf("Name: %s. Regex: %s", tv.Name, tv.Regex)
+	}
+	return fmt.Sprintf("Name: %s. Kind: %s", tv.Name, tv.Kind)
 }
 
-// Validate validates a value against the type validator's regex
+// Check verifies the validator definition before it is stored
+func (tv *TypeValidator) Check() error {
+	if tv.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidTypeValidator)
+	}
+
+	if tv.Kind != "" && !slices.Contains(TypeValidatorKinds, tv.Kind) {
+		return fmt.Errorf("%w: unknown kind '%s'", ErrInvalidTypeValidator, tv.Kind)
+	}
+
+	if tv.Regex != "" {
+		if _, err := regexp.Compile(tv.Regex); err != nil {
+			return fmt.Errorf("%w: invalid regex pattern: %v", ErrInvalidTypeValidator, err)
+		}
+	}
+
+	if tv.Min != nil && tv.Max != nil && *tv.Min > *tv.Max {
+		return fmt.Errorf("%w: min is greater than max", ErrInvalidTypeValidator)
+	}
+
+	if (tv.MinLength != nil && *tv.MinLength < 0) || (tv.MaxLength != nil && *tv.MaxLength < 0) {
+		return fmt.Errorf("%w: lengths can't be negative", ErrInvalidTypeValidator)
+	}
+
+	if tv.MinLength != nil && tv.MaxLength != nil && *tv.MinLength > *tv.MaxLength {
+		return fmt.Errorf("%w: minLength is greater than maxLength", ErrInvalidTypeValidator)
+	}
+
+	switch tv.Kind {
+	case KindEnum:
+		if len(tv.Values) == 0 {
+			return fmt.Errorf("%w: enum requires values", ErrInvalidTypeValidator)
+		}
+	case KindJSONSchema:
+		if len(tv.Schema) == 0 {
+			return fmt.Errorf("%w: jsonschema requires a schema", ErrInvalidTypeValidator)
+		}
+		if _, err := compileJSONSchema(tv.Schema); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidTypeValidator, err)
+		}
+	}
+
+	return nil
+}
+
+// Compile compiles the schema of jsonschema once, so Validate doesn't compile it on every value
+func (tv *TypeValidator) Compile() error {
+	if tv.Kind != KindJSONSchema {
+		return nil
+	}
+	schema, err := compileJSONSchema(tv.Schema)
+	if err != nil {
+		return err
+	}
+	tv.schema = schema
+	return nil
+}
+
+// Validate validates a value against the type validator's kind (regex when there is none)
 func (tv *TypeValidator) Validate(value string) error {
+	switch tv.Kind {
+	case KindEnum:
+		if !slices.Contains(tv.Values, value) {
+			return fmt.Errorf("value must be one of %v", tv.Values)
+		}
+		return nil
+	case KindInteger:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("value is not a valid integer")
+		}
+		return tv.validateRange(float64(n))
+	case KindNumber:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+			return fmt.Errorf("value is not a valid number")
+		}
+		return tv.validateRange(n)
+	case KindString:
+		length := utf8.RuneCountInString(value)
+		if tv.MinLength != nil && length < *tv.MinLength {
+			return fmt.Errorf("value must have at least %d characters", *tv.MinLength)
+		}
+		if tv.MaxLength != nil && length > *tv.MaxLength {
+			return fmt.Errorf("value must have at most %d characters", *tv.MaxLength)
+		}
+		if tv.Regex == "" {
+			return nil
+		}
+		return tv.validateRegex(value)
+	case KindDuration:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("value is not a valid duration (e.g. 30s, 1h30m)")
+		}
+		return tv.validateRange(d.Seconds())
+	case KindBoolean:
+		if value != "true" && value != "false" {
+			return fmt.Errorf("value must be true or false")
+		}
+		return nil
+	case KindEmail:
+		address, err := mail.ParseAddress(value)
+		if err != nil || address.Address != value {
+			return fmt.Errorf("value is not a valid email address")
+		}
+		return nil
+	case KindCIDR:
+		// the error of ParsePrefix quotes the input
+		if _, err := netip.ParsePrefix(value); err != nil {
+			return fmt.Errorf("value is not a valid CIDR")
+		}
+		return nil
+	case KindJSONSchema:
+		schema := tv.schema
+		if schema == nil {
+			var err error
+			if schema, err = compileJSONSchema(tv.Schema); err != nil {
+				return fmt.Errorf("invalid schema of type validator '%s': %w", tv.Name, err)
+			}
+		}
+		return schema.validate([]byte(value))
+	default:
+		return tv.validateRegex(value)
+	}
+}
+
+func (tv *TypeValidator) validateRegex(value string) error {
 	matched, err := regexp.MatchString(tv.Regex, value)
 	if err != nil {
 		return fmt.Errorf("invalid regex pattern: %w", err)
@@ -29,6 +211,17 @@ func (tv *TypeValidator) Validate(value string) error {
 	return nil
 }
 
+// validateRange the errors never carry the value, it may be a secret
+func (tv *TypeValidator) validateRange(n float64) error {
+	if tv.Min != nil && n < *tv.Min {
+		return fmt.Errorf("value is lower than %v", *tv.Min)
+	}
+	if tv.Max != nil && n > *tv.Max {
+		return fmt.Errorf("value is greater than %v", *tv.Max)
+	}
+	return nil
+}
+
 // Built-in type validators
 var (
 	TypeValidatorString = TypeValidator{
@@ -117,3 +310,35 @@ func validateURL(value string, scheme string) error {
 	}
 	return nil
 }
+
+// RevalidationIssue entry that fails (or could not be checked against) a type validator
+type RevalidationIssue struct {
+	Key   string `json:"key" example:"development/service/email"`
+	Error string `json:"error" example:"value does not match regex"`
+}
+
+// RevalidationReport result of checking the stored entries against a new validator definition
+type RevalidationReport struct {
+	Validator string `json:"validator" example:"email"`
+	// Checked entries referencing the validator
+	Checked int `json:"checked"`
+	// Invalid entries whose current value fails the new definition
+	Invalid []RevalidationIssue `json:"invalid"`
+	// Skipped entries whose value could not be read (secure values)
+	Skipped []RevalidationIssue `json:"skipped"`
+	// Applied the validator was stored
+	Applied bool `json:"applied"`
+}
+
+// TypeValidatorUsage entries that reference a type validator
+type TypeValidatorUsage struct {
+	Validator string                    `json:"validator" example:"email"`
+	Count     int                       `json:"count"`
+	Entries   []TypeValidatorUsageEntry `json:"entries"`
+}
+
+type TypeValidatorUsageEntry struct {
+	Key     string `json:"key" example:"development/service/email"`
+	Secure  bool   `json:"secure"`
+	Version int64  `json:"version"`
+}
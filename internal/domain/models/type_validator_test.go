@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -339,3 +340,111 @@ func TestTypeValidator_String(t *testing.T) {
 		t.Errorf("TypeValidator.String() = %v, want %v", result, expected)
 	}
 }
+
+func TestTypeValidator_ValidateKinds(t *testing.T) {
+	minimum, maximum := 1.0, 65535.0
+	minDuration, maxDuration := 1.0, 3600.0
+	minLength, maxLength := 3, 5
+
+	port := TypeValidator{Name: "port", Kind: KindInteger, Min: &minimum, Max: &maximum}
+	ratio := TypeValidator{Name: "ratio", Kind: KindNumber, Max: &minimum}
+	timeout := TypeValidator{Name: "timeout", Kind: KindDuration, Min: &minDuration, Max: &maxDuration}
+	code := TypeValidator{Name: "code", Kind: KindString, MinLength: &minLength, MaxLength: &maxLength, Regex: "^[A-Z]+$"}
+
+	tests := []struct {
+		name      string
+		validator TypeValidator
+		value     string
+		wantErr   bool
+	}{
+		{name: "enum - allowed", validator: TypeValidator{Name: "level", Kind: KindEnum, Values: []string{"debug", "info"}}, value: "info"},
+		{name: "enum - not allowed", validator: TypeValidator{Name: "level", Kind: KindEnum, Values: []string{"debug", "info"}}, value: "INFO", wantErr: true},
+		{name: "integer - in range", validator: port, value: "8080"},
+		{name: "integer - below min", validator: port, value: "0", wantErr: true},
+		{name: "integer - above max", validator: port, value: "70000", wantErr: true},
+		{name: "integer - decimal", validator: port, value: "80.5", wantErr: true},
+		{name: "number - in range", validator: ratio, value: "0.75"},
+		{name: "number - above max", validator: ratio, value: "1.5", wantErr: true},
+		{name: "number - NaN", validator: ratio, value: "NaN", wantErr: true},
+		{name: "string - valid", validator: code, value: "ABCD"},
+		{name: "string - too short", validator: code, value: "AB", wantErr: true},
+		{name: "string - too long", validator: code, value: "ABCDEF", wantErr: true},
+		{name: "string - regex mismatch", validator: code, value: "abcd", wantErr: true},
+		{name: "string - counts runes", validator: TypeValidator{Name: "s", Kind: KindString, MaxLength: &minLength}, value: "ñññ"},
+		{name: "duration - valid", validator: timeout, value: "1m30s"},
+		{name: "duration - above max", validator: timeout, value: "2h", wantErr: true},
+		{name: "duration - invalid", validator: timeout, value: "30", wantErr: true},
+		{name: "boolean - true", validator: TypeValidator{Name: "b", Kind: KindBoolean}, value: "true"},
+		{name: "boolean - not strict", validator: TypeValidator{Name: "b", Kind: KindBoolean}, value: "TRUE", wantErr: true},
+		{name: "email - valid", validator: TypeValidator{Name: "e", Kind: KindEmail}, value: "ops@example.com"},
+		{name: "email - display name", validator: TypeValidator{Name: "e", Kind: KindEmail}, value: "Ops <ops@example.com>", wantErr: true},
+		{name: "cidr - ipv4", validator: TypeValidator{Name: "c", Kind: KindCIDR}, value: "10.0.0.0/16"},
+		{name: "cidr - ipv6", validator: TypeValidator{Name: "c", Kind: KindCIDR}, value: "2001:db8::/32"},
+		{name: "cidr - bare ip", validator: TypeValidator{Name: "c", Kind: KindCIDR}, value: "10.0.0.1", wantErr: true},
+		{name: "regex kind", validator: TypeValidator{Name: "r", Kind: KindRegex, Regex: `^v\d+$`}, value: "v2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateValue(&tt.validator, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateValue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTypeValidator_Check(t *testing.T) {
+	minimum, maximum := 10.0, 1.0
+
+	tests := []struct {
+		name      string
+		validator TypeValidator
+		wantErr   bool
+	}{
+		{name: "legacy regex", validator: TypeValidator{Name: "r", Regex: "^a+$"}},
+		{name: "missing name", validator: TypeValidator{Regex: "^a+$"}, wantErr: true},
+		{name: "unknown kind", validator: TypeValidator{Name: "x", Kind: "color"}, wantErr: true},
+		{name: "invalid regex", validator: TypeValidator{Name: "r", Regex: "("}, wantErr: true},
+		{name: "enum without values", validator: TypeValidator{Name: "e", Kind: KindEnum}, wantErr: true},
+		{name: "min greater than max", validator: TypeValidator{Name: "n", Kind: KindNumber, Min: &minimum, Max: &maximum}, wantErr: true},
+		{name: "jsonschema without schema", validator: TypeValidator{Name: "j", Kind: KindJSONSchema}, wantErr: true},
+		{name: "jsonschema with $ref", validator: TypeValidator{Name: "j", Kind: KindJSONSchema, Schema: []byte(`{"$ref":"#/definitions/a"}`)}, wantErr: true},
+		{name: "jsonschema valid", validator: TypeValidator{Name: "j", Kind: KindJSONSchema, Schema: []byte(`{"type":"object"}`)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.validator.Check()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTypeValidator_ErrorsDoNotContainTheValue(t *testing.T) {
+	low, high := 10.0, 20.0
+	tests := []struct {
+		validator TypeValidator
+		value     string
+	}{
+		{validator: TypeValidator{Name: "port", Kind: KindInteger, Min: &low, Max: &high}, value: "987654321"},
+		{validator: TypeValidator{Name: "port", Kind: KindInteger, Min: &low, Max: &high}, value: "3"},
+		{validator: TypeValidator{Name: "ratio", Kind: KindNumber, Max: &high}, value: "123456.789"},
+		{validator: TypeValidator{Name: "timeout", Kind: KindDuration, Max: &high}, value: "987654s"},
+		{validator: TypeValidator{Name: "cidr", Kind: KindCIDR}, value: "s3cr3t-not-a-cidr"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.validator.Name+"/"+tt.value, func(t *testing.T) {
+			err := tt.validator.Validate(tt.value)
+			if err == nil {
+				t.Fatal("expected a validation error")
+			}
+			if strings.Contains(err.Error(), tt.value) {
+				t.Errorf("error %q must not contain the value", err.Error())
+			}
+		})
+	}
+}
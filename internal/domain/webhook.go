@@ -1,7 +1,40 @@
 package domain
 
+import (
+	"encoding/json"
+	"time"
+)
+
 type Webhook struct {
 	ID     string      `json:"id" dynamodbav:"ID"`
 	URL    string      `json:"url" dynamodbav:"URL"`
 	Events []EventType `json:"events" dynamodbav:"Events"` // Lista de eventos a los que está suscrito
+	// Secret clave HMAC-SHA256 para firmar los payloads, solo se devuelve al crear el webhook
+	Secret    string    `json:"secret,omitempty" dynamodbav:"Secret"`
+	CreatedAt time.Time `json:"createdAt" dynamodbav:"CreatedAt,unixtime"`
+	CreatedBy string    `json:"createdBy" dynamodbav:"CreatedBy"`
+}
+
+// Subscribed verifica si el webhook está suscrito al tipo de evento
+func (w Webhook) Subscribed(eventType EventType) bool {
+	for _, e := range w.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery dead letter of a delivery that failed after every retry
+type WebhookDelivery struct {
+	ID            string          `json:"id" dynamodbav:"ID"`
+	WebhookID     string          `json:"webhookId" dynamodbav:"WebhookID"`
+	URL           string          `json:"url" dynamodbav:"URL"`
+	EventType     EventType       `json:"eventType" dynamodbav:"EventType"`
+	TransactionId string          `json:"transactionId" dynamodbav:"TransactionId"`
+	Payload       json.RawMessage `json:"payload" dynamodbav:"Payload"`
+	Attempts      int             `json:"attempts" dynamodbav:"Attempts"`
+	LastStatus    int             `json:"lastStatus,omitempty" dynamodbav:"LastStatus,omitempty"`
+	LastError     string          `json:"lastError" dynamodbav:"LastError"`
+	FailedAt      time.Time       `json:"failedAt" dynamodbav:"FailedAt,unixtime"`
 }
@@ -18,6 +18,22 @@ func (a Authn) tryJwt(r *http.Request) (context.Context, error) {
 		return nil, ErrInvalidAuthHeaderFormat
 	}
 
+	return a.parseJwt(r, tokenString)
+}
+
+// tryStreamToken JWT of the access_token query param. EventSource can't send headers, so the
+// param is only accepted by the event stream.
+func (a Authn) tryStreamToken(r *http.Request) (context.Context, bool, error) {
+	tokenString := r.URL.Query().Get(StreamTokenParam)
+	if tokenString == "" || r.Method != http.MethodGet || r.URL.Path != StreamPath {
+		return nil, false, nil
+	}
+
+	ctx, err := a.parseJwt(r, tokenString)
+	return ctx, true, err
+}
+
+func (a Authn) parseJwt(r *http.Request, tokenString string) (context.Context, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("algoritmo de firma inesperado: %v", token.Header["alg"])
@@ -5,6 +5,13 @@ import (
 	"strings"
 )
 
+const (
+	// StreamPath event stream, the only route that accepts the token as query param
+	StreamPath = "/api/events"
+	// StreamTokenParam query param with the JWT of the event stream
+	StreamTokenParam = "access_token"
+)
+
 var (
 	ErrMissingAuthHeader       = errors.New("authorization header is required")
 	ErrUnsupportedAuthScheme   = errors.New("unsupported or missing authentication scheme")
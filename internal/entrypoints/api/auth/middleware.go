@@ -42,7 +42,10 @@ func (a Authn) Handler() func(http.Handler) http.Handler {
 			} else if _, ok := extractAuthValue(authorization, "Bearer"); ok {
 				newCtx, err = a.tryJwt(r)
 			} else if authorization == "" {
-				err = ErrMissingAuthHeader
+				var ok bool
+				if newCtx, ok, err = a.tryStreamToken(r); !ok {
+					err = ErrMissingAuthHeader
+				}
 			} else {
 				err = ErrUnsupportedAuthScheme
 			}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"nbox/internal/application"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/norlis/httpgate/pkg/adapter/apidriven/presenters"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestAuthn_StreamToken(t *testing.T) {
+	config := &application.Config{HmacSecretKey: []byte("secret")}
+	authn := NewAuthn("NBOX_TEST_CREDENTIALS", config, presenters.NewPresenters(zap.NewNop()), zap.NewNop(), nil)
+
+	now := time.Now()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{
+		Username: "viewer",
+		Roles:    []string{"viewer"},
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}).SignedString(config.HmacSecretKey)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		status int
+	}{
+		{"event stream with token", http.MethodGet, "/api/events?access_token=" + token, http.StatusOK},
+		{"event stream without token", http.MethodGet, "/api/events", http.StatusUnauthorized},
+		{"event stream with invalid token", http.MethodGet, "/api/events?access_token=invalid", http.StatusUnauthorized},
+		{"other route with token", http.MethodGet, "/api/entry/key?key=a&access_token=" + token, http.StatusUnauthorized},
+		{"other method with token", http.MethodPost, "/api/events?access_token=" + token, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var user application.User
+			handler := authn.Handler()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				user, _ = application.UserFromContext(r.Context())
+			}))
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, nil))
+
+			assert.Equal(t, tt.status, rec.Code)
+			if tt.status == http.StatusOK {
+				assert.Equal(t, "viewer", user.Name)
+			}
+		})
+	}
+}
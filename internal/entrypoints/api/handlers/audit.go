@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"fmt"
+	"nbox/internal/domain/models"
+	"nbox/internal/usecases"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/norlis/httpgate/pkg/adapter/apidriven/presenters"
+	_ "github.com/norlis/httpgate/pkg/kit/problem"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+type AuditHandler struct {
+	revealUseCase *usecases.SecretRevealUseCase
+	render        presenters.Presenters
+}
+
+func NewAuditHandler(revealUseCase *usecases.SecretRevealUseCase, render presenters.Presenters) *AuditHandler {
+	return &AuditHandler{revealUseCase: revealUseCase, render: render}
+}
+
+// SecretReveals
+// @Summary List secret reveals
+// @Description who read plain secret values through GET /api/entry/secret-value, newest first
+// @Tags audit
+// @Produce json
+// @Param key query string false "entry key, e.g. production/myapp/db_password"
+// @Param user query string false "username"
+// @Param since query string false "RFC3339 lower bound, e.g. 2026-10-01T00:00:00Z"
+// @Param until query string false "RFC3339 upper bound"
+// @Param limit query int false "max records (default 100, max 1000)"
+// @Security 	 BasicAuth
+// @Security 	 BearerAuth
+// @Success 200 {object} []models.SecretReveal ""
+// @Failure 400 {object} problem.ProblemDetail "Bad Request"
+// @Failure 401 {object} problem.ProblemDetail "Unauthorized"
+// @Failure 500 {object} problem.ProblemDetail "Internal error"
+// @Router /api/audit/secret-reveals [get]
+func (h *AuditHandler) SecretReveals(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter := models.SecretRevealFilter{
+		Key:   query.Get("key"),
+		User:  query.Get("user"),
+		Limit: defaultAuditLimit,
+	}
+
+	var err error
+	if filter.Since, err = timeQuery(r, "since"); err != nil {
+		h.render.Error(w, r, err, presenters.WithStatus(http.StatusBadRequest))
+		return
+	}
+	if filter.Until, err = timeQuery(r, "until"); err != nil {
+		h.render.Error(w, r, err, presenters.WithStatus(http.StatusBadRequest))
+		return
+	}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxAuditLimit {
+			h.render.Error(w, r, fmt.Errorf("limit must be between 1 and %d", maxAuditLimit), presenters.WithStatus(http.StatusBadRequest))
+			return
+		}
+		filter.Limit = limit
+	}
+
+	reveals, err := h.revealUseCase.List(r.Context(), filter)
+	if err != nil {
+		h.render.Error(w, r, err, presenters.WithStatus(http.StatusInternalServerError))
+		return
+	}
+
+	h.render.JSON(w, r, reveals)
+}
+
+// timeQuery RFC3339 query param, zero time when absent
+func timeQuery(r *http.Request, name string) (time.Time, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s %q, expected RFC3339", name, value)
+	}
+	return t, nil
+}
@@ -12,9 +12,10 @@ import (
 )
 
 type BoxHandler struct {
-	store      domain.TemplateAdapter
-	boxUseCase *usecases.BoxUseCase
-	render     presenters.Presenters
+	store           domain.TemplateAdapter
+	boxUseCase      *usecases.BoxUseCase
+	templateUseCase domain.TemplateUseCase
+	render          presenters.Presenters
 }
 
 type CommandBox struct {
@@ -22,8 +23,8 @@ type CommandBox struct {
 	Payload models.Box `json:"payload"`
 }
 
-func NewBoxHandler(store domain.TemplateAdapter, boxUseCase *usecases.BoxUseCase, render presenters.Presenters) *BoxHandler {
-	return &BoxHandler{store: store, boxUseCase: boxUseCase, render: render}
+func NewBoxHandler(store domain.TemplateAdapter, boxUseCase *usecases.BoxUseCase, templateUseCase domain.TemplateUseCase, render presenters.Presenters) *BoxHandler {
+	return &BoxHandler{store: store, boxUseCase: boxUseCase, templateUseCase: templateUseCase, render: render}
 }
 
 // UpsertBox
@@ -49,8 +50,8 @@ func (b *BoxHandler) UpsertBox(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result := b.store.UpsertBox(ctx, &command.Payload)
-	b.render.JSON(w, r, result)
+	changes := b.templateUseCase.UpsertBox(ctx, &command.Payload)
+	b.render.JSON(w, r, changes.Paths())
 }
 
 // Exist
@@ -3,9 +3,14 @@ package handlers
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"nbox/internal/application"
 	"nbox/internal/domain"
 	"nbox/internal/domain/models"
+	"nbox/internal/domain/models/operations"
+	"nbox/internal/usecases"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/norlis/httpgate/pkg/adapter/apidriven/presenters"
@@ -13,28 +18,38 @@ import (
 )
 
 type EntryHandler struct {
-	entryAdapter  domain.EntryAdapter
-	entryUseCase  domain.EntryUseCase
-	secretAdapter domain.SecretAdapter
-	render        presenters.Presenters
+	entryAdapter       domain.EntryAdapter
+	entryUseCase       domain.EntryUseCase
+	entryDeleteUseCase domain.EntryDeleteUseCase
+	secretAdapter      domain.SecretAdapter
+	rollbackUseCase    *usecases.RollbackUseCase
+	retypeUseCase      *usecases.RetypeUseCase
+	secretCheckUseCase *usecases.SecretCheckUseCase
+	revealUseCase      *usecases.SecretRevealUseCase
+	listUseCase        *usecases.EntryListUseCase
+	render             presenters.Presenters
 }
 
-func NewEntryHandler(entryAdapter domain.EntryAdapter, secretAdapter domain.SecretAdapter, entryUseCase domain.EntryUseCase, render presenters.Presenters) *EntryHandler {
-	return &EntryHandler{entryAdapter: entryAdapter, secretAdapter: secretAdapter, entryUseCase: entryUseCase, render: render}
+func NewEntryHandler(entryAdapter domain.EntryAdapter, secretAdapter domain.SecretAdapter, entryUseCase domain.EntryUseCase, entryDeleteUseCase domain.EntryDeleteUseCase, rollbackUseCase *usecases.RollbackUseCase, retypeUseCase *usecases.RetypeUseCase, secretCheckUseCase *usecases.SecretCheckUseCase, revealUseCase *usecases.SecretRevealUseCase, listUseCase *usecases.EntryListUseCase, render presenters.Presenters) *EntryHandler {
+	return &EntryHandler{entryAdapter: entryAdapter, secretAdapter: secretAdapter, entryUseCase: entryUseCase, entryDeleteUseCase: entryDeleteUseCase, rollbackUseCase: rollbackUseCase, retypeUseCase: retypeUseCase, secretCheckUseCase: secretCheckUseCase, revealUseCase: revealUseCase, listUseCase: listUseCase, render: render}
 }
 
 // Upsert
 // @Summary Upsert entries
-// @Description insert / update vars
+// @Description insert / update vars. With atomic=true every entry is written or none: an invalid entry
+// @Description aborts the batch and the secrets already written are restored when a later write fails
 // @Tags entry
 // @Accept json
 // @Produce json
 // @Param data body []models.Entry true "Upsert template"
+// @Param atomic query bool false "all-or-nothing batch, the entries not written are reported as aborted"
+// @Param If-Match header string false "Expected version of the entry (single entry requests only), 0 means the key must not exist"
 // @Security 	 BasicAuth
 // @Security 	 BearerAuth
 // @Success 200 {object} map[string]string ""
 // @Failure 400 {object} problem.ProblemDetail "Bad Request"
 // @Failure 401 {object} problem.ProblemDetail "Unauthorized"
+// @Failure 409 {object} []operations.Result "Version conflicts"
 // @Failure 422 {object} []operations.Result "Validation errors"
 // @Failure 500 {object} problem.ProblemDetail "Internal error"
 // @Router /api/entry [post]
@@ -47,17 +62,50 @@ func (h *EntryHandler) Upsert(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		version, err := parseIfMatch(ifMatch)
+		if err != nil {
+			h.render.Error(w, r, err, presenters.WithStatus(http.StatusBadRequest))
+			return
+		}
+		if len(entries) != 1 {
+			h.render.Error(w, r, errors.New("If-Match requires a single entry, use expected_version on each entry instead"), presenters.WithStatus(http.StatusBadRequest))
+			return
+		}
+		if entries[0].ExpectedVersion == nil {
+			entries[0].ExpectedVersion = &version
+		}
+	}
+
+	switch r.URL.Query().Get("atomic") {
+	case "", "false":
+	case "true":
+		ctx = application.NewContextWithAtomic(ctx)
+	default:
+		h.render.Error(w, r, errors.New("atomic must be true or false"), presenters.WithStatus(http.StatusBadRequest))
+		return
+	}
+
 	results := h.entryUseCase.Upsert(ctx, entries)
 
-	// Check if there are any validation errors
+	// Check if there are any validation errors or stale writes
 	hasErrors := false
+	hasConflicts := false
 	for _, result := range results {
+		if result.Type == operations.Conflict {
+			hasConflicts = true
+		}
 		if result.Error != nil {
 			hasErrors = true
-			break
 		}
 	}
 
+	if hasConflicts {
+		w.WriteHeader(http.StatusConflict)
+		h.render.JSON(w, r, results)
+		return
+	}
+
 	if hasErrors {
 		w.WriteHeader(http.StatusUnprocessableEntity)
 		h.render.JSON(w, r, results)
@@ -69,19 +117,46 @@ func (h *EntryHandler) Upsert(w http.ResponseWriter, r *http.Request) {
 
 // ListByPrefix
 // @Summary Filter by prefix
-// @Description list all keys by path
+// @Description list all keys by path. With recursive, limit or next the response is a page
+// @Description ({"entries": [...], "next": "..."}); recursive walks the sub folders, next continues a listing
 // @Tags entry
 // @Produce json
 // @Param v query string true "key path"
+// @Param recursive query bool false "include the keys of the sub folders"
+// @Param limit query int false "max entries of the page (default 500, max 1000)"
+// @Param next query string false "cursor returned by the previous page"
 // @Security 	 BasicAuth
 // @Security 	 BearerAuth
-// @Success 200 {object} []models.Entry ""
+// @Success 200 {object} []models.Entry "models.EntryPage when recursive, limit or next are set"
+// @Failure 400 {object} problem.ProblemDetail "Bad Request"
 // @Failure 401 {object} problem.ProblemDetail "Unauthorized"
 // @Failure 500 {object} problem.ProblemDetail "Internal error"
 // @Router /api/entry/prefix [get]
 func (h *EntryHandler) ListByPrefix(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	prefix := r.URL.Query().Get("v")
+	query := r.URL.Query()
+	prefix := query.Get("v")
+
+	if query.Has("recursive") || query.Has("limit") || query.Has("next") {
+		opts, err := listOptions(prefix, query.Get("recursive"), query.Get("limit"), query.Get("next"))
+		if err != nil {
+			h.render.Error(w, r, err, presenters.WithStatus(http.StatusBadRequest))
+			return
+		}
+
+		page, err := h.listUseCase.List(ctx, opts)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, domain.ErrInvalidCursor) {
+				status = http.StatusBadRequest
+			}
+			h.render.Error(w, r, err, presenters.WithStatus(status))
+			return
+		}
+
+		h.render.JSON(w, r, page)
+		return
+	}
 
 	entries, err := h.entryAdapter.List(ctx, prefix)
 	if err != nil {
@@ -101,6 +176,7 @@ func (h *EntryHandler) ListByPrefix(w http.ResponseWriter, r *http.Request) {
 // @Security 	 BasicAuth
 // @Security 	 BearerAuth
 // @Success 200 {object} models.Entry ""
+// @Header 200 {string} ETag "Version of the entry, usable as If-Match on POST /api/entry"
 // @Failure 401 {object} problem.ProblemDetail "Unauthorized"
 // @Failure 500 {object} problem.ProblemDetail "Internal error"
 // @Router /api/entry/key [get]
@@ -113,31 +189,36 @@ func (h *EntryHandler) GetByKey(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if entry != nil {
+		w.Header().Set("ETag", strconv.Quote(strconv.FormatInt(entry.Version, 10)))
+	}
+
 	h.render.JSON(w, r, entry)
 }
 
 // DeleteKey
 // @Summary Delete
-// @Description delete keys & children
+// @Description delete keys & children. The secrets of the deleted secure entries are removed from
+// @Description Parameter Store, with their history; "secrets" holds the result of each removal.
 // @Tags entry
 // @Produce json
 // @Param v query string true "key path"
 // @Security 	 BasicAuth
 // @Security 	 BearerAuth
-// @Success 200 {object} object{message=string} ""
+// @Success 200 {object} object{message=string,deleted=[]string,secrets=[]operations.Result} ""
 // @Failure 401 {object} problem.ProblemDetail "Unauthorized"
 // @Failure 500 {object} problem.ProblemDetail "Internal error"
 // @Router /api/entry/key [delete]
 func (h *EntryHandler) DeleteKey(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	key := r.URL.Query().Get("v")
-	err := h.entryAdapter.Delete(ctx, key)
+	deleted, secrets, err := h.entryDeleteUseCase.Delete(ctx, key)
 	if err != nil {
 		h.render.Error(w, r, err, presenters.WithStatus(http.StatusBadRequest))
 		return
 	}
 
-	h.render.JSON(w, r, map[string]string{"message": "ok"})
+	h.render.JSON(w, r, map[string]any{"message": "ok", "deleted": deleted, "secrets": secrets})
 }
 
 // Tracking
@@ -165,15 +246,139 @@ func (h *EntryHandler) Tracking(w http.ResponseWriter, r *http.Request) {
 	h.render.JSON(w, r, entries)
 }
 
+// Rollback
+// @Summary Rollback to a tracked revision
+// @Description restores the value the key had at a revision of GET /api/track/key, identified by its version.
+// @Description The value is validated against the current type validator and secure values are written again to Parameter Store.
+// @Tags entry
+// @Accept json
+// @Produce json
+// @Param data body models.RollbackRequest true "Key and revision version"
+// @Security 	 BasicAuth
+// @Security 	 BearerAuth
+// @Success 200 {object} []operations.Result ""
+// @Failure 400 {object} problem.ProblemDetail "Bad Request"
+// @Failure 401 {object} problem.ProblemDetail "Unauthorized"
+// @Failure 404 {object} problem.ProblemDetail "Revision not found"
+// @Failure 409 {object} []operations.Result "The entry changed during the rollback"
+// @Failure 410 {object} problem.ProblemDetail "The secret of the revision was deleted with the key"
+// @Failure 422 {object} []operations.Result "Validation errors"
+// @Failure 500 {object} problem.ProblemDetail "Internal error"
+// @Router /api/track/key/rollback [post]
+func (h *EntryHandler) Rollback(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var request models.RollbackRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.render.Error(w, r, err, presenters.WithStatus(http.StatusBadRequest))
+		return
+	}
+
+	if request.Key == "" || request.Version <= 0 {
+		h.render.Error(w, r, errors.New("key and version are required"), presenters.WithStatus(http.StatusBadRequest))
+		return
+	}
+
+	results, err := h.rollbackUseCase.Rollback(ctx, request)
+	if errors.Is(err, domain.ErrRevisionNotFound) {
+		h.render.Error(w, r, err, presenters.WithStatus(http.StatusNotFound))
+		return
+	}
+	if errors.Is(err, domain.ErrSecretHistoryGone) {
+		h.render.Error(w, r, err, presenters.WithStatus(http.StatusGone))
+		return
+	}
+	if err != nil {
+		h.render.Error(w, r, err, presenters.WithStatus(http.StatusBadRequest))
+		return
+	}
+
+	for _, result := range results {
+		if result.Type == operations.Conflict {
+			w.WriteHeader(http.StatusConflict)
+			h.render.JSON(w, r, results)
+			return
+		}
+		if result.Error != nil {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			h.render.JSON(w, r, results)
+			return
+		}
+	}
+
+	h.render.JSON(w, r, results)
+}
+
+// Retype
+// @Summary Change the type validator of an entry
+// @Description moves the entry to another type validator without delete/recreate. The current value (or the supplied one)
+// @Description is validated against the new validator and the change is recorded in the tracking history as "retype".
+// @Tags entry
+// @Accept json
+// @Produce json
+// @Param data body models.RetypeRequest true "Key and new type validator"
+// @Security 	 BasicAuth
+// @Security 	 BearerAuth
+// @Success 200 {object} []operations.Result ""
+// @Failure 400 {object} problem.ProblemDetail "Bad Request"
+// @Failure 401 {object} problem.ProblemDetail "Unauthorized"
+// @Failure 404 {object} problem.ProblemDetail "Entry not found"
+// @Failure 409 {object} []operations.Result "The entry changed during the retype"
+// @Failure 422 {object} []operations.Result "The value fails the new type validator"
+// @Failure 500 {object} problem.ProblemDetail "Internal error"
+// @Router /api/entry/retype [post]
+func (h *EntryHandler) Retype(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var request models.RetypeRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.render.Error(w, r, err, presenters.WithStatus(http.StatusBadRequest))
+		return
+	}
+
+	if request.Key == "" {
+		h.render.Error(w, r, errors.New("key is required"), presenters.WithStatus(http.StatusBadRequest))
+		return
+	}
+
+	results, err := h.retypeUseCase.Retype(ctx, request)
+	if errors.Is(err, domain.ErrEntryNotFound) {
+		h.render.Error(w, r, err, presenters.WithStatus(http.StatusNotFound))
+		return
+	}
+	if err != nil {
+		h.render.Error(w, r, err, presenters.WithStatus(http.StatusBadRequest))
+		return
+	}
+
+	for _, result := range results {
+		if result.Type == operations.Conflict {
+			w.WriteHeader(http.StatusConflict)
+			h.render.JSON(w, r, results)
+			return
+		}
+		if result.Error != nil {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			h.render.JSON(w, r, results)
+			return
+		}
+	}
+
+	h.render.JSON(w, r, results)
+}
+
 // RetrieveSecretValue
 // @Summary Retrieve secret value
-// @Description plain value
+// @Description plain value. Every reveal is audited (GET /api/audit/secret-reveals) and emits a secret.revealed event;
+// @Description when NBOX_SECRET_REVEAL_REASONS is set the reason is required and must be one of those values
 // @Tags entry
 // @Produce json
 // @Param v query string true "key path"
+// @Param reason query string false "why the value is needed, e.g. incident-4521"
 // @Security 	 BasicAuth
 // @Security 	 BearerAuth
 // @Success 200 {object} models.Entry ""
+// @Failure 400 {object} problem.ProblemDetail "Bad Request"
 // @Failure 401 {object} problem.ProblemDetail "Unauthorized"
 // @Failure 404 {object} problem.ProblemDetail "Not found"
 // @Failure 500 {object} problem.ProblemDetail "Internal error"
@@ -187,11 +392,11 @@ func (h *EntryHandler) RetrieveSecretValue(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	if !strings.HasPrefix(key, "/") {
-		key = "/" + key
+	entry, err := h.revealUseCase.Reveal(ctx, key, r.URL.Query().Get("reason"))
+	if errors.Is(err, domain.ErrSecretAuditFailed) {
+		h.render.Error(w, r, err, presenters.WithStatus(http.StatusInternalServerError))
+		return
 	}
-
-	entry, err := h.secretAdapter.RetrieveSecretValue(ctx, key)
 	if err != nil {
 		h.render.Error(w, r, err, presenters.WithStatus(http.StatusBadRequest))
 		return
@@ -204,3 +409,67 @@ func (h *EntryHandler) RetrieveSecretValue(w http.ResponseWriter, r *http.Reques
 
 	h.render.JSON(w, r, entry)
 }
+
+// parseIfMatch acepta la versión con o sin comillas ("3", W/"3", 3)
+func parseIfMatch(value string) (int64, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "W/")
+	version, err := strconv.ParseInt(strings.Trim(value, `"`), 10, 64)
+	if err != nil || version < 0 {
+		return 0, fmt.Errorf("invalid If-Match header %q, expected an entry version", value)
+	}
+	return version, nil
+}
+
+// SecretCheck
+// @Summary Check secrets against their type validators
+// @Description decrypts the secrets under the prefix and reports the ones whose value fails the type validator declared
+// @Description in the secret store (SSM tag nbox:type-validator), whose validator does not exist, whose entry declares
+// @Description another validator or that no secure entry references. Values are never returned.
+// @Tags entry
+// @Produce json
+// @Param prefix query string false "key prefix, e.g. production/myapp"
+// @Security 	 BasicAuth
+// @Security 	 BearerAuth
+// @Success 200 {object} models.SecretCheckReport ""
+// @Failure 401 {object} problem.ProblemDetail "Unauthorized"
+// @Failure 500 {object} problem.ProblemDetail "Internal error"
+// @Router /api/entry/secret-check [get]
+func (h *EntryHandler) SecretCheck(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	report, err := h.secretCheckUseCase.Check(ctx, r.URL.Query().Get("prefix"))
+	if err != nil {
+		h.render.Error(w, r, err, presenters.WithStatus(http.StatusInternalServerError))
+		return
+	}
+
+	h.render.JSON(w, r, report)
+}
+
+const (
+	defaultListLimit = 500
+	maxListLimit     = 1000
+)
+
+// listOptions paginated listing from the query params
+func listOptions(prefix, recursive, limit, next string) (models.ListOptions, error) {
+	opts := models.ListOptions{Prefix: prefix, Limit: defaultListLimit, Next: next}
+
+	if recursive != "" {
+		value, err := strconv.ParseBool(recursive)
+		if err != nil {
+			return opts, fmt.Errorf("recursive must be a boolean")
+		}
+		opts.Recursive = value
+	}
+
+	if limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 1 || value > maxListLimit {
+			return opts, fmt.Errorf("limit must be between 1 and %d", maxListLimit)
+		}
+		opts.Limit = value
+	}
+
+	return opts, nil
+}
@@ -37,8 +37,13 @@ func NewExportHandler(
 // @Tags         export
 // @Security 	 BasicAuth
 // @Security 	 BearerAuth
-// @Param        prefix query string true "Prefix to filter entries (required). Example: 'production/', 'staging/myapp/'"
+// @Param        prefix query string false "Prefix to filter entries (required without service and stage). Example: 'production/', 'staging/myapp/'"
+// @Param        service query string false "Export the effective configuration of the service (with stage) instead of a prefix"
+// @Param        stage query string false "Stage of the effective configuration, e.g. production"
 // @Param        format query string false "Output format" Enums(json, yaml, dotenv, ecs) default(json)
+// @Param        recursive query bool false "Include the keys of the sub folders of the prefix"
+// @Param        limit query int false "Export one page of at most limit entries (max 1000)"
+// @Param        next query string false "Cursor of the next page, from the X-Next-Cursor header"
 // @Produce      json
 // @Produce      application/x-yaml
 // @Produce      text/plain
@@ -46,6 +51,7 @@ func NewExportHandler(
 // @Header       200 {string} Content-Disposition "attachment; filename=nbox-export-{prefix}-{timestamp}.{ext}"
 // @Header       200 {string} X-Export-Count "Number of entries exported"
 // @Header       200 {string} X-Export-Size "Size in bytes of exported file"
+// @Header       200 {string} X-Next-Cursor "Cursor of the next page, absent on the last one"
 // @Failure      400 {object} problem.ProblemDetail "Invalid parameters (missing prefix or invalid format)"
 // @Failure      401 {object} problem.ProblemDetail "Unauthorized - Missing or invalid token"
 // @Failure      403 {object} problem.ProblemDetail "Forbidden - Insufficient permissions"
@@ -56,11 +62,13 @@ func (h *ExportHandler) Export(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	prefix := r.URL.Query().Get("prefix")
+	service := r.URL.Query().Get("service")
+	stage := r.URL.Query().Get("stage")
 
-	if prefix == "" {
+	if prefix == "" && service == "" && stage == "" {
 		h.logger.Warn("Export request without prefix")
 		h.render.Error(w, r,
-			fmt.Errorf("prefix parameter is required"),
+			fmt.Errorf("prefix parameter is required, or service and stage"),
 			presenters.WithStatus(http.StatusBadRequest))
 		return
 	}
@@ -72,9 +80,23 @@ func (h *ExportHandler) Export(w http.ResponseWriter, r *http.Request) {
 
 	format := models.ExportFormat(formatStr)
 
+	query := r.URL.Query()
+	listOpts, err := listOptions(prefix, query.Get("recursive"), query.Get("limit"), query.Get("next"))
+	if err != nil {
+		h.render.Error(w, r, err, presenters.WithStatus(http.StatusBadRequest))
+		return
+	}
+
 	opts := models.ExportOptions{
-		Prefix: prefix,
-		Format: format,
+		Prefix:    prefix,
+		Format:    format,
+		Service:   service,
+		Stage:     stage,
+		Recursive: listOpts.Recursive,
+		Next:      listOpts.Next,
+	}
+	if query.Has("limit") {
+		opts.Limit = listOpts.Limit
 	}
 
 	result, err := h.exportUseCase.Export(ctx, opts)
@@ -88,12 +110,18 @@ func (h *ExportHandler) Export(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if opts.Resolved() {
+		prefix = fmt.Sprintf("%s-%s", stage, service)
+	}
 	filename := h.exportUseCase.GetFilename(format, prefix)
 
 	w.Header().Set("Content-Type", h.exportUseCase.GetContentType(format))
 	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
 	w.Header().Set("X-Export-Count", fmt.Sprintf("%d", len(result.Entries)))
 	w.Header().Set("X-Export-Size", fmt.Sprintf("%d", result.Size))
+	if result.Next != "" {
+		w.Header().Set("X-Next-Cursor", result.Next)
+	}
 
 	w.WriteHeader(http.StatusOK)
 	if _, err := w.Write(result.Content); err != nil {
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+	"nbox/internal/usecases"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/norlis/httpgate/pkg/adapter/apidriven/presenters"
+	"go.uber.org/zap"
+)
+
+// ImportHandler maneja las peticiones de importación
+type ImportHandler struct {
+	importUseCase *usecases.ImportUseCase
+	render        presenters.Presenters
+	logger        *zap.Logger
+}
+
+// NewImportHandler crea una nueva instancia
+func NewImportHandler(
+	importUseCase *usecases.ImportUseCase,
+	render presenters.Presenters,
+	logger *zap.Logger,
+) *ImportHandler {
+	return &ImportHandler{
+		importUseCase: importUseCase,
+		render:        render,
+		logger:        logger,
+	}
+}
+
+// Import godoc
+// @Summary      Import configuration entries
+// @Description  Import entries from a file produced by /api/entry/export (JSON, YAML or dotenv).
+// @Description  The file is sent as multipart field "file" or as the raw request body.
+// @Description  Entries go through the same validation as POST /api/entry (type validators, secure entries).
+// @Tags         export
+// @Security 	 BasicAuth
+// @Security 	 BearerAuth
+// @Accept       multipart/form-data
+// @Accept       json
+// @Accept       application/x-yaml
+// @Accept       plain
+// @Param        file formData file false "File to import"
+// @Param        format query string false "Input format, inferred from the file extension when empty" Enums(json, yaml, dotenv)
+// @Param        strategy query string false "What to do with existing keys" Enums(skip, overwrite, fail) default(fail)
+// @Param        dry_run query bool false "Only report conflicts, nothing is written" default(false)
+// @Param        prefix query string false "Prefix for dotenv variables (required for dotenv). Example: 'production/myapp'"
+// @Produce      json
+// @Success      200 {object} models.ImportResult "Import report"
+// @Failure      400 {object} problem.ProblemDetail "Invalid file, format or strategy"
+// @Failure      401 {object} problem.ProblemDetail "Unauthorized"
+// @Failure      403 {object} problem.ProblemDetail "Forbidden"
+// @Failure      409 {object} models.ImportResult "Conflicts detected (strategy=fail)"
+// @Failure      413 {object} problem.ProblemDetail "File too large"
+// @Failure      422 {object} models.ImportResult "Validation errors"
+// @Failure      500 {object} problem.ProblemDetail "Internal server error"
+// @Router       /api/entry/import [post]
+func (h *ImportHandler) Import(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	content, filename, err := h.readContent(w, r)
+	if err != nil {
+		status := http.StatusBadRequest
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			status = http.StatusRequestEntityTooLarge
+			err = fmt.Errorf("%w: max %d bytes", domain.ErrImportSizeLimitExceeded, usecases.MaxImportSize)
+		}
+		h.render.Error(w, r, err, presenters.WithStatus(status))
+		return
+	}
+
+	dryRun := false
+	if v := query.Get("dry_run"); v != "" {
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			h.render.Error(w, r, fmt.Errorf("invalid dry_run: %w", err), presenters.WithStatus(http.StatusBadRequest))
+			return
+		}
+	}
+
+	strategy := query.Get("strategy")
+	if strategy == "" {
+		strategy = string(models.OverwriteFail)
+	}
+
+	opts := models.ImportOptions{
+		Prefix:   query.Get("prefix"),
+		Format:   importFormat(query.Get("format"), filename),
+		Strategy: models.OverwriteStrategy(strategy),
+		DryRun:   dryRun,
+	}
+
+	result, err := h.importUseCase.Import(ctx, content, opts)
+	if err != nil {
+		h.logger.Error("Import failed",
+			zap.Error(err),
+			zap.String("prefix", opts.Prefix),
+			zap.String("format", string(opts.Format)),
+		)
+
+		switch {
+		case errors.Is(err, domain.ErrConflictsDetected):
+			w.WriteHeader(http.StatusConflict)
+			h.render.JSON(w, r, result)
+		case errors.Is(err, domain.ErrImportSizeLimitExceeded):
+			h.render.Error(w, r, err, presenters.WithStatus(http.StatusRequestEntityTooLarge))
+		case errors.Is(err, domain.ErrInvalidImportFormat),
+			errors.Is(err, domain.ErrInvalidOverwriteStrategy),
+			errors.Is(err, domain.ErrInvalidFileFormat),
+			errors.Is(err, domain.ErrLossyDotEnvImport):
+			h.render.Error(w, r, err, presenters.WithStatus(http.StatusBadRequest))
+		default:
+			h.render.Error(w, r, err, presenters.WithStatus(http.StatusInternalServerError))
+		}
+		return
+	}
+
+	for _, res := range result.Results {
+		if res.Error != nil {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			h.render.JSON(w, r, result)
+			return
+		}
+	}
+
+	h.render.JSON(w, r, result)
+}
+
+// readContent acepta multipart (campo "file") o el body completo
+func (h *ImportHandler) readContent(w http.ResponseWriter, r *http.Request) ([]byte, string, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, usecases.MaxImportSize)
+
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		content, err := io.ReadAll(r.Body)
+		return content, "", err
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		return nil, "", err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	content, err := io.ReadAll(file)
+	return content, header.Filename, err
+}
+
+// importFormat usa el parámetro format o, si no viene, la extensión del archivo
+func importFormat(format string, filename string) models.ExportFormat {
+	if format != "" {
+		return models.ExportFormat(format)
+	}
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		return models.ExportFormatYAML
+	case ".env":
+		return models.ExportFormatDotEnv
+	default:
+		return models.ExportFormatJSON
+	}
+}
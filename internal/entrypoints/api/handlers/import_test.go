@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"nbox/internal/application"
+	"nbox/internal/domain/models"
+	"nbox/internal/usecases"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/norlis/httpgate/pkg/adapter/apidriven/presenters"
+	"go.uber.org/zap"
+)
+
+// mockFailingEntryAdapter the store is unavailable
+type mockFailingEntryAdapter struct {
+	mockEntryAdapter
+}
+
+func (m *mockFailingEntryAdapter) Retrieve(context.Context, string) (*models.Entry, error) {
+	return nil, errors.New("store unavailable")
+}
+
+func TestImportHandler_ErrorStatus(t *testing.T) {
+	// validation errors are found before the store is read
+	tests := []struct {
+		name   string
+		query  string
+		body   string
+		status int
+	}{
+		{"invalid format", "?format=xml", `{}`, http.StatusBadRequest},
+		{"invalid strategy", "?format=json&strategy=merge", `{}`, http.StatusBadRequest},
+		{"invalid file", "?format=json", `not json`, http.StatusBadRequest},
+		{"store failure", "?format=json", `[{"key": "development/app/port", "value": "8080"}]`, http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &application.Config{}
+			keyPolicy, err := usecases.NewKeyPolicy(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			useCase := usecases.NewImportUseCase(&mockFailingEntryAdapter{}, nil, keyPolicy, zap.NewNop())
+			handler := NewImportHandler(useCase, presenters.NewPresenters(zap.NewNop()), zap.NewNop())
+
+			req := httptest.NewRequest(http.MethodPost, "/api/entry/import"+tt.query, strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			handler.Import(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+		})
+	}
+}
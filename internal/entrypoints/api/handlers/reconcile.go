@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"nbox/internal/usecases"
+	"net/http"
+
+	"github.com/norlis/httpgate/pkg/adapter/apidriven/presenters"
+	_ "github.com/norlis/httpgate/pkg/kit/problem"
+)
+
+type ReconcileHandler struct {
+	reconcileUseCase *usecases.ReconcileUseCase
+	render           presenters.Presenters
+}
+
+func NewReconcileHandler(reconcileUseCase *usecases.ReconcileUseCase, render presenters.Presenters) *ReconcileHandler {
+	return &ReconcileHandler{reconcileUseCase: reconcileUseCase, render: render}
+}
+
+// Report
+// @Summary Compare secure entries with Parameter Store
+// @Description reports parameters tagged project=nbox that no secure entry references (orphan), secure entries
+// @Description whose parameter does not exist (missing_parameter) and references in another format than the
+// @Description configured one (reference_mismatch). Nothing is changed.
+// @Tags entry
+// @Produce json
+// @Param prefix query string false "key prefix, e.g. production/myapp"
+// @Security 	 BasicAuth
+// @Security 	 BearerAuth
+// @Success 200 {object} models.ReconcileReport ""
+// @Failure 401 {object} problem.ProblemDetail "Unauthorized"
+// @Failure 500 {object} problem.ProblemDetail "Internal error"
+// @Router /api/entry/reconcile [get]
+func (h *ReconcileHandler) Report(w http.ResponseWriter, r *http.Request) {
+	h.reconcile(w, r, false)
+}
+
+// Repair
+// @Summary Repair secure entries out of sync with Parameter Store
+// @Description same report as GET, orphan parameters (written more than 10 minutes ago) are deleted and references
+// @Description are rewritten in the configured format. Missing parameters are only reported.
+// @Tags entry
+// @Produce json
+// @Param prefix query string false "key prefix, e.g. production/myapp"
+// @Security 	 BasicAuth
+// @Security 	 BearerAuth
+// @Success 200 {object} models.ReconcileReport ""
+// @Failure 401 {object} problem.ProblemDetail "Unauthorized"
+// @Failure 500 {object} problem.ProblemDetail "Internal error"
+// @Router /api/entry/reconcile [post]
+func (h *ReconcileHandler) Repair(w http.ResponseWriter, r *http.Request) {
+	h.reconcile(w, r, true)
+}
+
+func (h *ReconcileHandler) reconcile(w http.ResponseWriter, r *http.Request, repair bool) {
+	report, err := h.reconcileUseCase.Reconcile(r.Context(), r.URL.Query().Get("prefix"), repair)
+	if err != nil {
+		h.render.Error(w, r, err, presenters.WithStatus(http.StatusInternalServerError))
+		return
+	}
+
+	h.render.JSON(w, r, report)
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"errors"
+	"nbox/internal/domain"
+	"nbox/internal/usecases"
+	"net/http"
+
+	"github.com/norlis/httpgate/pkg/adapter/apidriven/presenters"
+	_ "github.com/norlis/httpgate/pkg/kit/problem"
+)
+
+type ResolveHandler struct {
+	resolveUseCase *usecases.ResolveUseCase
+	render         presenters.Presenters
+}
+
+func NewResolveHandler(resolveUseCase *usecases.ResolveUseCase, render presenters.Presenters) *ResolveHandler {
+	return &ResolveHandler{resolveUseCase: resolveUseCase, render: render}
+}
+
+// Resolve
+// @Summary Effective configuration of a service in a stage
+// @Description overlays the layers of NBOX_RESOLVE_LAYERS (global, stage and stage/service by default), a higher
+// @Description layer replaces the variables of the lower ones. Each variable reports the layer that sets it and
+// @Description the layers it overrides. Secure variables keep the reference of the secret.
+// @Tags entry
+// @Produce json
+// @Param service query string true "service, e.g. myapp"
+// @Param stage query string true "stage, e.g. production"
+// @Security 	 BasicAuth
+// @Security 	 BearerAuth
+// @Success 200 {object} models.ResolveResult ""
+// @Failure 400 {object} problem.ProblemDetail "Missing service or stage"
+// @Failure 401 {object} problem.ProblemDetail "Unauthorized"
+// @Failure 500 {object} problem.ProblemDetail "Internal error"
+// @Router /api/entry/resolve [get]
+func (h *ResolveHandler) Resolve(w http.ResponseWriter, r *http.Request) {
+	result, err := h.resolveUseCase.Resolve(r.Context(), r.URL.Query().Get("service"), r.URL.Query().Get("stage"))
+	if errors.Is(err, domain.ErrInvalidScope) {
+		h.render.Error(w, r, err, presenters.WithStatus(http.StatusBadRequest))
+		return
+	}
+	if err != nil {
+		h.render.Error(w, r, err, presenters.WithStatus(http.StatusInternalServerError))
+		return
+	}
+
+	h.render.JSON(w, r, result)
+}
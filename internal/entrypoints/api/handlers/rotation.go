@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+	"nbox/internal/usecases"
+	"net/http"
+
+	"github.com/norlis/httpgate/pkg/adapter/apidriven/presenters"
+	_ "github.com/norlis/httpgate/pkg/kit/problem"
+)
+
+type RotationHandler struct {
+	rotationUseCase *usecases.RotationUseCase
+	render          presenters.Presenters
+}
+
+func NewRotationHandler(rotationUseCase *usecases.RotationUseCase, render presenters.Presenters) *RotationHandler {
+	return &RotationHandler{rotationUseCase: rotationUseCase, render: render}
+}
+
+// Upsert
+// @Summary Set rotation policy
+// @Description rotate a secure entry every interval with a generated value. The generator must produce values
+// @Description accepted by the entry's type validator. Rotation state (last/next rotation) is kept on updates.
+// @Tags rotation
+// @Accept json
+// @Produce json
+// @Security 	 BasicAuth
+// @Security 	 BearerAuth
+// @Param data body models.RotationPolicy true "key, interval, grace_period and generator"
+// @Success 200 {object} models.RotationPolicy ""
+// @Failure 400 {object} problem.ProblemDetail "Bad Request"
+// @Failure 401 {object} problem.ProblemDetail "Unauthorized"
+// @Failure 404 {object} problem.ProblemDetail "Entry not found"
+// @Failure 500 {object} problem.ProblemDetail "Internal error"
+// @Router /api/rotation [post]
+func (h *RotationHandler) Upsert(w http.ResponseWriter, r *http.Request) {
+	var policy models.RotationPolicy
+	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+		h.render.Error(w, r, err, presenters.WithStatus(http.StatusBadRequest))
+		return
+	}
+
+	saved, err := h.rotationUseCase.SetPolicy(r.Context(), policy)
+	if err != nil {
+		h.renderError(w, r, err)
+		return
+	}
+
+	h.render.JSON(w, r, saved)
+}
+
+// List
+// @Summary List rotation policies
+// @Tags rotation
+// @Produce json
+// @Security 	 BasicAuth
+// @Security 	 BearerAuth
+// @Success 200 {object} []models.RotationPolicy ""
+// @Failure 401 {object} problem.ProblemDetail "Unauthorized"
+// @Failure 500 {object} problem.ProblemDetail "Internal error"
+// @Router /api/rotation [get]
+func (h *RotationHandler) List(w http.ResponseWriter, r *http.Request) {
+	policies, err := h.rotationUseCase.List(r.Context())
+	if err != nil {
+		h.render.Error(w, r, err, presenters.WithStatus(http.StatusInternalServerError))
+		return
+	}
+
+	h.render.JSON(w, r, policies)
+}
+
+// GetByKey
+// @Summary Retrieve rotation policy
+// @Tags rotation
+// @Produce json
+// @Param v query string true "key path"
+// @Security 	 BasicAuth
+// @Security 	 BearerAuth
+// @Success 200 {object} models.RotationPolicy ""
+// @Failure 401 {object} problem.ProblemDetail "Unauthorized"
+// @Failure 404 {object} problem.ProblemDetail "Not found"
+// @Failure 500 {object} problem.ProblemDetail "Internal error"
+// @Router /api/rotation/key [get]
+func (h *RotationHandler) GetByKey(w http.ResponseWriter, r *http.Request) {
+	key := r.URL.Query().Get("v")
+	if key == "" {
+		h.render.Error(w, r, errors.New("empty key"), presenters.WithStatus(http.StatusBadRequest))
+		return
+	}
+
+	policy, err := h.rotationUseCase.Policy(r.Context(), key)
+	if err != nil {
+		h.renderError(w, r, err)
+		return
+	}
+
+	h.render.JSON(w, r, policy)
+}
+
+// Delete
+// @Summary Delete rotation policy
+// @Description stop rotating the secret, its current value is kept
+// @Tags rotation
+// @Produce json
+// @Param v query string true "key path"
+// @Security 	 BasicAuth
+// @Security 	 BearerAuth
+// @Success 200 {object} object{message=string} ""
+// @Failure 401 {object} problem.ProblemDetail "Unauthorized"
+// @Failure 404 {object} problem.ProblemDetail "Not found"
+// @Failure 500 {object} problem.ProblemDetail "Internal error"
+// @Router /api/rotation/key [delete]
+func (h *RotationHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	key := r.URL.Query().Get("v")
+	if key == "" {
+		h.render.Error(w, r, errors.New("empty key"), presenters.WithStatus(http.StatusBadRequest))
+		return
+	}
+
+	if err := h.rotationUseCase.DeletePolicy(r.Context(), key); err != nil {
+		h.renderError(w, r, err)
+		return
+	}
+
+	h.render.JSON(w, r, map[string]string{"message": "ok"})
+}
+
+// Rotate
+// @Summary Rotate secret now
+// @Description writes a generated value (never returned) and emits secret.rotated; the replaced value is
+// @Description readable through GET /api/rotation/previous until the grace period ends
+// @Tags rotation
+// @Produce json
+// @Param v query string true "key path"
+// @Security 	 BasicAuth
+// @Security 	 BearerAuth
+// @Success 200 {object} models.RotationResult ""
+// @Failure 400 {object} problem.ProblemDetail "Bad Request"
+// @Failure 401 {object} problem.ProblemDetail "Unauthorized"
+// @Failure 404 {object} problem.ProblemDetail "Not found"
+// @Failure 409 {object} problem.ProblemDetail "Entry changed during the rotation"
+// @Failure 500 {object} problem.ProblemDetail "Internal error"
+// @Router /api/rotation/rotate [post]
+func (h *RotationHandler) Rotate(w http.ResponseWriter, r *http.Request) {
+	key := r.URL.Query().Get("v")
+	if key == "" {
+		h.render.Error(w, r, errors.New("empty key"), presenters.WithStatus(http.StatusBadRequest))
+		return
+	}
+
+	result, err := h.rotationUseCase.Rotate(r.Context(), key, models.RotationManual)
+	if err != nil {
+		h.renderError(w, r, err)
+		return
+	}
+
+	h.render.JSON(w, r, result)
+}
+
+// Previous
+// @Summary Retrieve previous secret value
+// @Description value replaced by the last rotation, only during the grace period. Audited like GET /api/entry/secret-value.
+// @Tags rotation
+// @Produce json
+// @Param v query string true "key path"
+// @Param reason query string false "why the value is needed"
+// @Security 	 BasicAuth
+// @Security 	 BearerAuth
+// @Success 200 {object} models.Entry ""
+// @Failure 400 {object} problem.ProblemDetail "Bad Request"
+// @Failure 401 {object} problem.ProblemDetail "Unauthorized"
+// @Failure 404 {object} problem.ProblemDetail "Not found"
+// @Failure 410 {object} problem.ProblemDetail "Grace period expired"
+// @Failure 500 {object} problem.ProblemDetail "Internal error"
+// @Router /api/rotation/previous [get]
+func (h *RotationHandler) Previous(w http.ResponseWriter, r *http.Request) {
+	key := r.URL.Query().Get("v")
+	if key == "" {
+		h.render.Error(w, r, errors.New("empty key"), presenters.WithStatus(http.StatusBadRequest))
+		return
+	}
+
+	entry, err := h.rotationUseCase.Previous(r.Context(), key, r.URL.Query().Get("reason"))
+	if err != nil {
+		h.renderError(w, r, err)
+		return
+	}
+	if entry == nil {
+		h.render.Error(w, r, errors.New("not found key"), presenters.WithStatus(http.StatusNotFound))
+		return
+	}
+
+	h.render.JSON(w, r, entry)
+}
+
+func (h *RotationHandler) renderError(w http.ResponseWriter, r *http.Request, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, domain.ErrRotationPolicyNotFound), errors.Is(err, domain.ErrEntryNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidRotationPolicy), errors.Is(err, domain.ErrInvalidRevealReason):
+		status = http.StatusBadRequest
+	case errors.Is(err, domain.ErrVersionConflict):
+		status = http.StatusConflict
+	case errors.Is(err, domain.ErrRotationGraceExpired):
+		status = http.StatusGone
+	}
+	h.render.Error(w, r, err, presenters.WithStatus(status))
+}
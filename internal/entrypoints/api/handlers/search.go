@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+	"nbox/internal/usecases"
+	"net/http"
+	"strconv"
+
+	"github.com/norlis/httpgate/pkg/adapter/apidriven/presenters"
+	_ "github.com/norlis/httpgate/pkg/kit/problem"
+)
+
+const (
+	defaultSearchLimit = 100
+	maxSearchLimit     = 1000
+)
+
+type SearchHandler struct {
+	searchUseCase *usecases.SearchUseCase
+	render        presenters.Presenters
+}
+
+func NewSearchHandler(searchUseCase *usecases.SearchUseCase, render presenters.Presenters) *SearchHandler {
+	return &SearchHandler{searchUseCase: searchUseCase, render: render}
+}
+
+// Search
+// @Summary Search keys across the store
+// @Description match the full key by glob (production/*/db_host, `*` does not cross `/`), substring or regex.
+// @Description Only keys and metadata are returned, never the values.
+// @Tags entry
+// @Produce json
+// @Param q query string true "query"
+// @Param mode query string false "match mode" Enums(glob, substring, regex) default(substring)
+// @Param secure query bool false "only secure (true) or plain (false) entries"
+// @Param type_validator_name query string false "only entries validated by the type validator"
+// @Param limit query int false "max keys (default 100, max 1000)"
+// @Security 	 BasicAuth
+// @Security 	 BearerAuth
+// @Success 200 {object} models.SearchResponse ""
+// @Failure 400 {object} problem.ProblemDetail "Bad Request"
+// @Failure 401 {object} problem.ProblemDetail "Unauthorized"
+// @Failure 500 {object} problem.ProblemDetail "Internal error"
+// @Router /api/entry/search [get]
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter := models.SearchFilter{
+		Query:             query.Get("q"),
+		Mode:              models.SearchMode(query.Get("mode")),
+		TypeValidatorName: query.Get("type_validator_name"),
+		Limit:             defaultSearchLimit,
+	}
+	if filter.Mode == "" {
+		filter.Mode = models.SearchModeSubstring
+	}
+
+	if value := query.Get("secure"); value != "" {
+		secure, err := strconv.ParseBool(value)
+		if err != nil {
+			h.render.Error(w, r, fmt.Errorf("secure must be a boolean"), presenters.WithStatus(http.StatusBadRequest))
+			return
+		}
+		filter.Secure = &secure
+	}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxSearchLimit {
+			h.render.Error(w, r, fmt.Errorf("limit must be between 1 and %d", maxSearchLimit), presenters.WithStatus(http.StatusBadRequest))
+			return
+		}
+		filter.Limit = limit
+	}
+
+	result, err := h.searchUseCase.Search(r.Context(), filter)
+	if errors.Is(err, domain.ErrInvalidSearch) {
+		h.render.Error(w, r, err, presenters.WithStatus(http.StatusBadRequest))
+		return
+	}
+	if err != nil {
+		h.render.Error(w, r, err, presenters.WithStatus(http.StatusInternalServerError))
+		return
+	}
+
+	h.render.JSON(w, r, result)
+}
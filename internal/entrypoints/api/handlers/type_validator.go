@@ -2,9 +2,13 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"nbox/internal/domain"
 	"nbox/internal/domain/models"
+	"nbox/internal/usecases"
 	"net/http"
+	"strconv"
 
 	"github.com/norlis/httpgate/pkg/adapter/apidriven/presenters"
 	_ "github.com/norlis/httpgate/pkg/kit/problem"
@@ -12,23 +16,40 @@ import (
 
 type TypeValidatorHandler struct {
 	typeValidatorAdapter domain.TypeValidatorAdapter
+	typeValidatorUseCase *usecases.TypeValidatorUseCase
 	render               presenters.Presenters
 }
 
-func NewTypeValidatorHandler(typeValidatorAdapter domain.TypeValidatorAdapter, render presenters.Presenters) *TypeValidatorHandler {
-	return &TypeValidatorHandler{typeValidatorAdapter: typeValidatorAdapter, render: render}
+func NewTypeValidatorHandler(typeValidatorAdapter domain.TypeValidatorAdapter, typeValidatorUseCase *usecases.TypeValidatorUseCase, render presenters.Presenters) *TypeValidatorHandler {
+	return &TypeValidatorHandler{typeValidatorAdapter: typeValidatorAdapter, typeValidatorUseCase: typeValidatorUseCase, render: render}
+}
+
+// boolQuery optional boolean query parameter, false when missing
+func boolQuery(r *http.Request, name string) (bool, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return b, nil
 }
 
 // Upsert
 // @Summary Create or update type validator
-// @Description Create or update a custom type validator
+// @Description Create or update a custom type validator. The entries already using it are checked against
+// @Description the new definition and reported; with strict=true the change is rejected when any of them fails.
 // @Tags type_validator
 // @Accept json
 // @Produce json
 // @Param data body models.TypeValidator true "Type Validator"
+// @Param strict query bool false "reject the change when existing entries fail"
 // @Param authorization header string true "Bearer | Basic"
-// @Success 200 {object} map[string]string ""
+// @Success 200 {object} models.RevalidationReport ""
 // @Failure 400 {object} problem.ProblemDetail "Bad Request"
+// @Failure 409 {object} models.RevalidationReport "Existing entries fail the new definition"
 // @Failure 401 {object} problem.ProblemDetail "Unauthorized"
 // @Failure 500 {object} problem.ProblemDetail "Internal error"
 // @Router /api/type-validator [post]
@@ -41,12 +62,24 @@ func (h *TypeValidatorHandler) Upsert(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.typeValidatorAdapter.Upsert(ctx, validator); err != nil {
+	strict, err := boolQuery(r, "strict")
+	if err != nil {
 		h.render.Error(w, r, err, presenters.WithStatus(http.StatusBadRequest))
 		return
 	}
 
-	h.render.JSON(w, r, map[string]string{"message": "ok"})
+	report, err := h.typeValidatorUseCase.Upsert(ctx, validator, strict)
+	if errors.Is(err, domain.ErrRevalidationFailed) {
+		w.WriteHeader(http.StatusConflict)
+		h.render.JSON(w, r, report)
+		return
+	}
+	if err != nil {
+		h.render.Error(w, r, err, presenters.WithStatus(http.StatusBadRequest))
+		return
+	}
+
+	h.render.JSON(w, r, report)
 }
 
 // List
@@ -106,15 +139,47 @@ func (h *TypeValidatorHandler) GetByName(w http.ResponseWriter, r *http.Request)
 	h.render.JSON(w, r, validator)
 }
 
+// Usage
+// @Summary Entries using a type validator
+// @Description List the entries whose values are validated by the type validator
+// @Tags type_validator
+// @Produce json
+// @Param name query string true "validator name"
+// @Param authorization header string true "Bearer | Basic"
+// @Success 200 {object} models.TypeValidatorUsage ""
+// @Failure 400 {object} problem.ProblemDetail "Bad Request"
+// @Failure 401 {object} problem.ProblemDetail "Unauthorized"
+// @Failure 500 {object} problem.ProblemDetail "Internal error"
+// @Router /api/type-validator/name/usage [get]
+func (h *TypeValidatorHandler) Usage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	name := r.URL.Query().Get("name")
+
+	if name == "" {
+		h.render.Error(w, r, http.ErrMissingFile, presenters.WithStatus(http.StatusBadRequest))
+		return
+	}
+
+	usage, err := h.typeValidatorUseCase.Usage(ctx, name)
+	if err != nil {
+		h.render.Error(w, r, err, presenters.WithStatus(http.StatusInternalServerError))
+		return
+	}
+
+	h.render.JSON(w, r, usage)
+}
+
 // Delete
 // @Summary Delete type validator
-// @Description Delete a custom type validator
+// @Description Delete a custom type validator. It is rejected while entries still use it, unless force=true.
 // @Tags type_validator
 // @Produce json
 // @Param name query string true "validator name"
+// @Param force query bool false "delete even if entries still use it"
 // @Param authorization header string true "Bearer | Basic"
-// @Success 200 {object} object{message=string} ""
+// @Success 200 {object} object{message=string,keys=[]string} ""
 // @Failure 400 {object} problem.ProblemDetail "Bad Request"
+// @Failure 409 {object} object{message=string,keys=[]string} "Entries still use the validator"
 // @Failure 401 {object} problem.ProblemDetail "Unauthorized"
 // @Failure 500 {object} problem.ProblemDetail "Internal error"
 // @Router /api/type-validator/name [delete]
@@ -127,11 +192,28 @@ func (h *TypeValidatorHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.typeValidatorAdapter.Delete(ctx, name)
+	force, err := boolQuery(r, "force")
 	if err != nil {
 		h.render.Error(w, r, err, presenters.WithStatus(http.StatusBadRequest))
 		return
 	}
 
+	keys, err := h.typeValidatorUseCase.Delete(ctx, name, force)
+	if errors.Is(err, domain.ErrTypeValidatorInUse) {
+		w.WriteHeader(http.StatusConflict)
+		h.render.JSON(w, r, map[string]any{"message": err.Error(), "keys": keys})
+		return
+	}
+	if err != nil {
+		h.render.Error(w, r, err, presenters.WithStatus(http.StatusBadRequest))
+		return
+	}
+
+	if len(keys) > 0 {
+		// forced: the keys that still reference the deleted validator
+		h.render.JSON(w, r, map[string]any{"message": "ok", "keys": keys})
+		return
+	}
+
 	h.render.JSON(w, r, map[string]string{"message": "ok"})
 }
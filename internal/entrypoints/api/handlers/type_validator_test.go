@@ -5,7 +5,10 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"nbox/internal/domain"
 	"nbox/internal/domain/models"
+	"nbox/internal/domain/models/operations"
+	"nbox/internal/usecases"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -50,6 +53,52 @@ func (m *mockTypeValidatorAdapter) Delete(ctx context.Context, name string) erro
 	return nil
 }
 
+// mockEntryAdapter only answers ListByTypeValidator
+type mockEntryAdapter struct {
+	entries []models.Entry
+}
+
+func (m *mockEntryAdapter) Upsert(context.Context, []models.Entry) operations.Results { return nil }
+
+func (m *mockEntryAdapter) MaxAtomicBatch() int { return 0 }
+
+func (m *mockEntryAdapter) UpsertAll(context.Context, []models.Entry) (operations.Results, error) {
+	return nil, nil
+}
+
+func (m *mockEntryAdapter) Retrieve(context.Context, string) (*models.Entry, error) { return nil, nil }
+
+func (m *mockEntryAdapter) List(context.Context, string) ([]models.Entry, error) { return nil, nil }
+
+func (m *mockEntryAdapter) ListPage(context.Context, string, string, int) ([]models.Entry, string, error) {
+	return nil, "", nil
+}
+
+func (m *mockEntryAdapter) Delete(context.Context, string) ([]string, error) { return nil, nil }
+
+func (m *mockEntryAdapter) Tracking(context.Context, string) ([]models.Tracking, error) {
+	return nil, nil
+}
+
+func (m *mockEntryAdapter) SearchKeys(context.Context, string) ([]models.Entry, error) {
+	return nil, nil
+}
+
+func (m *mockEntryAdapter) ListByTypeValidator(_ context.Context, name string) ([]models.Entry, error) {
+	var entries []models.Entry
+	for _, entry := range m.entries {
+		if entry.TypeValidatorName == name {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func newTestTypeValidatorHandler(adapter domain.TypeValidatorAdapter, entries domain.EntryAdapter, render presenters.Presenters) *TypeValidatorHandler {
+	useCase := usecases.NewTypeValidatorUseCase(adapter, entries, nil, zap.NewNop())
+	return NewTypeValidatorHandler(adapter, useCase, render)
+}
+
 func TestTypeValidatorHandler_Upsert(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -87,7 +136,7 @@ func TestTypeValidatorHandler_Upsert(t *testing.T) {
 				upsertFunc: tt.adapterFunc,
 			}
 			render := presenters.NewPresenters(zap.NewNop())
-			handler := NewTypeValidatorHandler(adapter, render)
+			handler := newTestTypeValidatorHandler(adapter, &mockEntryAdapter{}, render)
 
 			body, _ := json.Marshal(tt.validator)
 			req := httptest.NewRequest(http.MethodPost, "/api/type-validator", bytes.NewBuffer(body))
@@ -105,7 +154,7 @@ func TestTypeValidatorHandler_Upsert(t *testing.T) {
 func TestTypeValidatorHandler_Upsert_InvalidJSON(t *testing.T) {
 	adapter := &mockTypeValidatorAdapter{}
 	render := presenters.NewPresenters(zap.NewNop())
-	handler := NewTypeValidatorHandler(adapter, render)
+	handler := newTestTypeValidatorHandler(adapter, &mockEntryAdapter{}, render)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/type-validator", bytes.NewBufferString("{invalid json}"))
 	rec := httptest.NewRecorder()
@@ -159,7 +208,7 @@ func TestTypeValidatorHandler_List(t *testing.T) {
 				listFunc: tt.adapterFunc,
 			}
 			render := presenters.NewPresenters(zap.NewNop())
-			handler := NewTypeValidatorHandler(adapter, render)
+			handler := newTestTypeValidatorHandler(adapter, &mockEntryAdapter{}, render)
 
 			req := httptest.NewRequest(http.MethodGet, "/api/type-validator", nil)
 			rec := httptest.NewRecorder()
@@ -229,7 +278,7 @@ func TestTypeValidatorHandler_GetByName(t *testing.T) {
 				retrieveFunc: tt.adapterFunc,
 			}
 			render := presenters.NewPresenters(zap.NewNop())
-			handler := NewTypeValidatorHandler(adapter, render)
+			handler := newTestTypeValidatorHandler(adapter, &mockEntryAdapter{}, render)
 
 			url := "/api/type-validator/name"
 			if tt.queryName != "" {
@@ -292,7 +341,7 @@ func TestTypeValidatorHandler_Delete(t *testing.T) {
 				deleteFunc: tt.adapterFunc,
 			}
 			render := presenters.NewPresenters(zap.NewNop())
-			handler := NewTypeValidatorHandler(adapter, render)
+			handler := newTestTypeValidatorHandler(adapter, &mockEntryAdapter{}, render)
 
 			url := "/api/type-validator/name"
 			if tt.queryName != "" {
@@ -319,3 +368,145 @@ func TestTypeValidatorHandler_Delete(t *testing.T) {
 		})
 	}
 }
+
+func TestTypeValidatorHandler_UpsertRevalidates(t *testing.T) {
+	entries := &mockEntryAdapter{entries: []models.Entry{
+		{Key: "development/app/port", Value: "8080", TypeValidatorName: "port"},
+		{Key: "development/app/admin-port", Value: "80", TypeValidatorName: "port"},
+	}}
+	tightened := models.TypeValidator{Name: "port", Regex: `^[0-9]{4,5}$`}
+
+	tests := []struct {
+		name           string
+		url            string
+		expectedStatus int
+		expectedStored bool
+	}{
+		{name: "report only", url: "/api/type-validator", expectedStatus: http.StatusOK, expectedStored: true},
+		{name: "strict rejects", url: "/api/type-validator?strict=true", expectedStatus: http.StatusConflict},
+		{name: "invalid strict", url: "/api/type-validator?strict=maybe", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored := false
+			adapter := &mockTypeValidatorAdapter{
+				upsertFunc: func(ctx context.Context, validator models.TypeValidator) error {
+					stored = true
+					return nil
+				},
+			}
+			handler := newTestTypeValidatorHandler(adapter, entries, presenters.NewPresenters(zap.NewNop()))
+
+			body, _ := json.Marshal(tightened)
+			req := httptest.NewRequest(http.MethodPost, tt.url, bytes.NewBuffer(body))
+			rec := httptest.NewRecorder()
+
+			handler.Upsert(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			if stored != tt.expectedStored {
+				t.Errorf("Expected stored %v, got %v", tt.expectedStored, stored)
+			}
+			if rec.Code == http.StatusBadRequest {
+				return
+			}
+
+			var report models.RevalidationReport
+			if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if report.Checked != 2 || len(report.Invalid) != 1 || report.Invalid[0].Key != "development/app/admin-port" {
+				t.Errorf("Unexpected report %+v", report)
+			}
+			if report.Applied != tt.expectedStored {
+				t.Errorf("Expected applied %v, got %v", tt.expectedStored, report.Applied)
+			}
+		})
+	}
+}
+
+func TestTypeValidatorHandler_DeleteInUse(t *testing.T) {
+	entries := &mockEntryAdapter{entries: []models.Entry{
+		{Key: "development/app/port", Value: "8080", TypeValidatorName: "port"},
+	}}
+
+	tests := []struct {
+		name            string
+		url             string
+		expectedStatus  int
+		expectedDeleted bool
+	}{
+		{name: "blocked", url: "/api/type-validator/name?name=port", expectedStatus: http.StatusConflict},
+		{name: "forced", url: "/api/type-validator/name?name=port&force=true", expectedStatus: http.StatusOK, expectedDeleted: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deleted := false
+			adapter := &mockTypeValidatorAdapter{
+				deleteFunc: func(ctx context.Context, name string) error {
+					deleted = true
+					return nil
+				},
+			}
+			handler := newTestTypeValidatorHandler(adapter, entries, presenters.NewPresenters(zap.NewNop()))
+
+			req := httptest.NewRequest(http.MethodDelete, tt.url, nil)
+			rec := httptest.NewRecorder()
+
+			handler.Delete(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			if deleted != tt.expectedDeleted {
+				t.Errorf("Expected deleted %v, got %v", tt.expectedDeleted, deleted)
+			}
+
+			var result struct {
+				Keys []string `json:"keys"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if len(result.Keys) != 1 || result.Keys[0] != "development/app/port" {
+				t.Errorf("Expected the referencing key, got %v", result.Keys)
+			}
+		})
+	}
+}
+
+func TestTypeValidatorHandler_Usage(t *testing.T) {
+	entries := &mockEntryAdapter{entries: []models.Entry{
+		{Key: "development/app/port", Value: "8080", TypeValidatorName: "port", Version: 3},
+		{Key: "development/app/name", Value: "app", TypeValidatorName: "name"},
+	}}
+	handler := newTestTypeValidatorHandler(&mockTypeValidatorAdapter{}, entries, presenters.NewPresenters(zap.NewNop()))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/type-validator/name/usage?name=port", nil)
+	rec := httptest.NewRecorder()
+	handler.Usage(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	var usage models.TypeValidatorUsage
+	if err := json.NewDecoder(rec.Body).Decode(&usage); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if usage.Count != 1 || usage.Entries[0].Key != "development/app/port" || usage.Entries[0].Version != 3 {
+		t.Errorf("Unexpected usage %+v", usage)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/type-validator/name/usage", nil)
+	rec = httptest.NewRecorder()
+	handler.Usage(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for missing name, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"nbox/internal/domain"
+	"nbox/internal/usecases"
+	"net/http"
+
+	"github.com/norlis/httpgate/pkg/adapter/apidriven/presenters"
+	_ "github.com/norlis/httpgate/pkg/kit/problem"
+)
+
+type WebhookHandler struct {
+	webhookUseCase *usecases.WebhookUseCase
+	render         presenters.Presenters
+}
+
+func NewWebhookHandler(webhookUseCase *usecases.WebhookUseCase, render presenters.Presenters) *WebhookHandler {
+	return &WebhookHandler{webhookUseCase: webhookUseCase, render: render}
+}
+
+// Create
+// @Summary Create webhook
+// @Description Subscribe an url to events. Payloads are signed with HMAC-SHA256 in the X-Nbox-Signature header;
+// @Description when no secret is sent one is generated. The secret is only returned by this endpoint.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security 	 BasicAuth
+// @Security 	 BearerAuth
+// @Param data body domain.Webhook true "url, events and optional secret"
+// @Success 201 {object} domain.Webhook ""
+// @Failure 400 {object} problem.ProblemDetail "Bad Request"
+// @Failure 401 {object} problem.ProblemDetail "Unauthorized"
+// @Failure 500 {object} problem.ProblemDetail "Internal error"
+// @Router /api/webhook [post]
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var webhook domain.Webhook
+	if err := json.NewDecoder(r.Body).Decode(&webhook); err != nil {
+		h.render.Error(w, r, err, presenters.WithStatus(http.StatusBadRequest))
+		return
+	}
+
+	created, err := h.webhookUseCase.Create(ctx, webhook)
+	if errors.Is(err, domain.ErrInvalidWebhook) {
+		h.render.Error(w, r, err, presenters.WithStatus(http.StatusBadRequest))
+		return
+	}
+	if err != nil {
+		h.render.Error(w, r, err, presenters.WithStatus(http.StatusInternalServerError))
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	h.render.JSON(w, r, created)
+}
+
+// List
+// @Summary List webhooks
+// @Description List the registered webhooks, secrets are not included
+// @Tags webhooks
+// @Produce json
+// @Security 	 BasicAuth
+// @Security 	 BearerAuth
+// @Success 200 {object} []domain.Webhook ""
+// @Failure 401 {object} problem.ProblemDetail "Unauthorized"
+// @Failure 500 {object} problem.ProblemDetail "Internal error"
+// @Router /api/webhook [get]
+func (h *WebhookHandler) List(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := h.webhookUseCase.List(r.Context())
+	if err != nil {
+		h.render.Error(w, r, err, presenters.WithStatus(http.StatusInternalServerError))
+		return
+	}
+
+	h.render.JSON(w, r, webhooks)
+}
+
+// Delete
+// @Summary Delete webhook
+// @Description Remove the subscription, its dead letters are kept
+// @Tags webhooks
+// @Produce json
+// @Security 	 BasicAuth
+// @Security 	 BearerAuth
+// @Param id path string true "webhook id"
+// @Success 200 {object} object{message=string} ""
+// @Failure 401 {object} problem.ProblemDetail "Unauthorized"
+// @Failure 404 {object} problem.ProblemDetail "Not found"
+// @Failure 500 {object} problem.ProblemDetail "Internal error"
+// @Router /api/webhook/{id} [delete]
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	err := h.webhookUseCase.Delete(r.Context(), r.PathValue("id"))
+	if errors.Is(err, domain.ErrWebhookNotFound) {
+		h.render.Error(w, r, err, presenters.WithStatus(http.StatusNotFound))
+		return
+	}
+	if err != nil {
+		h.render.Error(w, r, err, presenters.WithStatus(http.StatusInternalServerError))
+		return
+	}
+
+	h.render.JSON(w, r, map[string]string{"message": "ok"})
+}
+
+// FailedDeliveries
+// @Summary List failed deliveries
+// @Description Dead letters of the webhook: deliveries that exhausted their retries, newest first
+// @Tags webhooks
+// @Produce json
+// @Security 	 BasicAuth
+// @Security 	 BearerAuth
+// @Param id path string true "webhook id"
+// @Success 200 {object} []domain.WebhookDelivery ""
+// @Failure 401 {object} problem.ProblemDetail "Unauthorized"
+// @Failure 500 {object} problem.ProblemDetail "Internal error"
+// @Router /api/webhook/{id}/deliveries [get]
+func (h *WebhookHandler) FailedDeliveries(w http.ResponseWriter, r *http.Request) {
+	deliveries, err := h.webhookUseCase.FailedDeliveries(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.render.Error(w, r, err, presenters.WithStatus(http.StatusInternalServerError))
+		return
+	}
+
+	h.render.JSON(w, r, deliveries)
+}
@@ -1,10 +1,7 @@
 package httpapi
 
 import (
-	"context"
 	"errors"
-	"log"
-	"nbox/internal/adapters/amazonaws"
 	"nbox/internal/adapters/sse"
 	"nbox/internal/application"
 	"nbox/internal/entrypoints/api/auth"
@@ -25,23 +22,32 @@ import (
 	"go.uber.org/zap"
 )
 
+// ReadinessCheckers dependencies reported by GET /ready, provided by the selected storage backend
+type ReadinessCheckers map[string]port.Checker
+
 type Params struct {
 	fx.In
-	Router          *http.ServeMux
-	Box             *handlers.BoxHandler
-	Entry           *handlers.EntryHandler
-	TypeValidator   *handlers.TypeValidatorHandler
-	Static          *handlers.StaticHandler
-	Authn           *auth.Authn
-	Status          *health.Status
-	Render          presenters.Presenters
-	Logger          *zap.Logger
-	S3Checker       *amazonaws.S3Checker
-	DynamoDBChecker *amazonaws.DynamoDBChecker
-	SSMChecker      *amazonaws.SSMChecker
-	EventBroker     *sse.EventBroker
-	UI              *handlers.UIHandler
-	Export          *handlers.ExportHandler
+	Router        *http.ServeMux
+	Box           *handlers.BoxHandler
+	Entry         *handlers.EntryHandler
+	TypeValidator *handlers.TypeValidatorHandler
+	Static        *handlers.StaticHandler
+	Authn         *auth.Authn
+	Status        *health.Status
+	Render        presenters.Presenters
+	Logger        *zap.Logger
+	Authz         *opa.Client
+	Checkers      ReadinessCheckers
+	EventBroker   *sse.EventBroker
+	UI            *handlers.UIHandler
+	Export        *handlers.ExportHandler
+	Import        *handlers.ImportHandler
+	Webhook       *handlers.WebhookHandler
+	Audit         *handlers.AuditHandler
+	Rotation      *handlers.RotationHandler
+	Reconcile     *handlers.ReconcileHandler
+	Resolve       *handlers.ResolveHandler
+	Search        *handlers.SearchHandler
 }
 
 // NewHttpApi
@@ -74,40 +80,25 @@ func NewHttpApi(params Params) {
 		middleware.AllowAll(params.Logger).Middleware,
 	}
 
-	opaConfig := opa.Config{
-		Query:        "data.authz.allow",
-		PoliciesPath: "policies/authz", // Directorio con authz.rego
-		DataFiles:    []string{},
-	}
-
-	authz, err := opa.NewOpaSdkClientFromConfig(context.Background(), opaConfig, params.Logger)
-
-	if err != nil {
-		log.Fatalf("No se pudo inicializar el cliente OPA: %v", err)
-	}
-
 	use := middleware.Chain(base...)
 
 	params.Router.Handle("GET /status", use(params.Status))
 	params.Router.Handle("GET /health", use(health.NewProbe(nil)))
 	//router.Handle("GET /live", use(health.NewProbe(nil)))
-	params.Router.Handle("GET /ready", use(health.NewProbe(map[string]port.Checker{
-		"s3":       params.S3Checker,
-		"ssm":      params.SSMChecker,
-		"dynamodb": params.DynamoDBChecker,
-	})))
+	params.Router.Handle("GET /ready", use(health.NewProbe(params.Checkers)))
 
 	params.Router.Handle("GET /swagger/", use(httpSwagger.Handler(
 		httpSwagger.URL("/swagger/doc.json"),
 	)))
 
 	params.Router.Handle("POST /api/auth/token", use(params.Authn.TokenHandler()))
-	params.Router.Handle("GET /api/events", params.EventBroker)
 	params.Router.HandleFunc("GET /events", params.UI.EventsPage)
 	params.Router.Handle("GET /assets/", params.UI.ServeAssets())
 
 	api := http.NewServeMux()
 
+	api.Handle("GET /api/events", params.EventBroker)
+
 	api.HandleFunc("POST /api/box", params.Box.UpsertBox)
 	api.HandleFunc("GET /api/box", params.Box.List)
 	api.HandleFunc("HEAD /api/box/{service}/{stage}/{template}", params.Box.Exist)
@@ -119,17 +110,40 @@ func NewHttpApi(params Params) {
 	api.HandleFunc("GET /api/entry/key", params.Entry.GetByKey)
 	api.HandleFunc("GET /api/entry/prefix", params.Entry.ListByPrefix)
 	api.HandleFunc("GET /api/entry/export", params.Export.Export)
+	api.HandleFunc("GET /api/entry/resolve", params.Resolve.Resolve)
+	api.HandleFunc("GET /api/entry/search", params.Search.Search)
+	api.HandleFunc("POST /api/entry/import", params.Import.Import)
 	api.HandleFunc("DELETE /api/entry/key", params.Entry.DeleteKey)
 
 	api.HandleFunc("GET /api/entry/secret-value", params.Entry.RetrieveSecretValue)
 
 	api.HandleFunc("GET /api/track/key", params.Entry.Tracking)
+	api.HandleFunc("POST /api/track/key/rollback", params.Entry.Rollback)
+	api.HandleFunc("POST /api/entry/retype", params.Entry.Retype)
+	api.HandleFunc("GET /api/entry/secret-check", params.Entry.SecretCheck)
+	api.HandleFunc("GET /api/entry/reconcile", params.Reconcile.Report)
+	api.HandleFunc("POST /api/entry/reconcile", params.Reconcile.Repair)
 
 	api.HandleFunc("POST /api/type-validator", params.TypeValidator.Upsert)
 	api.HandleFunc("GET /api/type-validator", params.TypeValidator.List)
 	api.HandleFunc("GET /api/type-validator/name", params.TypeValidator.GetByName)
+	api.HandleFunc("GET /api/type-validator/name/usage", params.TypeValidator.Usage)
 	api.HandleFunc("DELETE /api/type-validator/name", params.TypeValidator.Delete)
 
+	api.HandleFunc("POST /api/webhook", params.Webhook.Create)
+	api.HandleFunc("GET /api/webhook", params.Webhook.List)
+	api.HandleFunc("DELETE /api/webhook/{id}", params.Webhook.Delete)
+	api.HandleFunc("GET /api/webhook/{id}/deliveries", params.Webhook.FailedDeliveries)
+
+	api.HandleFunc("GET /api/audit/secret-reveals", params.Audit.SecretReveals)
+
+	api.HandleFunc("POST /api/rotation", params.Rotation.Upsert)
+	api.HandleFunc("GET /api/rotation", params.Rotation.List)
+	api.HandleFunc("GET /api/rotation/key", params.Rotation.GetByKey)
+	api.HandleFunc("DELETE /api/rotation/key", params.Rotation.Delete)
+	api.HandleFunc("POST /api/rotation/rotate", params.Rotation.Rotate)
+	api.HandleFunc("GET /api/rotation/previous", params.Rotation.Previous)
+
 	api.HandleFunc("GET /api/static/environments", params.Static.Environments)
 
 	//swagger.yaml
@@ -139,7 +153,7 @@ func NewHttpApi(params Params) {
 			base,
 			[]middleware.Middleware{
 				params.Authn.Handler(),
-				middleware.AuthorizationMiddleware(authz, FromContextExtractor),
+				middleware.AuthorizationMiddleware(params.Authz, FromContextExtractor),
 			}...,
 		)...,
 	)
@@ -4,15 +4,33 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"nbox/internal/adapters/sse"
 	"nbox/internal/application"
 	"net"
 	"net/http"
 	"time"
 
+	"github.com/norlis/httpgate/pkg/adapter/opa"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 )
 
+// NewOpaClient cliente OPA con las políticas de policies/authz (data.authz.allow)
+func NewOpaClient(logger *zap.Logger) (*opa.Client, error) {
+	opaConfig := opa.Config{
+		Query:        "data.authz.allow",
+		PoliciesPath: "policies/authz", // Directorio con authz.rego
+		DataFiles:    []string{},
+	}
+
+	return opa.NewOpaSdkClientFromConfig(context.Background(), opaConfig, logger)
+}
+
+// NewEventAuthorizer the SSE broker evaluates the same policy for every event it sends
+func NewEventAuthorizer(client *opa.Client) sse.Authorizer {
+	return client
+}
+
 func NewHttpServerMux(lc fx.Lifecycle, logger *zap.Logger) *http.ServeMux {
 	mux := http.NewServeMux()
 	listener := net.JoinHostPort(application.Address, application.Port)
@@ -0,0 +1,67 @@
+package scheduler
+
+import (
+	"context"
+	"nbox/internal/application"
+	"nbox/internal/usecases"
+	"sync"
+	"time"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// NewReconcileScheduler compares the secure entries with Parameter Store every NBOX_RECONCILE_SECONDS,
+// 0 (default) disables it. Differences are repaired only with NBOX_RECONCILE_REPAIR.
+func NewReconcileScheduler(lc fx.Lifecycle, config *application.Config, reconcile *usecases.ReconcileUseCase, logger *zap.Logger) {
+	logger = logger.Named("reconcile_scheduler")
+
+	if config.ReconcileSeconds <= 0 {
+		logger.Info("Reconcile scheduler disabled")
+		return
+	}
+	interval := time.Duration(config.ReconcileSeconds) * time.Second
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+						report, err := reconcile.Reconcile(ctx, "", config.ReconcileRepair)
+						if err != nil {
+							logger.Error("ErrReconcile", zap.Error(err))
+							continue
+						}
+						for _, issue := range report.Issues {
+							logger.Warn("Secure entry out of sync",
+								zap.String("problem", issue.Problem),
+								zap.String("key", issue.Key),
+								zap.String("parameter", issue.Parameter),
+								zap.Bool("repaired", issue.Repaired),
+								zap.String("error", issue.Error),
+							)
+						}
+					}
+				}
+			}()
+			logger.Info("Reconcile scheduler started", zap.Duration("interval", interval), zap.Bool("repair", config.ReconcileRepair))
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			wg.Wait()
+			return nil
+		},
+	})
+}
@@ -0,0 +1,65 @@
+package scheduler
+
+import (
+	"context"
+	"nbox/internal/application"
+	"nbox/internal/usecases"
+	"sync"
+	"time"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// NewRotationScheduler rotates the due secrets every NBOX_ROTATION_CHECK_SECONDS, 0 disables it.
+// Replicas may run it concurrently: rotations are conditional writes on the entry version.
+func NewRotationScheduler(lc fx.Lifecycle, config *application.Config, rotation *usecases.RotationUseCase, logger *zap.Logger) {
+	logger = logger.Named("rotation_scheduler")
+
+	if config.RotationCheckSeconds <= 0 {
+		logger.Info("Rotation scheduler disabled")
+		return
+	}
+	interval := time.Duration(config.RotationCheckSeconds) * time.Second
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				run(ctx, interval, rotation, logger)
+			}()
+			logger.Info("Rotation scheduler started", zap.Duration("interval", interval))
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			wg.Wait()
+			return nil
+		},
+	})
+}
+
+func run(ctx context.Context, interval time.Duration, rotation *usecases.RotationUseCase, logger *zap.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			rotated, err := rotation.RotateDue(ctx)
+			if err != nil {
+				logger.Error("ErrRotateDue", zap.Error(err))
+				continue
+			}
+			if len(rotated) > 0 {
+				logger.Info("Scheduled rotation", zap.Strings("keys", rotated))
+			}
+		}
+	}
+}
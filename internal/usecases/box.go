@@ -13,13 +13,15 @@ type BoxUseCase struct {
 	templateAdapter domain.TemplateAdapter
 	entryAdapter    domain.EntryAdapter
 	pathUseCase     *PathUseCase
+	resolveUseCase  *ResolveUseCase
 }
 
-func NewBox(boxOperation domain.TemplateAdapter, entryOperations domain.EntryAdapter, pathUseCase *PathUseCase) *BoxUseCase {
+func NewBox(boxOperation domain.TemplateAdapter, entryOperations domain.EntryAdapter, pathUseCase *PathUseCase, resolveUseCase *ResolveUseCase) *BoxUseCase {
 	return &BoxUseCase{
 		templateAdapter: boxOperation,
 		entryAdapter:    entryOperations,
 		pathUseCase:     pathUseCase,
+		resolveUseCase:  resolveUseCase,
 	}
 }
 
@@ -49,6 +51,16 @@ func (b *BoxUseCase) BuildBox(ctx context.Context, service string, stage string,
 		}
 	}
 
+	if proc.HasResolvedVars() {
+		resolved, err := b.resolveUseCase.Resolve(ctx, service, stage)
+		if err != nil {
+			return "", err
+		}
+		for _, entry := range resolved.Entries {
+			tree[ResolvedVarPrefix+entry.Key] = b.transformBySchema(schema, entry.Value)
+		}
+	}
+
 	return proc.Replace(tree), nil
 }
 
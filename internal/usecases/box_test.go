@@ -3,15 +3,20 @@ package usecases
 import (
 	"context"
 	"fmt"
+	"nbox/internal/application"
 	"strings"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 )
 
 func TestBoxUseCase_BuildBox(t *testing.T) {
 	mockTemplate := &mockTemplateAdapter{}
 	mockEntry := &mockEntryAdapter{}
 
-	useCase := NewBox(mockTemplate, mockEntry, NewPathUseCase())
+	useCase := NewBox(mockTemplate, mockEntry, NewPathUseCase(), NewResolveUseCase(mockEntry, &application.Config{}, zap.NewNop()))
 	results, err := useCase.BuildBox(context.Background(), "test", "development", "test.json", map[string]string{})
 
 	fmt.Println(results)
@@ -26,3 +31,23 @@ func TestBoxUseCase_BuildBox(t *testing.T) {
 		t.Errorf(`Expected %s got: %s`, expected, results)
 	}
 }
+
+type fixedTemplateAdapter struct {
+	mockTemplateAdapter
+	text string
+}
+
+func (m *fixedTemplateAdapter) RetrieveBox(_ context.Context, _ string, _ string, _ string) ([]byte, error) {
+	return []byte(m.text), nil
+}
+
+func TestBoxUseCase_BuildBoxWithResolvedVars(t *testing.T) {
+	templates := &fixedTemplateAdapter{text: `{"db": "{{ @DB_HOST }}", "level": "{{@LOG_LEVEL}}", "timeout": "{{global/TIMEOUT}}", "missing": "{{@MISSING}}"}`}
+	resolve := newResolveFixture()
+
+	useCase := NewBox(templates, resolve.entryAdapter, NewPathUseCase(), resolve)
+	result, err := useCase.BuildBox(context.Background(), "myapp", "production", "app.json", map[string]string{})
+	require.NoError(t, err)
+
+	assert.Equal(t, `{"db": "myapp.production.internal", "level": "warn", "timeout": "30", "missing": ""}`, result)
+}
@@ -2,6 +2,7 @@ package usecases
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"nbox/internal/application"
 	"nbox/internal/domain"
@@ -14,6 +15,7 @@ type EntryUseCase struct {
 	entryAdapter         domain.EntryAdapter
 	secretAdapter        domain.SecretAdapter
 	typeValidatorAdapter domain.TypeValidatorAdapter
+	keyPolicy            *KeyPolicy
 	config               *application.Config
 }
 
@@ -21,12 +23,14 @@ func NewEntryUseCase(
 	entryAdapter domain.EntryAdapter,
 	secretAdapter domain.SecretAdapter,
 	typeValidatorAdapter domain.TypeValidatorAdapter,
+	keyPolicy *KeyPolicy,
 	config *application.Config,
 ) domain.EntryUseCase {
 	return &EntryUseCase{
 		entryAdapter:         entryAdapter,
 		secretAdapter:        secretAdapter,
 		typeValidatorAdapter: typeValidatorAdapter,
+		keyPolicy:            keyPolicy,
 		config:               config,
 	}
 }
@@ -34,20 +38,141 @@ func NewEntryUseCase(
 // Upsert
 // ARN arn:aws:ssm:<REGION_NAME>:<ACCOUNT_ID>:parameter/<parameter-name>
 func (e *EntryUseCase) Upsert(ctx context.Context, entries []models.Entry) []operations.Result {
+	results, validatedEntries := e.validate(ctx, entries)
+
+	if application.AtomicFromContext(ctx) {
+		return e.upsertAtomic(ctx, results, validatedEntries)
+	}
+
+	// a versioned write can lose the race with another writer after its secret is written, the
+	// current secret is kept to put it back when the entry is not written
+	secrets := make([]models.Entry, 0)
+	previous := make(map[string]*models.Entry)
+	pending := make([]models.Entry, 0, len(validatedEntries))
+	for _, entry := range validatedEntries {
+		if entry.Secure && entry.ExpectedVersion != nil {
+			snapshot, err := e.previousSecret(ctx, entry.Key)
+			if err != nil {
+				results = append(results, operations.Result{Key: entry.Key, Type: operations.Error, Error: fmt.Errorf("can't read the current secret of '%s' to restore it: %w", entry.Key, err)})
+				continue
+			}
+			previous[entry.Key] = snapshot
+		}
+		if entry.Secure {
+			secrets = append(secrets, entry)
+		}
+		pending = append(pending, entry)
+	}
+	validatedEntries = pending
+
+	// Only call secret adapter if there are secrets to process
+	var secureResults operations.Results
+	if len(secrets) > 0 {
+		secureResults = e.secretAdapter.Upsert(ctx, secrets)
+	} else {
+		secureResults = make(operations.Results)
+	}
+
+	for i, entry := range validatedEntries {
+		if entry.Secure {
+			err := secureResults[entry.Key].Error
+			validatedEntries[i].Value = ""
+
+			if err != nil {
+				continue
+			}
+
+			key := cleanedKey(entry.Key)
+			validatedEntries[i].Value = e.GetParameterArn(key)
+		}
+	}
+
+	updated := e.entryAdapter.Upsert(ctx, validatedEntries)
+
+	lost := make([]models.Entry, 0)
+	for _, secret := range secrets {
+		_, versioned := previous[secret.Key]
+		if current, ok := updated[secret.Key]; versioned && ok && secureResults[secret.Key].Error == nil &&
+			(current.Error != nil || current.Type == operations.Conflict) {
+			lost = append(lost, secret)
+		}
+	}
+	for key, err := range e.restoreLostSecrets(ctx, lost, previous) {
+		current := updated[key]
+		current.Error = errors.Join(current.Error, err)
+		updated[key] = current
+	}
+
+	// a failed or conflicting entry write wins over the secret result, the entry was not written
+	for k, v := range secureResults {
+		if current, ok := updated[k]; ok && (current.Error != nil || current.Type == operations.Conflict) {
+			continue
+		}
+		updated[k] = v
+	}
+
+	for _, v := range updated {
+		results = append(results, v)
+	}
+
+	return results
+}
+
+// validate rejects keys and values outside the key policy, stale writes, type validator changes
+// and values that fail their validator. Valid entries come back with their normalized key.
+func (e *EntryUseCase) validate(ctx context.Context, entries []models.Entry) ([]operations.Result, []models.Entry) {
 	var results []operations.Result
 
+	// the whole batch is rejected, each key reports why
+	if err := e.keyPolicy.CheckBatch(len(entries)); err != nil {
+		for _, entry := range entries {
+			results = append(results, operations.Result{Key: entry.Key, Type: operations.Error, Error: err})
+		}
+		return results, make([]models.Entry, 0)
+	}
+
 	// Validate entries with type validators
 	validatedEntries := make([]models.Entry, 0)
 	for _, entry := range entries {
+		entry.Key = e.keyPolicy.Normalize(entry.Key)
+		if err := e.keyPolicy.CheckKey(entry.Key); err != nil {
+			results = append(results, operations.Result{Key: entry.Key, Type: operations.Error, Error: err})
+			continue
+		}
+		if err := e.keyPolicy.CheckValue(entry); err != nil {
+			results = append(results, operations.Result{Key: entry.Key, Type: operations.Error, Error: err})
+			continue
+		}
+
 		// Check if entry already exists to prevent type validator changes
 		existingEntry, err := e.entryAdapter.Retrieve(ctx, entry.Key)
-		if err == nil && existingEntry != nil {
+
+		// Reject stale writes before touching Parameter Store, the adapter checks again atomically
+		if err == nil && entry.ExpectedVersion != nil {
+			var current int64
+			if existingEntry != nil {
+				current = existingEntry.Version
+			}
+			if current != *entry.ExpectedVersion {
+				results = append(results, operations.Result{
+					Key:  entry.Key,
+					Type: operations.Conflict,
+					Error: fmt.Errorf("%w: key '%s' expected version %d, current %d",
+						domain.ErrVersionConflict, entry.Key, *entry.ExpectedVersion, current),
+				})
+				continue
+			}
+		}
+
+		// the validator only changes through RetypeUseCase, which marks the context
+		action, _ := application.ActionFromContext(ctx)
+		if err == nil && existingEntry != nil && action != models.ActionRetype {
 			// Entry exists, check if type validator is being changed
 			if existingEntry.TypeValidatorName != entry.TypeValidatorName {
 				results = append(results, operations.Result{
 					Key:  entry.Key,
 					Type: operations.Error,
-					Error: fmt.Errorf("cannot change type validator for existing key '%s' from '%s' to '%s'. Delete and recreate the entry to change type",
+					Error: fmt.Errorf("cannot change type validator for existing key '%s' from '%s' to '%s'. Use POST /api/entry/retype to change type",
 						entry.Key, existingEntry.TypeValidatorName, entry.TypeValidatorName),
 				})
 				continue
@@ -86,59 +211,217 @@ func (e *EntryUseCase) Upsert(ctx context.Context, entries []models.Entry) []ope
 		validatedEntries = append(validatedEntries, entry)
 	}
 
+	return results, validatedEntries
+}
+
+// upsertAtomic writes every entry or none. Any invalid entry aborts the batch before writing;
+// secrets go first and are put back to their previous value (or deleted when new) if the
+// entries can't be written, which happens in a single transaction of the entry adapter.
+func (e *EntryUseCase) upsertAtomic(ctx context.Context, failed []operations.Result, entries []models.Entry) []operations.Result {
+	// the transaction limit of the backend is checked before any secret is written
+	if limit := e.entryAdapter.MaxAtomicBatch(); limit > 0 && len(entries)+len(failed) > limit {
+		err := fmt.Errorf("%w: an atomic batch holds at most %d entries", domain.ErrBatchSizeTooLarge, limit)
+		for _, entry := range entries {
+			failed = append(failed, operations.Result{Key: entry.Key, Type: operations.Error, Error: err})
+		}
+		return abortResults(failed, entries, nil, nil)
+	}
+
+	seen := make(map[string]struct{}, len(entries))
+	for _, entry := range entries {
+		key := cleanedKey(entry.Key)
+		if _, ok := seen[key]; ok {
+			failed = append(failed, operations.Result{Key: entry.Key, Type: operations.Error, Error: fmt.Errorf("duplicated key '%s' in the atomic batch", entry.Key)})
+		}
+		seen[key] = struct{}{}
+	}
+	if len(failed) > 0 {
+		return abortResults(failed, entries, nil, nil)
+	}
+
 	secrets := make([]models.Entry, 0)
-	for _, entry := range validatedEntries {
-		if entry.Secure {
-			secrets = append(secrets, entry)
+	previous := make(map[string]*models.Entry)
+	for _, entry := range entries {
+		if !entry.Secure {
+			continue
+		}
+		snapshot, err := e.previousSecret(ctx, entry.Key)
+		if err != nil {
+			failed = append(failed, operations.Result{Key: entry.Key, Type: operations.Error, Error: fmt.Errorf("can't read the current secret of '%s' to restore it: %w", entry.Key, err)})
+			continue
 		}
+		previous[entry.Key] = snapshot
+		secrets = append(secrets, entry)
+	}
+	if len(failed) > 0 {
+		return abortResults(failed, entries, nil, nil)
 	}
 
-	// Only call secret adapter if there are secrets to process
-	var secureResults operations.Results
 	if len(secrets) > 0 {
-		secureResults = e.secretAdapter.Upsert(ctx, secrets)
-	} else {
-		secureResults = make(operations.Results)
+		written := make([]models.Entry, 0, len(secrets))
+		for key, result := range e.secretAdapter.Upsert(ctx, secrets) {
+			if result.Error != nil {
+				failed = append(failed, result)
+				continue
+			}
+			written = append(written, models.Entry{Key: key})
+		}
+		if len(failed) > 0 {
+			return abortResults(failed, entries, nil, e.restoreSecrets(ctx, written, previous))
+		}
 	}
 
-	for i, entry := range validatedEntries {
+	for i, entry := range entries {
 		if entry.Secure {
-			err := secureResults[entry.Key].Error
-			validatedEntries[i].Value = ""
+			entries[i].Value = e.GetParameterArn(cleanedKey(entry.Key))
+		}
+	}
 
-			if err != nil {
-				continue
+	updated, err := e.entryAdapter.UpsertAll(ctx, entries)
+	if err != nil {
+		for _, result := range updated {
+			failed = append(failed, result)
+		}
+		return abortResults(failed, entries, err, e.restoreSecrets(ctx, secrets, previous))
+	}
+
+	results := make([]operations.Result, 0, len(updated))
+	for _, result := range updated {
+		results = append(results, result)
+	}
+	return results
+}
+
+// previousSecret plain value of the secret of the key before the batch, nil when the key has no secret yet
+func (e *EntryUseCase) previousSecret(ctx context.Context, key string) (*models.Entry, error) {
+	existing, err := e.entryAdapter.Retrieve(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil || !existing.Secure || existing.Value == "" {
+		return nil, nil
+	}
+
+	secret, err := e.secretAdapter.RetrieveSecretValue(ctx, secretName(existing.Value))
+	if err != nil {
+		return nil, err
+	}
+	return &models.Entry{Key: key, Value: secret.Value, Secure: true, TypeValidatorName: existing.TypeValidatorName}, nil
+}
+
+// restoreSecrets puts back the previous value of the written secrets and deletes the new ones,
+// returns the keys that could not be restored
+func (e *EntryUseCase) restoreSecrets(ctx context.Context, written []models.Entry, previous map[string]*models.Entry) map[string]error {
+	restore := make([]models.Entry, 0)
+	names := make([]string, 0)
+	keyByName := make(map[string]string)
+	for _, entry := range written {
+		if snapshot := previous[entry.Key]; snapshot != nil {
+			restore = append(restore, *snapshot)
+			continue
+		}
+		name := secretName(e.secretAdapter.Reference(cleanedKey(entry.Key)))
+		names = append(names, name)
+		keyByName[name] = entry.Key
+	}
+
+	failures := make(map[string]error)
+	if len(restore) > 0 {
+		for key, result := range e.secretAdapter.Upsert(ctx, restore) {
+			if result.Error != nil {
+				failures[key] = result.Error
 			}
+		}
+	}
+	if len(names) > 0 {
+		for name, result := range e.secretAdapter.Delete(ctx, names) {
+			if result.Error != nil {
+				failures[keyByName[name]] = result.Error
+			}
+		}
+	}
+	return failures
+}
 
-			key := cleanedKey(entry.Key)
-			validatedEntries[i].Value = e.GetParameterArn(key)
+// restoreLostSecrets puts back the secret of the entries that were not written. A secret that no
+// longer holds the value of the entry was written again by someone else and is left alone; a new
+// secret of a key another writer created can't be restored, the key must be written again.
+func (e *EntryUseCase) restoreLostSecrets(ctx context.Context, lost []models.Entry, previous map[string]*models.Entry) map[string]error {
+	failures := make(map[string]error)
+	restore := make([]models.Entry, 0, len(lost))
+	for _, entry := range lost {
+		live, err := e.secretAdapter.RetrieveSecretValue(ctx, secretName(e.secretAdapter.Reference(cleanedKey(entry.Key))))
+		if err != nil {
+			failures[entry.Key] = fmt.Errorf("the previous secret could not be restored: %w", err)
+			continue
+		}
+		if live == nil || live.Value != entry.Value {
+			continue
 		}
+		if previous[entry.Key] == nil {
+			if current, err := e.entryAdapter.Retrieve(ctx, entry.Key); err != nil || current != nil {
+				failures[entry.Key] = errors.New("the secret holds the rejected value, write the key again")
+				continue
+			}
+		}
+		restore = append(restore, entry)
 	}
 
-	updated := e.entryAdapter.Upsert(ctx, validatedEntries)
+	for key, err := range e.restoreSecrets(ctx, restore, previous) {
+		failures[key] = fmt.Errorf("the previous secret could not be restored: %w", err)
+	}
+	return failures
+}
 
-	for k, v := range secureResults {
-		updated[k] = v
+// abortResults results of an atomic batch that was not applied: the failures as they are and
+// every other entry aborted by the cause, with the secrets that could not be restored
+func abortResults(failed []operations.Result, entries []models.Entry, cause error, unrestored map[string]error) []operations.Result {
+	aborted := domain.ErrAtomicAborted
+	if cause != nil && len(failed) == 0 {
+		aborted = fmt.Errorf("%w: %v", domain.ErrAtomicAborted, cause)
 	}
 
-	for _, v := range updated {
-		results = append(results, v)
+	results := make([]operations.Result, 0, len(entries))
+	keys := make(map[string]struct{}, len(failed))
+	for _, result := range failed {
+		keys[result.Key] = struct{}{}
+		results = append(results, result)
+	}
+
+	for _, entry := range entries {
+		if _, ok := keys[entry.Key]; ok {
+			continue
+		}
+		keys[entry.Key] = struct{}{}
+		results = append(results, operations.Result{Key: entry.Key, Type: operations.Aborted, Error: aborted})
 	}
 
+	for i, result := range results {
+		if err, ok := unrestored[result.Key]; ok {
+			results[i].Error = errors.Join(result.Error, fmt.Errorf("the previous secret could not be restored: %w", err))
+		}
+	}
 	return results
 }
 
+// GetParameterArn reference of the secret, in the format of the backend that stores the key
 func (e *EntryUseCase) GetParameterArn(key string) string {
-	if e.config.ParameterShortArn && !strings.HasPrefix(key, "/") {
+	return e.secretAdapter.Reference(key)
+}
+
+// ParameterReference value stored in a secure entry to find its Parameter Store parameter: the
+// parameter name with NBOX_PARAMETER_STORE_SHORT_ARN, the full ARN otherwise
+func ParameterReference(config *application.Config, key string) string {
+	if config.ParameterShortArn && !strings.HasPrefix(key, "/") {
 		return "/" + key
 	}
 
-	if e.config.ParameterShortArn {
+	if config.ParameterShortArn {
 		return key
 	}
 
 	return fmt.Sprintf(
-		"arn:aws:ssm:%s:%s:parameter/%s", e.config.RegionName, e.config.AccountId, cleanedKey(key),
+		"arn:aws:ssm:%s:%s:parameter/%s", config.RegionName, config.AccountId, cleanedKey(key),
 	)
 }
 
@@ -0,0 +1,100 @@
+package usecases
+
+import (
+	"context"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models/operations"
+	"slices"
+
+	"go.uber.org/zap"
+)
+
+type entryDeleteUseCase struct {
+	entryAdapter  domain.EntryAdapter
+	secretAdapter domain.SecretAdapter
+	pathUseCase   *PathUseCase
+	logger        *zap.Logger
+}
+
+func NewEntryDeleteUseCase(entryAdapter domain.EntryAdapter, secretAdapter domain.SecretAdapter, pathUseCase *PathUseCase, logger *zap.Logger) domain.EntryDeleteUseCase {
+	return &entryDeleteUseCase{
+		entryAdapter:  entryAdapter,
+		secretAdapter: secretAdapter,
+		pathUseCase:   pathUseCase,
+		logger:        logger.Named("entry_delete"),
+	}
+}
+
+// Delete removes the key and its children. The Parameter Store secrets of the deleted secure entries
+// are removed afterwards, a failure there is reported in the results and does not undo the delete.
+func (e *entryDeleteUseCase) Delete(ctx context.Context, key string) ([]string, []operations.Result, error) {
+	// the deleted keys don't say which entries were secure, they are read before the delete
+	secure, err := e.secureParameters(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	deleted, err := e.entryAdapter.Delete(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keys := make([]string, 0, len(secure))
+	names := make([]string, 0, len(secure))
+	for _, k := range deleted {
+		name, ok := secure[cleanedKey(k)]
+		if !ok {
+			continue
+		}
+		keys = append(keys, k)
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	results := make([]operations.Result, 0, len(keys))
+	if len(names) == 0 {
+		return deleted, results, nil
+	}
+
+	secrets := e.secretAdapter.Delete(ctx, names)
+	for _, k := range keys {
+		result, ok := secrets[secure[cleanedKey(k)]]
+		if !ok {
+			continue
+		}
+		if result.Error != nil {
+			e.logger.Error("ErrSecretDelete", zap.String("key", k), zap.Error(result.Error))
+		}
+		result.Key = k
+		results = append(results, result)
+	}
+
+	return deleted, results, nil
+}
+
+// secureParameters parameter names of the secure entries among the key and its children, by entry key
+func (e *entryDeleteUseCase) secureParameters(ctx context.Context, key string) (map[string]string, error) {
+	secure := make(map[string]string)
+
+	entry, err := e.entryAdapter.Retrieve(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if entry != nil && entry.Secure && entry.Value != "" {
+		secure[cleanedKey(key)] = secretName(entry.Value)
+	}
+
+	children, err := e.entryAdapter.List(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range children {
+		// a secure entry whose secret write failed has no reference
+		if child.Secure && child.Value != "" {
+			secure[cleanedKey(e.pathUseCase.Concat(child.Path, child.Key))] = secretName(child.Value)
+		}
+	}
+
+	return secure, nil
+}
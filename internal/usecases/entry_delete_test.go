@@ -0,0 +1,81 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"nbox/internal/domain/models"
+	"nbox/internal/domain/models/operations"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// mockEntryAdapterWithSubtree a folder with its children, Delete removes all of them
+type mockEntryAdapterWithSubtree struct {
+	mockEntryAdapter
+	children []models.Entry
+}
+
+func (m *mockEntryAdapterWithSubtree) List(_ context.Context, _ string) ([]models.Entry, error) {
+	return m.children, nil
+}
+
+func (m *mockEntryAdapterWithSubtree) Delete(_ context.Context, key string) ([]string, error) {
+	deleted := make([]string, 0, len(m.children))
+	for _, child := range m.children {
+		deleted = append(deleted, key+"/"+child.Key)
+	}
+	return deleted, nil
+}
+
+func TestEntryDeleteUseCase_DeletesSecrets(t *testing.T) {
+	adapter := &mockEntryAdapterWithSubtree{children: []models.Entry{
+		{Path: "production/myapp", Key: "db_host", Value: "db.internal"},
+		{Path: "production/myapp", Key: "db_password", Value: "/production/myapp/db_password", Secure: true},
+		{Path: "production/myapp", Key: "api_key", Value: "arn:aws:ssm:us-east-1:000000000000:parameter/production/myapp/api_key", Secure: true},
+	}}
+
+	var requested []string
+	secrets := &mockSecretAdapter{
+		deleteFunc: func(_ context.Context, keys []string) operations.Results {
+			requested = keys
+			return operations.Results{
+				"/production/myapp/db_password": {Key: "/production/myapp/db_password", Type: operations.Deleted},
+				"/production/myapp/api_key":     {Key: "/production/myapp/api_key", Type: operations.Error, Error: errors.New("access denied")},
+			}
+		},
+	}
+
+	uc := NewEntryDeleteUseCase(adapter, secrets, NewPathUseCase(), zap.NewNop())
+	deleted, results, err := uc.Delete(context.Background(), "production/myapp")
+	require.NoError(t, err)
+
+	assert.Len(t, deleted, 3)
+	// parameters are named after the entry reference
+	assert.Equal(t, []string{"/production/myapp/db_password", "/production/myapp/api_key"}, requested)
+	require.Len(t, results, 2)
+	assert.Equal(t, "production/myapp/db_password", results[0].Key)
+	assert.Equal(t, operations.Deleted, results[0].Type)
+	assert.Equal(t, operations.Error, results[1].Type)
+	assert.EqualError(t, results[1].Error, "access denied")
+}
+
+func TestEntryDeleteUseCase_NoSecureEntries(t *testing.T) {
+	adapter := &mockEntryAdapterWithSubtree{children: []models.Entry{
+		{Path: "production/myapp", Key: "db_host", Value: "db.internal"},
+	}}
+	secrets := &mockSecretAdapter{
+		deleteFunc: func(_ context.Context, _ []string) operations.Results {
+			t.Fatal("no secret to delete")
+			return nil
+		},
+	}
+
+	uc := NewEntryDeleteUseCase(adapter, secrets, NewPathUseCase(), zap.NewNop())
+	deleted, results, err := uc.Delete(context.Background(), "production/myapp")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"production/myapp/db_host"}, deleted)
+	assert.Empty(t, results)
+}
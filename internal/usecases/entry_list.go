@@ -0,0 +1,137 @@
+package usecases
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// listCursor position of the last entry returned: its path and key
+type listCursor struct {
+	Path string `json:"path"`
+	Key  string `json:"key"`
+}
+
+// EntryListUseCase lists a prefix one page at a time. The recursive listing walks the folder
+// markers written by Upsert depth first, in key order; the cursor is the last entry returned, so
+// nothing but the current page is kept in memory.
+type EntryListUseCase struct {
+	entryAdapter domain.EntryAdapter
+	pathUseCase  *PathUseCase
+	logger       *zap.Logger
+}
+
+func NewEntryListUseCase(entryAdapter domain.EntryAdapter, pathUseCase *PathUseCase, logger *zap.Logger) *EntryListUseCase {
+	return &EntryListUseCase{
+		entryAdapter: entryAdapter,
+		pathUseCase:  pathUseCase,
+		logger:       logger.Named("entry_list"),
+	}
+}
+
+// List page of the entries under the prefix. Entries keep their own path, the full key is
+// path + key.
+func (uc *EntryListUseCase) List(ctx context.Context, opts models.ListOptions) (*models.EntryPage, error) {
+	root := strings.Trim(opts.Prefix, "/")
+	if opts.Limit < 1 {
+		return nil, fmt.Errorf("limit must be greater than 0")
+	}
+
+	path, after := root, ""
+	if opts.Next != "" {
+		cursor, err := decodeCursor(opts.Next)
+		if err != nil {
+			return nil, err
+		}
+		if cursor.Path != root && !(opts.Recursive && isUnder(cursor.Path, root)) {
+			return nil, fmt.Errorf("%w: it does not belong to '%s'", domain.ErrInvalidCursor, opts.Prefix)
+		}
+		path, after = cursor.Path, cursor.Key
+	}
+
+	w := &listWalk{uc: uc, recursive: opts.Recursive, limit: opts.Limit, entries: make([]models.Entry, 0, opts.Limit)}
+	for {
+		full, err := w.fill(ctx, path, after)
+		if err != nil {
+			return nil, err
+		}
+		if full {
+			return &models.EntryPage{Entries: w.entries, Next: w.next}, nil
+		}
+		if path == root {
+			return &models.EntryPage{Entries: w.entries}, nil
+		}
+		// the folder is done, continue in the parent after its marker
+		after = uc.pathUseCase.BaseKey(path) + "/"
+		path = uc.pathUseCase.UnescapeEmptyPath(uc.pathUseCase.PathWithoutKey(path))
+	}
+}
+
+type listWalk struct {
+	uc        *EntryListUseCase
+	recursive bool
+	limit     int
+	entries   []models.Entry
+	next      string
+}
+
+// fill adds the entries of the path after the key, descending into folders when recursive;
+// full is true once the page has limit entries
+func (w *listWalk) fill(ctx context.Context, path, after string) (bool, error) {
+	for {
+		entries, next, err := w.uc.entryAdapter.ListPage(ctx, path, after, w.limit)
+		if err != nil {
+			w.uc.logger.Error("ErrListPage", zap.String("path", path), zap.Error(err))
+			return false, err
+		}
+
+		for _, entry := range entries {
+			if w.recursive && strings.HasSuffix(entry.Key, "/") {
+				full, err := w.fill(ctx, w.uc.pathUseCase.Concat(path, strings.TrimSuffix(entry.Key, "/")), "")
+				if err != nil || full {
+					return full, err
+				}
+				continue
+			}
+
+			w.entries = append(w.entries, entry)
+			if len(w.entries) == w.limit {
+				w.next = encodeCursor(listCursor{Path: path, Key: entry.Key})
+				return true, nil
+			}
+		}
+
+		if next == "" {
+			return false, nil
+		}
+		after = next
+	}
+}
+
+func encodeCursor(cursor listCursor) string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(value string) (listCursor, error) {
+	var cursor listCursor
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return cursor, domain.ErrInvalidCursor
+	}
+	if err := json.Unmarshal(raw, &cursor); err != nil || cursor.Key == "" {
+		return cursor, domain.ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+// isUnder path is a folder below the root ("" is the root of every key)
+func isUnder(path, root string) bool {
+	return root == "" || strings.HasPrefix(path, root+"/")
+}
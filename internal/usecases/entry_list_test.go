@@ -0,0 +1,118 @@
+package usecases
+
+import (
+	"context"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newListFixture tree with the folder markers written by Upsert, children sorted by key
+func newListFixture() *EntryListUseCase {
+	adapter := &mockEntryAdapterWithTree{tree: map[string][]models.Entry{
+		"": {
+			{Path: EmptyPath, Key: "development/"},
+			{Path: EmptyPath, Key: "production/"},
+		},
+		"development": {
+			{Path: "development", Key: "host", Value: "localhost"},
+		},
+		"production": {
+			{Path: "production", Key: "host", Value: "db.internal"},
+			{Path: "production", Key: "myapp/"},
+			{Path: "production", Key: "port", Value: "5432"},
+		},
+		"production/myapp": {
+			{Path: "production/myapp", Key: "db/"},
+			{Path: "production/myapp", Key: "name", Value: "myapp"},
+		},
+		"production/myapp/db": {
+			{Path: "production/myapp/db", Key: "password", Value: "secret"},
+			{Path: "production/myapp/db", Key: "user", Value: "admin"},
+		},
+	}}
+	return NewEntryListUseCase(adapter, NewPathUseCase(), zap.NewNop())
+}
+
+func fullKeys(entries []models.Entry) []string {
+	keys := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		keys = append(keys, NewPathUseCase().Concat(entry.Path, entry.Key))
+	}
+	return keys
+}
+
+func TestEntryListUseCase_Recursive(t *testing.T) {
+	uc := newListFixture()
+
+	page, err := uc.List(context.Background(), models.ListOptions{Prefix: "production/", Recursive: true, Limit: 100})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"production/host",
+		"production/myapp/db/password",
+		"production/myapp/db/user",
+		"production/myapp/name",
+		"production/port",
+	}, fullKeys(page.Entries))
+	assert.Empty(t, page.Next)
+}
+
+func TestEntryListUseCase_NotRecursive(t *testing.T) {
+	uc := newListFixture()
+
+	page, err := uc.List(context.Background(), models.ListOptions{Prefix: "production", Limit: 100})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"production/host", "production/myapp", "production/port"}, fullKeys(page.Entries))
+}
+
+func TestEntryListUseCase_Pagination(t *testing.T) {
+	for _, limit := range []int{1, 2, 3, 4} {
+		uc := newListFixture()
+		keys := make([]string, 0)
+		opts := models.ListOptions{Prefix: "", Recursive: true, Limit: limit}
+		for pages := 0; ; pages++ {
+			require.Less(t, pages, 10)
+			page, err := uc.List(context.Background(), opts)
+			require.NoError(t, err)
+			assert.LessOrEqual(t, len(page.Entries), limit)
+			keys = append(keys, fullKeys(page.Entries)...)
+			if page.Next == "" {
+				break
+			}
+			opts.Next = page.Next
+		}
+
+		assert.Equal(t, []string{
+			"development/host",
+			"production/host",
+			"production/myapp/db/password",
+			"production/myapp/db/user",
+			"production/myapp/name",
+			"production/port",
+		}, keys, "limit %d", limit)
+	}
+}
+
+func TestEntryListUseCase_InvalidCursor(t *testing.T) {
+	uc := newListFixture()
+	ctx := context.Background()
+
+	_, err := uc.List(ctx, models.ListOptions{Prefix: "production", Recursive: true, Limit: 10, Next: "%%%"})
+	assert.ErrorIs(t, err, domain.ErrInvalidCursor)
+
+	// a cursor of another prefix can't be used to list outside of the requested one
+	page, err := uc.List(ctx, models.ListOptions{Prefix: "", Recursive: true, Limit: 1})
+	require.NoError(t, err)
+	_, err = uc.List(ctx, models.ListOptions{Prefix: "production", Recursive: true, Limit: 10, Next: page.Next})
+	assert.ErrorIs(t, err, domain.ErrInvalidCursor)
+
+	// sub folders only belong to a recursive listing
+	page, err = uc.List(ctx, models.ListOptions{Prefix: "production", Recursive: true, Limit: 2})
+	require.NoError(t, err)
+	_, err = uc.List(ctx, models.ListOptions{Prefix: "production", Limit: 10, Next: page.Next})
+	assert.ErrorIs(t, err, domain.ErrInvalidCursor)
+}
@@ -2,14 +2,9 @@ package usecases
 
 import (
 	"context"
-	"encoding/json"
-	"nbox/internal/application"
 	"nbox/internal/domain"
 	"nbox/internal/domain/models"
 	"nbox/internal/domain/models/operations"
-	"time"
-
-	"github.com/norlis/httpgate/pkg/adapter/apidriven/middleware"
 )
 
 type entryUseCaseWithEvents struct {
@@ -27,22 +22,37 @@ func NewEntryUseCaseWithEvents(uc domain.EntryUseCase, notifier domain.EventNoti
 func (d *entryUseCaseWithEvents) Upsert(ctx context.Context, entries []models.Entry) []operations.Result {
 	results := d.wrappedUseCase.Upsert(ctx, entries)
 
-	id := middleware.TraceIdFromContext(ctx)
-	user, ok := application.UserFromContext(ctx)
+	event := newEvent(ctx, domain.EventEntryActions, results)
+	d.notifier.Dispatch(ctx, event)
+	return results
+}
 
-	payload, _ := json.Marshal(results)
-	updatedBy := "ghost"
+// DeletedEvent payload of entry.deleted
+type DeletedEvent struct {
+	Key     string              `json:"key"`
+	Deleted []string            `json:"deleted"`
+	Secrets []operations.Result `json:"secrets"`
+}
+
+type entryDeleteUseCaseWithEvents struct {
+	wrappedUseCase domain.EntryDeleteUseCase
+	notifier       domain.EventNotifier
+}
 
-	if ok {
-		updatedBy = user.Name
+func NewEntryDeleteUseCaseWithEvents(uc domain.EntryDeleteUseCase, notifier domain.EventNotifier) domain.EntryDeleteUseCase {
+	return &entryDeleteUseCaseWithEvents{
+		wrappedUseCase: uc,
+		notifier:       notifier,
 	}
-	event := domain.Event[json.RawMessage]{
-		Username:      updatedBy,
-		TransactionId: id,
-		Type:          domain.EventEntryActions,
-		Timestamp:     time.Now().UTC(),
-		Payload:       payload,
+}
+
+func (d *entryDeleteUseCaseWithEvents) Delete(ctx context.Context, key string) ([]string, []operations.Result, error) {
+	deleted, secrets, err := d.wrappedUseCase.Delete(ctx, key)
+	if err != nil {
+		return nil, nil, err
 	}
+
+	event := newEvent(ctx, domain.EventEntryDeleted, DeletedEvent{Key: key, Deleted: deleted, Secrets: secrets})
 	d.notifier.Dispatch(ctx, event)
-	return results
+	return deleted, secrets, nil
 }
@@ -4,9 +4,12 @@ import (
 	"context"
 	"errors"
 	"nbox/internal/application"
+	"nbox/internal/domain"
 	"nbox/internal/domain/models"
 	"nbox/internal/domain/models/operations"
+	"strings"
 	"testing"
+	"time"
 )
 
 // Mock adapters for testing with custom upsert function
@@ -31,7 +34,12 @@ func (m *mockEntryAdapterWithUpsert) Upsert(ctx context.Context, entries []model
 }
 
 type mockSecretAdapter struct {
-	upsertFunc func(ctx context.Context, entries []models.Entry) operations.Results
+	upsertFunc     func(ctx context.Context, entries []models.Entry) operations.Results
+	retrieveFunc   func(ctx context.Context, key string) (*models.Entry, error)
+	retrieveAtFunc func(ctx context.Context, key string, at time.Time) (*models.Entry, error)
+	listFunc       func(ctx context.Context, prefix string) ([]models.SecretParameter, error)
+	deleteFunc     func(ctx context.Context, keys []string) operations.Results
+	referenceFunc  func(key string) string
 }
 
 func (m *mockSecretAdapter) Upsert(ctx context.Context, entries []models.Entry) operations.Results {
@@ -50,9 +58,44 @@ func (m *mockSecretAdapter) Upsert(ctx context.Context, entries []models.Entry)
 }
 
 func (m *mockSecretAdapter) RetrieveSecretValue(ctx context.Context, key string) (*models.Entry, error) {
+	if m.retrieveFunc != nil {
+		return m.retrieveFunc(ctx, key)
+	}
+	return nil, nil
+}
+
+func (m *mockSecretAdapter) ListSecrets(ctx context.Context, prefix string) ([]models.SecretParameter, error) {
+	if m.listFunc != nil {
+		return m.listFunc(ctx, prefix)
+	}
+	return nil, nil
+}
+
+func (m *mockSecretAdapter) RetrieveSecretValueAt(ctx context.Context, key string, at time.Time) (*models.Entry, error) {
+	if m.retrieveAtFunc != nil {
+		return m.retrieveAtFunc(ctx, key, at)
+	}
 	return nil, nil
 }
 
+func (m *mockSecretAdapter) Delete(ctx context.Context, keys []string) operations.Results {
+	if m.deleteFunc != nil {
+		return m.deleteFunc(ctx, keys)
+	}
+	results := make(operations.Results)
+	for _, key := range keys {
+		results[key] = operations.Result{Key: key, Type: operations.Deleted}
+	}
+	return results
+}
+
+func (m *mockSecretAdapter) Reference(key string) string {
+	if m.referenceFunc != nil {
+		return m.referenceFunc(key)
+	}
+	return "/" + cleanedKey(key)
+}
+
 type mockTypeValidatorAdapter struct {
 	retrieveFunc func(ctx context.Context, name string) (*models.TypeValidator, error)
 	upsertFunc   func(ctx context.Context, validator models.TypeValidator) error
@@ -262,6 +305,7 @@ func TestEntryUseCase_Upsert_WithValidation(t *testing.T) {
 				entryAdapter,
 				secretAdapter,
 				tt.typeValidatorAdapter,
+				newTestKeyPolicy(config),
 				config,
 			)
 
@@ -331,6 +375,7 @@ func TestEntryUseCase_Upsert_SecureEntries(t *testing.T) {
 		entryAdapter,
 		secretAdapter,
 		typeValidatorAdapter,
+		newTestKeyPolicy(config),
 		config,
 	)
 
@@ -373,6 +418,7 @@ func TestEntryUseCase_Upsert_ValidationBeforeSecure(t *testing.T) {
 		entryAdapter,
 		secretAdapter,
 		typeValidatorAdapter,
+		newTestKeyPolicy(config),
 		config,
 	)
 
@@ -403,3 +449,406 @@ func TestEntryUseCase_Upsert_ValidationBeforeSecure(t *testing.T) {
 		t.Error("Secret adapter should not be called when validation fails")
 	}
 }
+
+type mockEntryAdapterWithVersion struct {
+	mockEntryAdapterWithUpsert
+	version int64
+}
+
+func (m *mockEntryAdapterWithVersion) Retrieve(ctx context.Context, key string) (*models.Entry, error) {
+	return &models.Entry{Key: key, Value: "current", Version: m.version}, nil
+}
+
+func TestEntryUseCase_Upsert_ExpectedVersion(t *testing.T) {
+	upserted := 0
+	entryAdapter := &mockEntryAdapterWithVersion{
+		mockEntryAdapterWithUpsert: mockEntryAdapterWithUpsert{
+			upsertFunc: func(ctx context.Context, entries []models.Entry) operations.Results {
+				upserted += len(entries)
+				results := make(operations.Results)
+				for _, entry := range entries {
+					results[entry.Key] = operations.Result{Key: entry.Key, Type: operations.Updated}
+				}
+				return results
+			},
+		},
+		version: 3,
+	}
+
+	useCase := NewEntryUseCase(entryAdapter, &mockSecretAdapter{}, &mockTypeValidatorAdapter{}, newTestKeyPolicy(&application.Config{}), &application.Config{})
+
+	stale := int64(2)
+	current := int64(3)
+	results := useCase.Upsert(context.Background(), []models.Entry{
+		{Key: "test/stale", Value: "a", ExpectedVersion: &stale},
+		{Key: "test/current", Value: "b", ExpectedVersion: &current},
+		{Key: "test/unconditional", Value: "c"},
+	})
+
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+
+	for _, result := range results {
+		if result.Key == "test/stale" {
+			if result.Type != operations.Conflict || !errors.Is(result.Error, domain.ErrVersionConflict) {
+				t.Errorf("Expected version conflict for stale write, got %s (%v)", result.Type, result.Error)
+			}
+			continue
+		}
+		if result.Error != nil {
+			t.Errorf("Key %s: expected no error, got %v", result.Key, result.Error)
+		}
+	}
+
+	if upserted != 2 {
+		t.Errorf("Expected 2 entries written, got %d", upserted)
+	}
+}
+
+// mockAtomicEntryAdapter existing entries by key and a transactional write that can fail
+type mockAtomicEntryAdapter struct {
+	mockEntryAdapter
+	maxBatch  int
+	existing  map[string]models.Entry
+	upsertErr error
+	written   []models.Entry
+	upserts   int
+}
+
+func (m *mockAtomicEntryAdapter) Retrieve(_ context.Context, key string) (*models.Entry, error) {
+	if entry, ok := m.existing[key]; ok {
+		return &entry, nil
+	}
+	return nil, nil
+}
+
+func (m *mockAtomicEntryAdapter) Upsert(_ context.Context, entries []models.Entry) operations.Results {
+	m.upserts++
+	return nil
+}
+
+func (m *mockAtomicEntryAdapter) MaxAtomicBatch() int {
+	return m.maxBatch
+}
+
+func (m *mockAtomicEntryAdapter) UpsertAll(_ context.Context, entries []models.Entry) (operations.Results, error) {
+	if m.upsertErr != nil {
+		return operations.Results{}, m.upsertErr
+	}
+	m.written = append(m.written, entries...)
+	results := make(operations.Results)
+	for _, entry := range entries {
+		results[entry.Key] = operations.Result{Key: entry.Key, Type: operations.Updated}
+	}
+	return results, nil
+}
+
+// recordingSecretAdapter secrets by name, with the calls made to write and delete them
+type recordingSecretAdapter struct {
+	mockSecretAdapter
+	values  map[string]string
+	fail    map[string]bool
+	upserts [][]models.Entry
+	deleted []string
+}
+
+func newRecordingSecretAdapter(values map[string]string) *recordingSecretAdapter {
+	m := &recordingSecretAdapter{values: values, fail: map[string]bool{}}
+	m.upsertFunc = func(_ context.Context, entries []models.Entry) operations.Results {
+		m.upserts = append(m.upserts, entries)
+		results := make(operations.Results)
+		for _, entry := range entries {
+			if m.fail[entry.Key] {
+				results[entry.Key] = operations.Result{Key: entry.Key, Type: operations.Error, Error: errors.New("throttled")}
+				continue
+			}
+			m.values["/"+entry.Key] = entry.Value
+			results[entry.Key] = operations.Result{Key: entry.Key, Type: operations.Updated}
+		}
+		return results
+	}
+	m.retrieveFunc = func(_ context.Context, name string) (*models.Entry, error) {
+		return &models.Entry{Key: name, Value: m.values[name]}, nil
+	}
+	m.deleteFunc = func(_ context.Context, names []string) operations.Results {
+		results := make(operations.Results)
+		for _, name := range names {
+			m.deleted = append(m.deleted, name)
+			delete(m.values, name)
+			results[name] = operations.Result{Key: name, Type: operations.Deleted}
+		}
+		return results
+	}
+	return m
+}
+
+func atomicContext() context.Context {
+	return application.NewContextWithAtomic(context.Background())
+}
+
+func resultsByKey(results []operations.Result) map[string]operations.Result {
+	byKey := make(map[string]operations.Result, len(results))
+	for _, result := range results {
+		byKey[result.Key] = result
+	}
+	return byKey
+}
+
+func TestEntryUseCase_UpsertAtomic_InvalidEntryAbortsBeforeWriting(t *testing.T) {
+	entryAdapter := &mockAtomicEntryAdapter{}
+	secrets := newRecordingSecretAdapter(map[string]string{})
+	useCase := NewEntryUseCase(entryAdapter, secrets, &mockTypeValidatorAdapter{}, newTestKeyPolicy(&application.Config{}), &application.Config{})
+
+	results := resultsByKey(useCase.Upsert(atomicContext(), []models.Entry{
+		{Key: "production/app/port", Value: "not-a-number", TypeValidatorName: "number"},
+		{Key: "production/app/host", Value: "db.internal"},
+		{Key: "production/app/password", Value: "secret", Secure: true},
+	}))
+
+	if results["production/app/port"].Type != operations.Error {
+		t.Errorf("Expected the invalid entry to fail, got %s", results["production/app/port"].Type)
+	}
+	for _, key := range []string{"production/app/host", "production/app/password"} {
+		if results[key].Type != operations.Aborted || !errors.Is(results[key].Error, domain.ErrAtomicAborted) {
+			t.Errorf("Key %s: expected aborted, got %s (%v)", key, results[key].Type, results[key].Error)
+		}
+	}
+	if len(secrets.upserts) != 0 || len(entryAdapter.written) != 0 || entryAdapter.upserts != 0 {
+		t.Error("Nothing must be written when an entry is invalid")
+	}
+}
+
+func TestEntryUseCase_UpsertAtomic_DuplicatedKeys(t *testing.T) {
+	entryAdapter := &mockAtomicEntryAdapter{}
+	useCase := NewEntryUseCase(entryAdapter, newRecordingSecretAdapter(map[string]string{}), &mockTypeValidatorAdapter{}, newTestKeyPolicy(&application.Config{}), &application.Config{})
+
+	results := useCase.Upsert(atomicContext(), []models.Entry{
+		{Key: "production/app/host", Value: "a"},
+		{Key: "/production/app/host", Value: "b"},
+	})
+
+	// both normalize to the same stored key, which is reported once
+	if len(results) != 1 || results[0].Key != "production/app/host" || results[0].Type != operations.Error || len(entryAdapter.written) != 0 {
+		t.Fatalf("Expected the batch to be rejected, got %v", results)
+	}
+}
+
+func TestEntryUseCase_UpsertAtomic_SecretFailureRestoresWrittenSecrets(t *testing.T) {
+	entryAdapter := &mockAtomicEntryAdapter{existing: map[string]models.Entry{
+		"production/app/password": {Key: "password", Value: "/production/app/password", Secure: true},
+	}}
+	secrets := newRecordingSecretAdapter(map[string]string{"/production/app/password": "old"})
+	secrets.fail["production/app/token"] = true
+	useCase := NewEntryUseCase(entryAdapter, secrets, &mockTypeValidatorAdapter{}, newTestKeyPolicy(&application.Config{}), &application.Config{})
+
+	results := resultsByKey(useCase.Upsert(atomicContext(), []models.Entry{
+		{Key: "production/app/password", Value: "new", Secure: true},
+		{Key: "production/app/api_key", Value: "key", Secure: true},
+		{Key: "production/app/token", Value: "token", Secure: true},
+	}))
+
+	if results["production/app/token"].Type != operations.Error {
+		t.Errorf("Expected the failed secret to be reported, got %s", results["production/app/token"].Type)
+	}
+	if results["production/app/password"].Type != operations.Aborted || results["production/app/api_key"].Type != operations.Aborted {
+		t.Errorf("Expected the other entries aborted, got %v", results)
+	}
+	if secrets.values["/production/app/password"] != "old" {
+		t.Errorf("Expected the previous secret restored, got %q", secrets.values["/production/app/password"])
+	}
+	if _, ok := secrets.values["/production/app/api_key"]; ok {
+		t.Error("Expected the new secret deleted")
+	}
+	if len(entryAdapter.written) != 0 {
+		t.Error("Entries must not be written when a secret fails")
+	}
+}
+
+func TestEntryUseCase_UpsertAtomic_EntryFailureRestoresSecrets(t *testing.T) {
+	entryAdapter := &mockAtomicEntryAdapter{upsertErr: errors.New("transaction canceled")}
+	secrets := newRecordingSecretAdapter(map[string]string{})
+	useCase := NewEntryUseCase(entryAdapter, secrets, &mockTypeValidatorAdapter{}, newTestKeyPolicy(&application.Config{}), &application.Config{})
+
+	results := useCase.Upsert(atomicContext(), []models.Entry{
+		{Key: "production/app/host", Value: "db.internal"},
+		{Key: "production/app/password", Value: "secret", Secure: true},
+	})
+
+	for _, result := range results {
+		if result.Type != operations.Aborted || !errors.Is(result.Error, domain.ErrAtomicAborted) {
+			t.Errorf("Key %s: expected aborted, got %s (%v)", result.Key, result.Type, result.Error)
+		}
+	}
+	if len(secrets.deleted) != 1 || secrets.deleted[0] != "/production/app/password" {
+		t.Errorf("Expected the new secret deleted, got %v", secrets.deleted)
+	}
+}
+
+func TestEntryUseCase_UpsertAtomic_WritesInOneTransaction(t *testing.T) {
+	entryAdapter := &mockAtomicEntryAdapter{}
+	secrets := newRecordingSecretAdapter(map[string]string{})
+	useCase := NewEntryUseCase(entryAdapter, secrets, &mockTypeValidatorAdapter{}, newTestKeyPolicy(&application.Config{}), &application.Config{})
+
+	results := useCase.Upsert(atomicContext(), []models.Entry{
+		{Key: "production/app/host", Value: "db.internal"},
+		{Key: "production/app/password", Value: "secret", Secure: true},
+	})
+
+	for _, result := range results {
+		if result.Error != nil {
+			t.Errorf("Key %s: expected no error, got %v", result.Key, result.Error)
+		}
+	}
+	if entryAdapter.upserts != 0 || len(entryAdapter.written) != 2 {
+		t.Fatalf("Expected a single transactional write of 2 entries, got %d upserts and %d written", entryAdapter.upserts, len(entryAdapter.written))
+	}
+	for _, entry := range entryAdapter.written {
+		if entry.Secure && entry.Value != "/production/app/password" {
+			t.Errorf("Expected the secret reference stored, got %q", entry.Value)
+		}
+	}
+}
+
+func TestEntryUseCase_Upsert_SecureConflictIsNotReportedAsSuccess(t *testing.T) {
+	// a concurrent writer bumps the version after validate, the conditional write fails
+	entryAdapter := &mockEntryAdapterWithUpsert{
+		upsertFunc: func(ctx context.Context, entries []models.Entry) operations.Results {
+			results := make(operations.Results)
+			for _, entry := range entries {
+				results[entry.Key] = operations.Result{Key: entry.Key, Type: operations.Conflict, Error: domain.ErrVersionConflict}
+			}
+			return results
+		},
+	}
+	useCase := NewEntryUseCase(entryAdapter, &mockSecretAdapter{}, &mockTypeValidatorAdapter{}, newTestKeyPolicy(&application.Config{}), &application.Config{})
+
+	results := useCase.Upsert(context.Background(), []models.Entry{{Key: "production/app/password", Value: "s3cr3t", Secure: true}})
+
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results))
+	}
+	if results[0].Type != operations.Conflict || !errors.Is(results[0].Error, domain.ErrVersionConflict) {
+		t.Errorf("Expected the conflict of the entry adapter, got %s (%v)", results[0].Type, results[0].Error)
+	}
+}
+
+// mockLosingEntryAdapter a concurrent writer wins every versioned write, after running concurrent
+type mockLosingEntryAdapter struct {
+	mockEntryAdapterWithStore
+	concurrent func()
+}
+
+func (m *mockLosingEntryAdapter) Upsert(_ context.Context, entries []models.Entry) operations.Results {
+	if m.concurrent != nil {
+		m.concurrent()
+	}
+	results := make(operations.Results)
+	for _, entry := range entries {
+		results[entry.Key] = operations.Result{Key: entry.Key, Type: operations.Conflict, Error: domain.ErrVersionConflict}
+	}
+	return results
+}
+
+func TestEntryUseCase_Upsert_ConflictRestoresTheSecret(t *testing.T) {
+	current := int64(2)
+	tests := []struct {
+		name       string
+		existing   map[string]models.Entry
+		concurrent func(secrets *recordingSecretAdapter)
+		want       string
+	}{
+		{
+			name: "the previous value is put back",
+			existing: map[string]models.Entry{
+				"production/app/password": {Key: "password", Value: "/production/app/password", Secure: true, Version: current},
+			},
+			want: "old",
+		},
+		{
+			name: "a later write of the winner is kept",
+			existing: map[string]models.Entry{
+				"production/app/password": {Key: "password", Value: "/production/app/password", Secure: true, Version: current},
+			},
+			concurrent: func(secrets *recordingSecretAdapter) { secrets.values["/production/app/password"] = "winner" },
+			want:       "winner",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secrets := newRecordingSecretAdapter(map[string]string{"/production/app/password": "old"})
+			entryAdapter := &mockLosingEntryAdapter{mockEntryAdapterWithStore: mockEntryAdapterWithStore{store: tt.existing}}
+			if tt.concurrent != nil {
+				entryAdapter.concurrent = func() { tt.concurrent(secrets) }
+			}
+			useCase := NewEntryUseCase(entryAdapter, secrets, &mockTypeValidatorAdapter{}, newTestKeyPolicy(&application.Config{}), &application.Config{})
+
+			results := useCase.Upsert(context.Background(), []models.Entry{
+				{Key: "production/app/password", Value: "new", Secure: true, ExpectedVersion: &current},
+			})
+
+			if len(results) != 1 {
+				t.Fatalf("Expected 1 result, got %v", results)
+			}
+			if results[0].Type != operations.Conflict || !errors.Is(results[0].Error, domain.ErrVersionConflict) {
+				t.Errorf("Expected the conflict of the entry adapter, got %s (%v)", results[0].Type, results[0].Error)
+			}
+			if got := secrets.values["/production/app/password"]; got != tt.want {
+				t.Errorf("Expected the secret to hold %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestEntryUseCase_Upsert_ConflictOnCreateKeepsTheWinnerSecret(t *testing.T) {
+	createOnly := int64(0)
+	secrets := newRecordingSecretAdapter(map[string]string{})
+	entryAdapter := &mockLosingEntryAdapter{mockEntryAdapterWithStore: mockEntryAdapterWithStore{store: map[string]models.Entry{}}}
+	// the winner created the key between validate and the write
+	entryAdapter.concurrent = func() {
+		entryAdapter.store["production/app/password"] = models.Entry{Key: "password", Value: "/production/app/password", Secure: true, Version: 1}
+	}
+	useCase := NewEntryUseCase(entryAdapter, secrets, &mockTypeValidatorAdapter{}, newTestKeyPolicy(&application.Config{}), &application.Config{})
+
+	results := useCase.Upsert(context.Background(), []models.Entry{
+		{Key: "production/app/password", Value: "new", Secure: true, ExpectedVersion: &createOnly},
+	})
+
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result, got %v", results)
+	}
+	if results[0].Type != operations.Conflict || !errors.Is(results[0].Error, domain.ErrVersionConflict) {
+		t.Errorf("Expected the conflict of the entry adapter, got %s (%v)", results[0].Type, results[0].Error)
+	}
+	if results[0].Error == nil || !strings.Contains(results[0].Error.Error(), "write the key again") {
+		t.Errorf("Expected the result to report the rejected secret, got %v", results[0].Error)
+	}
+	if len(secrets.deleted) != 0 {
+		t.Errorf("The secret of the winner must not be deleted, deleted %v", secrets.deleted)
+	}
+}
+
+func TestEntryUseCase_UpsertAtomic_BatchOverTransactionLimit(t *testing.T) {
+	entryAdapter := &mockAtomicEntryAdapter{maxBatch: 2}
+	secrets := newRecordingSecretAdapter(map[string]string{})
+	useCase := NewEntryUseCase(entryAdapter, secrets, &mockTypeValidatorAdapter{}, newTestKeyPolicy(&application.Config{}), &application.Config{})
+
+	results := useCase.Upsert(atomicContext(), []models.Entry{
+		{Key: "production/app/password", Value: "s3cr3t", Secure: true},
+		{Key: "production/app/host", Value: "db"},
+		{Key: "production/app/port", Value: "5432"},
+	})
+
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %v", results)
+	}
+	for _, result := range results {
+		if !errors.Is(result.Error, domain.ErrBatchSizeTooLarge) {
+			t.Errorf("Key %s: expected batch size error, got %v", result.Key, result.Error)
+		}
+	}
+	if len(secrets.upserts) != 0 || len(entryAdapter.written) != 0 {
+		t.Error("Nothing must be written when the batch exceeds the transaction limit")
+	}
+}
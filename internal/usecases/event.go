@@ -3,8 +3,11 @@ package usecases
 import (
 	"context"
 	"encoding/json"
+	"nbox/internal/application"
 	"nbox/internal/domain"
+	"time"
 
+	"github.com/norlis/httpgate/pkg/adapter/apidriven/middleware"
 	"go.uber.org/zap"
 )
 
@@ -37,3 +40,22 @@ func (e *EventUseCase) Dispatch(ctx context.Context, event domain.Event[json.Raw
 		}
 	}()
 }
+
+// newEvent builds an event for the current request: transaction id from the trace middleware
+// and the authenticated user ("ghost" when there is none)
+func newEvent(ctx context.Context, eventType domain.EventType, payload any) domain.Event[json.RawMessage] {
+	raw, _ := json.Marshal(payload)
+
+	username := "ghost"
+	if user, ok := application.UserFromContext(ctx); ok {
+		username = user.Name
+	}
+
+	return domain.Event[json.RawMessage]{
+		Username:      username,
+		TransactionId: middleware.TraceIdFromContext(ctx),
+		Type:          eventType,
+		Timestamp:     time.Now().UTC(),
+		Payload:       raw,
+	}
+}
@@ -15,25 +15,34 @@ import (
 	"go.uber.org/zap"
 )
 
+// exportPageSize entries read per page in the recursive export
+const exportPageSize = 500
+
 // ExportUseCase maneja la lógica de exportación
 type ExportUseCase struct {
-	entryAdapter domain.EntryAdapter
-	config       *application.Config
-	logger       *zap.Logger
-	exporters    map[models.ExportFormat]exporter.Exporter
+	entryAdapter   domain.EntryAdapter
+	resolveUseCase *ResolveUseCase
+	listUseCase    *EntryListUseCase
+	config         *application.Config
+	logger         *zap.Logger
+	exporters      map[models.ExportFormat]exporter.Exporter
 }
 
 // NewExportUseCase crea una nueva instancia
 func NewExportUseCase(
 	entryAdapter domain.EntryAdapter,
+	resolveUseCase *ResolveUseCase,
+	listUseCase *EntryListUseCase,
 	config *application.Config,
 	logger *zap.Logger,
 ) *ExportUseCase {
 	uc := &ExportUseCase{
-		entryAdapter: entryAdapter,
-		config:       config,
-		logger:       logger,
-		exporters:    make(map[models.ExportFormat]exporter.Exporter),
+		entryAdapter:   entryAdapter,
+		resolveUseCase: resolveUseCase,
+		listUseCase:    listUseCase,
+		config:         config,
+		logger:         logger,
+		exporters:      make(map[models.ExportFormat]exporter.Exporter),
 	}
 
 	uc.exporters[models.ExportFormatJSON] = exporter.NewJSONExporter()
@@ -48,16 +57,17 @@ func (uc *ExportUseCase) Export(ctx context.Context, opts models.ExportOptions)
 	uc.logger.Info("Starting export",
 		zap.String("prefix", opts.Prefix),
 		zap.String("format", string(opts.Format)),
+		zap.String("service", opts.Service),
+		zap.String("stage", opts.Stage),
 	)
 
 	if err := opts.Validate(); err != nil {
 		return nil, err
 	}
 
-	entries, err := uc.entryAdapter.List(ctx, opts.Prefix)
+	entries, next, err := uc.entries(ctx, opts)
 	if err != nil {
-		uc.logger.Error("Failed to list entries", zap.Error(err))
-		return nil, fmt.Errorf("failed to list entries: %w", err)
+		return nil, err
 	}
 
 	if len(entries) == 0 {
@@ -83,6 +93,7 @@ func (uc *ExportUseCase) Export(ctx context.Context, opts models.ExportOptions)
 		Entries: entries,
 		Content: content,
 		Size:    int64(len(content)),
+		Next:    next,
 	}
 
 	uc.logger.Info("Export completed successfully",
@@ -94,6 +105,53 @@ func (uc *ExportUseCase) Export(ctx context.Context, opts models.ExportOptions)
 	return result, nil
 }
 
+// entries of the prefix, or the effective configuration when a service and stage are given.
+// With a limit only one page of the prefix is exported and next is the cursor of the following one.
+func (uc *ExportUseCase) entries(ctx context.Context, opts models.ExportOptions) ([]models.Entry, string, error) {
+	if opts.Resolved() {
+		resolved, err := uc.resolveUseCase.Resolve(ctx, opts.Service, opts.Stage)
+		if err != nil {
+			return nil, "", err
+		}
+		return resolved.AsEntries(), "", nil
+	}
+
+	if opts.Limit > 0 || opts.Next != "" {
+		limit := opts.Limit
+		if limit == 0 {
+			limit = exportPageSize
+		}
+		page, err := uc.listUseCase.List(ctx, models.ListOptions{Prefix: opts.Prefix, Recursive: opts.Recursive, Limit: limit, Next: opts.Next})
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Entries, page.Next, nil
+	}
+
+	if opts.Recursive {
+		entries := make([]models.Entry, 0)
+		listOpts := models.ListOptions{Prefix: opts.Prefix, Recursive: true, Limit: exportPageSize}
+		for {
+			page, err := uc.listUseCase.List(ctx, listOpts)
+			if err != nil {
+				return nil, "", err
+			}
+			entries = append(entries, page.Entries...)
+			if page.Next == "" {
+				return entries, "", nil
+			}
+			listOpts.Next = page.Next
+		}
+	}
+
+	entries, err := uc.entryAdapter.List(ctx, opts.Prefix)
+	if err != nil {
+		uc.logger.Error("Failed to list entries", zap.Error(err))
+		return nil, "", fmt.Errorf("failed to list entries: %w", err)
+	}
+	return entries, "", nil
+}
+
 func (uc *ExportUseCase) GetContentType(format models.ExportFormat) string {
 	return format.ContentType()
 }
@@ -91,6 +91,30 @@ func TestExportOptions_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "recursive json format",
+			options: models.ExportOptions{
+				Format:    models.ExportFormatJSON,
+				Recursive: true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "recursive dotenv format repeats variable names",
+			options: models.ExportOptions{
+				Format:    models.ExportFormatDotEnv,
+				Recursive: true,
+			},
+			wantErr: true,
+		},
+		{
+			name: "recursive ecs format repeats variable names",
+			options: models.ExportOptions{
+				Format:    models.ExportFormatECSTaskDef,
+				Recursive: true,
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
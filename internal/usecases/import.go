@@ -0,0 +1,232 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+	"nbox/internal/domain/models/operations"
+	"nbox/internal/usecases/importer"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// MaxImportSize tamaño máximo aceptado para un archivo de importación
+const MaxImportSize = 5 << 20 // 5 MB
+
+var ErrSecureParameterReference = errors.New("secure value is a parameter reference, the plain secret is required")
+
+// ImportUseCase maneja la lógica de importación (inverso de ExportUseCase)
+type ImportUseCase struct {
+	entryAdapter domain.EntryAdapter
+	entryUseCase domain.EntryUseCase
+	keyPolicy    *KeyPolicy
+	logger       *zap.Logger
+	importers    map[models.ExportFormat]importer.Importer
+}
+
+// NewImportUseCase crea una nueva instancia
+func NewImportUseCase(
+	entryAdapter domain.EntryAdapter,
+	entryUseCase domain.EntryUseCase,
+	keyPolicy *KeyPolicy,
+	logger *zap.Logger,
+) *ImportUseCase {
+	uc := &ImportUseCase{
+		entryAdapter: entryAdapter,
+		entryUseCase: entryUseCase,
+		keyPolicy:    keyPolicy,
+		logger:       logger,
+		importers:    make(map[models.ExportFormat]importer.Importer),
+	}
+
+	uc.importers[models.ExportFormatJSON] = importer.NewJSONImporter()
+	uc.importers[models.ExportFormatYAML] = importer.NewYAMLImporter()
+	uc.importers[models.ExportFormatDotEnv] = importer.NewDotEnvImporter()
+
+	return uc
+}
+
+// Import parses the file and writes it through EntryUseCase.Upsert, so type validators and
+// secure entries behave exactly as in POST /api/entry. With DryRun nothing is written and the
+// result only reports conflicts.
+func (uc *ImportUseCase) Import(ctx context.Context, content []byte, opts models.ImportOptions) (*models.ImportResult, error) {
+	uc.logger.Info("Starting import",
+		zap.String("prefix", opts.Prefix),
+		zap.String("format", string(opts.Format)),
+		zap.String("strategy", string(opts.Strategy)),
+		zap.Bool("dryRun", opts.DryRun),
+	)
+
+	if !opts.Format.IsImportable() {
+		return nil, fmt.Errorf("%w: %s", domain.ErrInvalidImportFormat, opts.Format)
+	}
+
+	if !opts.Strategy.IsValid() {
+		return nil, fmt.Errorf("%w: %s", domain.ErrInvalidOverwriteStrategy, opts.Strategy)
+	}
+
+	if len(content) > MaxImportSize {
+		return nil, fmt.Errorf("%w: %d bytes (max %d)", domain.ErrImportSizeLimitExceeded, len(content), MaxImportSize)
+	}
+
+	entries, err := uc.importers[opts.Format].Import(content, opts.Prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%w: no entries found", domain.ErrInvalidFileFormat)
+	}
+
+	// keys as EntryUseCase.Upsert will store them, so the diff reads the same entries it writes
+	for i := range entries {
+		entries[i].Key = uc.keyPolicy.Normalize(entries[i].Key)
+	}
+	entries = uniqueByKey(entries)
+
+	if opts.Format == models.ExportFormatDotEnv {
+		if err := uc.checkDotEnvRoundTrip(ctx, opts.Prefix, entries); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &models.ImportResult{
+		DryRun:    opts.DryRun,
+		Strategy:  opts.Strategy,
+		Total:     len(entries),
+		Conflicts: make([]models.ImportConflict, 0),
+		Unchanged: make([]string, 0),
+		Skipped:   make([]string, 0),
+		Results:   make([]operations.Result, 0),
+	}
+
+	fresh := make([]models.Entry, 0, len(entries))
+	conflicting := make([]models.Entry, 0)
+
+	for _, entry := range entries {
+		existing, err := uc.entryAdapter.Retrieve(ctx, entry.Key)
+		if err != nil {
+			uc.logger.Error("Failed to retrieve entry", zap.Error(err), zap.String("key", entry.Key))
+			return nil, fmt.Errorf("failed to retrieve entry %s: %w", entry.Key, err)
+		}
+
+		// exported files do not carry the validator, keep the one already assigned
+		if existing != nil && entry.TypeValidatorName == "" {
+			entry.TypeValidatorName = existing.TypeValidatorName
+		}
+
+		// a key changed by someone else after this check is reported as a conflict, not overwritten
+		var version int64
+		if existing != nil {
+			version = existing.Version
+		}
+		entry.ExpectedVersion = &version
+
+		switch {
+		case existing != nil && existing.Value == entry.Value && existing.Secure == entry.Secure:
+			result.Unchanged = append(result.Unchanged, entry.Key)
+		case entry.Secure && isParameterReference(entry):
+			result.Results = append(result.Results, operations.Result{
+				Key: entry.Key, Type: operations.Error, Error: ErrSecureParameterReference,
+			})
+		case existing == nil:
+			fresh = append(fresh, entry)
+		default:
+			result.Conflicts = append(result.Conflicts, models.ImportConflict{
+				Key:           entry.Key,
+				Secure:        entry.Secure,
+				CurrentSecure: existing.Secure,
+			})
+			conflicting = append(conflicting, entry)
+		}
+	}
+
+	if opts.Strategy == models.OverwriteFail && len(result.Conflicts) > 0 && !opts.DryRun {
+		return result, fmt.Errorf("%w: %d keys", domain.ErrConflictsDetected, len(result.Conflicts))
+	}
+
+	toWrite := fresh
+	switch opts.Strategy {
+	case models.OverwriteSkip:
+		for _, entry := range conflicting {
+			result.Skipped = append(result.Skipped, entry.Key)
+		}
+	case models.OverwriteAll:
+		toWrite = append(toWrite, conflicting...)
+	}
+
+	if opts.DryRun || len(toWrite) == 0 {
+		return result, nil
+	}
+
+	// the file may hold more entries than a single upsert accepts
+	size := uc.keyPolicy.BatchMaxSize()
+	if size < 1 {
+		size = len(toWrite)
+	}
+	for start := 0; start < len(toWrite); start += size {
+		end := min(start+size, len(toWrite))
+		result.Results = append(result.Results, uc.entryUseCase.Upsert(ctx, toWrite[start:end])...)
+	}
+
+	uc.logger.Info("Import completed",
+		zap.Int("entries_count", result.Total),
+		zap.Int("written", len(toWrite)),
+		zap.Int("conflicts", len(result.Conflicts)),
+	)
+
+	return result, nil
+}
+
+// checkDotEnvRoundTrip dotenv names lose '-' and '.': DB_HOST exported from db-host comes back as
+// db_host and would create a new key instead of updating the original one
+func (uc *ImportUseCase) checkDotEnvRoundTrip(ctx context.Context, prefix string, entries []models.Entry) error {
+	existing, err := uc.entryAdapter.List(ctx, uc.keyPolicy.Normalize(prefix))
+	if err != nil {
+		return fmt.Errorf("failed to list entries of %s: %w", prefix, err)
+	}
+
+	byName := make(map[string]string)
+	for _, entry := range existing {
+		if !strings.HasSuffix(entry.Key, "/") && strings.ContainsAny(entry.Key, "-.") {
+			byName[domain.ConvertToEnvVarName(entry.Key)] = entry.Key
+		}
+	}
+
+	lossy := make([]string, 0)
+	for _, entry := range entries {
+		name := entry.Key[strings.LastIndex(entry.Key, "/")+1:]
+		if key, ok := byName[domain.ConvertToEnvVarName(name)]; ok && key != name {
+			lossy = append(lossy, key)
+		}
+	}
+	if len(lossy) > 0 {
+		return fmt.Errorf("%w: %s", domain.ErrLossyDotEnvImport, strings.Join(lossy, ", "))
+	}
+	return nil
+}
+
+// uniqueByKey when the file repeats a key the last occurrence wins
+func uniqueByKey(entries []models.Entry) []models.Entry {
+	index := make(map[string]int, len(entries))
+	unique := make([]models.Entry, 0, len(entries))
+	for _, entry := range entries {
+		if i, ok := index[entry.Key]; ok {
+			unique[i] = entry
+			continue
+		}
+		index[entry.Key] = len(unique)
+		unique = append(unique, entry)
+	}
+	return unique
+}
+
+// isParameterReference exported secure entries carry the reference of the secret (ARN, short
+// parameter name, vault: or local:), never the secret itself
+func isParameterReference(entry models.Entry) bool {
+	_, name, ok := models.ParseSecretReference(entry.Value)
+	return ok && (name != entry.Value || name == "/"+cleanedKey(entry.Key))
+}
@@ -0,0 +1,243 @@
+package usecases
+
+import (
+	"context"
+	"nbox/internal/application"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+	"nbox/internal/domain/models/operations"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type mockEntryAdapterWithStore struct {
+	mockEntryAdapter
+	store map[string]models.Entry
+}
+
+func (m *mockEntryAdapterWithStore) Retrieve(_ context.Context, key string) (*models.Entry, error) {
+	entry, ok := m.store[key]
+	if !ok {
+		return nil, nil
+	}
+	return &entry, nil
+}
+
+type recordingEntryUseCase struct {
+	upserted []models.Entry
+	batches  int
+}
+
+func (r *recordingEntryUseCase) Upsert(_ context.Context, entries []models.Entry) []operations.Result {
+	r.upserted = append(r.upserted, entries...)
+	r.batches++
+	results := make([]operations.Result, 0, len(entries))
+	for _, entry := range entries {
+		results = append(results, operations.Result{Key: entry.Key, Type: operations.Updated})
+	}
+	return results
+}
+
+const importJSON = `[
+  {"key": "production/myapp/", "value": "", "secure": false},
+  {"key": "production/myapp/db_host", "value": "db.internal", "secure": false},
+  {"key": "production/myapp/db_port", "value": "5432", "secure": false},
+  {"key": "production/myapp/new_key", "value": "new", "secure": false}
+]`
+
+func newImportFixture() (*ImportUseCase, *recordingEntryUseCase) {
+	adapter := &mockEntryAdapterWithStore{store: map[string]models.Entry{
+		"production/myapp/db_host": {Key: "production/myapp/db_host", Value: "localhost"},
+		"production/myapp/db_port": {Key: "production/myapp/db_port", Value: "5432", TypeValidatorName: "number"},
+	}}
+	entryUseCase := &recordingEntryUseCase{}
+	return NewImportUseCase(adapter, entryUseCase, newTestKeyPolicy(&application.Config{}), zap.NewNop()), entryUseCase
+}
+
+func TestImportUseCase_Strategies(t *testing.T) {
+	tests := []struct {
+		name        string
+		strategy    models.OverwriteStrategy
+		dryRun      bool
+		wantErr     error
+		wantWritten []string
+		wantSkipped []string
+	}{
+		{
+			name:        "overwrite writes new and conflicting keys",
+			strategy:    models.OverwriteAll,
+			wantWritten: []string{"production/myapp/new_key", "production/myapp/db_host"},
+			wantSkipped: []string{},
+		},
+		{
+			name:        "skip keeps existing values",
+			strategy:    models.OverwriteSkip,
+			wantWritten: []string{"production/myapp/new_key"},
+			wantSkipped: []string{"production/myapp/db_host"},
+		},
+		{
+			name:     "fail aborts on conflicts",
+			strategy: models.OverwriteFail,
+			wantErr:  domain.ErrConflictsDetected,
+		},
+		{
+			name:        "dry run reports without writing",
+			strategy:    models.OverwriteFail,
+			dryRun:      true,
+			wantSkipped: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, entryUseCase := newImportFixture()
+
+			result, err := uc.Import(context.Background(), []byte(importJSON), models.ImportOptions{
+				Format:   models.ExportFormatJSON,
+				Strategy: tt.strategy,
+				DryRun:   tt.dryRun,
+			})
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, entryUseCase.upserted)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, 3, result.Total)
+			assert.Equal(t, []string{"production/myapp/db_port"}, result.Unchanged)
+			require.Len(t, result.Conflicts, 1)
+			assert.Equal(t, "production/myapp/db_host", result.Conflicts[0].Key)
+			assert.Equal(t, tt.wantSkipped, result.Skipped)
+
+			written := make([]string, 0)
+			for _, entry := range entryUseCase.upserted {
+				written = append(written, entry.Key)
+			}
+			assert.ElementsMatch(t, tt.wantWritten, written)
+		})
+	}
+}
+
+func TestImportUseCase_InvalidOptions(t *testing.T) {
+	uc, _ := newImportFixture()
+
+	_, err := uc.Import(context.Background(), []byte(importJSON), models.ImportOptions{
+		Format: models.ExportFormatECSTaskDef, Strategy: models.OverwriteAll,
+	})
+	assert.ErrorIs(t, err, domain.ErrInvalidImportFormat)
+
+	_, err = uc.Import(context.Background(), []byte(importJSON), models.ImportOptions{
+		Format: models.ExportFormatJSON, Strategy: "merge",
+	})
+	assert.ErrorIs(t, err, domain.ErrInvalidOverwriteStrategy)
+}
+
+func TestImportUseCase_DotEnvRoundTrip(t *testing.T) {
+	uc, entryUseCase := newImportFixture()
+
+	content := "PRODUCTION_MYAPP_API_URL=https://example.com\n# comment\nPRODUCTION_MYAPP_GREETING=\"hello \\\"world\\\"\"\n"
+
+	_, err := uc.Import(context.Background(), []byte(content), models.ImportOptions{
+		Prefix:   "production/myapp",
+		Format:   models.ExportFormatDotEnv,
+		Strategy: models.OverwriteAll,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, entryUseCase.upserted, 2)
+	assert.Equal(t, "production/myapp/api_url", entryUseCase.upserted[0].Key)
+	assert.Equal(t, "production/myapp/greeting", entryUseCase.upserted[1].Key)
+	assert.Equal(t, `hello "world"`, entryUseCase.upserted[1].Value)
+}
+
+func TestImportUseCase_RejectsSecureReferences(t *testing.T) {
+	uc, entryUseCase := newImportFixture()
+
+	content := `[{"key": "production/myapp/password", "value": "arn:aws:ssm:us-east-1:123:parameter/production/myapp/password", "secure": true}]`
+
+	result, err := uc.Import(context.Background(), []byte(content), models.ImportOptions{
+		Format: models.ExportFormatJSON, Strategy: models.OverwriteAll,
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Results, 1)
+	assert.ErrorIs(t, result.Results[0].Error, ErrSecureParameterReference)
+	assert.Empty(t, entryUseCase.upserted)
+}
+
+func TestImportUseCase_WritesInBatches(t *testing.T) {
+	adapter := &mockEntryAdapterWithStore{store: map[string]models.Entry{}}
+	entryUseCase := &recordingEntryUseCase{}
+	uc := NewImportUseCase(adapter, entryUseCase, newTestKeyPolicy(&application.Config{BatchMaxSize: 2}), zap.NewNop())
+
+	result, err := uc.Import(context.Background(), []byte(importJSON), models.ImportOptions{
+		Format:   models.ExportFormatJSON,
+		Strategy: models.OverwriteAll,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, entryUseCase.batches)
+	assert.Len(t, entryUseCase.upserted, 3)
+	assert.Len(t, result.Results, 3)
+}
+
+func TestImportUseCase_NormalizesKeysBeforeDiff(t *testing.T) {
+	adapter := &mockEntryAdapterWithStore{store: map[string]models.Entry{
+		"production/myapp/db_host": {Key: "production/myapp/db_host", Value: "localhost", Version: 4},
+	}}
+	entryUseCase := &recordingEntryUseCase{}
+	uc := NewImportUseCase(adapter, entryUseCase, newTestKeyPolicy(&application.Config{}), zap.NewNop())
+
+	content := `[
+  {"key": "/Production/MyApp/DB_HOST", "value": "localhost"},
+  {"key": "Production/MyApp/DB_PORT/", "value": "5432"},
+  {"key": "production/myapp/db_port", "value": "5433"}
+]`
+	result, err := uc.Import(context.Background(), []byte(content), models.ImportOptions{
+		Format:   models.ExportFormatJSON,
+		Strategy: models.OverwriteAll,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Total)
+	assert.Equal(t, []string{"production/myapp/db_host"}, result.Unchanged)
+	require.Len(t, entryUseCase.upserted, 1)
+	assert.Equal(t, "production/myapp/db_port", entryUseCase.upserted[0].Key)
+	assert.Equal(t, "5433", entryUseCase.upserted[0].Value)
+}
+
+type mockEntryAdapterWithList struct {
+	mockEntryAdapterWithStore
+	listed []models.Entry
+}
+
+func (m *mockEntryAdapterWithList) List(_ context.Context, _ string) ([]models.Entry, error) {
+	return m.listed, nil
+}
+
+func TestImportUseCase_DotEnvRejectsLossyKeys(t *testing.T) {
+	adapter := &mockEntryAdapterWithList{
+		mockEntryAdapterWithStore: mockEntryAdapterWithStore{store: map[string]models.Entry{}},
+		listed: []models.Entry{
+			{Path: "production/myapp", Key: "db-host", Value: "db.internal"},
+			{Path: "production/myapp", Key: "db_port", Value: "5432"},
+		},
+	}
+	entryUseCase := &recordingEntryUseCase{}
+	uc := NewImportUseCase(adapter, entryUseCase, newTestKeyPolicy(&application.Config{}), zap.NewNop())
+	opts := models.ImportOptions{Format: models.ExportFormatDotEnv, Prefix: "production/myapp", Strategy: models.OverwriteAll}
+
+	_, err := uc.Import(context.Background(), []byte("DB_HOST=db.internal\n"), opts)
+	require.ErrorIs(t, err, domain.ErrLossyDotEnvImport)
+	assert.Contains(t, err.Error(), "db-host")
+	assert.Empty(t, entryUseCase.upserted)
+
+	// names that map back to their own key are fine
+	_, err = uc.Import(context.Background(), []byte("DB_PORT=5433\nNEW_KEY=x\n"), opts)
+	require.NoError(t, err)
+	assert.Len(t, entryUseCase.upserted, 2)
+}
@@ -0,0 +1,30 @@
+package importer
+
+import (
+	"nbox/internal/domain/models"
+	"strings"
+)
+
+// Importer interfaz para importadores de diferentes formatos (inverso de exporter.Exporter)
+type Importer interface {
+	Import(content []byte, prefix string) ([]models.Entry, error)
+}
+
+// normalize deja solo las variables con key, descartando los marcadores de carpeta ("path/")
+// que List devuelve y que los exportadores incluyen con valor vacío.
+func normalize(entries []models.Entry) []models.Entry {
+	normalized := make([]models.Entry, 0, len(entries))
+	for _, entry := range entries {
+		key := strings.TrimSpace(entry.Key)
+		if key == "" || strings.HasSuffix(key, "/") {
+			continue
+		}
+		normalized = append(normalized, models.Entry{
+			Key:               strings.Trim(key, "/ "),
+			Value:             entry.Value,
+			Secure:            entry.Secure,
+			TypeValidatorName: entry.TypeValidatorName,
+		})
+	}
+	return normalized
+}
@@ -0,0 +1,99 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+	"strings"
+)
+
+type DotEnvImporter struct{}
+
+func NewDotEnvImporter() *DotEnvImporter {
+	return &DotEnvImporter{}
+}
+
+// Import lee un archivo .env (KEY=value). Los nombres de variable no conservan la ruta original,
+// por lo que cada variable se guarda como <prefix>/<name en minúsculas>. Si el nombre empieza por
+// el prefijo convertido (PRODUCTION_MYAPP_ para production/myapp), se elimina para no duplicarlo.
+// example: prefix "production/myapp", "PRODUCTION_MYAPP_DB_HOST=x" -> "production/myapp/db_host"
+// Keys with '-' or '.' don't survive the round trip (db-host is exported as DB_HOST), the import
+// use case rejects the files that would create them again with '_'.
+func (i *DotEnvImporter) Import(content []byte, prefix string) ([]models.Entry, error) {
+	prefix = strings.Trim(strings.TrimSpace(prefix), "/")
+	if prefix == "" {
+		return nil, fmt.Errorf("%w: prefix is required for dotenv files", domain.ErrInvalidImportFormat)
+	}
+	envPrefix := domain.ConvertToEnvVarName(prefix) + "_"
+
+	entries := make([]models.Entry, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	line := 0
+
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		text = strings.TrimPrefix(text, "export ")
+
+		name, value, ok := strings.Cut(text, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("%w: line %d is not KEY=value", domain.ErrInvalidFileFormat, line)
+		}
+
+		value, err := i.unescapeValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", domain.ErrInvalidFileFormat, line, err)
+		}
+
+		name = strings.TrimPrefix(name, envPrefix)
+		entries = append(entries, models.Entry{
+			Key:   fmt.Sprintf("%s/%s", prefix, strings.ToLower(name)),
+			Value: value,
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidFileFormat, err)
+	}
+
+	return entries, nil
+}
+
+// unescapeValue inverso de DotEnvExporter.escapeValue
+func (i *DotEnvImporter) unescapeValue(value string) (string, error) {
+	if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+		return value[1 : len(value)-1], nil
+	}
+
+	if !strings.HasPrefix(value, `"`) {
+		return value, nil
+	}
+
+	if len(value) < 2 || !strings.HasSuffix(value, `"`) {
+		return "", fmt.Errorf("unterminated quoted value")
+	}
+
+	value = value[1 : len(value)-1]
+	var builder strings.Builder
+	escaped := false
+	for _, r := range value {
+		if escaped {
+			builder.WriteRune(r)
+			escaped = false
+			continue
+		}
+		if r == '\\' {
+			escaped = true
+			continue
+		}
+		builder.WriteRune(r)
+	}
+
+	return builder.String(), nil
+}
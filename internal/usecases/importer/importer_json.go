@@ -0,0 +1,23 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+)
+
+type JSONImporter struct{}
+
+func NewJSONImporter() *JSONImporter {
+	return &JSONImporter{}
+}
+
+// Import lee el array generado por JSONExporter; las keys ya son absolutas, prefix no aplica
+func (i *JSONImporter) Import(content []byte, _ string) ([]models.Entry, error) {
+	var entries []models.Entry
+	if err := json.Unmarshal(content, &entries); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidFileFormat, err)
+	}
+	return normalize(entries), nil
+}
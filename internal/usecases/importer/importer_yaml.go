@@ -0,0 +1,24 @@
+package importer
+
+import (
+	"fmt"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+type YAMLImporter struct{}
+
+func NewYAMLImporter() *YAMLImporter {
+	return &YAMLImporter{}
+}
+
+// Import lee la lista generada por YAMLExporter; las keys ya son absolutas, prefix no aplica
+func (i *YAMLImporter) Import(content []byte, _ string) ([]models.Entry, error) {
+	var entries []models.Entry
+	if err := yaml.Unmarshal(content, &entries); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidFileFormat, err)
+	}
+	return normalize(entries), nil
+}
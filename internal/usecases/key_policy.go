@@ -0,0 +1,100 @@
+package usecases
+
+import (
+	"fmt"
+	"nbox/internal/application"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+	"regexp"
+	"strings"
+)
+
+// KeyPolicy limits of the keys, values and batches accepted by EntryUseCase.Upsert.
+// A zero limit or an empty charset disables that check.
+type KeyPolicy struct {
+	config  *application.Config
+	segment *regexp.Regexp
+}
+
+// NewKeyPolicy fails when the charset of the config is not a valid character class
+func NewKeyPolicy(config *application.Config) (*KeyPolicy, error) {
+	policy := &KeyPolicy{config: config}
+	if config.KeyCharset != "" {
+		segment, err := regexp.Compile(fmt.Sprintf("^[%s]+$", config.KeyCharset))
+		if err != nil {
+			return nil, fmt.Errorf("invalid key charset '%s': %w", config.KeyCharset, err)
+		}
+		policy.segment = segment
+	}
+	return policy, nil
+}
+
+// NormalizeKey lowercase, without surrounding spaces or slashes and under the default prefix
+// unless it already starts with an allowed one. The entry adapters store keys this way.
+func NormalizeKey(config *application.Config, key string) string {
+	key = strings.ToLower(key)
+	key = strings.TrimSpace(key)
+	key = strings.Trim(key, "/")
+
+	for _, prefix := range config.AllowedPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return key
+		}
+	}
+	defaultPrefix := strings.Trim(config.DefaultPrefix, "/")
+	if defaultPrefix == "" {
+		return key
+	}
+	return fmt.Sprintf("%s/%s", defaultPrefix, key)
+}
+
+// Normalize key as it will be stored
+func (p *KeyPolicy) Normalize(key string) string {
+	return NormalizeKey(p.config, key)
+}
+
+// CheckKey validates a normalized key: length, depth and the charset of every segment
+func (p *KeyPolicy) CheckKey(key string) error {
+	if p.config.KeyMaxLength > 0 && len(key) > p.config.KeyMaxLength {
+		return fmt.Errorf("%w: key '%s' has %d bytes, the maximum is %d", domain.ErrKeyTooLong, key, len(key), p.config.KeyMaxLength)
+	}
+
+	segments := strings.Split(key, "/")
+	if p.config.KeyMaxDepth > 0 && len(segments) > p.config.KeyMaxDepth {
+		return fmt.Errorf("%w: key '%s' has %d levels, the maximum is %d", domain.ErrInvalidKeyFormat, key, len(segments), p.config.KeyMaxDepth)
+	}
+
+	for _, segment := range segments {
+		switch {
+		case segment == "":
+			return fmt.Errorf("%w: key '%s' has an empty segment", domain.ErrInvalidKeyFormat, key)
+		case strings.HasPrefix(segment, domain.ReservedKeyPrefix):
+			return fmt.Errorf("%w: segment '%s' of key '%s' starts with the reserved prefix '%s'", domain.ErrInvalidKeyFormat, segment, key, domain.ReservedKeyPrefix)
+		case p.segment != nil && !p.segment.MatchString(segment):
+			return fmt.Errorf("%w: segment '%s' of key '%s' only allows [%s]", domain.ErrInvalidKeyFormat, segment, key, p.config.KeyCharset)
+		}
+	}
+
+	return nil
+}
+
+// CheckValue the value of plain entries and the secret of secure ones share the limit
+func (p *KeyPolicy) CheckValue(entry models.Entry) error {
+	if p.config.ValueMaxLength > 0 && len(entry.Value) > p.config.ValueMaxLength {
+		return fmt.Errorf("%w: value of key '%s' has %d bytes, the maximum is %d", domain.ErrValueTooLong, entry.Key, len(entry.Value), p.config.ValueMaxLength)
+	}
+	return nil
+}
+
+// CheckBatch number of entries of a single upsert
+func (p *KeyPolicy) CheckBatch(size int) error {
+	if p.config.BatchMaxSize > 0 && size > p.config.BatchMaxSize {
+		return fmt.Errorf("%w: %d entries, the maximum is %d", domain.ErrBatchSizeTooLarge, size, p.config.BatchMaxSize)
+	}
+	return nil
+}
+
+// BatchMaxSize entries per upsert, 0 without limit
+func (p *KeyPolicy) BatchMaxSize() int {
+	return p.config.BatchMaxSize
+}
@@ -0,0 +1,140 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"nbox/internal/application"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+	"nbox/internal/domain/models/operations"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestKeyPolicy(config *application.Config) *KeyPolicy {
+	policy, err := NewKeyPolicy(config)
+	if err != nil {
+		panic(err)
+	}
+	return policy
+}
+
+func policyConfig() *application.Config {
+	return &application.Config{
+		DefaultPrefix:   "global",
+		AllowedPrefixes: []string{"global/", "production/"},
+		KeyCharset:      "a-z0-9._-",
+		KeyMaxDepth:     4,
+		KeyMaxLength:    40,
+		ValueMaxLength:  8,
+		BatchMaxSize:    3,
+	}
+}
+
+func TestNormalizeKey(t *testing.T) {
+	config := policyConfig()
+
+	assert.Equal(t, "production/app/host", NormalizeKey(config, " /Production/App/HOST/ "))
+	assert.Equal(t, "global/app/host", NormalizeKey(config, "app/host"))
+	assert.Equal(t, "app/host", NormalizeKey(&application.Config{}, "/app/host"))
+}
+
+func TestKeyPolicy_CheckKey(t *testing.T) {
+	policy := newTestKeyPolicy(policyConfig())
+
+	tests := []struct {
+		key  string
+		want error
+	}{
+		{key: "production/app/db_host", want: nil},
+		{key: "production/app/v1.2-beta", want: nil},
+		{key: "production/app/db host", want: domain.ErrInvalidKeyFormat},
+		{key: "production/app/_lock", want: domain.ErrInvalidKeyFormat},
+		{key: "production/app//host", want: domain.ErrInvalidKeyFormat},
+		{key: "production/a/b/c/d", want: domain.ErrInvalidKeyFormat},
+		{key: "production/" + strings.Repeat("a", 40), want: domain.ErrKeyTooLong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			err := policy.CheckKey(tt.key)
+			if tt.want == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestKeyPolicy_ZeroLimitsDisableChecks(t *testing.T) {
+	policy := newTestKeyPolicy(&application.Config{})
+
+	assert.NoError(t, policy.CheckKey("production/a b/"+strings.Repeat("x/", 20)+"c"))
+	assert.NoError(t, policy.CheckValue(models.Entry{Key: "k", Value: strings.Repeat("v", 1<<20)}))
+	assert.NoError(t, policy.CheckBatch(10000))
+}
+
+func TestNewKeyPolicy_InvalidCharset(t *testing.T) {
+	_, err := NewKeyPolicy(&application.Config{KeyCharset: "z-a"})
+	assert.Error(t, err)
+}
+
+func TestEntryUseCase_Upsert_KeyPolicy(t *testing.T) {
+	var written []models.Entry
+	entryAdapter := &mockEntryAdapterWithUpsert{
+		upsertFunc: func(ctx context.Context, entries []models.Entry) operations.Results {
+			written = append(written, entries...)
+			results := make(operations.Results)
+			for _, entry := range entries {
+				results[entry.Key] = operations.Result{Key: entry.Key, Type: operations.Created}
+			}
+			return results
+		},
+	}
+	config := policyConfig()
+	useCase := NewEntryUseCase(entryAdapter, &mockSecretAdapter{}, &mockTypeValidatorAdapter{}, newTestKeyPolicy(config), config)
+
+	results := useCase.Upsert(context.Background(), []models.Entry{
+		{Key: "/Production/App/Host", Value: "db"},
+		{Key: "production/app/_lock", Value: "x"},
+		{Key: "production/app/big", Value: "123456789"},
+	})
+
+	byKey := make(map[string]operations.Result)
+	for _, result := range results {
+		byKey[result.Key] = result
+	}
+	require.Len(t, byKey, 3)
+	assert.Equal(t, operations.Created, byKey["production/app/host"].Type)
+	assert.ErrorIs(t, byKey["production/app/_lock"].Error, domain.ErrInvalidKeyFormat)
+	assert.ErrorIs(t, byKey["production/app/big"].Error, domain.ErrValueTooLong)
+
+	require.Len(t, written, 1)
+	assert.Equal(t, "production/app/host", written[0].Key)
+}
+
+func TestEntryUseCase_Upsert_BatchTooLarge(t *testing.T) {
+	var written []models.Entry
+	entryAdapter := &mockEntryAdapterWithUpsert{
+		upsertFunc: func(ctx context.Context, entries []models.Entry) operations.Results {
+			written = append(written, entries...)
+			return make(operations.Results)
+		},
+	}
+	config := policyConfig()
+	useCase := NewEntryUseCase(entryAdapter, &mockSecretAdapter{}, &mockTypeValidatorAdapter{}, newTestKeyPolicy(config), config)
+
+	entries := []models.Entry{{Key: "a", Value: "1"}, {Key: "b", Value: "2"}, {Key: "c", Value: "3"}, {Key: "d", Value: "4"}}
+	results := useCase.Upsert(context.Background(), entries)
+
+	require.Len(t, results, len(entries))
+	for _, result := range results {
+		assert.Equal(t, operations.Error, result.Type)
+		assert.True(t, errors.Is(result.Error, domain.ErrBatchSizeTooLarge), result.Key)
+	}
+	assert.Empty(t, written)
+}
@@ -17,6 +17,18 @@ func (m *mockEntryAdapter) Upsert(_ context.Context, _ []models.Entry) operation
 	return nil
 }
 
+func (m *mockEntryAdapter) MaxAtomicBatch() int {
+	return 0
+}
+
+func (m *mockEntryAdapter) UpsertAll(_ context.Context, entries []models.Entry) (operations.Results, error) {
+	results := make(operations.Results)
+	for _, entry := range entries {
+		results[entry.Key] = operations.Result{Key: entry.Key, Type: operations.Updated}
+	}
+	return results, nil
+}
+
 func (m *mockEntryAdapter) Retrieve(_ context.Context, _ string) (*models.Entry, error) {
 	return nil, nil
 }
@@ -33,14 +45,26 @@ func (m *mockEntryAdapter) List(_ context.Context, _ string) ([]models.Entry, er
 	return entries, nil
 }
 
-func (m *mockEntryAdapter) Delete(_ context.Context, _ string) error {
-	return nil
+func (m *mockEntryAdapter) ListPage(_ context.Context, _ string, _ string, _ int) ([]models.Entry, string, error) {
+	return nil, "", nil
+}
+
+func (m *mockEntryAdapter) Delete(_ context.Context, _ string) ([]string, error) {
+	return nil, nil
 }
 
 func (m *mockEntryAdapter) Tracking(_ context.Context, _ string) ([]models.Tracking, error) {
 	return nil, nil
 }
 
+func (m *mockEntryAdapter) ListByTypeValidator(_ context.Context, _ string) ([]models.Entry, error) {
+	return nil, nil
+}
+
+func (m *mockEntryAdapter) SearchKeys(_ context.Context, _ string) ([]models.Entry, error) {
+	return nil, nil
+}
+
 func (m *mockTemplateAdapter) UpsertBox(ctx context.Context, box *models.Box) []string {
 	return nil
 }
@@ -16,6 +16,8 @@ type Processor struct {
 const (
 	ExpressionSingle = `{([^{}]*)}`
 	ExpressionDouble = `{{(.*?)}}` // ExpressionDouble double curly braces
+	// ResolvedVarPrefix {{@DB_HOST}} effective value of the variable for the service and stage of the box
+	ResolvedVarPrefix = "@"
 )
 
 func NewProcessor(tmpl string) *Processor {
@@ -46,6 +48,9 @@ func (p *Processor) GetPrefixes() []string {
 	var k []string
 	for _, v := range p.vars {
 		cleaned := strings.TrimSpace(v)
+		if strings.HasPrefix(cleaned, ResolvedVarPrefix) {
+			continue
+		}
 		prefix := path.Dir(cleaned)
 		if prefix == "." {
 			prefix = ""
@@ -58,6 +63,16 @@ func (p *Processor) GetPrefixes() []string {
 	return k
 }
 
+// HasResolvedVars the template uses effective variables ({{@KEY}})
+func (p *Processor) HasResolvedVars() bool {
+	for _, v := range p.vars {
+		if strings.HasPrefix(strings.TrimSpace(v), ResolvedVarPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
 func (p *Processor) Replace(values map[string]string) string {
 	var oldnew []string
 	for _, v := range p.vars {
@@ -0,0 +1,223 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"nbox/internal/application"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// orphanMinAge a parameter is written before its entry, younger orphans may belong to an upsert in progress
+const orphanMinAge = 10 * time.Minute
+
+// ReconcileUseCase compares the secure entries with the parameters written by nbox. Secure entries
+// are split across both stores, a partial failure of EntryUseCase.Upsert leaves them out of sync.
+type ReconcileUseCase struct {
+	entryAdapter  domain.EntryAdapter
+	secretAdapter domain.SecretAdapter
+	pathUseCase   *PathUseCase
+	config        *application.Config
+	logger        *zap.Logger
+	now           func() time.Time
+}
+
+func NewReconcileUseCase(
+	entryAdapter domain.EntryAdapter,
+	secretAdapter domain.SecretAdapter,
+	pathUseCase *PathUseCase,
+	config *application.Config,
+	logger *zap.Logger,
+) *ReconcileUseCase {
+	return &ReconcileUseCase{
+		entryAdapter:  entryAdapter,
+		secretAdapter: secretAdapter,
+		pathUseCase:   pathUseCase,
+		config:        config,
+		logger:        logger.Named("reconcile"),
+		now:           func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Reconcile reports, under the prefix (all if empty):
+//   - orphan: parameter tagged project=nbox that no secure entry references
+//   - missing_parameter: secure entry whose parameter does not exist
+//   - reference_mismatch: secure entry referencing its parameter in another format than the configured one
+//
+// With repair, orphans older than orphanMinAge are deleted and references are rewritten in the
+// configured format. A missing parameter can't be repaired, its value must be written again.
+func (uc *ReconcileUseCase) Reconcile(ctx context.Context, prefix string, repair bool) (*models.ReconcileReport, error) {
+	prefix = strings.TrimPrefix(prefix, "/")
+
+	secrets, err := uc.secretAdapter.ListSecrets(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	parameters := make(map[string]models.SecretParameter, len(secrets))
+	for _, secret := range secrets {
+		parameters[secret.Name] = secret
+	}
+
+	// every secure entry is read: an entry outside the prefix may reference a parameter inside it
+	entries, err := uc.secureEntries(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	referenced := make(map[string]struct{}, len(entries))
+	for _, entry := range entries {
+		if entry.Value != "" {
+			referenced[secretName(entry.Value)] = struct{}{}
+		}
+	}
+
+	report := &models.ReconcileReport{
+		Prefix:     prefix,
+		Repair:     repair,
+		Parameters: len(secrets),
+		Issues:     make([]models.ReconcileIssue, 0),
+	}
+
+	ctx = application.NewContextWithAction(ctx, models.ActionReconcile)
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Key, prefix) {
+			continue
+		}
+		report.Entries++
+
+		// the secret write failed, the entry was saved without reference
+		if entry.Value == "" {
+			report.Issues = append(report.Issues, models.ReconcileIssue{
+				Key: entry.Key, Parameter: secretName(uc.secretAdapter.Reference(entry.Key)), Problem: models.SecretIssueMissingParameter,
+				Detail: "the entry has no parameter reference, the value must be written again",
+			})
+			continue
+		}
+
+		name := secretName(entry.Value)
+		if _, ok := parameters[name]; !ok {
+			// only parameters tagged project=nbox are listed, an untagged one may still exist
+			if _, err := uc.secretAdapter.RetrieveSecretValue(ctx, name); err != nil {
+				report.Issues = append(report.Issues, models.ReconcileIssue{
+					Key: entry.Key, Parameter: name, Problem: models.SecretIssueMissingParameter,
+					Detail: fmt.Sprintf("the parameter can't be read (%s), the value must be written again", err),
+				})
+				continue
+			}
+		}
+
+		// the format is only configurable for Parameter Store references
+		if backend, _, _ := models.ParseSecretReference(entry.Value); backend != models.SecretBackendSSM {
+			continue
+		}
+		expected := ParameterReference(uc.config, name)
+		if entry.Value == expected {
+			continue
+		}
+		issue := models.ReconcileIssue{
+			Key: entry.Key, Parameter: name, Problem: models.SecretIssueReferenceMismatch,
+			Detail: fmt.Sprintf("entry references '%s', expected '%s'", entry.Value, expected),
+		}
+		if repair {
+			uc.repairReference(ctx, entry, expected, &issue)
+		}
+		report.Issues = append(report.Issues, issue)
+	}
+
+	orphans := make([]string, 0)
+	for _, secret := range secrets {
+		if _, ok := referenced[secret.Name]; ok {
+			continue
+		}
+		issue := models.ReconcileIssue{
+			Key: secret.Key, Parameter: secret.Name, Problem: models.SecretIssueOrphan,
+			Detail: "no secure entry references the parameter",
+		}
+		if repair && uc.now().Sub(secret.UpdatedAt) < orphanMinAge {
+			issue.Detail = fmt.Sprintf("no secure entry references the parameter, written less than %s ago", orphanMinAge)
+		} else if repair {
+			orphans = append(orphans, secret.Name)
+		}
+		report.Issues = append(report.Issues, issue)
+	}
+
+	if len(orphans) > 0 {
+		results := uc.secretAdapter.Delete(ctx, orphans)
+		for i, issue := range report.Issues {
+			result, ok := results[issue.Parameter]
+			if issue.Problem != models.SecretIssueOrphan || !ok {
+				continue
+			}
+			if result.Error != nil {
+				report.Issues[i].Error = result.Error.Error()
+				continue
+			}
+			report.Issues[i].Repaired = true
+		}
+	}
+
+	sort.SliceStable(report.Issues, func(i, j int) bool { return report.Issues[i].Parameter < report.Issues[j].Parameter })
+
+	if len(report.Issues) > 0 {
+		uc.logger.Warn("secure entries out of sync",
+			zap.String("prefix", prefix),
+			zap.Bool("repair", repair),
+			zap.Int("issues", len(report.Issues)),
+		)
+	}
+
+	return report, nil
+}
+
+// repairReference rewrites the reference, conditional on the version read
+func (uc *ReconcileUseCase) repairReference(ctx context.Context, entry models.Entry, reference string, issue *models.ReconcileIssue) {
+	version := entry.Version
+	results := uc.entryAdapter.Upsert(ctx, []models.Entry{{
+		Key:               entry.Key,
+		Value:             reference,
+		Secure:            true,
+		TypeValidatorName: entry.TypeValidatorName,
+		ExpectedVersion:   &version,
+	}})
+	for _, result := range results {
+		if result.Error != nil {
+			issue.Error = result.Error.Error()
+			return
+		}
+	}
+	issue.Repaired = true
+	uc.logger.Info("Parameter reference repaired", zap.String("key", entry.Key), zap.String("reference", reference))
+}
+
+// secureEntries secure entries under the prefix and its sub folders, with the full key
+func (uc *ReconcileUseCase) secureEntries(ctx context.Context, prefix string) ([]models.Entry, error) {
+	children, err := uc.entryAdapter.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]models.Entry, 0)
+	for _, child := range children {
+		key := uc.pathUseCase.Concat(child.Path, child.Key)
+		// folder markers
+		if strings.HasSuffix(child.Key, "/") {
+			nested, err := uc.secureEntries(ctx, strings.TrimSuffix(key, "/"))
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, nested...)
+			continue
+		}
+		if !child.Secure {
+			continue
+		}
+		child.Key = key
+		entries = append(entries, child)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,161 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"nbox/internal/application"
+	"nbox/internal/domain/models"
+	"nbox/internal/domain/models/operations"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// mockEntryAdapterWithTree List returns the children of a path, folders end with "/"
+type mockEntryAdapterWithTree struct {
+	mockEntryAdapter
+	tree     map[string][]models.Entry
+	upserted []models.Entry
+	action   string
+}
+
+func (m *mockEntryAdapterWithTree) List(_ context.Context, prefix string) ([]models.Entry, error) {
+	return m.tree[prefix], nil
+}
+
+func (m *mockEntryAdapterWithTree) ListPage(_ context.Context, path string, after string, limit int) ([]models.Entry, string, error) {
+	entries := make([]models.Entry, 0, limit)
+	for _, entry := range m.tree[path] {
+		if entry.Key <= after {
+			continue
+		}
+		if len(entries) == limit {
+			return entries, entries[len(entries)-1].Key, nil
+		}
+		entries = append(entries, entry)
+	}
+	return entries, "", nil
+}
+
+func (m *mockEntryAdapterWithTree) Upsert(ctx context.Context, entries []models.Entry) operations.Results {
+	m.upserted = append(m.upserted, entries...)
+	m.action, _ = application.ActionFromContext(ctx)
+	results := make(operations.Results)
+	for _, entry := range entries {
+		results[entry.Key] = operations.Result{Key: entry.Key, Type: operations.Updated}
+	}
+	return results
+}
+
+func newReconcileFixture(now time.Time) (*ReconcileUseCase, *mockEntryAdapterWithTree, *[]string) {
+	adapter := &mockEntryAdapterWithTree{tree: map[string][]models.Entry{
+		"": {
+			{Path: EmptyPath, Key: "production/"},
+		},
+		"production": {
+			{Path: "production", Key: "host", Value: "db.internal"},
+			{Path: "production", Key: "myapp/"},
+		},
+		"production/myapp": {
+			// in sync
+			{Path: "production/myapp", Key: "db_password", Value: "/production/myapp/db_password", Secure: true, Version: 2},
+			// written with the full ARN before NBOX_PARAMETER_STORE_SHORT_ARN was enabled
+			{Path: "production/myapp", Key: "api_key", Value: "arn:aws:ssm:us-east-1:000000000000:parameter/production/myapp/api_key", Secure: true, Version: 4},
+			// the parameter is gone
+			{Path: "production/myapp", Key: "token", Value: "/production/myapp/token", Secure: true, Version: 1},
+			// the secret write failed
+			{Path: "production/myapp", Key: "cert", Secure: true, Version: 1},
+		},
+	}}
+
+	deleted := make([]string, 0)
+	secrets := &mockSecretAdapter{
+		listFunc: func(_ context.Context, _ string) ([]models.SecretParameter, error) {
+			return []models.SecretParameter{
+				{Name: "/production/myapp/db_password", Key: "production/myapp/db_password", UpdatedAt: now.Add(-time.Hour)},
+				{Name: "/production/myapp/api_key", Key: "production/myapp/api_key", UpdatedAt: now.Add(-time.Hour)},
+				// the entry write failed
+				{Name: "/production/myapp/old_password", Key: "production/myapp/old_password", UpdatedAt: now.Add(-time.Hour)},
+				// an upsert may be in progress
+				{Name: "/production/myapp/new_password", Key: "production/myapp/new_password", UpdatedAt: now.Add(-time.Minute)},
+			}, nil
+		},
+		retrieveFunc: func(_ context.Context, key string) (*models.Entry, error) {
+			return nil, errors.New("parameter not found")
+		},
+		deleteFunc: func(_ context.Context, names []string) operations.Results {
+			deleted = append(deleted, names...)
+			results := make(operations.Results)
+			for _, name := range names {
+				results[name] = operations.Result{Key: name, Type: operations.Deleted}
+			}
+			return results
+		},
+	}
+
+	config := &application.Config{ParameterShortArn: true}
+	uc := NewReconcileUseCase(adapter, secrets, NewPathUseCase(), config, zap.NewNop())
+	uc.now = func() time.Time { return now }
+	return uc, adapter, &deleted
+}
+
+func problems(report *models.ReconcileReport) map[string]models.ReconcileIssue {
+	issues := make(map[string]models.ReconcileIssue, len(report.Issues))
+	for _, issue := range report.Issues {
+		issues[issue.Parameter] = issue
+	}
+	return issues
+}
+
+func TestReconcileUseCase_Report(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	uc, adapter, deleted := newReconcileFixture(now)
+
+	report, err := uc.Reconcile(context.Background(), "production/", false)
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, report.Entries)
+	assert.Equal(t, 4, report.Parameters)
+
+	issues := problems(report)
+	require.Len(t, issues, 5)
+	assert.Equal(t, models.SecretIssueReferenceMismatch, issues["/production/myapp/api_key"].Problem)
+	assert.Equal(t, models.SecretIssueMissingParameter, issues["/production/myapp/token"].Problem)
+	assert.Equal(t, models.SecretIssueMissingParameter, issues["/production/myapp/cert"].Problem)
+	assert.Equal(t, models.SecretIssueOrphan, issues["/production/myapp/old_password"].Problem)
+	assert.Equal(t, models.SecretIssueOrphan, issues["/production/myapp/new_password"].Problem)
+
+	// a report changes nothing
+	assert.Empty(t, adapter.upserted)
+	assert.Empty(t, *deleted)
+	for _, issue := range report.Issues {
+		assert.False(t, issue.Repaired)
+	}
+}
+
+func TestReconcileUseCase_Repair(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	uc, adapter, deleted := newReconcileFixture(now)
+
+	report, err := uc.Reconcile(context.Background(), "", true)
+	require.NoError(t, err)
+	issues := problems(report)
+
+	// the reference is rewritten in the configured format, conditional on the version read
+	require.Len(t, adapter.upserted, 1)
+	assert.Equal(t, "production/myapp/api_key", adapter.upserted[0].Key)
+	assert.Equal(t, "/production/myapp/api_key", adapter.upserted[0].Value)
+	assert.Equal(t, int64(4), *adapter.upserted[0].ExpectedVersion)
+	assert.Equal(t, models.ActionReconcile, adapter.action)
+	assert.True(t, issues["/production/myapp/api_key"].Repaired)
+
+	// only the old orphan is deleted
+	assert.Equal(t, []string{"/production/myapp/old_password"}, *deleted)
+	assert.True(t, issues["/production/myapp/old_password"].Repaired)
+	assert.False(t, issues["/production/myapp/new_password"].Repaired)
+
+	assert.False(t, issues["/production/myapp/token"].Repaired)
+}
@@ -0,0 +1,103 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"nbox/internal/application"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// ResolveUseCase effective configuration of a service: the layers of NBOX_RESOLVE_LAYERS
+// (global → stage → service by default) overlaid, a higher layer replaces the variables of the lower ones
+type ResolveUseCase struct {
+	entryAdapter domain.EntryAdapter
+	config       *application.Config
+	logger       *zap.Logger
+}
+
+func NewResolveUseCase(entryAdapter domain.EntryAdapter, config *application.Config, logger *zap.Logger) *ResolveUseCase {
+	return &ResolveUseCase{
+		entryAdapter: entryAdapter,
+		config:       config,
+		logger:       logger.Named("resolve"),
+	}
+}
+
+// Layers paths of the layers for the service and stage, lowest precedence first. Each layer only
+// contributes its own variables, not the ones of its sub folders.
+func (uc *ResolveUseCase) Layers(service, stage string) ([]string, error) {
+	service, stage = strings.TrimSpace(service), strings.TrimSpace(stage)
+	if service == "" || stage == "" || strings.Contains(service, "/") || strings.Contains(stage, "/") {
+		return nil, fmt.Errorf("%w: service '%s', stage '%s'", domain.ErrInvalidScope, service, stage)
+	}
+
+	replacer := strings.NewReplacer(":service", service, ":stage", stage)
+	seen := make(map[string]struct{}, len(uc.config.ResolveLayers))
+	layers := make([]string, 0, len(uc.config.ResolveLayers))
+	for _, layer := range uc.config.ResolveLayers {
+		layer = strings.Trim(replacer.Replace(layer), "/")
+		if _, ok := seen[layer]; ok || layer == "" {
+			continue
+		}
+		seen[layer] = struct{}{}
+		layers = append(layers, layer)
+	}
+	return layers, nil
+}
+
+// Resolve variables of every layer, each one with the layer that sets its value and the lower
+// layers it overrides. Secure variables keep the reference of the secret, never its value.
+func (uc *ResolveUseCase) Resolve(ctx context.Context, service, stage string) (*models.ResolveResult, error) {
+	layers, err := uc.Layers(service, stage)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := make(map[string]*models.ResolvedEntry)
+	for _, layer := range layers {
+		entries, err := uc.entryAdapter.List(ctx, layer)
+		if err != nil {
+			uc.logger.Error("ErrResolveLayer", zap.String("layer", layer), zap.Error(err))
+			return nil, fmt.Errorf("failed to list layer %s: %w", layer, err)
+		}
+
+		for _, entry := range entries {
+			// sub folders are listed with the layer
+			if strings.TrimSpace(entry.Path) != layer || strings.HasSuffix(entry.Key, "/") {
+				continue
+			}
+
+			var overrides []string
+			if previous, ok := resolved[entry.Key]; ok {
+				overrides = append(previous.Overrides, previous.Layer)
+			}
+			resolved[entry.Key] = &models.ResolvedEntry{
+				Key:               entry.Key,
+				Value:             entry.Value,
+				Secure:            entry.Secure,
+				TypeValidatorName: entry.TypeValidatorName,
+				Version:           entry.Version,
+				Layer:             layer,
+				Overrides:         overrides,
+			}
+		}
+	}
+
+	result := &models.ResolveResult{
+		Service: strings.TrimSpace(service),
+		Stage:   strings.TrimSpace(stage),
+		Layers:  layers,
+		Entries: make([]models.ResolvedEntry, 0, len(resolved)),
+	}
+	for _, entry := range resolved {
+		result.Entries = append(result.Entries, *entry)
+	}
+	sort.Slice(result.Entries, func(i, j int) bool { return result.Entries[i].Key < result.Entries[j].Key })
+
+	return result, nil
+}
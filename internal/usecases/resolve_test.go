@@ -0,0 +1,70 @@
+package usecases
+
+import (
+	"context"
+	"nbox/internal/application"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newResolveFixture() *ResolveUseCase {
+	adapter := &mockEntryAdapterWithTree{tree: map[string][]models.Entry{
+		"global": {
+			{Path: "global", Key: "LOG_LEVEL", Value: "info"},
+			{Path: "global", Key: "DB_HOST", Value: "db.internal"},
+			{Path: "global", Key: "TIMEOUT", Value: "30"},
+			{Path: "global", Key: "myapp/"},
+		},
+		"production": {
+			{Path: "production", Key: "LOG_LEVEL", Value: "warn"},
+			{Path: "production", Key: "DB_HOST", Value: "db.production.internal"},
+			{Path: "production", Key: "myapp/"},
+			// other services live under the stage, the layer only holds its own variables
+			{Path: "production/billing", Key: "DB_HOST", Value: "billing.internal"},
+		},
+		"production/myapp": {
+			{Path: "production/myapp", Key: "DB_HOST", Value: "myapp.production.internal", Version: 4},
+			{Path: "production/myapp", Key: "DB_PASSWORD", Value: "/production/myapp/DB_PASSWORD", Secure: true},
+		},
+	}}
+	config := &application.Config{ResolveLayers: []string{"global", ":stage", ":stage/:service"}}
+	return NewResolveUseCase(adapter, config, zap.NewNop())
+}
+
+func TestResolveUseCase_Resolve(t *testing.T) {
+	uc := newResolveFixture()
+
+	result, err := uc.Resolve(context.Background(), "myapp", "production")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"global", "production", "production/myapp"}, result.Layers)
+	assert.Equal(t, []models.ResolvedEntry{
+		{Key: "DB_HOST", Value: "myapp.production.internal", Version: 4, Layer: "production/myapp", Overrides: []string{"global", "production"}},
+		{Key: "DB_PASSWORD", Value: "/production/myapp/DB_PASSWORD", Secure: true, Layer: "production/myapp"},
+		{Key: "LOG_LEVEL", Value: "warn", Layer: "production", Overrides: []string{"global"}},
+		{Key: "TIMEOUT", Value: "30", Layer: "global"},
+	}, result.Entries)
+
+	entries := result.AsEntries()
+	require.Len(t, entries, 4)
+	assert.Equal(t, models.Entry{Path: "production/myapp", Key: "DB_HOST", Value: "myapp.production.internal", Version: 4}, entries[0])
+}
+
+func TestResolveUseCase_Layers(t *testing.T) {
+	uc := newResolveFixture()
+	uc.config.ResolveLayers = []string{"global", ":stage/", ":stage", "/:stage/:service/"}
+
+	layers, err := uc.Layers("myapp", "qa")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"global", "qa", "qa/myapp"}, layers)
+
+	for _, scope := range [][2]string{{"", "production"}, {"myapp", ""}, {"my/app", "production"}} {
+		_, err := uc.Layers(scope[0], scope[1])
+		assert.ErrorIs(t, err, domain.ErrInvalidScope)
+	}
+}
@@ -0,0 +1,110 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"nbox/internal/application"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+	"nbox/internal/domain/models/operations"
+
+	"go.uber.org/zap"
+)
+
+// RetypeEvent payload of entry.retype
+type RetypeEvent struct {
+	Key     string              `json:"key"`
+	From    string              `json:"from"`
+	To      string              `json:"to"`
+	Results []operations.Result `json:"results"`
+}
+
+// RetypeUseCase moves an entry to another type validator keeping its tracking history
+type RetypeUseCase struct {
+	entryAdapter  domain.EntryAdapter
+	secretAdapter domain.SecretAdapter
+	entryUseCase  domain.EntryUseCase
+	notifier      domain.EventNotifier
+	logger        *zap.Logger
+}
+
+func NewRetypeUseCase(
+	entryAdapter domain.EntryAdapter,
+	secretAdapter domain.SecretAdapter,
+	entryUseCase domain.EntryUseCase,
+	notifier domain.EventNotifier,
+	logger *zap.Logger,
+) *RetypeUseCase {
+	return &RetypeUseCase{
+		entryAdapter:  entryAdapter,
+		secretAdapter: secretAdapter,
+		entryUseCase:  entryUseCase,
+		notifier:      notifier,
+		logger:        logger,
+	}
+}
+
+// Retype writes the entry again with the new type validator. The write goes through
+// EntryUseCase.Upsert with the "retype" action: the value (the supplied one or the current)
+// is validated against the new validator, the write is conditional on the version read here
+// and the tracking row is recorded as "retype".
+func (uc *RetypeUseCase) Retype(ctx context.Context, request models.RetypeRequest) ([]operations.Result, error) {
+	current, err := uc.entryAdapter.Retrieve(ctx, request.Key)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrEntryNotFound, request.Key)
+	}
+
+	if current.TypeValidatorName == request.TypeValidatorName {
+		return nil, fmt.Errorf("%w: key '%s' already uses type validator '%s'", domain.ErrValidationFailed, current.Key, current.TypeValidatorName)
+	}
+
+	var value string
+	switch {
+	case request.Value != nil:
+		value = *request.Value
+	case current.Secure:
+		secret, err := uc.secretAdapter.RetrieveSecretValue(ctx, secretName(current.Value))
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve secret value of %s: %w", current.Key, err)
+		}
+		if secret == nil {
+			return nil, fmt.Errorf("%w: %s", domain.ErrSecretNotFound, current.Key)
+		}
+		value = secret.Value
+	default:
+		value = current.Value
+	}
+
+	version := current.Version
+	if request.ExpectedVersion != nil {
+		version = *request.ExpectedVersion
+	}
+
+	entry := models.Entry{
+		Key:               current.Key,
+		Value:             value,
+		Secure:            current.Secure,
+		TypeValidatorName: request.TypeValidatorName,
+		ExpectedVersion:   &version,
+	}
+
+	uc.logger.Info("Retyping entry",
+		zap.String("key", current.Key),
+		zap.String("from", current.TypeValidatorName),
+		zap.String("to", request.TypeValidatorName),
+	)
+
+	results := uc.entryUseCase.Upsert(application.NewContextWithAction(ctx, models.ActionRetype), []models.Entry{entry})
+
+	uc.notifier.Dispatch(ctx, newEvent(ctx, domain.EventEntryRetype, RetypeEvent{
+		Key:     current.Key,
+		From:    current.TypeValidatorName,
+		To:      request.TypeValidatorName,
+		Results: results,
+	}))
+
+	return results, nil
+}
@@ -0,0 +1,120 @@
+package usecases
+
+import (
+	"context"
+	"nbox/internal/application"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+	"nbox/internal/domain/models/operations"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestRetypeUseCase_Retype(t *testing.T) {
+	adapter := &mockEntryAdapterWithStore{store: map[string]models.Entry{
+		"production/myapp/db_port": {Key: "production/myapp/db_port", Value: "5432", TypeValidatorName: "string", Version: 4},
+	}}
+	entryUseCase := &actionRecordingEntryUseCase{}
+	notifier := &recordingNotifier{}
+
+	uc := NewRetypeUseCase(adapter, &mockSecretAdapter{}, entryUseCase, notifier, zap.NewNop())
+
+	results, err := uc.Retype(context.Background(), models.RetypeRequest{Key: "production/myapp/db_port", TypeValidatorName: "number"})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	require.Len(t, entryUseCase.upserted, 1)
+	retyped := entryUseCase.upserted[0]
+	assert.Equal(t, "5432", retyped.Value)
+	assert.Equal(t, "number", retyped.TypeValidatorName)
+	require.NotNil(t, retyped.ExpectedVersion)
+	assert.Equal(t, int64(4), *retyped.ExpectedVersion)
+	assert.Equal(t, models.ActionRetype, entryUseCase.action)
+
+	require.Len(t, notifier.events, 1)
+	assert.Equal(t, domain.EventEntryRetype, notifier.events[0].Type)
+	assert.JSONEq(t, `{"key": "production/myapp/db_port", "from": "string", "to": "number", "results": [{"key": "production/myapp/db_port", "action": "updated", "error": null}]}`,
+		string(notifier.events[0].Payload))
+}
+
+func TestRetypeUseCase_SecureValue(t *testing.T) {
+	adapter := &mockEntryAdapterWithStore{store: map[string]models.Entry{
+		"production/myapp/password": {Key: "production/myapp/password", Value: "arn:aws:ssm:us-east-1:123:parameter/production/myapp/password", Secure: true, Version: 2},
+	}}
+
+	var requestedName string
+	secretAdapter := &mockSecretAdapter{
+		retrieveFunc: func(_ context.Context, key string) (*models.Entry, error) {
+			requestedName = key
+			return &models.Entry{Key: key, Value: "s3cr3t-value", Secure: true}, nil
+		},
+	}
+	entryUseCase := &actionRecordingEntryUseCase{}
+
+	uc := NewRetypeUseCase(adapter, secretAdapter, entryUseCase, &recordingNotifier{}, zap.NewNop())
+
+	_, err := uc.Retype(context.Background(), models.RetypeRequest{Key: "production/myapp/password", TypeValidatorName: "password"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "/production/myapp/password", requestedName)
+	require.Len(t, entryUseCase.upserted, 1)
+	assert.Equal(t, "s3cr3t-value", entryUseCase.upserted[0].Value)
+	assert.True(t, entryUseCase.upserted[0].Secure)
+}
+
+func TestRetypeUseCase_SuppliedValueAndErrors(t *testing.T) {
+	adapter := &mockEntryAdapterWithStore{store: map[string]models.Entry{
+		"production/myapp/timeout": {Key: "production/myapp/timeout", Value: "30", TypeValidatorName: "number", Version: 1},
+	}}
+	entryUseCase := &actionRecordingEntryUseCase{}
+	uc := NewRetypeUseCase(adapter, &mockSecretAdapter{}, entryUseCase, &recordingNotifier{}, zap.NewNop())
+
+	value := "30s"
+	_, err := uc.Retype(context.Background(), models.RetypeRequest{Key: "production/myapp/timeout", TypeValidatorName: "duration", Value: &value})
+	require.NoError(t, err)
+	require.Len(t, entryUseCase.upserted, 1)
+	assert.Equal(t, "30s", entryUseCase.upserted[0].Value)
+
+	_, err = uc.Retype(context.Background(), models.RetypeRequest{Key: "production/myapp/missing", TypeValidatorName: "number"})
+	assert.ErrorIs(t, err, domain.ErrEntryNotFound)
+
+	_, err = uc.Retype(context.Background(), models.RetypeRequest{Key: "production/myapp/timeout", TypeValidatorName: "number"})
+	assert.ErrorIs(t, err, domain.ErrValidationFailed)
+}
+
+type mockEntryAdapterWithStoreUpsert struct {
+	mockEntryAdapterWithStore
+}
+
+func (m *mockEntryAdapterWithStoreUpsert) Upsert(_ context.Context, entries []models.Entry) operations.Results {
+	results := make(operations.Results)
+	for _, entry := range entries {
+		results[entry.Key] = operations.Result{Key: entry.Key, Type: operations.Updated}
+	}
+	return results
+}
+
+func TestEntryUseCase_Upsert_TypeValidatorChangeOnlyOnRetype(t *testing.T) {
+	adapter := &mockEntryAdapterWithStoreUpsert{mockEntryAdapterWithStore{store: map[string]models.Entry{
+		"test/port": {Key: "test/port", Value: "8080", TypeValidatorName: "string", Version: 1},
+	}}}
+	useCase := NewEntryUseCase(adapter, &mockSecretAdapter{}, &mockTypeValidatorAdapter{}, newTestKeyPolicy(&application.Config{}), &application.Config{})
+	entry := models.Entry{Key: "test/port", Value: "8080", TypeValidatorName: "number"}
+
+	results := useCase.Upsert(context.Background(), []models.Entry{entry})
+	require.Len(t, results, 1)
+	assert.Error(t, results[0].Error)
+
+	results = useCase.Upsert(application.NewContextWithAction(context.Background(), models.ActionRetype), []models.Entry{entry})
+	require.Len(t, results, 1)
+	assert.NoError(t, results[0].Error)
+
+	// the new validator still applies
+	entry.Value = "http"
+	results = useCase.Upsert(application.NewContextWithAction(context.Background(), models.ActionRetype), []models.Entry{entry})
+	require.Len(t, results, 1)
+	assert.Error(t, results[0].Error)
+}
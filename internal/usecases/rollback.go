@@ -0,0 +1,137 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"nbox/internal/application"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+	"nbox/internal/domain/models/operations"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// RollbackEvent payload of entry.rollback
+type RollbackEvent struct {
+	Key       string              `json:"key"`
+	Version   int64               `json:"version"`
+	Timestamp time.Time           `json:"timestamp"`
+	Results   []operations.Result `json:"results"`
+}
+
+// RollbackUseCase restores a tracked revision of an entry
+type RollbackUseCase struct {
+	entryAdapter  domain.EntryAdapter
+	secretAdapter domain.SecretAdapter
+	entryUseCase  domain.EntryUseCase
+	notifier      domain.EventNotifier
+	logger        *zap.Logger
+}
+
+func NewRollbackUseCase(
+	entryAdapter domain.EntryAdapter,
+	secretAdapter domain.SecretAdapter,
+	entryUseCase domain.EntryUseCase,
+	notifier domain.EventNotifier,
+	logger *zap.Logger,
+) *RollbackUseCase {
+	return &RollbackUseCase{
+		entryAdapter:  entryAdapter,
+		secretAdapter: secretAdapter,
+		entryUseCase:  entryUseCase,
+		notifier:      notifier,
+		logger:        logger,
+	}
+}
+
+// Rollback writes again the value the key had at the tracking revision. The write goes through
+// EntryUseCase.Upsert: the value is validated against the current type validator, secure
+// values are written again to the secret store and the tracking row is recorded as "rollback".
+func (uc *RollbackUseCase) Rollback(ctx context.Context, request models.RollbackRequest) ([]operations.Result, error) {
+	history, err := uc.entryAdapter.Tracking(ctx, request.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	// delete rows repeat the version of the write before them
+	var revision *models.Tracking
+	deleted := false
+	for i := range history {
+		if history[i].Version == request.Version && history[i].Action != models.ActionDelete {
+			revision = &history[i]
+			break
+		}
+		deleted = deleted || history[i].Action == models.ActionDelete
+	}
+
+	if revision == nil {
+		return nil, fmt.Errorf("%w: key '%s' version %d", domain.ErrRevisionNotFound, request.Key, request.Version)
+	}
+
+	value := revision.Value
+	if revision.Secure {
+		// tracking only keeps the parameter reference, the value comes from the secret history
+		secret, err := uc.secretAdapter.RetrieveSecretValueAt(ctx, secretName(revision.Value), revision.UpdatedAt)
+		if err != nil && deleted {
+			// deleting a secure key deletes its secret with the history
+			uc.logger.Warn("ErrRetrieveSecretRevision", zap.String("key", request.Key), zap.Error(err))
+			return nil, fmt.Errorf("%w: key '%s' was deleted after version %d", domain.ErrSecretHistoryGone, request.Key, revision.Version)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve secret revision of %s: %w", request.Key, err)
+		}
+		value = secret.Value
+	}
+
+	current, err := uc.entryAdapter.Retrieve(ctx, revision.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := models.Entry{
+		Key:    revision.Key,
+		Value:  value,
+		Secure: revision.Secure,
+	}
+
+	// nobody may write the key between this read and the rollback.
+	// A deleted key is restored with the validator it had at the revision.
+	var version int64
+	entry.TypeValidatorName = revision.TypeValidatorName
+	if current != nil {
+		entry.TypeValidatorName = current.TypeValidatorName
+		version = current.Version
+	}
+	entry.ExpectedVersion = &version
+
+	uc.logger.Info("Rolling back entry",
+		zap.String("key", revision.Key),
+		zap.Int64("revision", revision.Version),
+		zap.Bool("secure", revision.Secure),
+	)
+
+	results := uc.entryUseCase.Upsert(application.NewContextWithAction(ctx, models.ActionRollback), []models.Entry{entry})
+
+	uc.notifier.Dispatch(ctx, newEvent(ctx, domain.EventEntryRollback, RollbackEvent{
+		Key:       revision.Key,
+		Version:   revision.Version,
+		Timestamp: revision.UpdatedAt,
+		Results:   results,
+	}))
+
+	return results, nil
+}
+
+// secretName name given to the SecretAdapter for the reference of a secure entry: Parameter Store
+// references (ARN or "/key") become the parameter name, references of other backends are kept
+func secretName(reference string) string {
+	backend, name, ok := models.ParseSecretReference(reference)
+	switch {
+	case !ok:
+		return "/" + reference
+	case backend == models.SecretBackendSSM:
+		return name
+	}
+	return reference
+}
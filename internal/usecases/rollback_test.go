@@ -0,0 +1,189 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"nbox/internal/application"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+	"nbox/internal/domain/models/operations"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type mockEntryAdapterWithHistory struct {
+	mockEntryAdapterWithStore
+	history []models.Tracking
+}
+
+func (m *mockEntryAdapterWithHistory) Tracking(_ context.Context, _ string) ([]models.Tracking, error) {
+	return m.history, nil
+}
+
+type recordingNotifier struct {
+	events []domain.Event[json.RawMessage]
+}
+
+func (n *recordingNotifier) Dispatch(_ context.Context, event domain.Event[json.RawMessage]) {
+	n.events = append(n.events, event)
+}
+
+type actionRecordingEntryUseCase struct {
+	recordingEntryUseCase
+	action string
+}
+
+func (r *actionRecordingEntryUseCase) Upsert(ctx context.Context, entries []models.Entry) []operations.Result {
+	r.action, _ = application.ActionFromContext(ctx)
+	return r.recordingEntryUseCase.Upsert(ctx, entries)
+}
+
+func TestRollbackUseCase_Rollback(t *testing.T) {
+	revision := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	adapter := &mockEntryAdapterWithHistory{
+		mockEntryAdapterWithStore: mockEntryAdapterWithStore{store: map[string]models.Entry{
+			"production/myapp/db_port": {Key: "production/myapp/db_port", Value: "6432", TypeValidatorName: "number", Version: 4},
+		}},
+		history: []models.Tracking{
+			{Key: "production/myapp/db_port", Value: "6432", Version: 4, UpdatedAt: revision.Add(time.Second / 2)},
+			{Key: "production/myapp/db_port", Value: "5432", Version: 3, UpdatedAt: revision},
+		},
+	}
+	entryUseCase := &actionRecordingEntryUseCase{}
+	notifier := &recordingNotifier{}
+
+	uc := NewRollbackUseCase(adapter, &mockSecretAdapter{}, entryUseCase, notifier, zap.NewNop())
+
+	results, err := uc.Rollback(context.Background(), models.RollbackRequest{Key: "production/myapp/db_port", Version: 3})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	require.Len(t, entryUseCase.upserted, 1)
+	restored := entryUseCase.upserted[0]
+	assert.Equal(t, "5432", restored.Value)
+	assert.Equal(t, "number", restored.TypeValidatorName)
+	require.NotNil(t, restored.ExpectedVersion)
+	assert.Equal(t, int64(4), *restored.ExpectedVersion)
+	assert.Equal(t, models.ActionRollback, entryUseCase.action)
+
+	require.Len(t, notifier.events, 1)
+	assert.Equal(t, domain.EventEntryRollback, notifier.events[0].Type)
+}
+
+func TestRollbackUseCase_SecureRevision(t *testing.T) {
+	revision := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	adapter := &mockEntryAdapterWithHistory{
+		mockEntryAdapterWithStore: mockEntryAdapterWithStore{store: map[string]models.Entry{}},
+		history: []models.Tracking{
+			{Key: "production/myapp/password", Value: "arn:aws:ssm:us-east-1:123:parameter/production/myapp/password", Secure: true, Version: 1, UpdatedAt: revision},
+		},
+	}
+
+	var requestedName string
+	var requestedAt time.Time
+	secretAdapter := &mockSecretAdapter{
+		retrieveAtFunc: func(_ context.Context, key string, at time.Time) (*models.Entry, error) {
+			requestedName = key
+			requestedAt = at
+			return &models.Entry{Key: key, Value: "old-secret", Secure: true}, nil
+		},
+	}
+	entryUseCase := &actionRecordingEntryUseCase{}
+
+	uc := NewRollbackUseCase(adapter, secretAdapter, entryUseCase, &recordingNotifier{}, zap.NewNop())
+
+	_, err := uc.Rollback(context.Background(), models.RollbackRequest{Key: "production/myapp/password", Version: 1})
+	require.NoError(t, err)
+
+	assert.Equal(t, "/production/myapp/password", requestedName)
+	assert.Equal(t, revision, requestedAt)
+	require.Len(t, entryUseCase.upserted, 1)
+	assert.Equal(t, "old-secret", entryUseCase.upserted[0].Value)
+	assert.True(t, entryUseCase.upserted[0].Secure)
+	assert.Equal(t, int64(0), *entryUseCase.upserted[0].ExpectedVersion)
+}
+
+func TestRollbackUseCase_RevisionNotFound(t *testing.T) {
+	adapter := &mockEntryAdapterWithHistory{}
+	uc := NewRollbackUseCase(adapter, &mockSecretAdapter{}, &actionRecordingEntryUseCase{}, &recordingNotifier{}, zap.NewNop())
+
+	_, err := uc.Rollback(context.Background(), models.RollbackRequest{Key: "production/myapp/db_port", Version: 1})
+	assert.ErrorIs(t, err, domain.ErrRevisionNotFound)
+}
+
+func TestRollbackUseCase_DeletedKey(t *testing.T) {
+	deletedAt := time.Date(2025, 3, 2, 10, 0, 0, 0, time.UTC)
+
+	adapter := &mockEntryAdapterWithHistory{
+		mockEntryAdapterWithStore: mockEntryAdapterWithStore{store: map[string]models.Entry{}},
+		history: []models.Tracking{
+			{Key: "production/myapp/db_port", Value: "5432", Action: models.ActionDelete, TypeValidatorName: "number", Version: 2, UpdatedAt: deletedAt},
+			{Key: "production/myapp/db_port", Value: "5432", Action: models.ActionUpsert, TypeValidatorName: "number", Version: 2, UpdatedAt: deletedAt.Add(-time.Hour)},
+			{Key: "production/myapp/db_port", Value: "5433", Action: models.ActionUpsert, TypeValidatorName: "number", Version: 1, UpdatedAt: deletedAt.Add(-2 * time.Hour)},
+		},
+	}
+	entryUseCase := &actionRecordingEntryUseCase{}
+	uc := NewRollbackUseCase(adapter, &mockSecretAdapter{}, entryUseCase, &recordingNotifier{}, zap.NewNop())
+
+	// the delete row repeats the version of the write before it
+	_, err := uc.Rollback(context.Background(), models.RollbackRequest{Key: "production/myapp/db_port", Version: 2})
+	require.NoError(t, err)
+	require.Len(t, entryUseCase.upserted, 1)
+	assert.Equal(t, "5432", entryUseCase.upserted[0].Value)
+	assert.Equal(t, "number", entryUseCase.upserted[0].TypeValidatorName)
+	assert.Equal(t, int64(0), *entryUseCase.upserted[0].ExpectedVersion)
+}
+
+func TestRollbackUseCase_RecreatedKey(t *testing.T) {
+	recreatedAt := time.Date(2025, 3, 2, 10, 0, 0, 0, time.UTC)
+
+	adapter := &mockEntryAdapterWithHistory{
+		mockEntryAdapterWithStore: mockEntryAdapterWithStore{store: map[string]models.Entry{
+			"production/myapp/db_port": {Key: "production/myapp/db_port", Value: "7432", Version: 1},
+		}},
+		history: []models.Tracking{
+			{Key: "production/myapp/db_port", Value: "7432", Action: models.ActionUpsert, Version: 1, UpdatedAt: recreatedAt},
+			{Key: "production/myapp/db_port", Value: "5432", Action: models.ActionDelete, Version: 1, UpdatedAt: recreatedAt.Add(-time.Hour)},
+			{Key: "production/myapp/db_port", Value: "5432", Action: models.ActionUpsert, Version: 1, UpdatedAt: recreatedAt.Add(-2 * time.Hour)},
+		},
+	}
+	entryUseCase := &actionRecordingEntryUseCase{}
+	uc := NewRollbackUseCase(adapter, &mockSecretAdapter{}, entryUseCase, &recordingNotifier{}, zap.NewNop())
+
+	// versions start again after the delete, the newest revision wins
+	_, err := uc.Rollback(context.Background(), models.RollbackRequest{Key: "production/myapp/db_port", Version: 1})
+	require.NoError(t, err)
+	require.Len(t, entryUseCase.upserted, 1)
+	assert.Equal(t, "7432", entryUseCase.upserted[0].Value)
+}
+
+func TestRollbackUseCase_DeletedSecureKey(t *testing.T) {
+	deletedAt := time.Date(2025, 3, 2, 10, 0, 0, 0, time.UTC)
+	reference := "arn:aws:ssm:us-east-1:123:parameter/production/myapp/password"
+
+	adapter := &mockEntryAdapterWithHistory{
+		mockEntryAdapterWithStore: mockEntryAdapterWithStore{store: map[string]models.Entry{}},
+		history: []models.Tracking{
+			{Key: "production/myapp/password", Value: reference, Secure: true, Action: models.ActionDelete, Version: 1, UpdatedAt: deletedAt},
+			{Key: "production/myapp/password", Value: reference, Secure: true, Action: models.ActionUpsert, Version: 1, UpdatedAt: deletedAt.Add(-time.Hour)},
+		},
+	}
+	secretAdapter := &mockSecretAdapter{
+		retrieveAtFunc: func(_ context.Context, _ string, _ time.Time) (*models.Entry, error) {
+			return nil, errors.New("parameter not found")
+		},
+	}
+	entryUseCase := &actionRecordingEntryUseCase{}
+	uc := NewRollbackUseCase(adapter, secretAdapter, entryUseCase, &recordingNotifier{}, zap.NewNop())
+
+	_, err := uc.Rollback(context.Background(), models.RollbackRequest{Key: "production/myapp/password", Version: 1})
+	assert.ErrorIs(t, err, domain.ErrSecretHistoryGone)
+	assert.Empty(t, entryUseCase.upserted)
+}
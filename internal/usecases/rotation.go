@@ -0,0 +1,339 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"nbox/internal/application"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	minRotationInterval = time.Minute
+	defaultGracePeriod  = 24 * time.Hour
+	// maxGenerateAttempts random values tried until one passes the type validator
+	maxGenerateAttempts = 100
+	// rotationRetryDelay wait before the scheduler retries a failed rotation
+	rotationRetryDelay = 5 * time.Minute
+	// rotationUser recorded as author of the scheduled rotations
+	rotationUser = "nbox-rotation"
+)
+
+// RotationUseCase rotation policies of secure entries and the rotation itself
+type RotationUseCase struct {
+	entryAdapter         domain.EntryAdapter
+	typeValidatorAdapter domain.TypeValidatorAdapter
+	entryUseCase         domain.EntryUseCase
+	revealUseCase        *SecretRevealUseCase
+	repository           domain.RotationRepository
+	notifier             domain.EventNotifier
+	logger               *zap.Logger
+	now                  func() time.Time
+}
+
+func NewRotationUseCase(
+	entryAdapter domain.EntryAdapter,
+	typeValidatorAdapter domain.TypeValidatorAdapter,
+	entryUseCase domain.EntryUseCase,
+	revealUseCase *SecretRevealUseCase,
+	repository domain.RotationRepository,
+	notifier domain.EventNotifier,
+	logger *zap.Logger,
+) *RotationUseCase {
+	return &RotationUseCase{
+		entryAdapter:         entryAdapter,
+		typeValidatorAdapter: typeValidatorAdapter,
+		entryUseCase:         entryUseCase,
+		revealUseCase:        revealUseCase,
+		repository:           repository,
+		notifier:             notifier,
+		logger:               logger,
+		now:                  func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// SetPolicy creates or replaces the policy of a secure entry. The generator must be able to produce
+// values accepted by the entry's type validator. The rotation state of an existing policy is kept
+// and the next rotation is recalculated from the last one.
+func (uc *RotationUseCase) SetPolicy(ctx context.Context, policy models.RotationPolicy) (*models.RotationPolicy, error) {
+	policy.Key = strings.TrimPrefix(policy.Key, "/")
+
+	interval, grace, err := policyDurations(policy)
+	if err != nil {
+		return nil, err
+	}
+	if err = policy.Generator.Check(); err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrInvalidRotationPolicy, err)
+	}
+
+	entry, err := uc.secureEntry(ctx, policy.Key)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = uc.generate(ctx, policy.Generator, entry.TypeValidatorName); err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrInvalidRotationPolicy, err)
+	}
+
+	current, err := uc.repository.Retrieve(ctx, policy.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	now := uc.now()
+	policy.GracePeriod = grace.String()
+	policy.NextRotationAt = now.Add(interval)
+	policy.LastRotatedAt, policy.PreviousVersionAt, policy.PreviousValidUntil, policy.LastError = nil, nil, nil, ""
+	if current != nil {
+		policy.LastRotatedAt = current.LastRotatedAt
+		policy.PreviousVersionAt = current.PreviousVersionAt
+		policy.PreviousValidUntil = current.PreviousValidUntil
+		if current.LastRotatedAt != nil {
+			policy.NextRotationAt = current.LastRotatedAt.Add(interval)
+		}
+	}
+	policy.UpdatedBy = "ghost"
+	if user, ok := application.UserFromContext(ctx); ok {
+		policy.UpdatedBy = user.Name
+	}
+
+	if err = uc.repository.Save(ctx, policy); err != nil {
+		return nil, err
+	}
+
+	uc.logger.Info("Rotation policy saved",
+		zap.String("key", policy.Key),
+		zap.String("interval", policy.Interval),
+		zap.Time("nextRotationAt", policy.NextRotationAt),
+	)
+
+	return &policy, nil
+}
+
+func (uc *RotationUseCase) Policy(ctx context.Context, key string) (*models.RotationPolicy, error) {
+	key = strings.TrimPrefix(key, "/")
+	policy, err := uc.repository.Retrieve(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrRotationPolicyNotFound, key)
+	}
+	return policy, nil
+}
+
+func (uc *RotationUseCase) List(ctx context.Context) ([]models.RotationPolicy, error) {
+	return uc.repository.List(ctx)
+}
+
+// DeletePolicy stops rotating the secret, its current value is kept
+func (uc *RotationUseCase) DeletePolicy(ctx context.Context, key string) error {
+	return uc.repository.Delete(ctx, strings.TrimPrefix(key, "/"))
+}
+
+// Rotate writes a generated value through EntryUseCase.Upsert with the "rotate" action, so the
+// value is validated, written with SecretAdapter.Upsert, conditional on the version read here
+// and tracked. The replaced version stays readable through Previous until the grace period ends.
+func (uc *RotationUseCase) Rotate(ctx context.Context, key, trigger string) (*models.RotationResult, error) {
+	policy, err := uc.Policy(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := uc.rotate(ctx, *policy, trigger)
+	// a conflict means another writer (or another replica's scheduler) changed the entry first,
+	// the policy it saved must not be overwritten
+	if errors.Is(err, domain.ErrVersionConflict) {
+		return nil, err
+	}
+	if err != nil {
+		policy.LastError = err.Error()
+		if trigger == models.RotationScheduled {
+			policy.NextRotationAt = uc.now().Add(rotationRetryDelay)
+		}
+		if saveErr := uc.repository.Save(ctx, *policy); saveErr != nil {
+			uc.logger.Error("ErrSaveRotationPolicy", zap.String("key", policy.Key), zap.Error(saveErr))
+		}
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (uc *RotationUseCase) rotate(ctx context.Context, policy models.RotationPolicy, trigger string) (*models.RotationResult, error) {
+	interval, grace, err := policyDurations(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := uc.secureEntry(ctx, policy.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := uc.generate(ctx, policy.Generator, entry.TypeValidatorName)
+	if err != nil {
+		return nil, err
+	}
+
+	// write time of the value being replaced, used to read it back during the grace period.
+	// Secret history lookups have second precision: a value written less than a second ago
+	// can't be told apart from the rotated one, so it is not offered as previous.
+	var previousAt *time.Time
+	if history, err := uc.entryAdapter.Tracking(ctx, policy.Key); err == nil && len(history) > 0 {
+		if uc.now().Sub(history[0].UpdatedAt) > time.Second {
+			previousAt = &history[0].UpdatedAt
+		}
+	}
+
+	version := entry.Version
+	results := uc.entryUseCase.Upsert(application.NewContextWithAction(ctx, models.ActionRotate), []models.Entry{{
+		Key:               entry.Key,
+		Value:             value,
+		Secure:            true,
+		TypeValidatorName: entry.TypeValidatorName,
+		ExpectedVersion:   &version,
+	}})
+	for _, result := range results {
+		if result.Error != nil {
+			return nil, fmt.Errorf("rotation of %s failed: %w", policy.Key, result.Error)
+		}
+	}
+
+	now := uc.now()
+	policy.LastRotatedAt = &now
+	policy.NextRotationAt = now.Add(interval)
+	policy.PreviousVersionAt = previousAt
+	policy.PreviousValidUntil = nil
+	if previousAt != nil {
+		until := now.Add(grace)
+		policy.PreviousValidUntil = &until
+	}
+	policy.LastError = ""
+
+	if err = uc.repository.Save(ctx, policy); err != nil {
+		// the secret is already rotated, the scheduler may rotate it again on its next run
+		uc.logger.Error("ErrSaveRotationPolicy", zap.String("key", policy.Key), zap.Error(err))
+	}
+
+	result := &models.RotationResult{
+		Key:                policy.Key,
+		Trigger:            trigger,
+		RotatedAt:          now,
+		NextRotationAt:     policy.NextRotationAt,
+		PreviousValidUntil: policy.PreviousValidUntil,
+	}
+
+	uc.logger.Info("Secret rotated",
+		zap.String("key", policy.Key),
+		zap.String("trigger", trigger),
+		zap.Time("nextRotationAt", policy.NextRotationAt),
+	)
+
+	// consumers reload the secret, the value is not part of the payload
+	uc.notifier.Dispatch(ctx, newEvent(ctx, domain.EventSecretRotated, result))
+
+	return result, nil
+}
+
+// RotateDue rotates every secret whose next rotation is due, returns the rotated keys.
+// A failure is recorded in the policy and retried after rotationRetryDelay.
+func (uc *RotationUseCase) RotateDue(ctx context.Context) ([]string, error) {
+	policies, err := uc.repository.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = application.NewContextWithUser(ctx, application.User{Name: rotationUser})
+
+	now := uc.now()
+	rotated := make([]string, 0)
+	for _, policy := range policies {
+		if !policy.Due(now) {
+			continue
+		}
+		if _, err := uc.Rotate(ctx, policy.Key, models.RotationScheduled); err != nil {
+			uc.logger.Error("ErrScheduledRotation", zap.String("key", policy.Key), zap.Error(err))
+			continue
+		}
+		rotated = append(rotated, policy.Key)
+	}
+
+	return rotated, nil
+}
+
+// Previous value replaced by the last rotation while the grace period lasts, audited like any reveal
+func (uc *RotationUseCase) Previous(ctx context.Context, key, reason string) (*models.Entry, error) {
+	policy, err := uc.Policy(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if policy.PreviousVersionAt == nil || policy.PreviousValidUntil == nil || uc.now().After(*policy.PreviousValidUntil) {
+		return nil, fmt.Errorf("%w: %s", domain.ErrRotationGraceExpired, policy.Key)
+	}
+
+	return uc.revealUseCase.RevealAt(ctx, policy.Key, reason, *policy.PreviousVersionAt)
+}
+
+func (uc *RotationUseCase) secureEntry(ctx context.Context, key string) (*models.Entry, error) {
+	entry, err := uc.entryAdapter.Retrieve(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrEntryNotFound, key)
+	}
+	if !entry.Secure {
+		return nil, fmt.Errorf("%w: key '%s' is not secure", domain.ErrInvalidRotationPolicy, key)
+	}
+	return entry, nil
+}
+
+// generate random values until one passes the entry's type validator
+func (uc *RotationUseCase) generate(ctx context.Context, generator models.SecretGenerator, validatorName string) (string, error) {
+	var validator *models.TypeValidator
+	if validatorName != "" {
+		found, err := uc.typeValidatorAdapter.Retrieve(ctx, validatorName)
+		if err != nil {
+			return "", err
+		}
+		if found == nil {
+			return "", fmt.Errorf("type validator '%s' not found", validatorName)
+		}
+		validator = found
+	}
+
+	var lastErr error
+	for range maxGenerateAttempts {
+		value, err := generator.Generate()
+		if err != nil {
+			return "", err
+		}
+		if lastErr = models.ValidateValue(validator, value); lastErr == nil {
+			return value, nil
+		}
+	}
+
+	return "", fmt.Errorf("generator does not produce values accepted by type validator '%s': %w", validatorName, lastErr)
+}
+
+func policyDurations(policy models.RotationPolicy) (time.Duration, time.Duration, error) {
+	interval, err := time.ParseDuration(policy.Interval)
+	if err != nil || interval < minRotationInterval {
+		return 0, 0, fmt.Errorf("%w: interval must be a duration of at least %s", domain.ErrInvalidRotationPolicy, minRotationInterval)
+	}
+
+	grace := defaultGracePeriod
+	if policy.GracePeriod != "" {
+		if grace, err = time.ParseDuration(policy.GracePeriod); err != nil || grace < 0 {
+			return 0, 0, fmt.Errorf("%w: grace_period must be a positive duration", domain.ErrInvalidRotationPolicy)
+		}
+	}
+
+	return interval, grace, nil
+}
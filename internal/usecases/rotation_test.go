@@ -0,0 +1,228 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"nbox/internal/application"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+	"nbox/internal/domain/models/operations"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type memoryRotationRepository struct {
+	policies map[string]models.RotationPolicy
+}
+
+func (m *memoryRotationRepository) Save(_ context.Context, policy models.RotationPolicy) error {
+	m.policies[policy.Key] = policy
+	return nil
+}
+
+func (m *memoryRotationRepository) Retrieve(_ context.Context, key string) (*models.RotationPolicy, error) {
+	policy, ok := m.policies[key]
+	if !ok {
+		return nil, nil
+	}
+	return &policy, nil
+}
+
+func (m *memoryRotationRepository) List(_ context.Context) ([]models.RotationPolicy, error) {
+	policies := make([]models.RotationPolicy, 0, len(m.policies))
+	for _, policy := range m.policies {
+		policies = append(policies, policy)
+	}
+	return policies, nil
+}
+
+func (m *memoryRotationRepository) Delete(_ context.Context, key string) error {
+	if _, ok := m.policies[key]; !ok {
+		return fmt.Errorf("%w: %s", domain.ErrRotationPolicyNotFound, key)
+	}
+	delete(m.policies, key)
+	return nil
+}
+
+type conflictEntryUseCase struct{}
+
+func (conflictEntryUseCase) Upsert(_ context.Context, entries []models.Entry) []operations.Result {
+	return []operations.Result{{Key: entries[0].Key, Type: operations.Conflict, Error: domain.ErrVersionConflict}}
+}
+
+type rotationFixture struct {
+	uc           *RotationUseCase
+	entryUseCase *actionRecordingEntryUseCase
+	repository   *memoryRotationRepository
+	notifier     *recordingNotifier
+	audit        *mockSecretAuditRepository
+	secrets      *mockSecretAdapter
+	now          time.Time
+}
+
+func newRotationFixture() *rotationFixture {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	previous := now.Add(-30 * 24 * time.Hour)
+
+	adapter := &mockEntryAdapterWithHistory{
+		mockEntryAdapterWithStore: mockEntryAdapterWithStore{store: map[string]models.Entry{
+			"production/myapp/db_password": {Key: "production/myapp/db_password", Value: "/production/myapp/db_password", Secure: true, TypeValidatorName: "password", Version: 3},
+			"production/myapp/db_host":     {Key: "production/myapp/db_host", Value: "db.internal", Version: 1},
+		}},
+		history: []models.Tracking{{Key: "production/myapp/db_password", UpdatedAt: previous, Version: 3}},
+	}
+	validators := &mockTypeValidatorAdapter{
+		retrieveFunc: func(_ context.Context, name string) (*models.TypeValidator, error) {
+			if name == "password" {
+				return &models.TypeValidator{Name: "password", Regex: `^[A-Za-z0-9]{24,}$`}, nil
+			}
+			return nil, nil
+		},
+	}
+
+	f := &rotationFixture{
+		entryUseCase: &actionRecordingEntryUseCase{},
+		repository:   &memoryRotationRepository{policies: map[string]models.RotationPolicy{}},
+		notifier:     &recordingNotifier{},
+		audit:        &mockSecretAuditRepository{},
+		secrets: &mockSecretAdapter{
+			retrieveAtFunc: func(_ context.Context, key string, at time.Time) (*models.Entry, error) {
+				if !at.Equal(previous) {
+					return nil, fmt.Errorf("unexpected time %s", at)
+				}
+				return &models.Entry{Key: key, Value: "old-password", Secure: true}, nil
+			},
+		},
+		now: now,
+	}
+
+	reveal := NewSecretRevealUseCase(f.secrets, &mockEntryAdapter{}, f.audit, f.notifier, &application.Config{}, zap.NewNop())
+	f.uc = NewRotationUseCase(adapter, validators, f.entryUseCase, reveal, f.repository, f.notifier, zap.NewNop())
+	f.uc.now = func() time.Time { return f.now }
+	return f
+}
+
+func passwordPolicy() models.RotationPolicy {
+	return models.RotationPolicy{
+		Key:         "/production/myapp/db_password",
+		Interval:    "720h",
+		GracePeriod: "1h",
+		Generator:   models.SecretGenerator{Kind: models.GeneratorRandom, Length: 32},
+	}
+}
+
+func TestRotationUseCase_SetPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  func(p *models.RotationPolicy)
+		wantErr error
+	}{
+		{name: "valid policy", policy: func(p *models.RotationPolicy) {}},
+		{name: "interval too short", policy: func(p *models.RotationPolicy) { p.Interval = "10s" }, wantErr: domain.ErrInvalidRotationPolicy},
+		{name: "unknown generator", policy: func(p *models.RotationPolicy) { p.Generator.Kind = "dictionary" }, wantErr: domain.ErrInvalidRotationPolicy},
+		{name: "generator shorter than the validator", policy: func(p *models.RotationPolicy) { p.Generator.Length = 16 }, wantErr: domain.ErrInvalidRotationPolicy},
+		{name: "charset rejected by the validator", policy: func(p *models.RotationPolicy) { p.Generator.Charset, p.Generator.Length = models.CharsetSymbols, 64 }, wantErr: domain.ErrInvalidRotationPolicy},
+		{name: "entry is not secure", policy: func(p *models.RotationPolicy) { p.Key = "production/myapp/db_host" }, wantErr: domain.ErrInvalidRotationPolicy},
+		{name: "entry does not exist", policy: func(p *models.RotationPolicy) { p.Key = "production/myapp/missing" }, wantErr: domain.ErrEntryNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newRotationFixture()
+			policy := passwordPolicy()
+			tt.policy(&policy)
+
+			saved, err := f.uc.SetPolicy(context.Background(), policy)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, f.repository.policies)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "production/myapp/db_password", saved.Key)
+			assert.Equal(t, f.now.Add(720*time.Hour), saved.NextRotationAt)
+			assert.Contains(t, f.repository.policies, "production/myapp/db_password")
+		})
+	}
+}
+
+func TestRotationUseCase_Rotate(t *testing.T) {
+	f := newRotationFixture()
+	_, err := f.uc.SetPolicy(context.Background(), passwordPolicy())
+	require.NoError(t, err)
+
+	f.now = f.now.Add(time.Hour)
+	result, err := f.uc.Rotate(context.Background(), "production/myapp/db_password", models.RotationManual)
+	require.NoError(t, err)
+
+	require.Len(t, f.entryUseCase.upserted, 1)
+	written := f.entryUseCase.upserted[0]
+	assert.Equal(t, models.ActionRotate, f.entryUseCase.action)
+	assert.True(t, written.Secure)
+	assert.Equal(t, "password", written.TypeValidatorName)
+	assert.Equal(t, int64(3), *written.ExpectedVersion)
+	assert.Regexp(t, regexp.MustCompile(`^[A-Za-z0-9]{32}$`), written.Value)
+
+	assert.Equal(t, f.now.Add(720*time.Hour), result.NextRotationAt)
+	require.NotNil(t, result.PreviousValidUntil)
+	assert.Equal(t, f.now.Add(time.Hour), *result.PreviousValidUntil)
+
+	require.Len(t, f.notifier.events, 1)
+	event := f.notifier.events[0]
+	assert.Equal(t, domain.EventSecretRotated, event.Type)
+	assert.NotContains(t, string(event.Payload), written.Value)
+
+	var payload models.RotationResult
+	require.NoError(t, json.Unmarshal(event.Payload, &payload))
+	assert.Equal(t, models.RotationManual, payload.Trigger)
+
+	// the replaced value is readable, and audited, until the grace period ends
+	previous, err := f.uc.Previous(context.Background(), "production/myapp/db_password", "deploy")
+	require.NoError(t, err)
+	assert.Equal(t, "old-password", previous.Value)
+	require.Len(t, f.audit.reveals, 1)
+	assert.True(t, f.audit.reveals[0].Previous)
+
+	f.now = f.now.Add(2 * time.Hour)
+	_, err = f.uc.Previous(context.Background(), "production/myapp/db_password", "deploy")
+	assert.ErrorIs(t, err, domain.ErrRotationGraceExpired)
+}
+
+func TestRotationUseCase_RotateDue(t *testing.T) {
+	f := newRotationFixture()
+	_, err := f.uc.SetPolicy(context.Background(), passwordPolicy())
+	require.NoError(t, err)
+
+	rotated, err := f.uc.RotateDue(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, rotated)
+
+	f.now = f.now.Add(721 * time.Hour)
+	rotated, err = f.uc.RotateDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"production/myapp/db_password"}, rotated)
+
+	policy := f.repository.policies["production/myapp/db_password"]
+	assert.Equal(t, f.now, *policy.LastRotatedAt)
+	assert.Equal(t, "nbox-rotation", f.notifier.events[0].Username)
+}
+
+func TestRotationUseCase_ConflictKeepsPolicy(t *testing.T) {
+	f := newRotationFixture()
+	_, err := f.uc.SetPolicy(context.Background(), passwordPolicy())
+	require.NoError(t, err)
+	f.uc.entryUseCase = conflictEntryUseCase{}
+
+	before := f.repository.policies["production/myapp/db_password"]
+	_, err = f.uc.Rotate(context.Background(), "production/myapp/db_password", models.RotationScheduled)
+	assert.ErrorIs(t, err, domain.ErrVersionConflict)
+	assert.Equal(t, before, f.repository.policies["production/myapp/db_password"])
+	assert.Empty(t, f.notifier.events)
+}
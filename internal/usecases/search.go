@@ -0,0 +1,117 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+	"path"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// SearchUseCase finds keys across the store. Only the literal prefix of the query is read from
+// the key index of the backend, the match itself runs here; values are never returned.
+type SearchUseCase struct {
+	entryAdapter domain.EntryAdapter
+	logger       *zap.Logger
+}
+
+func NewSearchUseCase(entryAdapter domain.EntryAdapter, logger *zap.Logger) *SearchUseCase {
+	return &SearchUseCase{
+		entryAdapter: entryAdapter,
+		logger:       logger.Named("search"),
+	}
+}
+
+// Search keys matching the filter sorted by key, at most filter.Limit
+func (uc *SearchUseCase) Search(ctx context.Context, filter models.SearchFilter) (*models.SearchResponse, error) {
+	match, prefix, err := compileSearch(filter.Query, filter.Mode)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := uc.entryAdapter.SearchKeys(ctx, prefix)
+	if err != nil {
+		uc.logger.Error("ErrSearchKeys", zap.String("prefix", prefix), zap.Error(err))
+		return nil, err
+	}
+
+	results := make([]models.SearchResult, 0)
+	for _, entry := range entries {
+		if !match(entry.Key) {
+			continue
+		}
+		if filter.Secure != nil && entry.Secure != *filter.Secure {
+			continue
+		}
+		if filter.TypeValidatorName != "" && entry.TypeValidatorName != filter.TypeValidatorName {
+			continue
+		}
+		if filter.Limit > 0 && len(results) == filter.Limit {
+			return &models.SearchResponse{Entries: results, Truncated: true}, nil
+		}
+		results = append(results, models.SearchResult{
+			Key:               entry.Key,
+			Secure:            entry.Secure,
+			TypeValidatorName: entry.TypeValidatorName,
+			Version:           entry.Version,
+		})
+	}
+
+	return &models.SearchResponse{Entries: results}, nil
+}
+
+// compileSearch matcher of the query and the literal prefix every matching key starts with.
+// Keys are stored lowercase, so glob and substring queries are lowercased too.
+func compileSearch(query string, mode models.SearchMode) (func(string) bool, string, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, "", fmt.Errorf("%w: q is required", domain.ErrInvalidSearch)
+	}
+
+	switch mode {
+	case models.SearchModeGlob:
+		pattern := strings.ToLower(strings.TrimPrefix(query, "/"))
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, "", fmt.Errorf("%w: %v", domain.ErrInvalidSearch, err)
+		}
+		prefix := pattern
+		if i := strings.IndexAny(pattern, `*?[\`); i >= 0 {
+			prefix = pattern[:i]
+		}
+		return func(key string) bool {
+			ok, _ := path.Match(pattern, key)
+			return ok
+		}, prefix, nil
+	case models.SearchModeSubstring:
+		substring := strings.ToLower(query)
+		return func(key string) bool { return strings.Contains(key, substring) }, "", nil
+	case models.SearchModeRegex:
+		re, err := regexp.Compile(query)
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: %v", domain.ErrInvalidSearch, err)
+		}
+		return re.MatchString, regexPrefix(query), nil
+	}
+
+	return nil, "", fmt.Errorf("%w: unknown mode '%s', use glob, substring or regex", domain.ErrInvalidSearch, mode)
+}
+
+// regexPrefix literal text right after a leading `^`, empty when the expression is not anchored
+func regexPrefix(expr string) string {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return ""
+	}
+	re = re.Simplify()
+	if re.Op != syntax.OpConcat || len(re.Sub) < 2 || re.Sub[0].Op != syntax.OpBeginText {
+		return ""
+	}
+	if literal := re.Sub[1]; literal.Op == syntax.OpLiteral && literal.Flags&syntax.FoldCase == 0 {
+		return string(literal.Rune)
+	}
+	return ""
+}
@@ -0,0 +1,138 @@
+package usecases
+
+import (
+	"context"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// mockKeyIndex SearchKeys over a sorted list of full keys, records the prefixes read
+type mockKeyIndex struct {
+	mockEntryAdapter
+	entries  []models.Entry
+	prefixes []string
+}
+
+func (m *mockKeyIndex) SearchKeys(_ context.Context, prefix string) ([]models.Entry, error) {
+	m.prefixes = append(m.prefixes, prefix)
+	entries := make([]models.Entry, 0)
+	for _, entry := range m.entries {
+		if strings.HasPrefix(entry.Key, prefix) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func newSearchFixture() (*SearchUseCase, *mockKeyIndex) {
+	index := &mockKeyIndex{entries: []models.Entry{
+		{Key: "development/myapp/db_host", Version: 1},
+		{Key: "global/api_url", TypeValidatorName: "url-https", Version: 2},
+		{Key: "production/billing/db_host", Version: 1},
+		{Key: "production/myapp/db/password", Secure: true, Version: 4},
+		{Key: "production/myapp/db_host", Version: 3},
+		{Key: "production/myapp/db_port", TypeValidatorName: "port", Version: 1},
+	}}
+	return NewSearchUseCase(index, zap.NewNop()), index
+}
+
+func searchKeys(result *models.SearchResponse) []string {
+	keys := make([]string, 0, len(result.Entries))
+	for _, entry := range result.Entries {
+		keys = append(keys, entry.Key)
+	}
+	return keys
+}
+
+func TestSearchUseCase_Modes(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter models.SearchFilter
+		prefix string
+		keys   []string
+	}{
+		{
+			name:   "glob does not cross folders",
+			filter: models.SearchFilter{Query: "production/*/db_host", Mode: models.SearchModeGlob},
+			prefix: "production/",
+			keys:   []string{"production/billing/db_host", "production/myapp/db_host"},
+		},
+		{
+			name:   "glob is case insensitive like the stored keys",
+			filter: models.SearchFilter{Query: "Production/MyApp/DB_*", Mode: models.SearchModeGlob},
+			prefix: "production/myapp/db_",
+			keys:   []string{"production/myapp/db_host", "production/myapp/db_port"},
+		},
+		{
+			name:   "substring reads the whole index",
+			filter: models.SearchFilter{Query: "db_host", Mode: models.SearchModeSubstring},
+			prefix: "",
+			keys:   []string{"development/myapp/db_host", "production/billing/db_host", "production/myapp/db_host"},
+		},
+		{
+			name:   "anchored regex reads its literal prefix",
+			filter: models.SearchFilter{Query: `^production/myapp/db_(host|port)$`, Mode: models.SearchModeRegex},
+			prefix: "production/myapp/db_",
+			keys:   []string{"production/myapp/db_host", "production/myapp/db_port"},
+		},
+		{
+			name:   "unanchored regex",
+			filter: models.SearchFilter{Query: `app/db`, Mode: models.SearchModeRegex},
+			prefix: "",
+			keys:   []string{"development/myapp/db_host", "production/myapp/db/password", "production/myapp/db_host", "production/myapp/db_port"},
+		},
+		{
+			name:   "secure filter",
+			filter: models.SearchFilter{Query: "production", Mode: models.SearchModeSubstring, Secure: new(bool)},
+			prefix: "",
+			keys:   []string{"production/billing/db_host", "production/myapp/db_host", "production/myapp/db_port"},
+		},
+		{
+			name:   "type validator filter",
+			filter: models.SearchFilter{Query: "*/*", Mode: models.SearchModeGlob, TypeValidatorName: "url-https"},
+			prefix: "",
+			keys:   []string{"global/api_url"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, index := newSearchFixture()
+
+			result, err := uc.Search(context.Background(), tt.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.keys, searchKeys(result))
+			assert.False(t, result.Truncated)
+			assert.Equal(t, []string{tt.prefix}, index.prefixes)
+		})
+	}
+}
+
+func TestSearchUseCase_Limit(t *testing.T) {
+	uc, _ := newSearchFixture()
+
+	result, err := uc.Search(context.Background(), models.SearchFilter{Query: "production/", Mode: models.SearchModeSubstring, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"production/billing/db_host", "production/myapp/db/password"}, searchKeys(result))
+	assert.True(t, result.Truncated)
+}
+
+func TestSearchUseCase_InvalidQuery(t *testing.T) {
+	uc, _ := newSearchFixture()
+
+	for _, filter := range []models.SearchFilter{
+		{Query: "", Mode: models.SearchModeSubstring},
+		{Query: "production/[", Mode: models.SearchModeGlob},
+		{Query: "(", Mode: models.SearchModeRegex},
+		{Query: "db", Mode: "fuzzy"},
+	} {
+		_, err := uc.Search(context.Background(), filter)
+		assert.ErrorIs(t, err, domain.ErrInvalidSearch, "query %q mode %q", filter.Query, filter.Mode)
+	}
+}
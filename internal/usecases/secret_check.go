@@ -0,0 +1,105 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+	"sort"
+
+	"go.uber.org/zap"
+)
+
+// SecretCheckUseCase compares the secrets in the secret store with their declared type validator
+type SecretCheckUseCase struct {
+	secretAdapter        domain.SecretAdapter
+	entryAdapter         domain.EntryAdapter
+	typeValidatorAdapter domain.TypeValidatorAdapter
+	logger               *zap.Logger
+}
+
+func NewSecretCheckUseCase(
+	secretAdapter domain.SecretAdapter,
+	entryAdapter domain.EntryAdapter,
+	typeValidatorAdapter domain.TypeValidatorAdapter,
+	logger *zap.Logger,
+) *SecretCheckUseCase {
+	return &SecretCheckUseCase{
+		secretAdapter:        secretAdapter,
+		entryAdapter:         entryAdapter,
+		typeValidatorAdapter: typeValidatorAdapter,
+		logger:               logger,
+	}
+}
+
+// Check validates the decrypted value of every secret under the prefix against the validator
+// declared in the secret store, and flags secrets whose entry is gone or declares another validator.
+// Values are never part of the report.
+func (uc *SecretCheckUseCase) Check(ctx context.Context, prefix string) (*models.SecretCheckReport, error) {
+	secrets, err := uc.secretAdapter.ListSecrets(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(secrets, func(i, j int) bool { return secrets[i].Name < secrets[j].Name })
+
+	report := &models.SecretCheckReport{
+		Prefix:  prefix,
+		Checked: len(secrets),
+		Issues:  make([]models.SecretCheckIssue, 0),
+	}
+	validators := map[string]*models.TypeValidator{}
+
+	for _, secret := range secrets {
+		issue := func(problem, detail string) {
+			report.Issues = append(report.Issues, models.SecretCheckIssue{
+				Key:               secret.Key,
+				Parameter:         secret.Name,
+				TypeValidatorName: secret.TypeValidatorName,
+				Problem:           problem,
+				Detail:            detail,
+			})
+		}
+
+		entry, err := uc.entryAdapter.Retrieve(ctx, secret.Key)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case entry == nil || !entry.Secure:
+			issue(models.SecretIssueOrphan, "no secure entry references the parameter")
+		case entry.TypeValidatorName != secret.TypeValidatorName:
+			issue(models.SecretIssueTypeMismatch,
+				fmt.Sprintf("entry declares '%s', parameter declares '%s'", entry.TypeValidatorName, secret.TypeValidatorName))
+		}
+
+		if secret.TypeValidatorName == "" {
+			continue
+		}
+
+		validator, ok := validators[secret.TypeValidatorName]
+		if !ok {
+			// built-in validators are resolved by the adapter
+			validator, err = uc.typeValidatorAdapter.Retrieve(ctx, secret.TypeValidatorName)
+			if err != nil {
+				return nil, err
+			}
+			validators[secret.TypeValidatorName] = validator
+		}
+		if validator == nil {
+			issue(models.SecretIssueMissingValidator, fmt.Sprintf("type validator '%s' not found", secret.TypeValidatorName))
+			continue
+		}
+
+		// fixed detail, the validator error could describe the value
+		if err := models.ValidateValue(validator, secret.Value); err != nil {
+			issue(models.SecretIssueInvalidValue, fmt.Sprintf("value does not satisfy type validator '%s'", secret.TypeValidatorName))
+		}
+	}
+
+	if len(report.Issues) > 0 {
+		uc.logger.Warn("secret consistency issues", zap.String("prefix", prefix), zap.Int("issues", len(report.Issues)))
+	}
+
+	return report, nil
+}
@@ -0,0 +1,91 @@
+package usecases
+
+import (
+	"context"
+	"nbox/internal/domain/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestSecretCheckUseCase_Check(t *testing.T) {
+	entries := &mockEntryAdapterWithStore{store: map[string]models.Entry{
+		"production/app/port":    {Key: "production/app/port", Secure: true, TypeValidatorName: "number"},
+		"production/app/pin":     {Key: "production/app/pin", Secure: true, TypeValidatorName: "pin"},
+		"production/app/retyped": {Key: "production/app/retyped", Secure: true, TypeValidatorName: "json"},
+		"production/app/legacy":  {Key: "production/app/legacy", Secure: true, TypeValidatorName: "gone"},
+		"production/app/plain":   {Key: "production/app/plain", Secure: true},
+	}}
+	secrets := &mockSecretAdapter{
+		listFunc: func(_ context.Context, prefix string) ([]models.SecretParameter, error) {
+			assert.Equal(t, "production/app", prefix)
+			return []models.SecretParameter{
+				{Name: "/production/app/port", Key: "production/app/port", TypeValidatorName: "number", Value: "not-a-number"},
+				{Name: "/production/app/pin", Key: "production/app/pin", TypeValidatorName: "pin", Value: "1234"},
+				{Name: "/production/app/retyped", Key: "production/app/retyped", TypeValidatorName: "number", Value: "42"},
+				{Name: "/production/app/legacy", Key: "production/app/legacy", TypeValidatorName: "gone", Value: "x"},
+				{Name: "/production/app/plain", Key: "production/app/plain", Value: "anything"},
+				{Name: "/production/app/deleted", Key: "production/app/deleted", Value: "old"},
+			}, nil
+		},
+	}
+	validators := &mockTypeValidatorAdapter{
+		retrieveFunc: func(_ context.Context, name string) (*models.TypeValidator, error) {
+			if validator, ok := models.BuiltInValidators[name]; ok {
+				return &validator, nil
+			}
+			if name == "pin" {
+				return &models.TypeValidator{Name: "pin", Regex: `^[0-9]{4}$`}, nil
+			}
+			return nil, nil
+		},
+	}
+
+	uc := NewSecretCheckUseCase(secrets, entries, validators, zap.NewNop())
+
+	report, err := uc.Check(context.Background(), "production/app")
+	require.NoError(t, err)
+	assert.Equal(t, 6, report.Checked)
+
+	problems := map[string]string{}
+	for _, issue := range report.Issues {
+		problems[issue.Key] = issue.Problem
+	}
+	assert.Equal(t, map[string]string{
+		"production/app/port":    models.SecretIssueInvalidValue,
+		"production/app/retyped": models.SecretIssueTypeMismatch,
+		"production/app/legacy":  models.SecretIssueMissingValidator,
+		"production/app/deleted": models.SecretIssueOrphan,
+	}, problems)
+
+	for _, issue := range report.Issues {
+		assert.NotContains(t, issue.Detail, "not-a-number", "values must not leak into the report")
+	}
+}
+
+func TestSecretCheckUseCase_Check_RangeErrorDoesNotLeakValue(t *testing.T) {
+	maxPort := 65535.0
+	entries := &mockEntryAdapterWithStore{store: map[string]models.Entry{
+		"production/app/port": {Key: "production/app/port", Secure: true, TypeValidatorName: "port"},
+	}}
+	secrets := &mockSecretAdapter{
+		listFunc: func(_ context.Context, _ string) ([]models.SecretParameter, error) {
+			return []models.SecretParameter{
+				{Name: "/production/app/port", Key: "production/app/port", TypeValidatorName: "port", Value: "987654321"},
+			}, nil
+		},
+	}
+	validators := &mockTypeValidatorAdapter{
+		retrieveFunc: func(_ context.Context, _ string) (*models.TypeValidator, error) {
+			return &models.TypeValidator{Name: "port", Kind: models.KindInteger, Max: &maxPort}, nil
+		},
+	}
+
+	report, err := NewSecretCheckUseCase(secrets, entries, validators, zap.NewNop()).Check(context.Background(), "production/app")
+	require.NoError(t, err)
+	require.Len(t, report.Issues, 1)
+	assert.Equal(t, models.SecretIssueInvalidValue, report.Issues[0].Problem)
+	assert.NotContains(t, report.Issues[0].Detail, "987654321")
+}
@@ -0,0 +1,138 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"nbox/internal/application"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/norlis/httpgate/pkg/adapter/apidriven/middleware"
+	"go.uber.org/zap"
+)
+
+const maxRevealReasonLength = 256
+
+// SecretRevealUseCase returns plain secret values leaving an audit record and a secret.revealed event
+type SecretRevealUseCase struct {
+	secretAdapter domain.SecretAdapter
+	entryAdapter  domain.EntryAdapter
+	repository    domain.SecretAuditRepository
+	notifier      domain.EventNotifier
+	config        *application.Config
+	logger        *zap.Logger
+}
+
+func NewSecretRevealUseCase(
+	secretAdapter domain.SecretAdapter,
+	entryAdapter domain.EntryAdapter,
+	repository domain.SecretAuditRepository,
+	notifier domain.EventNotifier,
+	config *application.Config,
+	logger *zap.Logger,
+) *SecretRevealUseCase {
+	return &SecretRevealUseCase{
+		secretAdapter: secretAdapter,
+		entryAdapter:  entryAdapter,
+		repository:    repository,
+		notifier:      notifier,
+		config:        config,
+		logger:        logger,
+	}
+}
+
+// Reveal decrypts the secret of the key. The value is only returned once the audit record is
+// stored; a missing secret returns nil and is not audited.
+func (uc *SecretRevealUseCase) Reveal(ctx context.Context, key, reason string) (*models.Entry, error) {
+	return uc.reveal(ctx, key, reason, false, func(name string) (*models.Entry, error) {
+		return uc.secretAdapter.RetrieveSecretValue(ctx, name)
+	})
+}
+
+// RevealAt decrypts the version the secret had at the given time, audited as a previous value
+func (uc *SecretRevealUseCase) RevealAt(ctx context.Context, key, reason string, at time.Time) (*models.Entry, error) {
+	return uc.reveal(ctx, key, reason, true, func(name string) (*models.Entry, error) {
+		return uc.secretAdapter.RetrieveSecretValueAt(ctx, name, at)
+	})
+}
+
+func (uc *SecretRevealUseCase) reveal(ctx context.Context, key, reason string, previous bool, retrieve func(name string) (*models.Entry, error)) (*models.Entry, error) {
+	key = strings.TrimPrefix(key, "/")
+	reason = strings.TrimSpace(reason)
+
+	if err := uc.checkReason(reason); err != nil {
+		return nil, err
+	}
+
+	entry, err := retrieve(uc.secretName(ctx, key))
+	if err != nil || entry == nil {
+		return nil, err
+	}
+
+	reveal := models.SecretReveal{
+		ID:            uuid.NewString(),
+		Key:           key,
+		User:          "ghost",
+		TransactionId: middleware.TraceIdFromContext(ctx),
+		Reason:        reason,
+		RevealedAt:    time.Now().UTC(),
+		Previous:      previous,
+	}
+	if user, ok := application.UserFromContext(ctx); ok {
+		reveal.User = user.Name
+	}
+
+	if err = uc.repository.Save(ctx, reveal); err != nil {
+		uc.logger.Error("ErrSaveSecretReveal", zap.String("key", key), zap.Error(err))
+		return nil, fmt.Errorf("%w: %s", domain.ErrSecretAuditFailed, key)
+	}
+
+	uc.logger.Info("Secret revealed",
+		zap.String("key", key),
+		zap.String("user", reveal.User),
+		zap.String("transactionId", reveal.TransactionId),
+		zap.String("reason", reason),
+		zap.Bool("previous", previous),
+	)
+
+	uc.notifier.Dispatch(ctx, newEvent(ctx, domain.EventSecretRevealed, reveal))
+
+	return entry, nil
+}
+
+// secretName the reference of the entry tells the backend that owns the secret; keys without
+// secure entry are looked up as Parameter Store names
+func (uc *SecretRevealUseCase) secretName(ctx context.Context, key string) string {
+	entry, err := uc.entryAdapter.Retrieve(ctx, key)
+	if err != nil || entry == nil || !entry.Secure || entry.Value == "" {
+		return "/" + key
+	}
+	return secretName(entry.Value)
+}
+
+// List audit records, newest first
+func (uc *SecretRevealUseCase) List(ctx context.Context, filter models.SecretRevealFilter) ([]models.SecretReveal, error) {
+	filter.Key = strings.TrimPrefix(filter.Key, "/")
+	return uc.repository.List(ctx, filter)
+}
+
+// checkReason with NBOX_SECRET_REVEAL_REASONS the reason is required and must be one of them
+func (uc *SecretRevealUseCase) checkReason(reason string) error {
+	if len(reason) > maxRevealReasonLength {
+		return fmt.Errorf("%w: exceeds %d characters", domain.ErrInvalidRevealReason, maxRevealReasonLength)
+	}
+
+	allowed := uc.config.SecretRevealReasons
+	if len(allowed) == 0 || slices.Contains(allowed, reason) {
+		return nil
+	}
+
+	if reason == "" {
+		return fmt.Errorf("%w: a reason is required (%s)", domain.ErrInvalidRevealReason, strings.Join(allowed, ", "))
+	}
+	return fmt.Errorf("%w: %q is not one of %s", domain.ErrInvalidRevealReason, reason, strings.Join(allowed, ", "))
+}
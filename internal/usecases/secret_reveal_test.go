@@ -0,0 +1,155 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"nbox/internal/application"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type mockSecretAuditRepository struct {
+	reveals []models.SecretReveal
+	err     error
+}
+
+func (m *mockSecretAuditRepository) Save(_ context.Context, reveal models.SecretReveal) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.reveals = append(m.reveals, reveal)
+	return nil
+}
+
+func (m *mockSecretAuditRepository) List(_ context.Context, filter models.SecretRevealFilter) ([]models.SecretReveal, error) {
+	var reveals []models.SecretReveal
+	for _, reveal := range m.reveals {
+		if (filter.Key == "" || reveal.Key == filter.Key) && filter.Match(reveal) {
+			reveals = append(reveals, reveal)
+		}
+	}
+	return reveals, nil
+}
+
+func newRevealSecretAdapter() *mockSecretAdapter {
+	return &mockSecretAdapter{
+		retrieveFunc: func(_ context.Context, key string) (*models.Entry, error) {
+			if key != "/production/myapp/db_password" {
+				return nil, nil
+			}
+			return &models.Entry{Key: key, Value: "s3cr3t", Secure: true}, nil
+		},
+	}
+}
+
+func TestSecretRevealUseCase_Reveal(t *testing.T) {
+	repository := &mockSecretAuditRepository{}
+	notifier := &recordingNotifier{}
+	uc := NewSecretRevealUseCase(newRevealSecretAdapter(), &mockEntryAdapter{}, repository, notifier, &application.Config{}, zap.NewNop())
+
+	ctx := application.NewContextWithUser(context.Background(), application.User{Name: "jdoe"})
+	entry, err := uc.Reveal(ctx, "production/myapp/db_password", " incident-4521 ")
+	require.NoError(t, err)
+	require.NotNil(t, entry)
+	assert.Equal(t, "s3cr3t", entry.Value)
+
+	require.Len(t, repository.reveals, 1)
+	reveal := repository.reveals[0]
+	assert.Equal(t, "production/myapp/db_password", reveal.Key)
+	assert.Equal(t, "jdoe", reveal.User)
+	assert.Equal(t, "incident-4521", reveal.Reason)
+	assert.NotEmpty(t, reveal.ID)
+	assert.False(t, reveal.RevealedAt.IsZero())
+
+	require.Len(t, notifier.events, 1)
+	event := notifier.events[0]
+	assert.Equal(t, domain.EventSecretRevealed, event.Type)
+	assert.Equal(t, "jdoe", event.Username)
+
+	var payload models.SecretReveal
+	require.NoError(t, json.Unmarshal(event.Payload, &payload))
+	assert.Equal(t, reveal.ID, payload.ID)
+	assert.NotContains(t, string(event.Payload), "s3cr3t")
+}
+
+func TestSecretRevealUseCase_RevealNotFound(t *testing.T) {
+	repository := &mockSecretAuditRepository{}
+	notifier := &recordingNotifier{}
+	uc := NewSecretRevealUseCase(newRevealSecretAdapter(), &mockEntryAdapter{}, repository, notifier, &application.Config{}, zap.NewNop())
+
+	entry, err := uc.Reveal(context.Background(), "/production/myapp/missing", "")
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+	assert.Empty(t, repository.reveals)
+	assert.Empty(t, notifier.events)
+}
+
+func TestSecretRevealUseCase_AuditFailureHidesValue(t *testing.T) {
+	repository := &mockSecretAuditRepository{err: errors.New("table not found")}
+	notifier := &recordingNotifier{}
+	uc := NewSecretRevealUseCase(newRevealSecretAdapter(), &mockEntryAdapter{}, repository, notifier, &application.Config{}, zap.NewNop())
+
+	entry, err := uc.Reveal(context.Background(), "production/myapp/db_password", "")
+	assert.ErrorIs(t, err, domain.ErrSecretAuditFailed)
+	assert.Nil(t, entry)
+	assert.Empty(t, notifier.events)
+}
+
+func TestSecretRevealUseCase_Reasons(t *testing.T) {
+	config := &application.Config{SecretRevealReasons: []string{"incident", "deploy"}}
+
+	tests := []struct {
+		name    string
+		reason  string
+		wantErr bool
+	}{
+		{name: "allowed reason", reason: "incident"},
+		{name: "missing reason", reason: "", wantErr: true},
+		{name: "unknown reason", reason: "curiosity", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &mockSecretAuditRepository{}
+			uc := NewSecretRevealUseCase(newRevealSecretAdapter(), &mockEntryAdapter{}, repository, &recordingNotifier{}, config, zap.NewNop())
+
+			entry, err := uc.Reveal(context.Background(), "production/myapp/db_password", tt.reason)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, domain.ErrInvalidRevealReason)
+				assert.Nil(t, entry)
+				assert.Empty(t, repository.reveals)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, repository.reveals, 1)
+		})
+	}
+}
+
+func TestSecretRevealUseCase_ResolvesTheReferenceOfTheEntry(t *testing.T) {
+	var requested []string
+	secrets := &mockSecretAdapter{
+		retrieveFunc: func(_ context.Context, key string) (*models.Entry, error) {
+			requested = append(requested, key)
+			return &models.Entry{Key: key, Value: "s3cr3t", Secure: true}, nil
+		},
+	}
+	entries := &mockEntryAdapterWithStore{store: map[string]models.Entry{
+		"production/payments/api_key": {Key: "api_key", Value: "vault:production/payments/api_key", Secure: true},
+		"global/myapp/password":       {Key: "password", Value: "arn:aws:ssm:us-east-1:123:parameter/myapp/password", Secure: true},
+	}}
+	uc := NewSecretRevealUseCase(secrets, entries, &mockSecretAuditRepository{}, &recordingNotifier{}, &application.Config{}, zap.NewNop())
+
+	for _, key := range []string{"production/payments/api_key", "global/myapp/password", "production/unknown"} {
+		_, err := uc.Reveal(context.Background(), key, "")
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, []string{"vault:production/payments/api_key", "/myapp/password", "/production/unknown"}, requested)
+}
@@ -0,0 +1,40 @@
+package usecases
+
+import (
+	"context"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+	"path"
+)
+
+type templateUseCase struct {
+	store domain.TemplateAdapter
+}
+
+func NewTemplateUseCase(store domain.TemplateAdapter) domain.TemplateUseCase {
+	return &templateUseCase{store: store}
+}
+
+// UpsertBox stores the templates of every stage and reports which ones were new
+func (t *templateUseCase) UpsertBox(ctx context.Context, box *models.Box) models.TemplateChanges {
+	existing := make(map[string]bool, len(box.Stage))
+	for stageName, stage := range box.Stage {
+		exists, err := t.store.BoxExists(ctx, box.Service, stageName, stage.Template.Name)
+		existing[path.Join(box.Service, stageName, stage.Template.Name)] = err == nil && exists
+	}
+
+	changes := models.TemplateChanges{
+		Created: make([]string, 0),
+		Updated: make([]string, 0),
+	}
+
+	for _, stored := range t.store.UpsertBox(ctx, box) {
+		if existing[stored] {
+			changes.Updated = append(changes.Updated, stored)
+			continue
+		}
+		changes.Created = append(changes.Created, stored)
+	}
+
+	return changes
+}
@@ -0,0 +1,39 @@
+package usecases
+
+import (
+	"context"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+)
+
+// TemplateEvent payload of template.created and template.updated
+type TemplateEvent struct {
+	Service string   `json:"service"`
+	Paths   []string `json:"paths"`
+}
+
+type templateUseCaseWithEvents struct {
+	wrappedUseCase domain.TemplateUseCase
+	notifier       domain.EventNotifier
+}
+
+func NewTemplateUseCaseWithEvents(uc domain.TemplateUseCase, notifier domain.EventNotifier) domain.TemplateUseCase {
+	return &templateUseCaseWithEvents{
+		wrappedUseCase: uc,
+		notifier:       notifier,
+	}
+}
+
+func (d *templateUseCaseWithEvents) UpsertBox(ctx context.Context, box *models.Box) models.TemplateChanges {
+	changes := d.wrappedUseCase.UpsertBox(ctx, box)
+
+	if len(changes.Created) > 0 {
+		d.notifier.Dispatch(ctx, newEvent(ctx, domain.EventTemplateCreated, TemplateEvent{Service: box.Service, Paths: changes.Created}))
+	}
+
+	if len(changes.Updated) > 0 {
+		d.notifier.Dispatch(ctx, newEvent(ctx, domain.EventTemplateUpdated, TemplateEvent{Service: box.Service, Paths: changes.Updated}))
+	}
+
+	return changes
+}
@@ -0,0 +1,88 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type mockTemplateAdapterWithStore struct {
+	mockTemplateAdapter
+	existing map[string]bool
+}
+
+func (m *mockTemplateAdapterWithStore) UpsertBox(_ context.Context, box *models.Box) []string {
+	result := make([]string, 0)
+	for stageName, stage := range box.Stage {
+		result = append(result, path.Join(box.Service, stageName, stage.Template.Name))
+	}
+	return result
+}
+
+func (m *mockTemplateAdapterWithStore) BoxExists(_ context.Context, service string, stage string, template string) (bool, error) {
+	return m.existing[path.Join(service, stage, template)], nil
+}
+
+func TestTemplateUseCaseWithEvents_UpsertBox(t *testing.T) {
+	store := &mockTemplateAdapterWithStore{existing: map[string]bool{"widget-x/development/task.json": true}}
+	notifier := &recordingNotifier{}
+	uc := NewTemplateUseCaseWithEvents(NewTemplateUseCase(store), notifier)
+
+	changes := uc.UpsertBox(context.Background(), &models.Box{
+		Service: "widget-x",
+		Stage: map[string]models.Stage{
+			"development": {Template: models.Template{Name: "task.json", Value: "{}"}},
+			"production":  {Template: models.Template{Name: "task.json", Value: "{}"}},
+		},
+	})
+
+	assert.Equal(t, []string{"widget-x/production/task.json"}, changes.Created)
+	assert.Equal(t, []string{"widget-x/development/task.json"}, changes.Updated)
+	assert.Len(t, changes.Paths(), 2)
+
+	require.Len(t, notifier.events, 2)
+	events := map[domain.EventType]TemplateEvent{}
+	for _, event := range notifier.events {
+		var payload TemplateEvent
+		require.NoError(t, json.Unmarshal(event.Payload, &payload))
+		events[event.Type] = payload
+	}
+	assert.Equal(t, []string{"widget-x/production/task.json"}, events[domain.EventTemplateCreated].Paths)
+	assert.Equal(t, []string{"widget-x/development/task.json"}, events[domain.EventTemplateUpdated].Paths)
+}
+
+type mockEntryAdapterWithDelete struct {
+	mockEntryAdapter
+	deleted []string
+}
+
+func (m *mockEntryAdapterWithDelete) Delete(_ context.Context, _ string) ([]string, error) {
+	return m.deleted, nil
+}
+
+func TestEntryDeleteUseCaseWithEvents_Delete(t *testing.T) {
+	adapter := &mockEntryAdapterWithDelete{deleted: []string{"development/myapp/db_host", "development/myapp/db_port"}}
+	notifier := &recordingNotifier{}
+	uc := NewEntryDeleteUseCaseWithEvents(NewEntryDeleteUseCase(adapter, &mockSecretAdapter{}, NewPathUseCase(), zap.NewNop()), notifier)
+
+	deleted, secrets, err := uc.Delete(context.Background(), "development/myapp")
+	require.NoError(t, err)
+	assert.Equal(t, adapter.deleted, deleted)
+	assert.Empty(t, secrets)
+
+	require.Len(t, notifier.events, 1)
+	assert.Equal(t, domain.EventEntryDeleted, notifier.events[0].Type)
+
+	var payload DeletedEvent
+	require.NoError(t, json.Unmarshal(notifier.events[0].Payload, &payload))
+	assert.Equal(t, "development/myapp", payload.Key)
+	assert.Equal(t, adapter.deleted, payload.Deleted)
+	assert.Equal(t, "ghost", notifier.events[0].Username)
+}
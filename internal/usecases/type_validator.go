@@ -0,0 +1,173 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+	"sort"
+
+	"go.uber.org/zap"
+)
+
+// TypeValidatorUseCase keeps the stored entries consistent with the custom type validators
+type TypeValidatorUseCase struct {
+	typeValidatorAdapter domain.TypeValidatorAdapter
+	entryAdapter         domain.EntryAdapter
+	secretAdapter        domain.SecretAdapter
+	logger               *zap.Logger
+}
+
+func NewTypeValidatorUseCase(
+	typeValidatorAdapter domain.TypeValidatorAdapter,
+	entryAdapter domain.EntryAdapter,
+	secretAdapter domain.SecretAdapter,
+	logger *zap.Logger,
+) *TypeValidatorUseCase {
+	return &TypeValidatorUseCase{
+		typeValidatorAdapter: typeValidatorAdapter,
+		entryAdapter:         entryAdapter,
+		secretAdapter:        secretAdapter,
+		logger:               logger,
+	}
+}
+
+// Upsert checks every entry referencing the validator against the new definition before
+// storing it. With strict the validator is not stored when any value fails, and the report is
+// returned with ErrRevalidationFailed.
+func (uc *TypeValidatorUseCase) Upsert(ctx context.Context, validator models.TypeValidator, strict bool) (*models.RevalidationReport, error) {
+	if err := validator.Check(); err != nil {
+		return nil, err
+	}
+
+	// built-in validators can't be changed, the adapter rejects them
+	if _, isBuiltIn := models.BuiltInValidators[validator.Name]; isBuiltIn {
+		return nil, uc.typeValidatorAdapter.Upsert(ctx, validator)
+	}
+
+	report, err := uc.Revalidate(ctx, validator)
+	if err != nil {
+		return nil, err
+	}
+
+	if strict && len(report.Invalid) > 0 {
+		return report, fmt.Errorf("%w: %d of %d entries are invalid", domain.ErrRevalidationFailed, len(report.Invalid), report.Checked)
+	}
+
+	if err = uc.typeValidatorAdapter.Upsert(ctx, validator); err != nil {
+		return nil, err
+	}
+	report.Applied = true
+
+	if len(report.Invalid) > 0 {
+		uc.logger.Warn("entries no longer match the type validator",
+			zap.String("validator", validator.Name), zap.Int("invalid", len(report.Invalid)))
+	}
+
+	return report, nil
+}
+
+// Revalidate validates the current value of every entry referencing the validator with the
+// given definition. Secure values are read from the secret store.
+func (uc *TypeValidatorUseCase) Revalidate(ctx context.Context, validator models.TypeValidator) (*models.RevalidationReport, error) {
+	entries, err := uc.entryAdapter.ListByTypeValidator(ctx, validator.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	// an invalid schema is reported by every value
+	_ = validator.Compile()
+
+	report := &models.RevalidationReport{
+		Validator: validator.Name,
+		Checked:   len(entries),
+		Invalid:   make([]models.RevalidationIssue, 0),
+		Skipped:   make([]models.RevalidationIssue, 0),
+	}
+
+	for _, entry := range entries {
+		value := entry.Value
+		if entry.Secure {
+			secret, err := uc.secretAdapter.RetrieveSecretValue(ctx, secretName(entry.Value))
+			if err != nil || secret == nil {
+				reason := "secret value not found"
+				if err != nil {
+					uc.logger.Warn("ErrRetrieveSecretValue", zap.String("key", entry.Key), zap.Error(err))
+					reason = "secret value could not be read"
+				}
+				report.Skipped = append(report.Skipped, models.RevalidationIssue{Key: entry.Key, Error: reason})
+				continue
+			}
+			value = secret.Value
+		}
+
+		if err := models.ValidateValue(&validator, value); err != nil {
+			reason := err.Error()
+			if entry.Secure {
+				// validator errors may quote the decrypted value
+				reason = fmt.Sprintf("secure value does not satisfy type validator '%s'", validator.Name)
+			}
+			report.Invalid = append(report.Invalid, models.RevalidationIssue{Key: entry.Key, Error: reason})
+		}
+	}
+
+	return report, nil
+}
+
+// Usage entries referencing the validator, sorted by key
+func (uc *TypeValidatorUseCase) Usage(ctx context.Context, name string) (*models.TypeValidatorUsage, error) {
+	entries, err := uc.entryAdapter.ListByTypeValidator(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+
+	usage := &models.TypeValidatorUsage{
+		Validator: name,
+		Count:     len(entries),
+		Entries:   make([]models.TypeValidatorUsageEntry, 0, len(entries)),
+	}
+	for _, entry := range entries {
+		usage.Entries = append(usage.Entries, models.TypeValidatorUsageEntry{
+			Key:     entry.Key,
+			Secure:  entry.Secure,
+			Version: entry.Version,
+		})
+	}
+
+	return usage, nil
+}
+
+// Delete removes the validator. While entries still reference it the delete is rejected with
+// ErrTypeValidatorInUse and their keys, unless force is set.
+func (uc *TypeValidatorUseCase) Delete(ctx context.Context, name string, force bool) ([]string, error) {
+	if _, isBuiltIn := models.BuiltInValidators[name]; isBuiltIn {
+		return nil, uc.typeValidatorAdapter.Delete(ctx, name)
+	}
+
+	usage, err := uc.Usage(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, usage.Count)
+	for _, entry := range usage.Entries {
+		keys = append(keys, entry.Key)
+	}
+
+	if len(keys) > 0 && !force {
+		return keys, fmt.Errorf("%w: %d entries use '%s'", domain.ErrTypeValidatorInUse, len(keys), name)
+	}
+
+	if err = uc.typeValidatorAdapter.Delete(ctx, name); err != nil {
+		return nil, err
+	}
+
+	if len(keys) > 0 {
+		uc.logger.Warn("type validator deleted while in use", zap.String("validator", name), zap.Strings("keys", keys))
+	}
+
+	return keys, nil
+}
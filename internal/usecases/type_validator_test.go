@@ -0,0 +1,164 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"nbox/internal/domain"
+	"nbox/internal/domain/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type mockEntryAdapterWithValidators struct {
+	mockEntryAdapter
+	entries []models.Entry
+}
+
+func (m *mockEntryAdapterWithValidators) ListByTypeValidator(_ context.Context, name string) ([]models.Entry, error) {
+	var entries []models.Entry
+	for _, entry := range m.entries {
+		if entry.TypeValidatorName == name {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func newTestTypeValidatorUseCase(stored *[]models.TypeValidator, deleted *[]string) *TypeValidatorUseCase {
+	validators := &mockTypeValidatorAdapter{
+		upsertFunc: func(_ context.Context, validator models.TypeValidator) error {
+			*stored = append(*stored, validator)
+			return nil
+		},
+		deleteFunc: func(_ context.Context, name string) error {
+			*deleted = append(*deleted, name)
+			return nil
+		},
+	}
+	entries := &mockEntryAdapterWithValidators{entries: []models.Entry{
+		{Key: "development/app/port", Value: "8080", TypeValidatorName: "port"},
+		{Key: "development/app/admin-port", Value: "80", TypeValidatorName: "port"},
+		{Key: "production/app/port", Value: "arn:aws:ssm:us-east-1:000000000000:parameter/production/app/port", Secure: true, TypeValidatorName: "port"},
+		{Key: "production/app/missing", Value: "/production/app/missing", Secure: true, TypeValidatorName: "port"},
+		{Key: "development/app/name", Value: "app", TypeValidatorName: "name"},
+	}}
+	secrets := &mockSecretAdapter{
+		retrieveFunc: func(_ context.Context, key string) (*models.Entry, error) {
+			if key == "/production/app/port" {
+				return &models.Entry{Key: key, Value: "443"}, nil
+			}
+			return nil, errors.New("parameter not found")
+		},
+	}
+	return NewTypeValidatorUseCase(validators, entries, secrets, zap.NewNop())
+}
+
+func TestTypeValidatorUseCase_Upsert(t *testing.T) {
+	var stored []models.TypeValidator
+	var deleted []string
+	uc := newTestTypeValidatorUseCase(&stored, &deleted)
+	tightened := models.TypeValidator{Name: "port", Regex: `^[0-9]{4,5}$`}
+
+	report, err := uc.Upsert(context.Background(), tightened, false)
+	require.NoError(t, err)
+	assert.True(t, report.Applied)
+	assert.Equal(t, 4, report.Checked)
+	assert.Equal(t, []models.RevalidationIssue{
+		{Key: "development/app/admin-port", Error: "value does not match type validator 'port'"},
+		{Key: "production/app/port", Error: "secure value does not satisfy type validator 'port'"},
+	}, report.Invalid)
+	assert.Equal(t, []models.RevalidationIssue{
+		{Key: "production/app/missing", Error: "secret value could not be read"},
+	}, report.Skipped)
+	assert.Len(t, stored, 1)
+}
+
+func TestTypeValidatorUseCase_RevalidateDoesNotLeakSecureValues(t *testing.T) {
+	entries := &mockEntryAdapterWithValidators{entries: []models.Entry{
+		{Key: "development/app/config", Value: `{"s3cr3t": true}`, TypeValidatorName: "config"},
+		{Key: "production/app/config", Value: "/production/app/config", Secure: true, TypeValidatorName: "config"},
+	}}
+	secrets := &mockSecretAdapter{
+		retrieveFunc: func(_ context.Context, key string) (*models.Entry, error) {
+			return &models.Entry{Key: key, Value: `{"s3cr3t": true}`}, nil
+		},
+	}
+	uc := NewTypeValidatorUseCase(&mockTypeValidatorAdapter{}, entries, secrets, zap.NewNop())
+	validator := models.TypeValidator{
+		Name:   "config",
+		Kind:   models.KindJSONSchema,
+		Schema: []byte(`{"type": "object", "additionalProperties": false}`),
+	}
+
+	report, err := uc.Revalidate(context.Background(), validator)
+	require.NoError(t, err)
+	assert.Equal(t, []models.RevalidationIssue{
+		{Key: "development/app/config", Error: "$.s3cr3t: property is not allowed"},
+		{Key: "production/app/config", Error: "secure value does not satisfy type validator 'config'"},
+	}, report.Invalid)
+}
+
+func TestTypeValidatorUseCase_UpsertStrict(t *testing.T) {
+	var stored []models.TypeValidator
+	var deleted []string
+	uc := newTestTypeValidatorUseCase(&stored, &deleted)
+
+	report, err := uc.Upsert(context.Background(), models.TypeValidator{Name: "port", Regex: `^[0-9]{4,5}$`}, true)
+	assert.ErrorIs(t, err, domain.ErrRevalidationFailed)
+	require.NotNil(t, report)
+	assert.False(t, report.Applied)
+	assert.Len(t, report.Invalid, 2)
+	assert.Empty(t, stored)
+
+	// every readable value still passes: the change is applied
+	report, err = uc.Upsert(context.Background(), models.TypeValidator{Name: "port", Regex: `^[0-9]+$`}, true)
+	require.NoError(t, err)
+	assert.True(t, report.Applied)
+	assert.Empty(t, report.Invalid)
+	assert.Len(t, stored, 1)
+
+	_, err = uc.Upsert(context.Background(), models.TypeValidator{Name: "port", Kind: "unknown"}, true)
+	assert.ErrorIs(t, err, models.ErrInvalidTypeValidator)
+}
+
+func TestTypeValidatorUseCase_Delete(t *testing.T) {
+	var stored []models.TypeValidator
+	var deleted []string
+	uc := newTestTypeValidatorUseCase(&stored, &deleted)
+
+	keys, err := uc.Delete(context.Background(), "name", false)
+	assert.ErrorIs(t, err, domain.ErrTypeValidatorInUse)
+	assert.Equal(t, []string{"development/app/name"}, keys)
+	assert.Empty(t, deleted)
+
+	keys, err = uc.Delete(context.Background(), "name", true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"development/app/name"}, keys)
+	assert.Equal(t, []string{"name"}, deleted)
+
+	keys, err = uc.Delete(context.Background(), "unused", false)
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+	assert.Equal(t, []string{"name", "unused"}, deleted)
+}
+
+func TestTypeValidatorUseCase_Usage(t *testing.T) {
+	var stored []models.TypeValidator
+	var deleted []string
+	uc := newTestTypeValidatorUseCase(&stored, &deleted)
+
+	usage, err := uc.Usage(context.Background(), "port")
+	require.NoError(t, err)
+	assert.Equal(t, "port", usage.Validator)
+	assert.Equal(t, 4, usage.Count)
+	assert.Equal(t, "development/app/admin-port", usage.Entries[0].Key)
+	assert.True(t, usage.Entries[3].Secure)
+
+	usage, err = uc.Usage(context.Background(), "unused")
+	require.NoError(t, err)
+	assert.Zero(t, usage.Count)
+	assert.Empty(t, usage.Entries)
+}
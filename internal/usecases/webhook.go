@@ -0,0 +1,94 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"nbox/internal/application"
+	"nbox/internal/domain"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// WebhookUseCase alta, baja y consulta de webhooks
+type WebhookUseCase struct {
+	repository domain.WebhookRepository
+	logger     *zap.Logger
+}
+
+func NewWebhookUseCase(repository domain.WebhookRepository, logger *zap.Logger) *WebhookUseCase {
+	return &WebhookUseCase{repository: repository, logger: logger}
+}
+
+// Create registers the webhook. When no secret is given one is generated; the response is the
+// only place where the secret is returned.
+func (uc *WebhookUseCase) Create(ctx context.Context, webhook domain.Webhook) (*domain.Webhook, error) {
+	target, err := url.Parse(webhook.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, fmt.Errorf("%w: url must be an absolute http(s) url", domain.ErrInvalidWebhook)
+	}
+
+	if len(webhook.Events) == 0 {
+		return nil, fmt.Errorf("%w: at least one event is required", domain.ErrInvalidWebhook)
+	}
+
+	for _, eventType := range webhook.Events {
+		if !eventType.IsValid() {
+			return nil, fmt.Errorf("%w: unknown event %s", domain.ErrInvalidWebhook, eventType)
+		}
+	}
+
+	if webhook.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err = rand.Read(secret); err != nil {
+			return nil, err
+		}
+		webhook.Secret = hex.EncodeToString(secret)
+	}
+
+	webhook.ID = uuid.NewString()
+	webhook.CreatedAt = time.Now().UTC()
+	webhook.CreatedBy = "ghost"
+	if user, ok := application.UserFromContext(ctx); ok {
+		webhook.CreatedBy = user.Name
+	}
+
+	if err = uc.repository.Create(ctx, webhook); err != nil {
+		return nil, err
+	}
+
+	uc.logger.Info("Webhook created",
+		zap.String("id", webhook.ID),
+		zap.String("url", webhook.URL),
+		zap.String("createdBy", webhook.CreatedBy),
+	)
+
+	return &webhook, nil
+}
+
+// List webhooks registrados, sin el secreto
+func (uc *WebhookUseCase) List(ctx context.Context) ([]domain.Webhook, error) {
+	webhooks, err := uc.repository.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+
+	return webhooks, nil
+}
+
+func (uc *WebhookUseCase) Delete(ctx context.Context, webhookID string) error {
+	return uc.repository.Delete(ctx, webhookID)
+}
+
+// FailedDeliveries dead letters del webhook, más recientes primero
+func (uc *WebhookUseCase) FailedDeliveries(ctx context.Context, webhookID string) ([]domain.WebhookDelivery, error) {
+	return uc.repository.ListFailedDeliveries(ctx, webhookID)
+}
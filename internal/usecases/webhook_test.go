@@ -0,0 +1,103 @@
+package usecases
+
+import (
+	"context"
+	"nbox/internal/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type mockWebhookRepository struct {
+	webhooks []domain.Webhook
+}
+
+func (m *mockWebhookRepository) FindByEventType(_ context.Context, _ domain.EventType) ([]domain.Webhook, error) {
+	return m.webhooks, nil
+}
+
+func (m *mockWebhookRepository) List(_ context.Context) ([]domain.Webhook, error) {
+	return append([]domain.Webhook(nil), m.webhooks...), nil
+}
+
+func (m *mockWebhookRepository) Create(_ context.Context, webhook domain.Webhook) error {
+	m.webhooks = append(m.webhooks, webhook)
+	return nil
+}
+
+func (m *mockWebhookRepository) Delete(_ context.Context, _ string) error {
+	return nil
+}
+
+func (m *mockWebhookRepository) SaveFailedDelivery(_ context.Context, _ domain.WebhookDelivery) error {
+	return nil
+}
+
+func (m *mockWebhookRepository) ListFailedDeliveries(_ context.Context, _ string) ([]domain.WebhookDelivery, error) {
+	return nil, nil
+}
+
+func TestWebhookUseCase_Create(t *testing.T) {
+	tests := []struct {
+		name    string
+		webhook domain.Webhook
+		wantErr bool
+	}{
+		{
+			name:    "valid webhook",
+			webhook: domain.Webhook{URL: "https://hooks.example.com/nbox", Events: []domain.EventType{domain.EventEntryActions}},
+		},
+		{
+			name:    "unsupported scheme",
+			webhook: domain.Webhook{URL: "ftp://hooks.example.com", Events: []domain.EventType{domain.EventEntryActions}},
+			wantErr: true,
+		},
+		{
+			name:    "without events",
+			webhook: domain.Webhook{URL: "https://hooks.example.com/nbox"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown event",
+			webhook: domain.Webhook{URL: "https://hooks.example.com/nbox", Events: []domain.EventType{"entry.unknown"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := NewWebhookUseCase(&mockWebhookRepository{}, zap.NewNop())
+
+			created, err := uc.Create(context.Background(), tt.webhook)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, domain.ErrInvalidWebhook)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotEmpty(t, created.ID)
+			assert.Len(t, created.Secret, 64)
+			assert.Equal(t, "ghost", created.CreatedBy)
+		})
+	}
+}
+
+func TestWebhookUseCase_ListHidesSecret(t *testing.T) {
+	repository := &mockWebhookRepository{}
+	uc := NewWebhookUseCase(repository, zap.NewNop())
+
+	_, err := uc.Create(context.Background(), domain.Webhook{
+		URL:    "https://hooks.example.com/nbox",
+		Events: []domain.EventType{domain.EventTemplateCreated},
+		Secret: "s3cr3t",
+	})
+	require.NoError(t, err)
+
+	webhooks, err := uc.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, webhooks, 1)
+	assert.Empty(t, webhooks[0].Secret)
+	assert.Equal(t, "s3cr3t", repository.webhooks[0].Secret)
+}
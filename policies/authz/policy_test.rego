@@ -165,6 +165,34 @@ test_viewer_prod_can_export_entries if {
 		with data.permissions as {"entries:read:export": {"patterns": ["^GET:/api/entry/export\\?(.*)"]}}
 }
 
+test_viewer_can_resolve_non_production_config if {
+	authz.allow
+		with input as {"payload": {"roles": ["viewer"]}, "action": "GET:/api/entry/resolve?service=myapp&stage=qa"}
+		with data.roles as {"viewer": {"permissions": ["entries:read:resolve:non_production"]}}
+		with data.permissions as {"entries:read:resolve:non_production": {"patterns": ["^GET:/api/entry/resolve\\?(.*&)?stage=(development|qa)(&.*)?$"]}}
+}
+
+test_viewer_cannot_resolve_production_config if {
+	not authz.allow
+		with input as {"payload": {"roles": ["viewer"]}, "action": "GET:/api/entry/resolve?service=myapp&stage=production"}
+		with data.roles as {"viewer": {"permissions": ["entries:read:resolve:non_production"]}}
+		with data.permissions as {"entries:read:resolve:non_production": {"patterns": ["^GET:/api/entry/resolve\\?(.*&)?stage=(development|qa)(&.*)?$"]}}
+}
+
+test_viewer_prod_can_search_keys if {
+	authz.allow
+		with input as {"payload": {"roles": ["viewer_prod"]}, "action": "GET:/api/entry/search?q=production/*/db_host&mode=glob"}
+		with data.roles as {"viewer_prod": {"permissions": ["entries:search"]}}
+		with data.permissions as {"entries:search": {"patterns": ["^GET:/api/entry/search\\?(.*)"]}}
+}
+
+test_viewer_cannot_search_keys if {
+	not authz.allow
+		with input as {"payload": {"roles": ["viewer"]}, "action": "GET:/api/entry/search?q=db_host"}
+		with data.roles as {"viewer": {"permissions": ["entries:read:prefix:non_production"]}}
+		with data.permissions as {"entries:read:prefix:non_production": {"patterns": ["^GET:/api/entry/prefix\\?v=(development|qa|global)/(.*)"]}}
+}
+
 test_viewer_prod_cannot_write_entries if {
 	not authz.allow
 		with input as {"payload": {"roles": ["viewer_prod"]}, "action": "POST:/api/entry"}
@@ -205,6 +233,72 @@ test_editor_can_write_entries if {
 		with data.permissions as {"entries:write": {"patterns": ["^POST:/api/entry$"]}}
 }
 
+test_editor_can_write_entries_atomically if {
+	authz.allow
+		with input as {"payload": {"roles": ["editor"]}, "action": "POST:/api/entry?atomic=true"}
+		with data.roles as {"editor": {"permissions": ["entries:write"]}}
+		with data.permissions as {"entries:write": {"patterns": ["^POST:/api/entry(\\?.*)?$"]}}
+}
+
+test_entries_write_pattern_does_not_grant_import if {
+	not authz.allow
+		with input as {"payload": {"roles": ["editor"]}, "action": "POST:/api/entry/import?strategy=overwrite"}
+		with data.roles as {"editor": {"permissions": ["entries:write"]}}
+		with data.permissions as {"entries:write": {"patterns": ["^POST:/api/entry(\\?.*)?$"]}}
+}
+
+test_editor_can_import_entries if {
+	authz.allow
+		with input as {"payload": {"roles": ["editor"]}, "action": "POST:/api/entry/import?strategy=skip&dry_run=true"}
+		with data.roles as {"editor": {"permissions": ["entries:write:import"]}}
+		with data.permissions as {"entries:write:import": {"patterns": ["^POST:/api/entry/import(\\?.*)?$"]}}
+}
+
+test_editor_can_read_the_revisions_to_rollback if {
+	authz.allow
+		with input as {"payload": {"roles": ["editor"]}, "action": "GET:/api/track/key?v=production/app/host"}
+		with data.roles as {"editor": {"permissions": ["tracking:read", "tracking:rollback"]}}
+		with data.permissions as {"tracking:read": {"patterns": ["^GET:/api/track/key\\?v=(.*)"]}}
+}
+
+test_editor_can_rollback_entries if {
+	authz.allow
+		with input as {"payload": {"roles": ["editor"]}, "action": "POST:/api/track/key/rollback"}
+		with data.roles as {"editor": {"permissions": ["tracking:rollback"]}}
+		with data.permissions as {"tracking:rollback": {"patterns": ["^POST:/api/track/key/rollback$"]}}
+}
+
+test_viewer_can_subscribe_to_events if {
+	authz.allow
+		with input as {"payload": {"roles": ["viewer"]}, "action": "GET:/api/events?types=entry.upsert&prefix=development/app/"}
+		with data.roles as {"viewer": {"permissions": ["events:subscribe"]}}
+		with data.permissions as {"events:subscribe": {"patterns": ["^GET:/api/events(\\?.*)?$"]}}
+}
+
+test_anonymous_cannot_subscribe_to_events if {
+	not authz.allow
+		with input as {"payload": {"roles": []}, "action": "GET:/api/events"}
+		with data.roles as {"anonymous": {"permissions": ["public:health"]}}
+		with data.permissions as {"public:health": {"patterns": ["^GET:/health$"]}}
+}
+
+test_integrations_can_manage_webhooks if {
+	authz.allow
+		with input as {"payload": {"roles": ["integrations"]}, "action": "DELETE:/api/webhook/0b7c1f2e"}
+		with data.roles as {"integrations": {"permissions": ["webhooks:read", "webhooks:write"]}}
+		with data.permissions as {
+			"webhooks:read": {"patterns": ["^GET:/api/webhook(/[^/]+/deliveries)?$"]},
+			"webhooks:write": {"patterns": ["^POST:/api/webhook$", "^DELETE:/api/webhook/[^/]+$"]},
+		}
+}
+
+test_editor_cannot_manage_webhooks if {
+	not authz.allow
+		with input as {"payload": {"roles": ["editor"]}, "action": "POST:/api/webhook"}
+		with data.roles as {"editor": {"permissions": ["entries:write"]}}
+		with data.permissions as {"entries:write": {"patterns": ["^POST:/api/entry$"]}}
+}
+
 test_editor_can_read_entries_key if {
 	authz.allow
 		with input as {"payload": {"roles": ["editor"]}, "action": "GET:/api/entry/key?v=production/app/config"}
@@ -264,6 +358,44 @@ test_maintainer_can_delete_entries if {
 		with data.permissions as {"entries:delete": {"patterns": ["^DELETE:/api/entry/key\\?v=(.*)"]}}
 }
 
+test_maintainer_can_retype_entries if {
+	authz.allow
+		with input as {"payload": {"roles": ["maintainer"]}, "action": "POST:/api/entry/retype"}
+		with data.roles as {"maintainer": {"permissions": ["entries:delete", "entries:retype"]}}
+		with data.permissions as {"entries:retype": {"patterns": ["^POST:/api/entry/retype$"]}}
+}
+
+test_maintainer_can_check_secrets if {
+	authz.allow
+		with input as {"payload": {"roles": ["maintainer"]}, "action": "GET:/api/entry/secret-check?prefix=production/app"}
+		with data.roles as {"maintainer": {"permissions": ["secrets:check"]}}
+		with data.permissions as {"secrets:check": {"patterns": ["^GET:/api/entry/secret-check(\\?.*)?$"]}}
+}
+
+test_maintainer_can_reconcile_secrets if {
+	authz.allow
+		with input as {"payload": {"roles": ["maintainer"]}, "action": "POST:/api/entry/reconcile?prefix=production/app"}
+		with data.roles as {"maintainer": {"permissions": ["secrets:check", "secrets:reconcile"]}}
+		with data.permissions as {"secrets:reconcile": {"patterns": ["^POST:/api/entry/reconcile(\\?.*)?$"]}}
+}
+
+test_secret_check_does_not_repair if {
+	not authz.allow
+		with input as {"payload": {"roles": ["maintainer"]}, "action": "POST:/api/entry/reconcile"}
+		with data.roles as {"maintainer": {"permissions": ["secrets:check"]}}
+		with data.permissions as {"secrets:check": {"patterns": ["^GET:/api/entry/secret-check(\\?.*)?$", "^GET:/api/entry/reconcile(\\?.*)?$"]}}
+}
+
+test_editor_cannot_retype_entries if {
+	not authz.allow
+		with input as {"payload": {"roles": ["editor"]}, "action": "POST:/api/entry/retype"}
+		with data.roles as {"editor": {"permissions": ["entries:write"]}}
+		with data.permissions as {
+			"entries:write": {"patterns": ["^POST:/api/entry$"]},
+			"entries:retype": {"patterns": ["^POST:/api/entry/retype$"]},
+		}
+}
+
 test_maintainer_cannot_write_entries if {
 	not authz.allow
 		with input as {"payload": {"roles": ["maintainer"]}, "action": "POST:/api/entry"}
@@ -480,4 +612,38 @@ test_permission_regex_no_match_with_extra_path if {
 		with input as {"payload": {"roles": ["editor"]}, "action": "POST:/api/entry/extra"}
 		with data.roles as {"editor": {"permissions": ["entries:write"]}}
 		with data.permissions as {"entries:write": {"patterns": ["^POST:/api/entry$"]}}
-}
\ No newline at end of file
+}
+test_auditor_can_read_secret_reveals if {
+	authz.allow
+		with input as {"payload": {"roles": ["auditor"]}, "action": "GET:/api/audit/secret-reveals?key=production/app/db_password"}
+		with data.roles as {"auditor": {"permissions": ["audit:read"]}}
+		with data.permissions as {"audit:read": {"patterns": ["^GET:/api/audit/secret-reveals(\\?.*)?$"]}}
+}
+
+test_secrets_reader_cannot_read_secret_reveals if {
+	not authz.allow
+		with input as {"payload": {"roles": ["secrets_reader"]}, "action": "GET:/api/audit/secret-reveals"}
+		with data.roles as {"secrets_reader": {"permissions": ["secrets:read:value"]}}
+		with data.permissions as {
+			"secrets:read:value": {"patterns": ["^GET:/api/entry/secret-value\\?v=(.*)"]},
+			"audit:read": {"patterns": ["^GET:/api/audit/secret-reveals(\\?.*)?$"]},
+		}
+}
+
+test_maintainer_can_rotate_secrets if {
+	authz.allow
+		with input as {"payload": {"roles": ["maintainer"]}, "action": "POST:/api/rotation/rotate?v=production/app/db_password"}
+		with data.roles as {"maintainer": {"permissions": ["rotation:write"]}}
+		with data.permissions as {"rotation:write": {"patterns": ["^POST:/api/rotation$", "^POST:/api/rotation/rotate\\?v=(.*)"]}}
+}
+
+test_maintainer_cannot_read_previous_secret if {
+	not authz.allow
+		with input as {"payload": {"roles": ["maintainer"]}, "action": "GET:/api/rotation/previous?v=production/app/db_password"}
+		with data.roles as {"maintainer": {"permissions": ["rotation:read", "rotation:write"]}}
+		with data.permissions as {
+			"rotation:read": {"patterns": ["^GET:/api/rotation$", "^GET:/api/rotation/key\\?v=(.*)"]},
+			"rotation:write": {"patterns": ["^POST:/api/rotation$", "^POST:/api/rotation/rotate\\?v=(.*)"]},
+			"secrets:read:previous": {"patterns": ["^GET:/api/rotation/previous\\?v=(.*)"]},
+		}
+}